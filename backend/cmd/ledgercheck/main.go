@@ -0,0 +1,57 @@
+// ledgercheck сверяет главную книгу: каждая запись сбалансирована, сумма всех движений равна нулю,
+// остатки счетов клиентов и основной долг по долям совпадают с проводками.
+// Печатает отчёт в JSON; при расхождениях завершается с кодом 1
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	"github.com/Arlandaren/easyfund/internal/repos"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", 5*time.Minute, "максимальная длительность сверки")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := config.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	ledger := services.NewLedgerService(repos.NewLedgerRepository(db), repos.NewTxManager(db))
+	report, err := ledger.Check(ctx)
+	if err != nil {
+		log.Fatalf("Ledger check failed: %v", err)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+
+	if !report.OK() {
+		fmt.Fprintf(os.Stderr, "ledger is inconsistent: %d unbalanced entries, %d balance mismatches, trial balance %s\n",
+			len(report.UnbalancedEntries), len(report.Mismatches), report.TrialBalance)
+		os.Exit(1)
+	}
+}
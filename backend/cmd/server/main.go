@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
@@ -11,8 +13,10 @@ import (
 	"github.com/Arlandaren/easyfund/internal/handlers"
 	"github.com/Arlandaren/easyfund/internal/logger"
 	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/openbanking"
 	"github.com/Arlandaren/easyfund/internal/repos"
 	"github.com/Arlandaren/easyfund/internal/services"
+	"github.com/Arlandaren/easyfund/internal/storage"
 )
 
 func main() {
@@ -33,10 +37,15 @@ func main() {
 	}
 	defer db.Close()
 
+	// Хранилище документов
+	blobStore, err := storage.NewBlobStore(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to initialize document storage: %v", err)
+	}
+
 	// Gin
 	router := gin.Default()
 
-
 	// CORSMiddleware: используем без аргументов (см. internal/middleware/cors.go ниже)
 	router.Use(middleware.CORSMiddleware())
 
@@ -47,22 +56,103 @@ func main() {
 	paymentRepo := repos.NewLoanPaymentRepository(db)
 	transactionRepo := repos.NewTransactionRepository(db)
 	applicationRepo := repos.NewCreditApplicationRepository(db)
+	scheduleRepo := repos.NewLoanScheduleRepository(db)
+	bankRepo := repos.NewBankRepository(db)
+	txManager := repos.NewTxManager(db)
+	accrualRepo := repos.NewInterestAccrualRepository(db)
+	historyRepo := repos.NewStatusHistoryRepository(db)
+	settingsRepo := repos.NewLendingSettingsRepository(db)
+	offerRepo := repos.NewOfferRepository(db)
+	participantRepo := repos.NewParticipantRepository(db)
+	documentRepo := repos.NewDocumentRepository(db)
+	scoringRepo := repos.NewScoringRepository(db)
+	affordabilityRepo := repos.NewAffordabilityRepository(db)
+	decisionRepo := repos.NewDecisionRepository(db)
+	productRepo := repos.NewProductRepository(db)
+	ledgerRepo := repos.NewLedgerRepository(db)
+	transferRepo := repos.NewTransferRepository(db)
+	bankConnectionRepo := repos.NewBankConnectionRepository(db)
 
 	// Сервисы
-	userService := services.NewUserService(userRepo)                // содержит GetUserByEmail (исправлено)
+	userService := services.NewUserService(userRepo) // содержит GetUserByEmail (исправлено)
 	tokenService := services.NewTokenService(&cfg.JWT)
-	loanService := services.NewLoanService(loanRepo, accountRepo, paymentRepo)
-	accountService := services.NewUserBankAccountService(accountRepo)
+	ledgerService := services.NewLedgerService(ledgerRepo, txManager)
+	accrualService := services.NewInterestAccrualService(loanRepo, accrualRepo, scheduleRepo, paymentRepo, txManager, cfg.Loans)
+	statusService := services.NewStatusService(loanRepo, applicationRepo, historyRepo, txManager)
+	delinquencyService := services.NewDelinquencyService(loanRepo, scheduleRepo, paymentRepo, txManager, statusService, cfg.Loans)
+	disbursementService := services.NewDisbursementService(loanRepo, accountRepo, transactionRepo, ledgerService, txManager)
+	loanService := services.NewLoanService(loanRepo, accountRepo, paymentRepo, scheduleRepo, bankRepo, productRepo, txManager, ledgerService, disbursementService, accrualService, delinquencyService, statusService, cfg.Loans)
+	accountService := services.NewUserBankAccountService(accountRepo, ledgerService, txManager)
+	distributionService := services.NewDistributionService(settingsRepo, bankRepo)
+	lenderService := services.NewLenderProfileService(settingsRepo, bankRepo, txManager)
+	productService := services.NewProductService(productRepo, bankRepo, txManager)
+	calculatorService := services.NewCalculatorService(distributionService, productRepo)
+	offerService := services.NewOfferService(offerRepo, applicationRepo, participantRepo, settingsRepo, bankRepo, productRepo, loanService, statusService, txManager)
 	transactionService := services.NewTransactionService(transactionRepo)
-	applicationService := services.NewCreditApplicationService(applicationRepo, loanRepo)
+	transferService := services.NewTransferService(transferRepo, accountRepo, transactionRepo, ledgerService, txManager)
+	scoringService := services.NewScoringService(scoringRepo, userRepo)
+	affordabilityService := services.NewAffordabilityService(affordabilityRepo, distributionService, cfg.Affordability)
+	decisionService := services.NewDecisionService(decisionRepo, cfg.Decisions)
+	applicationService := services.NewCreditApplicationService(applicationRepo, offerRepo, participantRepo, bankRepo, productRepo, loanService, distributionService, scoringService, affordabilityService, decisionService, statusService, txManager)
+	documentService := services.NewDocumentService(documentRepo, applicationRepo, blobStore, cfg.Documents)
+
+	// Разметка CSV-выписок банков
+	statementMappings, err := services.LoadStatementCSVMappings(cfg.Statements.CSVMappingsFile)
+	if err != nil {
+		log.Fatalf("Failed to load statement CSV mappings: %v", err)
+	}
+	statementService := services.NewStatementService(transactionRepo, accountRepo, bankRepo, txManager, statementMappings)
+
+	// API банков; в разработке — встроенный mock-сервер
+	mockURL := ""
+	if cfg.OpenBanking.Mock {
+		if cfg.Env == config.EnvProduction {
+			log.Fatalf("OPEN_BANKING_MOCK must not be enabled in production")
+		}
+		mockBanks := openbanking.NewMockServer()
+		defer mockBanks.Close()
+		mockURL = mockBanks.URL()
+		logger.Log.Infof("Open banking mock server on %s", mockURL)
+	}
+	connectors, err := openbanking.NewConnectors(cfg.OpenBanking, mockURL)
+	if err != nil {
+		log.Fatalf("Failed to configure open banking: %v", err)
+	}
+	bankSyncService := services.NewBankSyncService(bankConnectionRepo, accountRepo, transactionRepo, bankRepo, ledgerService, txManager, connectors)
+
+	// Правила автоматического решения по заявкам
+	if _, err := decisionService.ReloadRules(context.Background()); err != nil {
+		log.Fatalf("Failed to load decision rules: %v", err)
+	}
+
+	// Фоновое ежедневное начисление процентов и пересчёт просрочки
+	go services.RunDailyAccrual(context.Background(), accrualService, delinquencyService, time.Hour)
+	// Повтор неудавшихся зачислений кредитов на счета заёмщиков
+	go services.RunDisbursementRetry(context.Background(), disbursementService, 10*time.Minute)
+	// Отложенные и регулярные переводы между счетами
+	go services.RunScheduledTransfers(context.Background(), transferService, time.Minute)
+	// Синхронизация счетов и операций, подключённых через API банков
+	if cfg.OpenBanking.SyncInterval > 0 && len(connectors) > 0 {
+		go services.RunBankSync(context.Background(), bankSyncService, cfg.OpenBanking.SyncInterval)
+	}
 
 	// Хэндлеры
 	userHandler := handlers.NewUserHandler(userService)
-	loanHandler := handlers.NewLoanHandler(loanService, accountService)
+	loanHandler := handlers.NewLoanHandler(loanService, accountService, distributionService, productService, disbursementService)
 	accountHandler := handlers.NewUserBankAccountHandler(accountService)
 	transactionHandler := handlers.NewTransactionHandler(transactionService)
 	applicationHandler := handlers.NewCreditApplicationHandler(applicationService)
 	authHandler := handlers.NewAuthHandler(userService, tokenService)
+	lenderHandler := handlers.NewLenderProfileHandler(lenderService)
+	offerHandler := handlers.NewOfferHandler(offerService, applicationService)
+	documentHandler := handlers.NewDocumentHandler(documentService, applicationService, cfg.Documents.MaxSizeBytes)
+	scoringHandler := handlers.NewScoringHandler(scoringService)
+	decisionHandler := handlers.NewDecisionHandler(decisionService)
+	productHandler := handlers.NewProductHandler(productService)
+	calculatorHandler := handlers.NewCalculatorHandler(calculatorService)
+	transferHandler := handlers.NewTransferHandler(transferService)
+	statementHandler := handlers.NewStatementHandler(statementService, cfg.Statements.MaxSizeBytes)
+	bankConnectionHandler := handlers.NewBankConnectionHandler(bankSyncService)
 
 	// Роутинг
 	handlers.RegisterRoutes(
@@ -73,6 +163,17 @@ func main() {
 		transactionHandler,
 		applicationHandler,
 		authHandler,
+		lenderHandler,
+		offerHandler,
+		documentHandler,
+		scoringHandler,
+		decisionHandler,
+		productHandler,
+		calculatorHandler,
+		transferHandler,
+		statementHandler,
+		bankConnectionHandler,
+		middleware.RateLimit(cfg.RateLimit.PublicPerMinute, cfg.RateLimit.PublicBurst),
 		cfg.JWT.Secret,
 	)
 
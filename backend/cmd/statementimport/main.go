@@ -0,0 +1,91 @@
+// statementimport загружает банковские выписки (CSV, OFX, camt.053, MT940) в транзакции пользователя:
+//
+//	statementimport -user 42 -bank 3 [-format CSV] выписка.csv [ещё.ofx …]
+//
+// Для каждого файла печатает отчёт в JSON; если хотя бы одна строка не загружена, завершается с кодом 1
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	"github.com/Arlandaren/easyfund/internal/repos"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+func main() {
+	userID := flag.Int64("user", 0, "ID пользователя")
+	bankID := flag.Int("bank", 0, "ID банка")
+	format := flag.String("format", "", "формат выписки: CSV | OFX | CAMT053 | MT940; пусто — определить автоматически")
+	timeout := flag.Duration("timeout", 5*time.Minute, "максимальная длительность импорта")
+	flag.Parse()
+
+	if *userID <= 0 || *bankID <= 0 || flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: statementimport -user ID -bank ID [-format FORMAT] FILE...")
+		os.Exit(2)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	mappings, err := services.LoadStatementCSVMappings(cfg.Statements.CSVMappingsFile)
+	if err != nil {
+		log.Fatalf("Failed to load statement CSV mappings: %v", err)
+	}
+
+	db, err := config.NewDB(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	svc := services.NewStatementService(
+		repos.NewTransactionRepository(db),
+		repos.NewUserBankAccountRepository(db),
+		repos.NewBankRepository(db),
+		repos.NewTxManager(db),
+		mappings,
+	)
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	failed := 0
+	for _, path := range flag.Args() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read %s: %v", path, err)
+		}
+		report, err := svc.Import(ctx, &services.StatementImport{
+			UserID:   *userID,
+			BankID:   int16(*bankID),
+			Format:   *format,
+			FileName: filepath.Base(path),
+			Data:     data,
+		})
+		if err != nil {
+			log.Fatalf("Failed to import %s: %v", path, err)
+		}
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		failed += report.Failed
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d statement lines were not imported\n", failed)
+		os.Exit(1)
+	}
+}
@@ -5,12 +5,20 @@ import "time"
 type Environment string
 
 type Config struct {
-	Env      Environment
-	Server   ServerConfig
-	Database DBConfig
-	JWT      JWTConfig
-	Logger   LoggerConfig
-	CORS     CORSConfig
+	Env           Environment
+	Server        ServerConfig
+	Database      DBConfig
+	JWT           JWTConfig
+	Logger        LoggerConfig
+	CORS          CORSConfig
+	Loans         LoanConfig
+	Storage       StorageConfig
+	Documents     DocumentConfig
+	Affordability AffordabilityConfig
+	Decisions     DecisionConfig
+	RateLimit     RateLimitConfig
+	Statements    StatementConfig
+	OpenBanking   OpenBankingConfig
 }
 
 type ServerConfig struct {
@@ -19,13 +27,13 @@ type ServerConfig struct {
 }
 
 type DBConfig struct {
-	Host        string
-	Port        string
-	User        string
-	Password    string
-	DBName      string
-	SSLMode     string
-	MaxConns    int // максимум соединений в пуле
+	Host     string
+	Port     string
+	User     string
+	Password string
+	DBName   string
+	SSLMode  string
+	MaxConns int // максимум соединений в пуле
 }
 
 type JWTConfig struct {
@@ -41,3 +49,69 @@ type LoggerConfig struct {
 type CORSConfig struct {
 	AllowedOrigins []string
 }
+
+type LoanConfig struct {
+	AllocationStrategy string // стратегия распределения платежа по умолчанию: PRO_RATA | HIGHEST_RATE_FIRST | BANK_PRIORITY
+	PenaltyRate        string // годовая ставка пени на просроченную сумму, %
+	DefaultAfterDays   int    // после стольких дней просрочки кредит переходит в DEFAULTED
+}
+
+type StorageConfig struct {
+	Driver   string // хранилище файлов: local | s3
+	LocalDir string // каталог для local
+	S3       S3Config
+}
+
+// S3Config — S3-совместимое хранилище; локально — MinIO из docker-compose
+type S3Config struct {
+	Endpoint  string // например http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool // адреса вида endpoint/bucket/key (MinIO), иначе bucket.endpoint/key
+}
+
+type DocumentConfig struct {
+	MaxSizeBytes int64 // максимальный размер загружаемого документа
+}
+
+// AffordabilityConfig — пороги показателя долговой нагрузки (ПДН) при подаче заявки, %
+type AffordabilityConfig struct {
+	FlagPDN      string // выше — заявка принимается с пометкой FLAGGED
+	RejectPDN    string // выше — заявка отклоняется
+	IncomeMonths int    // за сколько месяцев усредняются поступления
+	AssumedRate  string // годовая ставка для оценки платежа, если банки не котируют тип кредита
+}
+
+// DecisionConfig — автоматическое решение по заявкам
+type DecisionConfig struct {
+	RulesFile string // JSON-набор правил; пусто — встроенный набор по умолчанию
+	Enforce   bool   // применять REJECT/APPROVE к заявкам; иначе решения только записываются
+}
+
+// RateLimitConfig — ограничение частоты запросов к публичным endpoints с одного IP
+type RateLimitConfig struct {
+	PublicPerMinute int // запросов в минуту; 0 — без ограничения
+	PublicBurst     int // допустимый всплеск
+}
+
+// StatementConfig — импорт банковских выписок
+type StatementConfig struct {
+	CSVMappingsFile string // JSON с разметкой CSV по банкам; пусто — встроенная разметка
+	MaxSizeBytes    int64  // максимальный размер файла выписки
+}
+
+// OpenBankingConfig — подключение к API банков для синхронизации счетов и операций
+type OpenBankingConfig struct {
+	Banks        map[string]BankAPIConfig // по коду банка; банки без адреса не синхронизируются
+	Mock         bool                     // поднять встроенный mock-сервер и направить на него все банки без адреса
+	SyncInterval time.Duration            // период фоновой синхронизации; 0 — выключена
+	Timeout      time.Duration            // таймаут запроса к банку
+}
+
+// BankAPIConfig — адрес и токен API банка
+type BankAPIConfig struct {
+	BaseURL string
+	Token   string
+}
@@ -8,7 +8,6 @@ import (
 	"time"
 )
 
-
 const (
 	EnvDevelopment Environment = "development"
 	EnvProduction  Environment = "production"
@@ -66,9 +65,66 @@ func Load() (*Config, error) {
 		CORS: CORSConfig{
 			AllowedOrigins: parseStringList(getEnv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")),
 		},
+		Loans: LoanConfig{
+			AllocationStrategy: getEnv("PAYMENT_ALLOCATION_STRATEGY", "PRO_RATA"),
+			PenaltyRate:        getEnv("LOAN_PENALTY_RATE", "20"),
+			DefaultAfterDays:   parseIntWithDefault(getEnv("LOAN_DEFAULT_AFTER_DAYS", ""), 90),
+		},
+		Storage: StorageConfig{
+			Driver:   getEnv("STORAGE_DRIVER", "local"),
+			LocalDir: getEnv("STORAGE_LOCAL_DIR", "./data/documents"),
+			S3: S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", ""),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", "easyfund-documents"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+				PathStyle: parseBoolWithDefault(getEnv("S3_PATH_STYLE", ""), true),
+			},
+		},
+		Documents: DocumentConfig{
+			MaxSizeBytes: int64(parseIntWithDefault(getEnv("DOCUMENT_MAX_SIZE_MB", ""), 10)) << 20,
+		},
+		Affordability: AffordabilityConfig{
+			FlagPDN:      getEnv("AFFORDABILITY_FLAG_PDN", "50"),
+			RejectPDN:    getEnv("AFFORDABILITY_REJECT_PDN", "80"),
+			IncomeMonths: parseIntWithDefault(getEnv("AFFORDABILITY_INCOME_MONTHS", ""), 6),
+			AssumedRate:  getEnv("AFFORDABILITY_ASSUMED_RATE", "20"),
+		},
+		Decisions: DecisionConfig{
+			RulesFile: getEnv("DECISION_RULES_FILE", ""),
+			Enforce:   parseBoolWithDefault(getEnv("DECISION_ENFORCE", ""), false),
+		},
+		RateLimit: RateLimitConfig{
+			PublicPerMinute: parseIntWithDefault(getEnv("RATE_LIMIT_PUBLIC_PER_MINUTE", ""), 30),
+			PublicBurst:     parseIntWithDefault(getEnv("RATE_LIMIT_PUBLIC_BURST", ""), 10),
+		},
+		Statements: StatementConfig{
+			CSVMappingsFile: getEnv("STATEMENT_CSV_MAPPINGS_FILE", ""),
+			MaxSizeBytes:    int64(parseIntWithDefault(getEnv("STATEMENT_MAX_SIZE_MB", ""), 5)) << 20,
+		},
+		OpenBanking: loadOpenBankingConfig(),
 	}, nil
 }
 
+// openBankingBanks — коды банков, для которых читаются OPEN_BANKING_<CODE>_URL и OPEN_BANKING_<CODE>_TOKEN
+var openBankingBanks = []string{"ALFA", "VTB", "SBER", "TBANK", "OPT"}
+
+func loadOpenBankingConfig() OpenBankingConfig {
+	cfg := OpenBankingConfig{
+		Banks:        make(map[string]BankAPIConfig, len(openBankingBanks)),
+		Mock:         parseBoolWithDefault(getEnv("OPEN_BANKING_MOCK", ""), false),
+		SyncInterval: time.Minute * time.Duration(parseIntWithDefault(getEnv("OPEN_BANKING_SYNC_INTERVAL_MIN", ""), 30)),
+		Timeout:      time.Second * time.Duration(parseIntWithDefault(getEnv("OPEN_BANKING_TIMEOUT_SEC", ""), 15)),
+	}
+	for _, code := range openBankingBanks {
+		if url := getEnv("OPEN_BANKING_"+code+"_URL", ""); url != "" {
+			cfg.Banks[code] = BankAPIConfig{BaseURL: url, Token: getEnv("OPEN_BANKING_"+code+"_TOKEN", "")}
+		}
+	}
+	return cfg
+}
+
 // getEnv получает значение переменной окружения или возвращает значение по умолчанию
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -88,6 +144,14 @@ func parseIntWithDefault(value string, defaultValue int) int {
 	return defaultValue
 }
 
+// parseBoolWithDefault парсит строку в bool или возвращает значение по умолчанию
+func parseBoolWithDefault(value string, defaultValue bool) bool {
+	if parsed, err := strconv.ParseBool(value); err == nil {
+		return parsed
+	}
+	return defaultValue
+}
+
 // parseStringList парсит строку в список строк
 func parseStringList(value string) []string {
 	if value == "" {
@@ -3,10 +3,12 @@ package errors
 import "errors"
 
 var (
-	ErrUnauthorized      = errors.New("unauthorized")
-	ErrForbidden         = errors.New("forbidden")
-	ErrNotFound          = errors.New("not found")
-	ErrBadRequest        = errors.New("bad request")
-	ErrInternalServer    = errors.New("internal server error")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrBadRequest         = errors.New("bad request")
+	ErrConflict           = errors.New("conflict")
+	ErrInternalServer     = errors.New("internal server error")
+	ErrBadGateway         = errors.New("bad gateway") // внешняя система (API банка) ответила ошибкой или недоступна
 	ErrInvalidCredentials = errors.New("invalid credentials")
 )
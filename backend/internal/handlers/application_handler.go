@@ -1,15 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
 	"github.com/Arlandaren/easyfund/internal/logger"
 	"github.com/Arlandaren/easyfund/internal/middleware"
 	"github.com/Arlandaren/easyfund/internal/models"
 	"github.com/Arlandaren/easyfund/internal/services"
+	"github.com/gin-gonic/gin"
 )
 
 type CreditApplicationHandler struct {
@@ -20,10 +23,24 @@ func NewCreditApplicationHandler(service services.CreditApplicationService) *Cre
 	return &CreditApplicationHandler{service: service}
 }
 
+// SubmitApplicationRequest — заявка в один банк (bank_id), в несколько (bank_ids) или в любой (any_bank);
+// draft — сохранить черновиком, не отправляя в банки; product_id — заявка по продукту каталога
 type SubmitApplicationRequest struct {
-	BankID         int16  `json:"bank_id" binding:"required"`
-	TypeCode       string `json:"type_code" binding:"required"`
-	RequestedAmount string `json:"requested_amount" binding:"required"`
+	BankID          int16   `json:"bank_id"`
+	BankIDs         []int16 `json:"bank_ids"`
+	AnyBank         bool    `json:"any_bank"`
+	ProductID       *int64  `json:"product_id"`
+	TypeCode        string  `json:"type_code" binding:"omitempty,oneof=PERSONAL AUTO MORTGAGE OTHER"` // обязателен без product_id
+	RequestedAmount string  `json:"requested_amount" binding:"required"`
+	TermMonths      int     `json:"term_months" binding:"omitempty,min=1,max=360"` // пусто — 12 месяцев
+	Draft           bool    `json:"draft"`
+}
+
+func (r *SubmitApplicationRequest) bankIDs() []int16 {
+	if r.BankID != 0 {
+		return append([]int16{r.BankID}, r.BankIDs...)
+	}
+	return r.BankIDs
 }
 
 // POST /api/v1/applications (защищенный)
@@ -40,25 +57,284 @@ func (h *CreditApplicationHandler) SubmitApplication(c *gin.Context) {
 		return
 	}
 
+	status := models.ApplicationStatusPending
+	if req.Draft {
+		status = models.ApplicationStatusDraft
+	}
+
 	app := &models.CreditApplication{
-		UserID:         userID,
-		BankID:         req.BankID,
-		TypeCode:       req.TypeCode,
-		StatusCode:     "PENDING",
+		UserID:          userID,
+		AnyBank:         req.AnyBank,
+		TypeCode:        req.TypeCode,
+		ProductID:       req.ProductID,
+		StatusCode:      status,
 		RequestedAmount: req.RequestedAmount,
-		SubmittedAt:    time.Now(),
-		UpdatedAt:      time.Now(),
+		TermMonths:      req.TermMonths,
+		SubmittedAt:     time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 
-	appID, err := h.service.SubmitApplication(c.Request.Context(), app)
+	detail, err := h.service.SubmitApplication(c.Request.Context(), app, req.bankIDs())
 	if err != nil {
+		if writeAffordabilityError(c, err) {
+			logger.Log.Infof("User %d application rejected by affordability check", userID)
+			return
+		}
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logger.Log.Errorf("Failed to submit application: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit application"})
 		return
 	}
 
-	logger.Log.Infof("User %d submitted credit application %d", userID, appID)
-	c.JSON(http.StatusCreated, gin.H{"application_id": appID, "status": "PENDING"})
+	if req.Draft {
+		logger.Log.Infof("User %d saved draft application %d", userID, detail.ApplicationID)
+	} else {
+		logger.Log.Infof("User %d submitted credit application %d to %d bank(s)", userID, detail.ApplicationID, len(detail.Participants))
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"application_id": detail.ApplicationID,
+		"status":         detail.StatusCode,
+		"participants":   detail.Participants,
+		"participation":  detail.Participation,
+		"affordability":  detail.Affordability,
+	})
+}
+
+// PUT /api/v1/applications/:id (защищенный)
+// Правка черновика заявки его владельцем
+func (h *CreditApplicationHandler) UpdateDraft(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req SubmitApplicationRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	app := &models.CreditApplication{
+		ApplicationID:   appID,
+		UserID:          userID,
+		AnyBank:         req.AnyBank,
+		TypeCode:        req.TypeCode,
+		ProductID:       req.ProductID,
+		RequestedAmount: req.RequestedAmount,
+		TermMonths:      req.TermMonths,
+	}
+
+	detail, err := h.service.UpdateDraft(c.Request.Context(), app, req.bankIDs())
+	if err != nil {
+		h.writeError(c, err, "Failed to update draft")
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// POST /api/v1/applications/:id/submit (защищенный)
+// Отправка черновика в банки
+func (h *CreditApplicationHandler) SubmitDraft(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	detail, err := h.service.SubmitDraft(c.Request.Context(), appID, userID)
+	if err != nil {
+		h.writeError(c, err, "Failed to submit draft")
+		return
+	}
+
+	logger.Log.Infof("User %d submitted draft application %d to %d bank(s)", userID, appID, len(detail.Participants))
+	c.JSON(http.StatusOK, detail)
+}
+
+type CancelApplicationRequest struct {
+	Reason string `json:"reason"`
+}
+
+// POST /api/v1/applications/:id/cancel (защищенный)
+// Отмена заявки заёмщиком; действующие предложения банков истекают
+func (h *CreditApplicationHandler) CancelApplication(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req CancelApplicationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	detail, err := h.service.CancelApplication(c.Request.Context(), appID, userID, req.Reason)
+	if err != nil {
+		h.writeError(c, err, "Failed to cancel application")
+		return
+	}
+
+	logger.Log.Infof("User %d cancelled application %d", userID, appID)
+	c.JSON(http.StatusOK, detail)
+}
+
+// GET /api/v1/applications/:id (защищенный)
+// Доступна заёмщику, администратору и банкам-участникам
+func (h *CreditApplicationHandler) GetApplication(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	detail, err := h.service.GetApplicationDetail(c.Request.Context(), appID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+			return
+		}
+		logger.Log.Errorf("Failed to get application: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get application"})
+		return
+	}
+
+	if !canViewApplication(c, userID, detail) {
+		logger.Log.Warnf("User %d tried to get application %d of user %d", userID, appID, detail.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+func canViewApplication(c *gin.Context, userID int64, detail *models.ApplicationDetailDTO) bool {
+	switch middleware.GetRoleFromContext(c) {
+	case models.RoleAdmin:
+		return true
+	case models.RoleBank:
+		// Черновик банкам ещё не отправлен
+		if detail.StatusCode == models.ApplicationStatusDraft {
+			break
+		}
+		bankID := middleware.GetBankIDFromContext(c)
+		for _, p := range detail.Participants {
+			if p.BankID == bankID {
+				return true
+			}
+		}
+	}
+	return detail.UserID == userID
+}
+
+// GET /api/v1/banks/:id/applications (сотрудник банка/администратор)
+func (h *CreditApplicationHandler) ListBankApplications(c *gin.Context) {
+	bankID, err := strconv.ParseInt(c.Param("id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+
+	if middleware.GetRoleFromContext(c) == models.RoleBank && middleware.GetBankIDFromContext(c) != int16(bankID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	apps, err := h.service.ListBankApplications(c.Request.Context(), int16(bankID), c.Query("status"))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to list applications of bank %d: %v", bankID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list applications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, apps)
+}
+
+type DeclineParticipationRequest struct {
+	BankID int16  `json:"bank_id"` // только для администратора
+	Reason string `json:"reason"`
+}
+
+// POST /api/v1/applications/:id/participation/decline (сотрудник банка/администратор)
+func (h *CreditApplicationHandler) DeclineParticipation(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req DeclineParticipationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	bankID := middleware.GetBankIDFromContext(c)
+	if middleware.GetRoleFromContext(c) == models.RoleAdmin {
+		bankID = req.BankID
+	}
+	if bankID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bank_id is required"})
+		return
+	}
+
+	detail, err := h.service.DeclineParticipation(c.Request.Context(), appID, bankID, userID, req.Reason)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, apperrors.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to decline participation: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to decline participation"})
+		return
+	}
+
+	logger.Log.Infof("Bank %d declined application %d", bankID, appID)
+	c.JSON(http.StatusOK, detail)
 }
 
 // GET /api/v1/users/:id/applications (защищенный)
@@ -94,7 +370,10 @@ func (h *CreditApplicationHandler) GetUserApplications(c *gin.Context) {
 }
 
 type ApproveApplicationRequest struct {
-	Splits []map[int16]string `json:"splits" binding:"required"`
+	Splits        []map[int16]string `json:"splits"`        // сумма долей должна совпадать с requested_amount; пусто — автораспределение
+	InterestRate  string             `json:"interest_rate"` // пусто — ставка по профилям банков
+	TermMonths    int                `json:"term_months" binding:"omitempty,min=1,max=360"`
+	RepaymentType string             `json:"repayment_type" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
 }
 
 // POST /api/v1/applications/:id/approve (администратор/защищенный)
@@ -118,15 +397,33 @@ func (h *CreditApplicationHandler) ApproveApplication(c *gin.Context) {
 		return
 	}
 
-	err = h.service.ApproveApplication(c.Request.Context(), appID, req.Splits)
+	terms := &models.ApprovalTerms{
+		Splits:        req.Splits,
+		InterestRate:  req.InterestRate,
+		TermMonths:    req.TermMonths,
+		RepaymentType: req.RepaymentType,
+	}
+
+	loan, err := h.service.ApproveApplication(c.Request.Context(), appID, terms, userID)
 	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.ErrBadRequest):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, apperrors.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+			return
+		case errors.Is(err, apperrors.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		logger.Log.Errorf("Failed to approve application: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve application"})
 		return
 	}
 
-	logger.Log.Infof("User %d (admin) approved application %d", userID, appID)
-	c.JSON(http.StatusOK, gin.H{"message": "Application approved"})
+	logger.Log.Infof("User %d (admin) approved application %d, loan %d", userID, appID, loan.Loan.LoanID)
+	c.JSON(http.StatusOK, gin.H{"message": "Application approved", "loan": loan})
 }
 
 // POST /api/v1/applications/:id/reject (администратор/защищенный)
@@ -144,8 +441,12 @@ func (h *CreditApplicationHandler) RejectApplication(c *gin.Context) {
 		return
 	}
 
-	err = h.service.RejectApplication(c.Request.Context(), appID)
+	err = h.service.RejectApplication(c.Request.Context(), appID, userID)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		logger.Log.Errorf("Failed to reject application: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject application"})
 		return
@@ -154,3 +455,72 @@ func (h *CreditApplicationHandler) RejectApplication(c *gin.Context) {
 	logger.Log.Infof("User %d (admin) rejected application %d", userID, appID)
 	c.JSON(http.StatusOK, gin.H{"message": "Application rejected"})
 }
+
+// GET /api/v1/applications/:id/history (защищенный)
+func (h *CreditApplicationHandler) GetApplicationHistory(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	app, err := h.service.GetApplication(c.Request.Context(), appID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get application: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	if app.UserID != userID {
+		logger.Log.Warnf("User %d tried to get history of application %d of user %d", userID, appID, app.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	history, err := h.service.GetApplicationHistory(c.Request.Context(), appID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get application history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get application history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *CreditApplicationHandler) writeError(c *gin.Context, err error, msg string) {
+	if writeAffordabilityError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
+
+// writeAffordabilityError отвечает 422 с расчётом ПДН, если заявку отклонила проверка долговой нагрузки
+func writeAffordabilityError(c *gin.Context, err error) bool {
+	var rejected *services.AffordabilityError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":         err.Error(),
+		"affordability": rejected.Assessment,
+	})
+	return true
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type BankConnectionHandler struct {
+	service services.BankSyncService
+}
+
+func NewBankConnectionHandler(service services.BankSyncService) *BankConnectionHandler {
+	return &BankConnectionHandler{service: service}
+}
+
+// ConnectBankRequest — подключение счёта в банке через API банка
+type ConnectBankRequest struct {
+	CustomerID string `json:"customer_id" binding:"required"` // идентификатор клиента, под которым банк выдал согласие
+}
+
+// POST /api/v1/users/:id/banks/:bank_id/connection (защищенный)
+func (h *BankConnectionHandler) Connect(c *gin.Context) {
+	userID, bankID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	var req ConnectBankRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	connection, err := h.service.Connect(c.Request.Context(), userID, bankID, req.CustomerID)
+	if err != nil {
+		h.writeError(c, err, "Failed to connect bank")
+		return
+	}
+
+	logger.Log.Infof("User %d connected bank %d (connection %d)", userID, bankID, connection.ConnectionID)
+	c.JSON(http.StatusCreated, connection)
+}
+
+// DELETE /api/v1/users/:id/banks/:bank_id/connection (защищенный)
+func (h *BankConnectionHandler) Disconnect(c *gin.Context) {
+	userID, bankID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Disconnect(c.Request.Context(), userID, bankID); err != nil {
+		h.writeError(c, err, "Failed to disconnect bank")
+		return
+	}
+
+	logger.Log.Infof("User %d disconnected bank %d", userID, bankID)
+	c.JSON(http.StatusOK, gin.H{"message": "Bank disconnected"})
+}
+
+// POST /api/v1/users/:id/banks/:bank_id/sync (защищенный)
+func (h *BankConnectionHandler) Sync(c *gin.Context) {
+	userID, bankID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.SyncUserBank(c.Request.Context(), userID, bankID)
+	if err != nil {
+		h.writeError(c, err, "Failed to sync bank")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GET /api/v1/users/:id/bank-connections (защищенный)
+func (h *BankConnectionHandler) ListConnections(c *gin.Context) {
+	requestingUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if requestingUserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to list bank connections of user %d", requestingUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	connections, err := h.service.ListUserConnections(c.Request.Context(), userID)
+	if err != nil {
+		h.writeError(c, err, "Failed to list bank connections")
+		return
+	}
+
+	c.JSON(http.StatusOK, connections)
+}
+
+// authorize разбирает :id и :bank_id и пускает только владельца или администратора;
+// при отказе ответ уже записан
+func (h *BankConnectionHandler) authorize(c *gin.Context) (int64, int16, bool) {
+	requestingUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, 0, false
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, 0, false
+	}
+	bankID, err := strconv.ParseInt(c.Param("bank_id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return 0, 0, false
+	}
+
+	if requestingUserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to manage bank %d connection of user %d", requestingUserID, bankID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return 0, 0, false
+	}
+	return userID, int16(bankID), true
+}
+
+func (h *BankConnectionHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrBadGateway):
+		logger.Log.Warnf("%s: %v", msg, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
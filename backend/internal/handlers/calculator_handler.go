@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type CalculatorHandler struct {
+	service services.CalculatorService
+}
+
+func NewCalculatorHandler(service services.CalculatorService) *CalculatorHandler {
+	return &CalculatorHandler{service: service}
+}
+
+type CalculatorRequest struct {
+	Amount        string `json:"amount" binding:"required"`
+	TermMonths    int    `json:"term_months" binding:"omitempty,min=1,max=360"` // пусто — 12 месяцев или ближайший допустимый срок продукта
+	TypeCode      string `json:"type_code" binding:"omitempty,oneof=PERSONAL AUTO MORTGAGE OTHER"`
+	ProductID     *int64 `json:"product_id"`
+	RepaymentType string `json:"repayment_type" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
+}
+
+// POST /api/v1/calculator (публичный, с ограничением частоты запросов)
+func (h *CalculatorHandler) Quote(c *gin.Context) {
+	var req CalculatorRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	quote, err := h.service.Quote(c.Request.Context(), models.QuoteRequest{
+		Amount:        req.Amount,
+		TermMonths:    req.TermMonths,
+		TypeCode:      req.TypeCode,
+		ProductID:     req.ProductID,
+		RepaymentType: req.RepaymentType,
+	})
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to calculate quote: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type DecisionHandler struct {
+	service services.DecisionService
+}
+
+func NewDecisionHandler(service services.DecisionService) *DecisionHandler {
+	return &DecisionHandler{service: service}
+}
+
+// GET /api/v1/admin/decision-rules (администратор)
+func (h *DecisionHandler) GetRules(c *gin.Context) {
+	rules, err := h.service.RuleSet()
+	if err != nil {
+		logger.Log.Errorf("Failed to get decision rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get decision rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "enforce": h.service.Enforcing()})
+}
+
+// POST /api/v1/admin/decision-rules/reload (администратор)
+// Перечитывает файл правил; при ошибке в файле продолжает действовать прежний набор
+func (h *DecisionHandler) ReloadRules(c *gin.Context) {
+	rules, err := h.service.ReloadRules(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to reload decision rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reload decision rules"})
+		return
+	}
+
+	logger.Log.Infof("Decision rules reloaded: version %s (%s)", rules.Version, rules.Checksum)
+	c.JSON(http.StatusOK, rules)
+}
+
+// DryRunRequest — пробный прогон: rules не задан — действующий набор; from/to — период подачи заявок
+type DryRunRequest struct {
+	Rules *models.DecisionRuleSet `json:"rules"`
+	From  *time.Time              `json:"from"` // по умолчанию — 30 дней назад
+	To    *time.Time              `json:"to"`   // по умолчанию — сейчас
+	Limit int                     `json:"limit"`
+}
+
+// POST /api/v1/admin/decision-rules/dry-run (администратор)
+func (h *DecisionHandler) DryRun(c *gin.Context) {
+	var req DryRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	to := time.Now()
+	if req.To != nil {
+		to = *req.To
+	}
+	from := to.AddDate(0, 0, -30)
+	if req.From != nil {
+		from = *req.From
+	}
+
+	report, err := h.service.DryRun(c.Request.Context(), req.Rules, from, to, req.Limit)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to dry-run decision rules: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to dry-run decision rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GET /api/v1/admin/applications/:id/decisions (администратор)
+func (h *DecisionHandler) GetApplicationDecisions(c *gin.Context) {
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	decisions, err := h.service.DecisionHistory(c.Request.Context(), appID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get decisions of application %d: %v", appID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get decisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+// Запас на заголовки multipart сверх размера самого файла
+const multipartOverhead = 1 << 20
+
+type DocumentHandler struct {
+	service      services.DocumentService
+	appService   services.CreditApplicationService
+	maxSizeBytes int64
+}
+
+func NewDocumentHandler(service services.DocumentService, appService services.CreditApplicationService, maxSizeBytes int64) *DocumentHandler {
+	return &DocumentHandler{
+		service:      service,
+		appService:   appService,
+		maxSizeBytes: maxSizeBytes,
+	}
+}
+
+// POST /api/v1/applications/:id/documents (защищенный, multipart: file, doc_type)
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	userID, appID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSizeBytes+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Document is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	content, err := file.Open()
+	if err != nil {
+		logger.Log.Errorf("Failed to open uploaded file: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload document"})
+		return
+	}
+	defer content.Close()
+
+	doc, err := h.service.UploadDocument(c.Request.Context(), &models.DocumentUpload{
+		ApplicationID: appID,
+		UploadedBy:    userID,
+		DocType:       c.PostForm("doc_type"),
+		FileName:      file.Filename,
+		ContentType:   file.Header.Get("Content-Type"),
+		Content:       content,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to upload document")
+		return
+	}
+
+	logger.Log.Infof("User %d uploaded document %d (%s, %d bytes) to application %d", userID, doc.DocumentID, doc.DocType, doc.SizeBytes, appID)
+	c.JSON(http.StatusCreated, doc)
+}
+
+// GET /api/v1/applications/:id/documents (защищенный)
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	_, appID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	docs, err := h.service.ListDocuments(c.Request.Context(), appID)
+	if err != nil {
+		h.writeError(c, err, "Failed to list documents")
+		return
+	}
+
+	c.JSON(http.StatusOK, docs)
+}
+
+// GET /api/v1/applications/:id/documents/:doc_id (защищенный)
+func (h *DocumentHandler) DownloadDocument(c *gin.Context) {
+	_, appID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	docID, err := strconv.ParseInt(c.Param("doc_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	doc, content, err := h.service.OpenDocument(c.Request.Context(), appID, docID)
+	if err != nil {
+		h.writeError(c, err, "Failed to download document")
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, doc.SizeBytes, doc.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"X-Checksum-SHA256":      doc.ChecksumSHA256,
+		"ETag":                   `"` + doc.ChecksumSHA256 + `"`,
+	})
+}
+
+// DELETE /api/v1/applications/:id/documents/:doc_id (защищенный)
+func (h *DocumentHandler) DeleteDocument(c *gin.Context) {
+	userID, appID, ok := h.authorize(c)
+	if !ok {
+		return
+	}
+
+	docID, err := strconv.ParseInt(c.Param("doc_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+		return
+	}
+
+	if err := h.service.DeleteDocument(c.Request.Context(), appID, docID, userID); err != nil {
+		h.writeError(c, err, "Failed to delete document")
+		return
+	}
+
+	logger.Log.Infof("User %d deleted document %d of application %d", userID, docID, appID)
+	c.JSON(http.StatusOK, gin.H{"message": "Document deleted"})
+}
+
+// authorize пускает к документам заявки только заёмщика и банки-участники;
+// при отказе ответ уже записан
+func (h *DocumentHandler) authorize(c *gin.Context) (int64, int64, bool) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, 0, false
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return 0, 0, false
+	}
+
+	detail, err := h.appService.GetApplicationDetail(c.Request.Context(), appID)
+	if err != nil {
+		h.writeError(c, err, "Failed to get application")
+		return 0, 0, false
+	}
+
+	// Администратор документы заёмщика не видит: паспорт и справки нужны только банкам
+	allowed := canViewApplication(c, userID, detail)
+	if middleware.GetRoleFromContext(c) == models.RoleAdmin {
+		allowed = detail.UserID == userID
+	}
+	if !allowed {
+		logger.Log.Warnf("User %d tried to access documents of application %d", userID, appID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return 0, 0, false
+	}
+	return userID, appID, true
+}
+
+func (h *DocumentHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type LenderProfileHandler struct {
+	service services.LenderProfileService
+}
+
+func NewLenderProfileHandler(service services.LenderProfileService) *LenderProfileHandler {
+	return &LenderProfileHandler{service: service}
+}
+
+type LenderProductRequest struct {
+	TypeCode string  `json:"type_code" binding:"required,oneof=PERSONAL AUTO MORTGAGE OTHER"`
+	Weight   string  `json:"weight"`    // аппетит к продукту, по умолчанию 1
+	BaseRate *string `json:"base_rate"` // null — продукт не выдаётся
+}
+
+type LenderProfileRequest struct {
+	BankID        int16                  `json:"bank_id"` // только при создании
+	MinTicket     string                 `json:"min_ticket" binding:"required"`
+	MaxTicket     string                 `json:"max_ticket" binding:"required"`
+	ExposureLimit string                 `json:"exposure_limit" binding:"required"`
+	TargetShare   string                 `json:"target_share" binding:"required"`
+	Currencies    []string               `json:"currencies" binding:"required"`
+	RiskAppetite  string                 `json:"risk_appetite" binding:"omitempty,oneof=LOW MEDIUM HIGH"`
+	Active        *bool                  `json:"active"`
+	Products      []LenderProductRequest `json:"products" binding:"dive"`
+}
+
+func (r *LenderProfileRequest) toProfile(bankID int16) *models.LenderProfile {
+	p := &models.LenderProfile{
+		BankLendingSettings: models.BankLendingSettings{
+			BankID:        bankID,
+			MinTicket:     r.MinTicket,
+			MaxTicket:     r.MaxTicket,
+			ExposureLimit: r.ExposureLimit,
+			TargetShare:   r.TargetShare,
+			Currencies:    r.Currencies,
+			RiskAppetite:  r.RiskAppetite,
+			Active:        r.Active == nil || *r.Active,
+		},
+	}
+	for _, pr := range r.Products {
+		p.Products = append(p.Products, models.BankProductAppetite{
+			BankID:   bankID,
+			TypeCode: pr.TypeCode,
+			Weight:   pr.Weight,
+			BaseRate: pr.BaseRate,
+		})
+	}
+	return p
+}
+
+// GET /api/v1/admin/lenders (администратор)
+func (h *LenderProfileHandler) ListProfiles(c *gin.Context) {
+	profiles, err := h.service.ListProfiles(c.Request.Context())
+	if err != nil {
+		logger.Log.Errorf("Failed to list lender profiles: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list lender profiles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profiles)
+}
+
+// GET /api/v1/admin/lenders/:id (администратор)
+func (h *LenderProfileHandler) GetProfile(c *gin.Context) {
+	bankID, err := strconv.ParseInt(c.Param("id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+
+	profile, err := h.service.GetProfile(c.Request.Context(), int16(bankID))
+	if err != nil {
+		h.writeError(c, err, "Failed to get lender profile")
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// POST /api/v1/admin/lenders (администратор)
+func (h *LenderProfileHandler) CreateProfile(c *gin.Context) {
+	var req LenderProfileRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if req.BankID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bank_id is required"})
+		return
+	}
+
+	profile, err := h.service.CreateProfile(c.Request.Context(), req.toProfile(req.BankID))
+	if err != nil {
+		h.writeError(c, err, "Failed to create lender profile")
+		return
+	}
+
+	logger.Log.Infof("Lender profile for bank %d created", profile.BankID)
+	c.JSON(http.StatusCreated, profile)
+}
+
+// PUT /api/v1/admin/lenders/:id (администратор)
+func (h *LenderProfileHandler) UpdateProfile(c *gin.Context) {
+	bankID, err := strconv.ParseInt(c.Param("id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+
+	var req LenderProfileRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(c.Request.Context(), req.toProfile(int16(bankID)))
+	if err != nil {
+		h.writeError(c, err, "Failed to update lender profile")
+		return
+	}
+
+	logger.Log.Infof("Lender profile for bank %d updated", bankID)
+	c.JSON(http.StatusOK, profile)
+}
+
+// DELETE /api/v1/admin/lenders/:id (администратор)
+func (h *LenderProfileHandler) DeleteProfile(c *gin.Context) {
+	bankID, err := strconv.ParseInt(c.Param("id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+
+	if err := h.service.DeleteProfile(c.Request.Context(), int16(bankID)); err != nil {
+		h.writeError(c, err, "Failed to delete lender profile")
+		return
+	}
+
+	logger.Log.Infof("Lender profile for bank %d deleted", bankID)
+	c.JSON(http.StatusOK, gin.H{"message": "Lender profile deleted"})
+}
+
+func (h *LenderProfileHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -1,37 +1,51 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
 	"github.com/Arlandaren/easyfund/internal/logger"
 	"github.com/Arlandaren/easyfund/internal/middleware"
 	"github.com/Arlandaren/easyfund/internal/models"
 	"github.com/Arlandaren/easyfund/internal/services"
+	"github.com/gin-gonic/gin"
 )
 
 type LoanHandler struct {
-	loanService    services.LoanService
-	accountService services.UserBankAccountService
+	loanService         services.LoanService
+	accountService      services.UserBankAccountService
+	distributionService services.DistributionService
+	productService      services.ProductService
+	disbursementService services.DisbursementService
 }
 
-func NewLoanHandler(loanService services.LoanService, accountService services.UserBankAccountService) *LoanHandler {
+func NewLoanHandler(loanService services.LoanService, accountService services.UserBankAccountService, distributionService services.DistributionService, productService services.ProductService, disbursementService services.DisbursementService) *LoanHandler {
 	return &LoanHandler{
-		loanService:    loanService,
-		accountService: accountService,
+		loanService:         loanService,
+		accountService:      accountService,
+		distributionService: distributionService,
+		productService:      productService,
+		disbursementService: disbursementService,
 	}
 }
 
 type CreateLoanRequest struct {
 	OriginalAmount string             `json:"original_amount" binding:"required"`
-	InterestRate   string             `json:"interest_rate" binding:"required"`
+	InterestRate   string             `json:"interest_rate"` // пусто — ставка по профилям банков
 	Purpose        string             `json:"purpose"`
-	Splits         []map[int16]string `json:"splits" binding:"required"`
+	TermMonths     int                `json:"term_months" binding:"required,min=1,max=360"`
+	RepaymentType  string             `json:"repayment_type" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
+	DayCount       string             `json:"day_count_convention" binding:"omitempty,oneof=ACT_365 ACT_ACT 30_360"`
+	TypeCode       string             `json:"type_code" binding:"omitempty,oneof=PERSONAL AUTO MORTGAGE OTHER"`
+	Splits         []map[int16]string `json:"splits"`     // пусто — распределение по банкам автоматически
+	ProductID      *int64             `json:"product_id"` // тип, банки и диапазон ставки — из продукта
+	UserID         *int64             `json:"user_id"`    // заёмщик; пусто — сам администратор
 }
 
-// POST /api/v1/loans (защищенный)
+// POST /api/v1/loans (только администратор; заёмщики получают кредит через заявку и предложения банков)
 func (h *LoanHandler) CreateLoan(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
 	if err != nil {
@@ -44,28 +58,130 @@ func (h *LoanHandler) CreateLoan(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
+	borrowerID := userID
+	if req.UserID != nil {
+		borrowerID = *req.UserID
+	}
 
 	loan := &models.Loan{
-		UserID:        userID,
-		OriginalAmount: req.OriginalAmount,
-		TakenAt:       time.Now(),
-		InterestRate:  req.InterestRate,
-		Status:        "ACTIVE",
-		Purpose:       req.Purpose,
-		CreatedAt:     time.Now(),
+		UserID:             borrowerID,
+		OriginalAmount:     req.OriginalAmount,
+		TakenAt:            time.Now(),
+		InterestRate:       req.InterestRate,
+		Status:             "ACTIVE",
+		Purpose:            req.Purpose,
+		TermMonths:         req.TermMonths,
+		RepaymentType:      req.RepaymentType,
+		DayCountConvention: req.DayCount,
+		ProductID:          req.ProductID,
+		CreatedAt:          time.Now(),
+	}
+
+	typeCode := req.TypeCode
+	if typeCode == "" {
+		typeCode = models.LoanTypePersonal
+	}
+	var product *models.LoanProduct
+	if req.ProductID != nil {
+		product, err = h.productService.GetProduct(c.Request.Context(), *req.ProductID)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrNotFound) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			logger.Log.Errorf("Failed to get product: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
+			return
+		}
+		typeCode = product.TypeCode
 	}
 
-	detail, err := h.loanService.CreateLoan(c.Request.Context(), loan, req.Splits)
+	// Доли и ставка, если не заданы, берутся из профилей кредиторов (и продукта, если указан)
+	splits := req.Splits
+	if len(splits) == 0 {
+		var proposal *models.SplitProposal
+		if product != nil {
+			proposal, err = h.distributionService.ProposeProductSplits(c.Request.Context(), req.OriginalAmount, product)
+		} else {
+			proposal, err = h.distributionService.ProposeSplits(c.Request.Context(), req.OriginalAmount, typeCode)
+		}
+		if err != nil {
+			h.writeDistributionError(c, err)
+			return
+		}
+		splits = proposal.AsSplits()
+		if loan.InterestRate == "" {
+			loan.InterestRate = proposal.InterestRate
+		}
+	}
+	if loan.InterestRate == "" {
+		var rate string
+		if product != nil {
+			rate, err = h.distributionService.QuoteProductRate(c.Request.Context(), product, splits)
+		} else {
+			rate, err = h.distributionService.QuoteRate(c.Request.Context(), typeCode, splits)
+		}
+		if err != nil {
+			h.writeDistributionError(c, err)
+			return
+		}
+		loan.InterestRate = rate
+	}
+
+	detail, err := h.loanService.CreateLoan(c.Request.Context(), loan, splits)
 	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		logger.Log.Errorf("Failed to create loan: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create loan"})
 		return
 	}
 
-	logger.Log.Infof("User %d created loan with ID %d", userID, detail.Loan.LoanID)
+	logger.Log.Infof("User %d created loan with ID %d for user %d", userID, detail.Loan.LoanID, borrowerID)
 	c.JSON(http.StatusCreated, detail)
 }
 
+type DistributionRequest struct {
+	Amount   string `json:"amount" binding:"required"`
+	TypeCode string `json:"type_code" binding:"omitempty,oneof=PERSONAL AUTO MORTGAGE OTHER"`
+}
+
+// POST /api/v1/loans/distribution (защищенный)
+func (h *LoanHandler) ProposeDistribution(c *gin.Context) {
+	if _, err := middleware.GetUserIDFromContext(c); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req DistributionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	if req.TypeCode == "" {
+		req.TypeCode = models.LoanTypePersonal
+	}
+
+	proposal, err := h.distributionService.ProposeSplits(c.Request.Context(), req.Amount, req.TypeCode)
+	if err != nil {
+		h.writeDistributionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, proposal)
+}
+
+func (h *LoanHandler) writeDistributionError(c *gin.Context, err error) {
+	if errors.Is(err, apperrors.ErrBadRequest) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	logger.Log.Errorf("Failed to distribute loan: %v", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to distribute loan"})
+}
+
 // GET /api/v1/loans/:id (защищенный)
 func (h *LoanHandler) GetLoanDetail(c *gin.Context) {
 	userID, err := middleware.GetUserIDFromContext(c)
@@ -98,6 +214,37 @@ func (h *LoanHandler) GetLoanDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, detail)
 }
 
+// GET /api/v1/loans/:id/schedule (защищенный)
+func (h *LoanHandler) GetLoanSchedule(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	loanIDStr := c.Param("id")
+	loanID, err := strconv.ParseInt(loanIDStr, 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	schedule, err := h.loanService.GetLoanSchedule(c.Request.Context(), loanID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get loan schedule: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get loan schedule"})
+		return
+	}
+
+	if schedule.Loan.UserID != userID {
+		logger.Log.Warnf("User %d tried to access schedule of loan %d of user %d", userID, loanID, schedule.Loan.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
+}
+
 // GET /api/v1/users/:id/loans (защищенный)
 func (h *LoanHandler) ListUserLoans(c *gin.Context) {
 	requestingUserID, err := middleware.GetUserIDFromContext(c)
@@ -152,21 +299,21 @@ func (h *LoanHandler) GetTotalDebt(c *gin.Context) {
 		return
 	}
 
-	totalDebt, err := h.loanService.GetTotalDebt(c.Request.Context(), userID)
+	debt, err := h.loanService.GetTotalDebt(c.Request.Context(), userID)
 	if err != nil {
 		logger.Log.Errorf("Failed to get total debt: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get total debt"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"total_debt": totalDebt})
+	c.JSON(http.StatusOK, debt)
 }
 
 type MakePaymentRequest struct {
-	LoanID      int64                     `json:"loan_id" binding:"required"`
-	TotalAmount string                   `json:"total_amount" binding:"required"`
-	Comment     string                   `json:"comment"`
-	Allocations []map[string]interface{} `json:"allocations" binding:"required"`
+	TotalAmount string `json:"total_amount" binding:"required"`
+	Comment     string `json:"comment"`
+	// Стратегия распределения по банкам; если не задана — берётся из конфигурации
+	Strategy string `json:"strategy" binding:"omitempty,oneof=PRO_RATA HIGHEST_RATE_FIRST BANK_PRIORITY"`
 }
 
 // POST /api/v1/loans/:id/payment (защищенный)
@@ -205,34 +352,205 @@ func (h *LoanHandler) MakePayment(c *gin.Context) {
 	}
 
 	payment := &models.LoanPayment{
-		LoanID:      loanID,
-		UserID:      userID,
-		PaidAt:      time.Now(),
-		TotalAmount: req.TotalAmount,
-		Comment:     req.Comment,
+		LoanID:             loanID,
+		UserID:             userID,
+		PaidAt:             time.Now(),
+		TotalAmount:        req.TotalAmount,
+		Comment:            req.Comment,
+		AllocationStrategy: req.Strategy,
+	}
+
+	result, err := h.loanService.MakePayment(c.Request.Context(), payment)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, apperrors.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to make payment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to make payment"})
+		return
 	}
 
-	// Парсим allocations
-	var allocations []models.PaymentAllocation
-	for _, alloc := range req.Allocations {
-		splitID := int64(alloc["split_id"].(float64))
-		principalPaid := alloc["principal_paid"].(string)
-		interestPaid := alloc["interest_paid"].(string)
+	logger.Log.Infof("User %d made payment %d for loan %d", userID, result.Payment.PaymentID, loanID)
+	c.JSON(http.StatusCreated, result)
+}
 
-		allocations = append(allocations, models.PaymentAllocation{
-			SplitID:       splitID,
-			PrincipalPaid: principalPaid,
-			InterestPaid:  interestPaid,
-		})
+type PrepaymentRequest struct {
+	Amount  string `json:"amount" binding:"required"`
+	Mode    string `json:"mode" binding:"required,oneof=SHORTEN_TERM REDUCE_INSTALLMENT"`
+	Comment string `json:"comment"`
+	// Стратегия распределения по банкам; если не задана — берётся из конфигурации
+	Strategy string `json:"strategy" binding:"omitempty,oneof=PRO_RATA HIGHEST_RATE_FIRST BANK_PRIORITY"`
+}
+
+// POST /api/v1/loans/:id/prepayment (защищенный)
+func (h *LoanHandler) Prepay(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
 
-	err = h.loanService.MakePayment(c.Request.Context(), payment, allocations)
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
 	if err != nil {
-		logger.Log.Errorf("Failed to make payment: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to make payment"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	var req PrepaymentRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	// Проверяем, что это кредит пользователя
+	loan, err := h.loanService.GetLoanDetail(c.Request.Context(), loanID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get loan: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get loan"})
+		return
+	}
+
+	if loan.Loan.UserID != userID {
+		logger.Log.Warnf("User %d tried to prepay loan %d of user %d", userID, loanID, loan.Loan.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	payment := &models.LoanPayment{
+		LoanID:             loanID,
+		UserID:             userID,
+		PaidAt:             time.Now(),
+		TotalAmount:        req.Amount,
+		Comment:            req.Comment,
+		AllocationStrategy: req.Strategy,
+	}
+
+	result, err := h.loanService.Prepay(c.Request.Context(), payment, req.Mode)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, apperrors.ErrConflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to make prepayment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to make prepayment"})
+		return
+	}
+
+	logger.Log.Infof("User %d made prepayment %d (%s) for loan %d", userID, result.Payment.PaymentID, req.Mode, loanID)
+	c.JSON(http.StatusCreated, result)
+}
+
+// POST /api/v1/loans/:id/disburse (защищенный) — повтор зачисления незачисленных долей
+func (h *LoanHandler) Disburse(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	detail, err := h.loanService.GetLoanDetail(c.Request.Context(), loanID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get loan: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if detail.Loan.UserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to disburse loan %d of user %d", userID, loanID, detail.Loan.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	result, err := h.disbursementService.DisburseLoan(c.Request.Context(), loanID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to disburse loan %d: %v", loanID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disburse loan"})
+		return
+	}
+
+	logger.Log.Infof("User %d retried disbursement of loan %d: %s", userID, loanID, result.Status)
+	c.JSON(http.StatusOK, result)
+}
+
+// GET /api/v1/banks/:id/delinquencies?bucket=31-60 (сотрудник банка/администратор)
+func (h *LoanHandler) ListBankDelinquencies(c *gin.Context) {
+	bankID, err := strconv.ParseInt(c.Param("id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+	// Банк видит только просрочку по своим долям
+	if middleware.GetRoleFromContext(c) == models.RoleBank && middleware.GetBankIDFromContext(c) != int16(bankID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
 		return
 	}
 
-	logger.Log.Infof("User %d made payment for loan %d", userID, loanID)
-	c.JSON(http.StatusCreated, gin.H{"message": "Payment recorded successfully"})
-}
\ No newline at end of file
+	items, err := h.loanService.ListBankDelinquencies(c.Request.Context(), int16(bankID), c.Query("bucket"))
+	if err != nil {
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		logger.Log.Errorf("Failed to list delinquencies for bank %d: %v", bankID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list delinquencies"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bank_id": bankID, "delinquencies": items})
+}
+
+// GET /api/v1/loans/:id/history (защищенный)
+func (h *LoanHandler) GetLoanHistory(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	loanID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid loan ID"})
+		return
+	}
+
+	loan, err := h.loanService.GetLoanDetail(c.Request.Context(), loanID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get loan: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Loan not found"})
+		return
+	}
+
+	if loan.Loan.UserID != userID {
+		logger.Log.Warnf("User %d tried to get history of loan %d of user %d", userID, loanID, loan.Loan.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	history, err := h.loanService.GetLoanHistory(c.Request.Context(), loanID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get loan history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get loan history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
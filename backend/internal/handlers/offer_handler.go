@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type OfferHandler struct {
+	service    services.OfferService
+	appService services.CreditApplicationService
+}
+
+func NewOfferHandler(service services.OfferService, appService services.CreditApplicationService) *OfferHandler {
+	return &OfferHandler{
+		service:    service,
+		appService: appService,
+	}
+}
+
+type PostOfferRequest struct {
+	BankID        int16      `json:"bank_id"` // только для администратора; сотрудник банка предлагает от своего банка
+	Amount        string     `json:"amount" binding:"required"`
+	InterestRate  string     `json:"interest_rate" binding:"required"`
+	TermMonths    int        `json:"term_months" binding:"required,min=1,max=360"`
+	RepaymentType string     `json:"repayment_type" binding:"omitempty,oneof=ANNUITY DIFFERENTIATED"`
+	ExpiresAt     *time.Time `json:"expires_at"` // по умолчанию через 7 дней
+}
+
+type AcceptOffersRequest struct {
+	OfferIDs []int64 `json:"offer_ids" binding:"required,min=1"`
+}
+
+// POST /api/v1/applications/:id/offers (сотрудник банка/администратор)
+func (h *OfferHandler) PostOffer(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req PostOfferRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	bankID := middleware.GetBankIDFromContext(c)
+	if middleware.GetRoleFromContext(c) == models.RoleAdmin {
+		bankID = req.BankID
+	}
+	if bankID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bank_id is required"})
+		return
+	}
+
+	offer := &models.ApplicationOffer{
+		ApplicationID: appID,
+		BankID:        bankID,
+		Amount:        req.Amount,
+		InterestRate:  req.InterestRate,
+		TermMonths:    req.TermMonths,
+		RepaymentType: req.RepaymentType,
+		CreatedBy:     &userID,
+	}
+	if req.ExpiresAt != nil {
+		offer.ExpiresAt = *req.ExpiresAt
+	}
+
+	offer, err = h.service.PostOffer(c.Request.Context(), offer)
+	if err != nil {
+		h.writeError(c, err, "Failed to post offer")
+		return
+	}
+
+	logger.Log.Infof("Bank %d posted offer %d on application %d", bankID, offer.OfferID, appID)
+	c.JSON(http.StatusCreated, offer)
+}
+
+// GET /api/v1/applications/:id/offers (защищенный)
+// Заёмщик и администратор видят все предложения, сотрудник банка — только предложения своего банка
+func (h *OfferHandler) ListOffers(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	app, err := h.appService.GetApplication(c.Request.Context(), appID)
+	if err != nil {
+		logger.Log.Errorf("Failed to get application: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return
+	}
+
+	role := middleware.GetRoleFromContext(c)
+	if app.UserID != userID && role != models.RoleAdmin && role != models.RoleBank {
+		logger.Log.Warnf("User %d tried to get offers of application %d of user %d", userID, appID, app.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	offers, err := h.service.ListOffers(c.Request.Context(), appID)
+	if err != nil {
+		logger.Log.Errorf("Failed to list offers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list offers"})
+		return
+	}
+
+	if role == models.RoleBank && app.UserID != userID {
+		bankID := middleware.GetBankIDFromContext(c)
+		own := make([]models.ApplicationOffer, 0, len(offers))
+		for _, o := range offers {
+			if o.BankID == bankID {
+				own = append(own, o)
+			}
+		}
+		offers = own
+	}
+
+	c.JSON(http.StatusOK, offers)
+}
+
+// POST /api/v1/applications/:id/offers/accept (защищенный, заёмщик)
+func (h *OfferHandler) AcceptOffers(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+
+	var req AcceptOffersRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	loan, err := h.service.AcceptOffers(c.Request.Context(), appID, req.OfferIDs, userID)
+	if err != nil {
+		h.writeError(c, err, "Failed to accept offers")
+		return
+	}
+
+	logger.Log.Infof("User %d accepted offers %v on application %d, loan %d", userID, req.OfferIDs, appID, loan.Loan.LoanID)
+	c.JSON(http.StatusOK, gin.H{"message": "Offers accepted", "loan": loan})
+}
+
+// POST /api/v1/applications/:id/offers/:offer_id/decline (защищенный, заёмщик)
+func (h *OfferHandler) DeclineOffer(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid application ID"})
+		return
+	}
+	offerID, err := strconv.ParseInt(c.Param("offer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	offer, err := h.service.DeclineOffer(c.Request.Context(), appID, offerID, userID)
+	if err != nil {
+		h.writeError(c, err, "Failed to decline offer")
+		return
+	}
+
+	logger.Log.Infof("User %d declined offer %d on application %d", userID, offerID, appID)
+	c.JSON(http.StatusOK, offer)
+}
+
+// POST /api/v1/offers/:id/withdraw (сотрудник банка/администратор)
+func (h *OfferHandler) WithdrawOffer(c *gin.Context) {
+	offerID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	// Администратор может отозвать любое предложение
+	bankID := middleware.GetBankIDFromContext(c)
+	if middleware.GetRoleFromContext(c) == models.RoleAdmin {
+		bankID = 0
+	}
+
+	offer, err := h.service.WithdrawOffer(c.Request.Context(), offerID, bankID)
+	if err != nil {
+		h.writeError(c, err, "Failed to withdraw offer")
+		return
+	}
+
+	logger.Log.Infof("Offer %d withdrawn by bank %d", offerID, offer.BankID)
+	c.JSON(http.StatusOK, offer)
+}
+
+func (h *OfferHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
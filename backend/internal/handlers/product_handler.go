@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type ProductHandler struct {
+	service services.ProductService
+}
+
+func NewProductHandler(service services.ProductService) *ProductHandler {
+	return &ProductHandler{service: service}
+}
+
+type ProductRequest struct {
+	Code                  string  `json:"code" binding:"required"`
+	Name                  string  `json:"name" binding:"required"`
+	Description           string  `json:"description"`
+	TypeCode              string  `json:"type_code" binding:"required,oneof=PERSONAL AUTO MORTGAGE OTHER"`
+	Currency              string  `json:"currency"` // по умолчанию RUB
+	MinAmount             string  `json:"min_amount" binding:"required"`
+	MaxAmount             string  `json:"max_amount" binding:"required"`
+	MinTermMonths         int     `json:"min_term_months" binding:"required,min=1,max=360"`
+	MaxTermMonths         int     `json:"max_term_months" binding:"required,min=1,max=360"`
+	MinRate               string  `json:"min_rate" binding:"required"`
+	MaxRate               string  `json:"max_rate" binding:"required"`
+	OriginationFeePercent string  `json:"origination_fee_percent"`
+	OriginationFeeFixed   string  `json:"origination_fee_fixed"`
+	MonthlyFee            string  `json:"monthly_fee"`
+	InsurancePercent      string  `json:"insurance_percent"`
+	GracePeriodDays       int     `json:"grace_period_days" binding:"min=0"`
+	BankIDs               []int16 `json:"bank_ids"`
+	Active                *bool   `json:"active"`
+}
+
+func (r *ProductRequest) toProduct(productID int64) *models.LoanProduct {
+	return &models.LoanProduct{
+		ProductID:             productID,
+		Code:                  r.Code,
+		Name:                  r.Name,
+		Description:           r.Description,
+		TypeCode:              r.TypeCode,
+		Currency:              r.Currency,
+		MinAmount:             r.MinAmount,
+		MaxAmount:             r.MaxAmount,
+		MinTermMonths:         r.MinTermMonths,
+		MaxTermMonths:         r.MaxTermMonths,
+		MinRate:               r.MinRate,
+		MaxRate:               r.MaxRate,
+		OriginationFeePercent: r.OriginationFeePercent,
+		OriginationFeeFixed:   r.OriginationFeeFixed,
+		MonthlyFee:            r.MonthlyFee,
+		InsurancePercent:      r.InsurancePercent,
+		GracePeriodDays:       r.GracePeriodDays,
+		BankIDs:               r.BankIDs,
+		Active:                r.Active == nil || *r.Active,
+	}
+}
+
+// GET /api/v1/products (публичный) — действующие продукты
+func (h *ProductHandler) ListCatalog(c *gin.Context) {
+	products, err := h.service.ListProducts(c.Request.Context(), true)
+	if err != nil {
+		logger.Log.Errorf("Failed to list products: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// GET /api/v1/products/:id (публичный) — отключённые продукты не показываются
+func (h *ProductHandler) GetCatalogProduct(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	product, err := h.service.GetProduct(c.Request.Context(), productID)
+	if err == nil && !product.Active {
+		err = fmt.Errorf("%w: product %d", apperrors.ErrNotFound, productID)
+	}
+	if err != nil {
+		h.writeError(c, err, "Failed to get product")
+		return
+	}
+
+	c.JSON(http.StatusOK, product)
+}
+
+// GET /api/v1/admin/products (администратор) — все продукты, включая отключённые
+func (h *ProductHandler) ListProducts(c *gin.Context) {
+	products, err := h.service.ListProducts(c.Request.Context(), false)
+	if err != nil {
+		logger.Log.Errorf("Failed to list products: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list products"})
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
+
+// POST /api/v1/admin/products (администратор)
+func (h *ProductHandler) CreateProduct(c *gin.Context) {
+	var req ProductRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	product, err := h.service.CreateProduct(c.Request.Context(), req.toProduct(0))
+	if err != nil {
+		h.writeError(c, err, "Failed to create product")
+		return
+	}
+
+	logger.Log.Infof("Product %d (%s) created", product.ProductID, product.Code)
+	c.JSON(http.StatusCreated, product)
+}
+
+// PUT /api/v1/admin/products/:id (администратор)
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	var req ProductRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	product, err := h.service.UpdateProduct(c.Request.Context(), req.toProduct(productID))
+	if err != nil {
+		h.writeError(c, err, "Failed to update product")
+		return
+	}
+
+	logger.Log.Infof("Product %d updated", productID)
+	c.JSON(http.StatusOK, product)
+}
+
+// DELETE /api/v1/admin/products/:id (администратор)
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	productID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid product ID"})
+		return
+	}
+
+	if err := h.service.DeleteProduct(c.Request.Context(), productID); err != nil {
+		h.writeError(c, err, "Failed to delete product")
+		return
+	}
+
+	logger.Log.Infof("Product %d deleted", productID)
+	c.JSON(http.StatusOK, gin.H{"message": "Product deleted"})
+}
+
+func (h *ProductHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
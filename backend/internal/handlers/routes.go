@@ -4,6 +4,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
 )
 
 func RegisterRoutes(
@@ -14,6 +15,17 @@ func RegisterRoutes(
 	transactionHandler *TransactionHandler,
 	applicationHandler *CreditApplicationHandler,
 	authHandler *AuthHandler,
+	lenderHandler *LenderProfileHandler,
+	offerHandler *OfferHandler,
+	documentHandler *DocumentHandler,
+	scoringHandler *ScoringHandler,
+	decisionHandler *DecisionHandler,
+	productHandler *ProductHandler,
+	calculatorHandler *CalculatorHandler,
+	transferHandler *TransferHandler,
+	statementHandler *StatementHandler,
+	bankConnectionHandler *BankConnectionHandler,
+	publicLimiter gin.HandlerFunc,
 	jwtSecret string,
 ) {
 	v1 := router.Group("/api/v1")
@@ -22,7 +34,14 @@ func RegisterRoutes(
 	v1.POST("/auth/register", authHandler.Register)
 	v1.POST("/auth/login", authHandler.Login)
 	v1.POST("/auth/refresh", authHandler.RefreshToken)
-	v1.GET("/users/random", userHandler.GetRandomUser)
+
+	// Демо-пользователь и калькулятор — с ограничением частоты запросов с одного IP
+	v1.GET("/users/random", publicLimiter, userHandler.GetRandomUser)
+	v1.POST("/calculator", publicLimiter, calculatorHandler.Quote)
+
+	// Каталог кредитных продуктов
+	v1.GET("/products", productHandler.ListCatalog)
+	v1.GET("/products/:id", productHandler.GetCatalogProduct)
 
 	// Защищённые endpoints
 	protected := v1.Group("")
@@ -42,10 +61,25 @@ func RegisterRoutes(
 	// Транзакции
 	protected.GET("/users/:id/transactions", transactionHandler.GetUserTransactionHistory)
 	protected.GET("/users/:id/banks/:bank_id/transactions", transactionHandler.GetBankTransactionHistory)
+	protected.POST("/users/:id/banks/:bank_id/statements", statementHandler.ImportStatement)
+
+	// Подключение счетов через API банков
+	protected.POST("/users/:id/banks/:bank_id/connection", bankConnectionHandler.Connect)
+	protected.DELETE("/users/:id/banks/:bank_id/connection", bankConnectionHandler.Disconnect)
+	protected.POST("/users/:id/banks/:bank_id/sync", bankConnectionHandler.Sync)
+	protected.GET("/users/:id/bank-connections", bankConnectionHandler.ListConnections)
+
+	// Переводы между своими счетами
+	protected.GET("/users/:id/transfers", transferHandler.ListUserTransfers)
 
 	// Заявки на кредит
 	protected.GET("/users/:id/applications", applicationHandler.GetUserApplications)
 
+	// Кредитная оценка и сводка
+	protected.GET("/users/:id/credit-score", scoringHandler.GetCreditScore)
+	protected.GET("/users/:id/credit-score/history", scoringHandler.GetCreditScoreHistory)
+	protected.GET("/users/:id/stats", scoringHandler.GetUserStats)
+
 	// === 2. Потом — маршруты с двумя сегментами (включая /users/:id) ===
 	protected.GET("/users/:id", userHandler.GetUser)
 	protected.PUT("/users/:id", userHandler.UpdateUser)
@@ -54,12 +88,67 @@ func RegisterRoutes(
 	// === 3. Остальные маршруты (без :id или с другим паттерном) ===
 	protected.POST("/users", userHandler.CreateUser)
 
-	// Глобальные кредиты и заявки
-	protected.POST("/loans", loanHandler.CreateLoan)
+	// Глобальные кредиты и заявки. Прямое создание кредита с выдачей — только администратор
+	protected.POST("/loans", middleware.RequireRole(models.RoleAdmin), loanHandler.CreateLoan)
+	protected.POST("/loans/distribution", loanHandler.ProposeDistribution)
 	protected.GET("/loans/:id", loanHandler.GetLoanDetail)
+	protected.GET("/loans/:id/schedule", loanHandler.GetLoanSchedule)
+	protected.GET("/loans/:id/history", loanHandler.GetLoanHistory)
 	protected.POST("/loans/:id/payment", loanHandler.MakePayment)
+	protected.POST("/loans/:id/prepayment", loanHandler.Prepay)
+	protected.POST("/loans/:id/disburse", loanHandler.Disburse)
+
+	// Переводы между своими счетами: разовые, отложенные и регулярные
+	protected.POST("/transfers", transferHandler.CreateTransfer)
+	protected.GET("/transfers/:id", transferHandler.GetTransfer)
+	protected.POST("/transfers/:id/cancel", transferHandler.CancelTransfer)
 
 	protected.POST("/applications", applicationHandler.SubmitApplication)
-	protected.POST("/applications/:id/approve", applicationHandler.ApproveApplication)
-	protected.POST("/applications/:id/reject", applicationHandler.RejectApplication)
-}
\ No newline at end of file
+	protected.GET("/applications/:id", applicationHandler.GetApplication)
+	protected.PUT("/applications/:id", applicationHandler.UpdateDraft)
+	protected.POST("/applications/:id/submit", applicationHandler.SubmitDraft)
+	protected.POST("/applications/:id/cancel", applicationHandler.CancelApplication)
+	protected.GET("/applications/:id/history", applicationHandler.GetApplicationHistory)
+
+	// Документы заявки: доступны заёмщику и банкам-участникам
+	protected.POST("/applications/:id/documents", documentHandler.UploadDocument)
+	protected.GET("/applications/:id/documents", documentHandler.ListDocuments)
+	protected.GET("/applications/:id/documents/:doc_id", documentHandler.DownloadDocument)
+	protected.DELETE("/applications/:id/documents/:doc_id", documentHandler.DeleteDocument)
+
+	// Прямое одобрение и отказ — только администратор; обычный путь — через предложения банков
+	protected.POST("/applications/:id/approve", middleware.RequireRole(models.RoleAdmin), applicationHandler.ApproveApplication)
+	protected.POST("/applications/:id/reject", middleware.RequireRole(models.RoleAdmin), applicationHandler.RejectApplication)
+
+	// Предложения банков по заявкам и просрочка по долям банка
+	lenders := middleware.RequireRole(models.RoleBank, models.RoleAdmin)
+	protected.GET("/banks/:id/applications", lenders, applicationHandler.ListBankApplications)
+	protected.GET("/banks/:id/delinquencies", lenders, loanHandler.ListBankDelinquencies)
+	protected.POST("/applications/:id/participation/decline", lenders, applicationHandler.DeclineParticipation)
+	protected.POST("/applications/:id/offers", lenders, offerHandler.PostOffer)
+	protected.GET("/applications/:id/offers", offerHandler.ListOffers)
+	protected.POST("/applications/:id/offers/accept", offerHandler.AcceptOffers)
+	protected.POST("/applications/:id/offers/:offer_id/decline", offerHandler.DeclineOffer)
+	protected.POST("/offers/:id/withdraw", lenders, offerHandler.WithdrawOffer)
+
+	// Администрирование: профили кредиторов
+	admin := protected.Group("/admin")
+	admin.Use(middleware.RequireRole(models.RoleAdmin))
+	admin.GET("/lenders", lenderHandler.ListProfiles)
+	admin.POST("/lenders", lenderHandler.CreateProfile)
+	admin.GET("/lenders/:id", lenderHandler.GetProfile)
+	admin.PUT("/lenders/:id", lenderHandler.UpdateProfile)
+	admin.DELETE("/lenders/:id", lenderHandler.DeleteProfile)
+
+	// Администрирование: правила автоматического решения
+	admin.GET("/decision-rules", decisionHandler.GetRules)
+	admin.POST("/decision-rules/reload", decisionHandler.ReloadRules)
+	admin.POST("/decision-rules/dry-run", decisionHandler.DryRun)
+	admin.GET("/applications/:id/decisions", decisionHandler.GetApplicationDecisions)
+
+	// Администрирование: каталог кредитных продуктов
+	admin.GET("/products", productHandler.ListProducts)
+	admin.POST("/products", productHandler.CreateProduct)
+	admin.PUT("/products/:id", productHandler.UpdateProduct)
+	admin.DELETE("/products/:id", productHandler.DeleteProduct)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type ScoringHandler struct {
+	service services.ScoringService
+}
+
+func NewScoringHandler(service services.ScoringService) *ScoringHandler {
+	return &ScoringHandler{service: service}
+}
+
+// GET /api/v1/users/:id/credit-score?refresh=true (защищенный)
+func (h *ScoringHandler) GetCreditScore(c *gin.Context) {
+	userID, ok := h.authorizeSelf(c)
+	if !ok {
+		return
+	}
+
+	refresh, _ := strconv.ParseBool(c.Query("refresh"))
+	score, err := h.service.GetCreditScore(c.Request.Context(), userID, refresh)
+	if err != nil {
+		logger.Log.Errorf("Failed to get credit score: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit score"})
+		return
+	}
+
+	c.JSON(http.StatusOK, score)
+}
+
+// GET /api/v1/users/:id/credit-score/history?limit=20 (защищенный)
+func (h *ScoringHandler) GetCreditScoreHistory(c *gin.Context) {
+	userID, ok := h.authorizeSelf(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	history, err := h.service.ScoreHistory(c.Request.Context(), userID, limit)
+	if err != nil {
+		logger.Log.Errorf("Failed to get credit score history: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credit score history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GET /api/v1/users/:id/stats (защищенный)
+func (h *ScoringHandler) GetUserStats(c *gin.Context) {
+	userID, ok := h.authorizeSelf(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.service.GetUserStats(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, apperrors.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		logger.Log.Errorf("Failed to get user stats: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get user stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// authorizeSelf пускает только к собственным данным; при отказе ответ уже записан
+func (h *ScoringHandler) authorizeSelf(c *gin.Context) (int64, bool) {
+	requestingUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return 0, false
+	}
+
+	if requestingUserID != userID {
+		logger.Log.Warnf("User %d tried to access credit data of user %d", requestingUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return 0, false
+	}
+	return userID, true
+}
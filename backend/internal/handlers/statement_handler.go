@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type StatementHandler struct {
+	service      services.StatementService
+	maxSizeBytes int64
+}
+
+func NewStatementHandler(service services.StatementService, maxSizeBytes int64) *StatementHandler {
+	return &StatementHandler{
+		service:      service,
+		maxSizeBytes: maxSizeBytes,
+	}
+}
+
+// POST /api/v1/users/:id/banks/:bank_id/statements (защищенный, multipart: file, format)
+func (h *StatementHandler) ImportStatement(c *gin.Context) {
+	requestingUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	bankID, err := strconv.ParseInt(c.Param("bank_id"), 10, 16)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid bank ID"})
+		return
+	}
+
+	if requestingUserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to import a statement for user %d", requestingUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSizeBytes+multipartOverhead)
+	file, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Statement is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+	if file.Size > h.maxSizeBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Statement is too large"})
+		return
+	}
+	content, err := file.Open()
+	if err != nil {
+		logger.Log.Errorf("Failed to open uploaded statement: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import statement"})
+		return
+	}
+	defer content.Close()
+	data, err := io.ReadAll(content)
+	if err != nil {
+		logger.Log.Errorf("Failed to read uploaded statement: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import statement"})
+		return
+	}
+
+	report, err := h.service.Import(c.Request.Context(), &services.StatementImport{
+		UserID:   userID,
+		BankID:   int16(bankID),
+		Format:   c.PostForm("format"),
+		FileName: file.Filename,
+		Data:     data,
+	})
+	if err != nil {
+		h.writeError(c, err, "Failed to import statement")
+		return
+	}
+
+	logger.Log.Infof("User %d imported %s statement for user %d, bank %d: %d imported, %d duplicates, %d failed",
+		requestingUserID, report.Format, userID, bankID, report.Imported, report.Duplicates, report.Failed)
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *StatementHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/middleware"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/services"
+)
+
+type TransferHandler struct {
+	service services.TransferService
+}
+
+func NewTransferHandler(service services.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+type CreateTransferRequest struct {
+	FromAccountID int64      `json:"from_account_id" binding:"required"`
+	ToAccountID   int64      `json:"to_account_id" binding:"required"`
+	Amount        string     `json:"amount" binding:"required"`
+	Description   string     `json:"description"`
+	ScheduledAt   *time.Time `json:"scheduled_at"` // пусто или в прошлом — выполнить сразу
+	Recurrence    string     `json:"recurrence" binding:"omitempty,oneof=ONCE DAILY WEEKLY MONTHLY"`
+	EndsAt        *time.Time `json:"ends_at"` // для регулярных: запуски не позже этого момента
+}
+
+// POST /api/v1/transfers (защищенный)
+func (h *TransferHandler) CreateTransfer(c *gin.Context) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req CreateTransferRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	t := &models.Transfer{
+		UserID:        userID,
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		Description:   req.Description,
+		Recurrence:    req.Recurrence,
+		EndsAt:        req.EndsAt,
+	}
+	if req.ScheduledAt != nil {
+		t.StartsAt = *req.ScheduledAt
+	}
+
+	transfer, err := h.service.CreateTransfer(c.Request.Context(), t)
+	if err != nil {
+		h.writeError(c, err, "Failed to create transfer")
+		return
+	}
+
+	logger.Log.Infof("User %d created transfer %d: %s", userID, transfer.TransferID, transfer.Status)
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// GET /api/v1/transfers/:id (защищенный)
+func (h *TransferHandler) GetTransfer(c *gin.Context) {
+	transfer, ok := h.ownTransfer(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// POST /api/v1/transfers/:id/cancel (защищенный)
+func (h *TransferHandler) CancelTransfer(c *gin.Context) {
+	transfer, ok := h.ownTransfer(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.service.CancelTransfer(c.Request.Context(), transfer.TransferID)
+	if err != nil {
+		h.writeError(c, err, "Failed to cancel transfer")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// GET /api/v1/users/:id/transfers (защищенный)
+func (h *TransferHandler) ListUserTransfers(c *gin.Context) {
+	requestingUserID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if requestingUserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to list transfers of user %d", requestingUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return
+	}
+
+	transfers, err := h.service.ListUserTransfers(c.Request.Context(), userID)
+	if err != nil {
+		h.writeError(c, err, "Failed to list transfers")
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// ownTransfer загружает перевод из :id и проверяет, что он принадлежит пользователю (или запрос от администратора)
+func (h *TransferHandler) ownTransfer(c *gin.Context) (*models.Transfer, bool) {
+	userID, err := middleware.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	transferID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transfer ID"})
+		return nil, false
+	}
+
+	transfer, err := h.service.GetTransfer(c.Request.Context(), transferID)
+	if err != nil {
+		h.writeError(c, err, "Failed to get transfer")
+		return nil, false
+	}
+
+	if transfer.UserID != userID && middleware.GetRoleFromContext(c) != models.RoleAdmin {
+		logger.Log.Warnf("User %d tried to access transfer %d of user %d", userID, transferID, transfer.UserID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
+		return nil, false
+	}
+	return transfer, true
+}
+
+func (h *TransferHandler) writeError(c *gin.Context, err error, msg string) {
+	switch {
+	case errors.Is(err, apperrors.ErrBadRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, apperrors.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.Log.Errorf("%s: %v", msg, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": msg})
+	}
+}
@@ -5,15 +5,17 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/Arlandaren/easyfund/internal/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/Arlandaren/easyfund/internal/logger"
 )
 
 // CustomClaims структура для хранения custom claims в JWT
 type CustomClaims struct {
 	UserID int64  `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role"`
+	BankID int16  `json:"bank_id,omitempty"` // банк сотрудника для роли BANK
 	jwt.RegisteredClaims
 }
 
@@ -88,6 +90,8 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 
 		c.Set("userID", claims.UserID)
 		c.Set("email", claims.Email)
+		c.Set("role", claims.Role)
+		c.Set("bankID", claims.BankID)
 
 		logger.Log.Infof("User %d authenticated successfully", claims.UserID)
 
@@ -108,4 +112,36 @@ func GetUserIDFromContext(c *gin.Context) (int64, error) {
 	}
 
 	return userID, nil
-}
\ No newline at end of file
+}
+
+// GetRoleFromContext возвращает роль пользователя из токена
+func GetRoleFromContext(c *gin.Context) string {
+	return c.GetString("role")
+}
+
+// GetBankIDFromContext возвращает банк сотрудника (0 — пользователь не сотрудник банка)
+func GetBankIDFromContext(c *gin.Context) int16 {
+	bankID, _ := c.Get("bankID")
+	id, _ := bankID.(int16)
+	return id
+}
+
+// RequireRole пропускает запрос, только если роль из токена входит в roles.
+// Используется после AuthMiddleware
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if role == r {
+				c.Next()
+				return
+			}
+		}
+
+		logger.Log.Warnf("Role %q is not allowed for %s %s", role, c.Request.Method, c.Request.URL.Path)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Insufficient permissions",
+		})
+		c.Abort()
+	}
+}
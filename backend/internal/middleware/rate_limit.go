@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/Arlandaren/easyfund/internal/logger"
+)
+
+// bucket — «ведро токенов» одного клиента
+type bucket struct {
+	tokens float64
+	seen   time.Time
+}
+
+// RateLimit ограничивает частоту запросов с одного IP: perMinute запросов в минуту
+// с допустимым всплеском burst. Сверх лимита — 429 с заголовком Retry-After.
+// Лимитер хранится в памяти процесса; один экземпляр можно повесить на несколько маршрутов
+func RateLimit(perMinute, burst int) gin.HandlerFunc {
+	if perMinute <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	perSecond := float64(perMinute) / 60
+	// Ведро, простоявшее столько, снова полное — его можно забыть
+	idle := time.Duration(float64(burst)/perSecond*float64(time.Second)) + time.Minute
+
+	var mu sync.Mutex
+	buckets := map[string]*bucket{}
+	lastSweep := time.Now()
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		if now.Sub(lastSweep) > idle {
+			for k, b := range buckets {
+				if now.Sub(b.seen) > idle {
+					delete(buckets, k)
+				}
+			}
+			lastSweep = now
+		}
+		b, ok := buckets[ip]
+		if !ok {
+			b = &bucket{tokens: float64(burst), seen: now}
+			buckets[ip] = b
+		}
+		b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.seen).Seconds()*perSecond)
+		b.seen = now
+		allowed := b.tokens >= 1
+		var wait time.Duration
+		if allowed {
+			b.tokens--
+		} else {
+			wait = time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		}
+		mu.Unlock()
+
+		if !allowed {
+			logger.Log.Warnf("Rate limit exceeded for %s on %s %s", ip, c.Request.Method, c.Request.URL.Path)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// Итог проверки долговой нагрузки
+const (
+	AffordabilityOK       = "OK"
+	AffordabilityFlagged  = "FLAGGED"  // заявка принята, но ПДН выше порога внимания или доход не подтверждён
+	AffordabilityRejected = "REJECTED" // заявка не принимается; в БД не сохраняется
+)
+
+// AffordabilityAssessment — расчёт показателя долговой нагрузки (ПДН, DTI):
+// (ExistingPayments + NewPayment) / MonthlyIncome
+type AffordabilityAssessment struct {
+	ApplicationID    int64     `json:"-" db:"application_id"`
+	Status           string    `json:"status" db:"status"`
+	MonthlyIncome    string    `json:"monthly_income" db:"monthly_income"`
+	ExistingPayments string    `json:"existing_payments" db:"existing_payments"`
+	NewPayment       string    `json:"new_payment" db:"new_payment"`
+	AssumedRate      string    `json:"assumed_rate" db:"assumed_rate"`
+	TermMonths       int       `json:"term_months" db:"term_months"`
+	PDN              *string   `json:"pdn" db:"pdn"` // %, nil — доход не подтверждён
+	FlagThreshold    string    `json:"flag_threshold" db:"flag_threshold"`
+	RejectThreshold  string    `json:"reject_threshold" db:"reject_threshold"`
+	Reasons          []string  `json:"reasons" db:"reasons"`
+	CheckedAt        time.Time `json:"checked_at" db:"checked_at"`
+}
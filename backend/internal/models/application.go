@@ -4,14 +4,33 @@ import (
 	"time"
 )
 
+// Типы кредита (credit_application_types)
+const (
+	LoanTypePersonal = "PERSONAL"
+	LoanTypeAuto     = "AUTO"
+	LoanTypeMortgage = "MORTGAGE"
+	LoanTypeOther    = "OTHER"
+)
+
 type CreditApplication struct {
 	ApplicationID   int64     `json:"application_id" db:"application_id"`
-	UserID          int64 `json:"user_id" db:"user_id"`
-	BankID          int16     `json:"bank_id" db:"bank_id"`
+	UserID          int64     `json:"user_id" db:"user_id"`
+	BankID          *int16    `json:"bank_id" db:"bank_id"`   // nil — консорциумная заявка, банки в участниках
+	AnyBank         bool      `json:"any_bank" db:"any_bank"` // адресована всем подходящим банкам
 	TypeCode        string    `json:"type_code" db:"type_code"`
 	StatusCode      string    `json:"status_code" db:"status_code"`
 	RequestedAmount string    `json:"requested_amount" db:"requested_amount"`
+	TermMonths      int       `json:"term_months" db:"term_months"`
+	ProductID       *int64    `json:"product_id" db:"product_id"` // nil — заявка без продукта каталога
 	LoanID          *int64    `json:"loan_id" db:"loan_id"`
 	SubmittedAt     time.Time `json:"submitted_at" db:"submitted_at"`
 	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
 }
+
+// ApprovalTerms — условия, на которых заявка одобряется в синдицированный кредит
+type ApprovalTerms struct {
+	Splits        []map[int16]string // bank_id → сумма доли; сумма долей равна RequestedAmount
+	InterestRate  string
+	TermMonths    int
+	RepaymentType string
+}
@@ -1,7 +1,8 @@
 package models
 
 type Bank struct {
-	BankID int16  `json:"bank_id" db:"bank_id"`
-	Code   string `json:"code" db:"code"`
-	Name   string `json:"name" db:"name"`
+	BankID          int16  `json:"bank_id" db:"bank_id"`
+	Code            string `json:"code" db:"code"`
+	Name            string `json:"name" db:"name"`
+	PaymentPriority int16  `json:"payment_priority" db:"payment_priority"` // меньше — раньше при распределении платежа
 }
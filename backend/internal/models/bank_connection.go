@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Статусы подключения к API банка
+const (
+	BankConnectionActive       = "ACTIVE"
+	BankConnectionDisconnected = "DISCONNECTED"
+)
+
+// BankConnection — подключение пользователя к API банка: по нему синхронизируются
+// остаток счёта и операции
+type BankConnection struct {
+	ConnectionID      int64      `json:"connection_id" db:"connection_id"`
+	UserID            int64      `json:"user_id" db:"user_id"`
+	BankID            int16      `json:"bank_id" db:"bank_id"`
+	CustomerID        string     `json:"customer_id" db:"customer_id"`                 // клиент в API банка
+	ExternalAccountID *string    `json:"external_account_id" db:"external_account_id"` // счёт в API банка
+	Cursor            string     `json:"-" db:"sync_cursor"`
+	BankBalance       *string    `json:"bank_balance" db:"bank_balance"`
+	Status            string     `json:"status" db:"status"`
+	LastSyncedAt      *time.Time `json:"last_synced_at" db:"last_synced_at"`
+	LastError         *string    `json:"last_error" db:"last_error"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// BankSyncReport — результат синхронизации подключения
+type BankSyncReport struct {
+	ConnectionID int64                `json:"connection_id"`
+	UserID       int64                `json:"user_id"`
+	BankID       int16                `json:"bank_id"`
+	AccountID    int64                `json:"account_id"` // счёт в user_bank_accounts
+	Fetched      int                  `json:"fetched"`    // операций получено от банка
+	Imported     int                  `json:"imported"`
+	Duplicates   int                  `json:"duplicates"`
+	Skipped      []StatementLineError `json:"skipped"`    // операции, которые не удалось загрузить; line — номер в ленте
+	Balance      string               `json:"balance"`    // остаток по данным банка
+	Adjustment   string               `json:"adjustment"` // на сколько изменён учётный остаток
+	SyncedAt     time.Time            `json:"synced_at"`
+}
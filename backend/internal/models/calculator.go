@@ -0,0 +1,44 @@
+package models
+
+// QuoteRequest — параметры ориентировочного расчёта кредита
+type QuoteRequest struct {
+	Amount        string `json:"amount"`
+	TermMonths    int    `json:"term_months"`
+	TypeCode      string `json:"type_code"`
+	ProductID     *int64 `json:"product_id"`
+	RepaymentType string `json:"repayment_type"`
+}
+
+// BankQuote — доля банка в ориентировочном расчёте
+type BankQuote struct {
+	BankID         int16   `json:"bank_id"`
+	BankName       string  `json:"bank_name"`
+	Amount         string  `json:"amount"`
+	Share          float64 `json:"share"` // % от суммы кредита
+	Rate           string  `json:"rate"`  // базовая ставка банка, % годовых
+	MonthlyPayment string  `json:"monthly_payment"`
+	TotalInterest  string  `json:"total_interest"`
+	TotalPayment   string  `json:"total_payment"`
+	Psk            string  `json:"psk"` // ПСК доли с учётом её части комиссий и страховки, % годовых
+}
+
+// LoanQuote — ориентировочный расчёт кредита по профилям кредиторов; не является офертой
+type LoanQuote struct {
+	Amount         string             `json:"amount"`
+	Currency       string             `json:"currency"`
+	TypeCode       string             `json:"type_code"`
+	ProductID      *int64             `json:"product_id,omitempty"`
+	TermMonths     int                `json:"term_months"`
+	RepaymentType  string             `json:"repayment_type"`
+	InterestRate   string             `json:"interest_rate"`   // средневзвешенная по долям ставка
+	MonthlyPayment string             `json:"monthly_payment"` // первый платёж; у аннуитета — постоянный
+	TotalInterest  string             `json:"total_interest"`
+	TotalPayment   string             `json:"total_payment"`
+	EffectiveRate  string             `json:"effective_rate"` // эффективная годовая ставка по сводному графику, %
+	Fees           string             `json:"fees"`           // комиссия за выдачу и плата за обслуживание за весь срок
+	Insurance      string             `json:"insurance"`
+	Psk            string             `json:"psk"`        // полная стоимость кредита, % годовых
+	PskAmount      string             `json:"psk_amount"` // ПСК в денежном выражении
+	Banks          []BankQuote        `json:"banks"`
+	Schedule       []LoanScheduleItem `json:"schedule"` // сводный график по всем банкам
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// Грейды кредитной оценки: A — лучший
+const (
+	CreditGradeA = "A"
+	CreditGradeB = "B"
+	CreditGradeC = "C"
+	CreditGradeD = "D"
+	CreditGradeE = "E"
+)
+
+// CreditScore — внутренняя кредитная оценка заёмщика (300–850)
+type CreditScore struct {
+	ScoreID       int64         `json:"score_id" db:"score_id"`
+	UserID        int64         `json:"user_id" db:"user_id"`
+	ApplicationID *int64        `json:"application_id" db:"application_id"` // заявка, при подаче которой посчитана оценка
+	Score         int           `json:"score" db:"score"`
+	Grade         string        `json:"grade" db:"grade"`
+	ModelVersion  string        `json:"model_version" db:"model_version"`
+	Factors       []ScoreFactor `json:"factors" db:"factors"`
+	Inputs        ScoringInputs `json:"inputs" db:"inputs"`
+	ComputedAt    time.Time     `json:"computed_at" db:"computed_at"`
+}
+
+// ScoreFactor — вклад одного фактора: Points прибавляются к базовой оценке
+type ScoreFactor struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Value       string `json:"value"` // значение показателя, на котором основан вклад
+	Points      int    `json:"points"`
+}
+
+// ScoringInputs — данные заёмщика для скоринга; обороты — за последние WindowMonths месяцев
+type ScoringInputs struct {
+	WindowMonths       int    `json:"window_months"`
+	Income             string `json:"income"`        // сумма поступлений
+	Expenses           string `json:"expenses"`      // сумма списаний, положительное число
+	IncomeMonths       int    `json:"income_months"` // месяцев с поступлениями
+	Balance            string `json:"balance"`       // остаток на всех счетах
+	Debt               string `json:"debt"`          // основной долг, проценты и пени по непогашенным кредитам
+	MaxDaysPastDue     int    `json:"max_days_past_due"`
+	DefaultedLoans     int    `json:"defaulted_loans"`
+	DueInstallments    int    `json:"due_installments"`     // наступивших плановых платежей
+	OnTimeInstallments int    `json:"on_time_installments"` // из них оплачено к сроку
+}
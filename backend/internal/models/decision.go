@@ -0,0 +1,103 @@
+package models
+
+import (
+	"time"
+)
+
+// Итог автоматического решения по заявке
+const (
+	DecisionApprove      = "APPROVE"
+	DecisionReject       = "REJECT"
+	DecisionManualReview = "MANUAL_REVIEW"
+	// DecisionExcludeBank — итог сработавшего BANK_BLACKLIST, пока у заявки остаются
+	// другие банки: банк BankID исключается из распределения, на решение не влияет
+	DecisionExcludeBank = "EXCLUDE_BANK"
+)
+
+// Виды правил
+const (
+	RuleMinScore      = "MIN_SCORE"      // оценка заёмщика ниже MinScore
+	RuleMaxPDN        = "MAX_PDN"        // ПДН выше MaxPDN или доход не подтверждён
+	RuleMaxAmount     = "MAX_AMOUNT"     // сумма заявки типа TypeCode выше MaxAmount
+	RuleBankBlacklist = "BANK_BLACKLIST" // заёмщик в стоп-листе банка-участника BankID
+)
+
+// DecisionRuleSet — декларативный набор правил из файла DECISION_RULES_FILE.
+// Решение — самый строгий итог среди сработавших правил (REJECT > MANUAL_REVIEW);
+// если не сработало ни одно — Default
+type DecisionRuleSet struct {
+	RuleSetID int64          `json:"rule_set_id,omitempty"`
+	Version   string         `json:"version"`
+	Checksum  string         `json:"checksum,omitempty"` // sha256 содержимого; различает правки без смены version
+	Default   string         `json:"default"`            // APPROVE | MANUAL_REVIEW
+	Rules     []DecisionRule `json:"rules"`
+	LoadedAt  time.Time      `json:"loaded_at,omitempty"`
+}
+
+// DecisionRule — одно правило; заполняются только поля, нужные его виду Kind.
+// TypeCode у любого правила ограничивает его заявками этого типа
+type DecisionRule struct {
+	ID          string  `json:"id"`
+	Kind        string  `json:"kind"`
+	Outcome     string  `json:"outcome"` // REJECT | MANUAL_REVIEW
+	Description string  `json:"description,omitempty"`
+	TypeCode    string  `json:"type_code,omitempty"`
+	MinScore    int     `json:"min_score,omitempty"`
+	MaxPDN      string  `json:"max_pdn,omitempty"`    // %
+	MaxAmount   string  `json:"max_amount,omitempty"` // руб.
+	BankID      int16   `json:"bank_id,omitempty"`
+	UserIDs     []int64 `json:"user_ids,omitempty"`
+}
+
+// DecisionInputs — данные заявки, по которым принимается решение
+type DecisionInputs struct {
+	UserID          int64   `json:"user_id"`
+	TypeCode        string  `json:"type_code"`
+	RequestedAmount string  `json:"requested_amount"`
+	TermMonths      int     `json:"term_months"`
+	BankIDs         []int16 `json:"bank_ids"`
+	Score           *int    `json:"score"`
+	PDN             *string `json:"pdn"` // nil — доход не подтверждён или проверки не было
+}
+
+// FiredRule — сработавшее правило с объяснением
+type FiredRule struct {
+	RuleID  string `json:"rule_id"`
+	Kind    string `json:"kind"`
+	Outcome string `json:"outcome"`
+	Reason  string `json:"reason"`
+	BankID  int16  `json:"bank_id,omitempty"` // BANK_BLACKLIST — банк, в стоп-листе которого заёмщик
+}
+
+// ApplicationDecision — автоматическое решение по заявке; хранится для аудита
+type ApplicationDecision struct {
+	DecisionID    int64          `json:"decision_id" db:"decision_id"`
+	ApplicationID int64          `json:"application_id" db:"application_id"`
+	RuleSetID     int64          `json:"rule_set_id" db:"rule_set_id"`
+	RuleVersion   string         `json:"rule_version" db:"rule_version"`
+	Outcome       string         `json:"outcome" db:"outcome"`
+	FiredRules    []FiredRule    `json:"fired_rules" db:"fired_rules"`
+	Inputs        DecisionInputs `json:"inputs" db:"inputs"`
+	Enforced      bool           `json:"enforced" db:"enforced"` // решение применено к заявке, а не только записано
+	DecidedAt     time.Time      `json:"decided_at" db:"decided_at"`
+}
+
+// DryRunResult — решение по исторической заявке при пробном наборе правил
+type DryRunResult struct {
+	ApplicationID   int64          `json:"application_id"`
+	StatusCode      string         `json:"status_code"`
+	Outcome         string         `json:"outcome"`
+	FiredRules      []FiredRule    `json:"fired_rules"`
+	Inputs          DecisionInputs `json:"inputs"`
+	PreviousOutcome *string        `json:"previous_outcome"` // решение по действовавшим правилам; nil — не принималось
+	Changed         bool           `json:"changed"`
+}
+
+// DryRunReport — сводка пробного прогона правил по историческим заявкам
+type DryRunReport struct {
+	RuleVersion string         `json:"rule_version"`
+	Evaluated   int            `json:"evaluated"`
+	Outcomes    map[string]int `json:"outcomes"`
+	Changed     int            `json:"changed"`
+	Results     []DryRunResult `json:"results"`
+}
@@ -0,0 +1,42 @@
+package models
+
+// DPD-корзины просрочки
+const (
+	DPDBucketCurrent = "CURRENT"
+	DPDBucket1To30   = "1-30"
+	DPDBucket31To60  = "31-60"
+	DPDBucket61To90  = "61-90"
+	DPDBucket90Plus  = "90+"
+)
+
+// LoanDelinquency — состояние просрочки по кредиту (по худшей доле)
+type LoanDelinquency struct {
+	DaysPastDue     int                `json:"days_past_due"`
+	Bucket          string             `json:"bucket"`
+	OverdueAmount   string             `json:"overdue_amount"`
+	PenaltyInterest string             `json:"penalty_interest"`
+	Splits          []SplitDelinquency `json:"splits"`
+}
+
+type SplitDelinquency struct {
+	SplitID         int64  `json:"split_id"`
+	BankID          int16  `json:"bank_id"`
+	DaysPastDue     int    `json:"days_past_due"`
+	Bucket          string `json:"bucket"`
+	OverdueAmount   string `json:"overdue_amount"`
+	PenaltyInterest string `json:"penalty_interest"`
+}
+
+// BankDelinquencyItem — просроченная доля в выборке по банку
+type BankDelinquencyItem struct {
+	LoanID             int64  `json:"loan_id" db:"loan_id"`
+	UserID             int64  `json:"user_id" db:"user_id"`
+	SplitID            int64  `json:"split_id" db:"split_id"`
+	BankID             int16  `json:"bank_id" db:"bank_id"`
+	LoanStatus         string `json:"loan_status" db:"status"`
+	DaysPastDue        int    `json:"days_past_due" db:"days_past_due"`
+	Bucket             string `json:"bucket"`
+	OverdueAmount      string `json:"overdue_amount" db:"overdue_amount"`
+	PenaltyInterest    string `json:"penalty_interest" db:"penalty_interest"`
+	RemainingPrincipal string `json:"remaining_principal" db:"remaining_principal"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// Риск-аппетит кредитора
+const (
+	RiskAppetiteLow    = "LOW"
+	RiskAppetiteMedium = "MEDIUM"
+	RiskAppetiteHigh   = "HIGH"
+)
+
+// CurrencyRUB — валюта кредитов платформы
+const CurrencyRUB = "RUB"
+
+// BankLendingSettings — параметры участия банка в синдицированных кредитах (профиль кредитора)
+type BankLendingSettings struct {
+	BankID          int16     `json:"bank_id" db:"bank_id"`
+	MinTicket       string    `json:"min_ticket" db:"min_ticket"`
+	MaxTicket       string    `json:"max_ticket" db:"max_ticket"`
+	ExposureLimit   string    `json:"exposure_limit" db:"exposure_limit"`
+	TargetShare     string    `json:"target_share" db:"target_share"` // numeric(5,2), %
+	Currencies      []string  `json:"currencies" db:"currencies"`
+	RiskAppetite    string    `json:"risk_appetite" db:"risk_appetite"`
+	Active          bool      `json:"active" db:"active"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+	CurrentExposure string    `json:"current_exposure" db:"current_exposure"` // непогашенный основной долг по банку
+}
+
+// SupportsCurrency сообщает, кредитует ли банк в валюте currency
+func (s *BankLendingSettings) SupportsCurrency(currency string) bool {
+	for _, c := range s.Currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
+
+// BankProductAppetite — условия банка по типу кредита
+type BankProductAppetite struct {
+	BankID   int16   `json:"bank_id" db:"bank_id"`
+	TypeCode string  `json:"type_code" db:"type_code"`
+	Weight   string  `json:"weight" db:"weight"`
+	BaseRate *string `json:"base_rate" db:"base_rate"` // nil — продукт не выдаётся
+}
+
+// LenderProfile — профиль кредитора для администрирования
+type LenderProfile struct {
+	BankLendingSettings
+	BankName          string                `json:"bank_name"`
+	AvailableCapacity string                `json:"available_capacity"` // exposure_limit − current_exposure
+	Products          []BankProductAppetite `json:"products"`
+}
+
+type ProposedSplit struct {
+	BankID   int16   `json:"bank_id"`
+	BankName string  `json:"bank_name"`
+	Amount   string  `json:"amount"`
+	Share    float64 `json:"share"` // % от суммы кредита
+	Rate     string  `json:"rate"`  // базовая ставка банка по типу кредита
+}
+
+// SplitProposal — предложенное распределение кредита между банками
+type SplitProposal struct {
+	Amount       string          `json:"amount"`
+	TypeCode     string          `json:"type_code"`
+	Currency     string          `json:"currency"`
+	InterestRate string          `json:"interest_rate"` // средневзвешенная по долям ставка
+	Splits       []ProposedSplit `json:"splits"`
+}
+
+// AsSplits переводит предложение в формат долей CreateLoan
+func (p *SplitProposal) AsSplits() []map[int16]string {
+	res := make([]map[int16]string, 0, len(p.Splits))
+	for _, s := range p.Splits {
+		res = append(res, map[int16]string{s.BankID: s.Amount})
+	}
+	return res
+}
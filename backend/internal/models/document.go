@@ -0,0 +1,38 @@
+package models
+
+import (
+	"io"
+	"time"
+)
+
+// Типы документов к заявке
+const (
+	DocumentTypeIncomeProof = "INCOME_PROOF" // справка о доходах, выписка
+	DocumentTypePassport    = "PASSPORT"
+	DocumentTypeCollateral  = "COLLATERAL" // документы на залог
+	DocumentTypeOther       = "OTHER"
+)
+
+// ApplicationDocument — документ, приложенный к кредитной заявке
+type ApplicationDocument struct {
+	DocumentID     int64     `json:"document_id" db:"document_id"`
+	ApplicationID  int64     `json:"application_id" db:"application_id"`
+	DocType        string    `json:"doc_type" db:"doc_type"`
+	FileName       string    `json:"file_name" db:"file_name"`
+	ContentType    string    `json:"content_type" db:"content_type"`
+	SizeBytes      int64     `json:"size_bytes" db:"size_bytes"`
+	ChecksumSHA256 string    `json:"checksum_sha256" db:"checksum_sha256"`
+	StorageKey     string    `json:"-" db:"storage_key"`
+	UploadedBy     *int64    `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// DocumentUpload — загружаемый файл; ContentType — тип, заявленный клиентом
+type DocumentUpload struct {
+	ApplicationID int64
+	UploadedBy    int64
+	DocType       string
+	FileName      string
+	ContentType   string
+	Content       io.Reader
+}
@@ -1,5 +1,9 @@
 package models
 
+import (
+	"time"
+)
+
 type UserStatsDTO struct {
 	User         *User  `json:"user"`
 	TotalBalance string `json:"total_balance"`
@@ -8,11 +12,73 @@ type UserStatsDTO struct {
 }
 
 type LoanDetailDTO struct {
-	Loan           *Loan       `json:"loan"`
-	Splits         []LoanSplit `json:"splits"`
-	PercentPaid    float64     `json:"percent_paid"`
-	RemainingDebt  string      `json:"remaining_debt"`
-	PaymentHistory []LoanPayment `json:"payment_history"`
+	Loan           *Loan            `json:"loan"`
+	Splits         []LoanSplit      `json:"splits"`
+	PercentPaid    float64          `json:"percent_paid"`
+	RemainingDebt  string           `json:"remaining_debt"`
+	PaymentHistory []LoanPayment    `json:"payment_history"`
+	Delinquency    *LoanDelinquency `json:"delinquency"`
+	// Остаток основного долга, начисленные проценты и пени; RemainingDebt — их сумма
+	RemainingPrincipal string         `json:"remaining_principal"`
+	AccruedInterest    string         `json:"accrued_interest"`
+	PenaltyInterest    string         `json:"penalty_interest"`
+	NextDue            *NextDueDTO    `json:"next_due"`
+	CreditCost         *CreditCostDTO `json:"credit_cost"`         // nil — ПСК не рассчитана (кредиты до её введения)
+	DisbursementStatus string         `json:"disbursement_status"` // PENDING | DISBURSED | PARTIAL | FAILED
+}
+
+// DisbursementDTO — результат зачисления долей кредита на счета заёмщика
+type DisbursementDTO struct {
+	LoanID int64       `json:"loan_id"`
+	Status string      `json:"status"` // PENDING | DISBURSED | PARTIAL | FAILED
+	Splits []LoanSplit `json:"splits"`
+}
+
+// CreditCostDTO — полная стоимость кредита при выдаче: ставка и переплата с разбивкой
+type CreditCostDTO struct {
+	Psk       string `json:"psk"`        // % годовых
+	PskAmount string `json:"psk_amount"` // проценты + комиссии + страховка
+	Interest  string `json:"interest"`   // проценты по графику
+	Fees      string `json:"fees"`       // комиссия за выдачу и плата за обслуживание за весь срок
+	Insurance string `json:"insurance"`
+}
+
+// NextDueDTO — ближайший плановый платёж; Amount включает непогашенную просрочку
+type NextDueDTO struct {
+	DueDate time.Time `json:"due_date"`
+	Amount  string    `json:"amount"`
+}
+
+type LoanDebtDTO struct {
+	LoanID          int64       `json:"loan_id"`
+	Status          string      `json:"status"`
+	Principal       string      `json:"principal"`
+	AccruedInterest string      `json:"accrued_interest"`
+	PenaltyInterest string      `json:"penalty_interest"`
+	Outstanding     string      `json:"outstanding"`
+	PercentPaid     float64     `json:"percent_paid"`
+	NextDue         *NextDueDTO `json:"next_due"`
+}
+
+type BankDebtDTO struct {
+	BankID          int16  `json:"bank_id"`
+	BankName        string `json:"bank_name"`
+	Principal       string `json:"principal"`
+	AccruedInterest string `json:"accrued_interest"`
+	PenaltyInterest string `json:"penalty_interest"`
+	Outstanding     string `json:"outstanding"`
+}
+
+// DebtSummaryDTO — задолженность пользователя по всем непогашенным кредитам
+type DebtSummaryDTO struct {
+	UserID          int64         `json:"user_id"`
+	TotalDebt       string        `json:"total_debt"`
+	Principal       string        `json:"principal"`
+	AccruedInterest string        `json:"accrued_interest"`
+	PenaltyInterest string        `json:"penalty_interest"`
+	NextDue         *NextDueDTO   `json:"next_due"`
+	ByBank          []BankDebtDTO `json:"by_bank"`
+	ByLoan          []LoanDebtDTO `json:"by_loan"`
 }
 
 type TransactionHistoryDTO struct {
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"time"
+)
+
+// Виды счетов главной книги
+const (
+	LedgerCustomerFunds    = "CUSTOMER_FUNDS"    // средства клиента на счёте в банке (user_bank_accounts)
+	LedgerLoanPrincipal    = "LOAN_PRINCIPAL"    // основной долг по доле кредита
+	LedgerBankSettlement   = "BANK_SETTLEMENT"   // расчёты банка с внешним миром: выдачи и поступления вне платформы
+	LedgerInterestIncome   = "INTEREST_INCOME"   // полученные проценты
+	LedgerPenaltyIncome    = "PENALTY_INCOME"    // полученные пени
+	LedgerFeeIncome        = "FEE_INCOME"        // комиссии
+	LedgerInsurancePayable = "INSURANCE_PAYABLE" // страховые премии к перечислению страховщику
+)
+
+const (
+	LedgerDebit  = "DEBIT"
+	LedgerCredit = "CREDIT"
+)
+
+// Типы записей
+const (
+	LedgerEntryOpening      = "OPENING"      // входящие остатки
+	LedgerEntryDisbursement = "DISBURSEMENT" // выдача: долг заёмщика против расчётов банка
+	LedgerEntryPayout       = "PAYOUT"       // зачисление выданных средств на счёт заёмщика
+	LedgerEntryRepayment    = "REPAYMENT"
+	LedgerEntryFee          = "FEE"
+	LedgerEntryTransfer     = "TRANSFER"
+	LedgerEntryBankSync     = "BANK_SYNC" // выравнивание остатка счёта по данным банка
+)
+
+type LedgerAccount struct {
+	LedgerAccountID int64     `json:"ledger_account_id" db:"ledger_account_id"`
+	Code            string    `json:"code" db:"code"`
+	Kind            string    `json:"kind" db:"kind"`
+	NormalBalance   string    `json:"normal_balance" db:"normal_balance"` // DEBIT | CREDIT
+	BankID          int16     `json:"bank_id" db:"bank_id"`
+	AccountID       *int64    `json:"account_id" db:"account_id"` // счёт клиента у CUSTOMER_FUNDS
+	SplitID         *int64    `json:"split_id" db:"split_id"`     // доля кредита у LOAN_PRINCIPAL
+	Currency        string    `json:"currency" db:"currency"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// LedgerEntry — запись журнала; сумма Amount её движений всегда равна нулю
+type LedgerEntry struct {
+	EntryID     int64           `json:"entry_id" db:"entry_id"`
+	EntryType   string          `json:"entry_type" db:"entry_type"`
+	Description string          `json:"description" db:"description"`
+	LoanID      *int64          `json:"loan_id" db:"loan_id"`
+	PaymentID   *int64          `json:"payment_id" db:"payment_id"`
+	OccurredAt  time.Time       `json:"occurred_at" db:"occurred_at"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	Postings    []LedgerPosting `json:"postings"`
+}
+
+type LedgerPosting struct {
+	PostingID       int64  `json:"posting_id" db:"posting_id"`
+	EntryID         int64  `json:"entry_id" db:"entry_id"`
+	LedgerAccountID int64  `json:"ledger_account_id" db:"ledger_account_id"`
+	Amount          string `json:"amount" db:"amount"` // > 0 — дебет, < 0 — кредит
+}
+
+// LedgerImbalance — запись, у которой дебет не равен кредиту
+type LedgerImbalance struct {
+	EntryID    int64  `json:"entry_id"`
+	Difference string `json:"difference"` // дебет − кредит
+}
+
+// LedgerMismatch — хранимый остаток расходится с остатком по проводкам
+type LedgerMismatch struct {
+	Kind   string `json:"kind"`   // CUSTOMER_FUNDS | LOAN_PRINCIPAL
+	RefID  int64  `json:"ref_id"` // account_id или split_id
+	Stored string `json:"stored"` // user_bank_accounts.balance / loan_splits.remaining_principal
+	Ledger string `json:"ledger"`
+}
+
+// LedgerCheckReport — результат сверки главной книги
+type LedgerCheckReport struct {
+	CheckedAt         time.Time         `json:"checked_at"`
+	Entries           int64             `json:"entries"`
+	Postings          int64             `json:"postings"`
+	TrialBalance      string            `json:"trial_balance"` // Σ всех движений, должна быть 0
+	UnbalancedEntries []LedgerImbalance `json:"unbalanced_entries"`
+	Mismatches        []LedgerMismatch  `json:"mismatches"`
+}
+
+func (r *LedgerCheckReport) OK() bool {
+	return len(r.UnbalancedEntries) == 0 && len(r.Mismatches) == 0 && r.TrialBalance == "0.00"
+}
@@ -4,21 +4,79 @@ import (
 	"time"
 )
 
+const (
+	LoanStatusActive    = "ACTIVE"
+	LoanStatusOverdue   = "OVERDUE"   // есть просрочка
+	LoanStatusDefaulted = "DEFAULTED" // просрочка дольше порога дефолта
+	LoanStatusClosed    = "CLOSED"
+)
+
+// Статусы зачисления доли кредита на счёт заёмщика
+const (
+	DisbursementPending   = "PENDING"
+	DisbursementDisbursed = "DISBURSED"
+	DisbursementFailed    = "FAILED"
+	DisbursementPartial   = "PARTIAL" // только для кредита: часть долей зачислена
+)
+
+const (
+	AccrualTypeInterest = "INTEREST"
+	AccrualTypePenalty  = "PENALTY"
+)
+
 type Loan struct {
-	LoanID         int64     `json:"loan_id" db:"loan_id"`
-	UserID         int64 `json:"user_id" db:"user_id"`
-	OriginalAmount string    `json:"original_amount" db:"original_amount"` // numeric
-	TakenAt        time.Time `json:"taken_at" db:"taken_at"`
-	InterestRate   string    `json:"interest_rate" db:"interest_rate"`     // numeric(5,2)
-	Status         string    `json:"status" db:"status"`
-	Purpose        string    `json:"purpose" db:"purpose"`
-	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	LoanID             int64     `json:"loan_id" db:"loan_id"`
+	UserID             int64     `json:"user_id" db:"user_id"`
+	OriginalAmount     string    `json:"original_amount" db:"original_amount"` // numeric
+	TakenAt            time.Time `json:"taken_at" db:"taken_at"`
+	InterestRate       string    `json:"interest_rate" db:"interest_rate"` // numeric(5,2)
+	Status             string    `json:"status" db:"status"`
+	Purpose            string    `json:"purpose" db:"purpose"`
+	TermMonths         int       `json:"term_months" db:"term_months"`
+	RepaymentType      string    `json:"repayment_type" db:"repayment_type"`             // ANNUITY | DIFFERENTIATED
+	DayCountConvention string    `json:"day_count_convention" db:"day_count_convention"` // ACT_365 | ACT_ACT | 30_360
+	ProductID          *int64    `json:"product_id" db:"product_id"`                     // nil — кредит без продукта каталога
+	OriginationFee     string    `json:"origination_fee" db:"origination_fee"`           // комиссия за выдачу
+	InsurancePremium   string    `json:"insurance_premium" db:"insurance_premium"`       // страховая премия при выдаче
+	MonthlyFee         string    `json:"monthly_fee" db:"monthly_fee"`                   // плата за обслуживание в месяц
+	GracePeriodDays    int       `json:"grace_period_days" db:"grace_period_days"`       // дней после даты платежа без пени
+	Psk                *string   `json:"psk" db:"psk"`                                   // ПСК, % годовых; nil — не рассчитана
+	PskAmount          *string   `json:"psk_amount" db:"psk_amount"`                     // ПСК в денежном выражении
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
 }
 
 type LoanSplit struct {
-	SplitID            int64  `json:"split_id" db:"split_id"`
-	LoanID             int64  `json:"loan_id" db:"loan_id"`
-	BankID             int16  `json:"bank_id" db:"bank_id"`
-	SplitAmount        string `json:"split_amount" db:"split_amount"`
-	RemainingPrincipal string `json:"remaining_principal" db:"remaining_principal"`
+	SplitID            int64     `json:"split_id" db:"split_id"`
+	LoanID             int64     `json:"loan_id" db:"loan_id"`
+	BankID             int16     `json:"bank_id" db:"bank_id"`
+	SplitAmount        string    `json:"split_amount" db:"split_amount"`
+	RemainingPrincipal string    `json:"remaining_principal" db:"remaining_principal"`
+	InterestRate       string    `json:"interest_rate" db:"interest_rate"`       // numeric(5,2)
+	AccruedInterest    string    `json:"accrued_interest" db:"accrued_interest"` // начислено и не уплачено
+	AccruedThrough     time.Time `json:"accrued_through" db:"accrued_through"`   // проценты начислены по эту дату включительно
+	DaysPastDue        int       `json:"days_past_due" db:"days_past_due"`
+	OverdueAmount      string    `json:"overdue_amount" db:"overdue_amount"`     // просроченные плановые платежи
+	PenaltyInterest    string    `json:"penalty_interest" db:"penalty_interest"` // начисленные и не уплаченные пени
+	InterestRemainder  float64   `json:"-" db:"interest_remainder"`              // доли копейки процентов, перенесённые на следующий день
+	PenaltyRemainder   float64   `json:"-" db:"penalty_remainder"`               // то же для пени
+	Psk                *string   `json:"psk" db:"psk"`                           // ПСК доли при выдаче, % годовых
+	PskAmount          *string   `json:"psk_amount" db:"psk_amount"`
+	// Зачисление доли на счёт заёмщика в банке BankID
+	DisbursementStatus   string     `json:"disbursement_status" db:"disbursement_status"` // PENDING | DISBURSED | FAILED
+	DisbursementAttempts int        `json:"disbursement_attempts" db:"disbursement_attempts"`
+	DisbursementError    *string    `json:"disbursement_error" db:"disbursement_error"` // причина последней неудачи
+	DisbursedAt          *time.Time `json:"disbursed_at" db:"disbursed_at"`
+	AccountID            *int64     `json:"account_id" db:"account_id"`
+	TransactionID        *int64     `json:"transaction_id" db:"transaction_id"`
+}
+
+type InterestAccrual struct {
+	AccrualID          int64     `json:"accrual_id" db:"accrual_id"`
+	SplitID            int64     `json:"split_id" db:"split_id"`
+	AccrualDate        time.Time `json:"accrual_date" db:"accrual_date"`
+	PrincipalBase      string    `json:"principal_base" db:"principal_base"`
+	InterestRate       string    `json:"interest_rate" db:"interest_rate"`
+	DayCountConvention string    `json:"day_count_convention" db:"day_count_convention"`
+	Amount             string    `json:"amount" db:"amount"`
+	AccrualType        string    `json:"accrual_type" db:"accrual_type"` // INTEREST | PENALTY
 }
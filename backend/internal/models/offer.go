@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Статусы предложения банка по заявке
+const (
+	OfferStatusPending   = "PENDING"
+	OfferStatusAccepted  = "ACCEPTED"
+	OfferStatusDeclined  = "DECLINED"
+	OfferStatusExpired   = "EXPIRED"
+	OfferStatusWithdrawn = "WITHDRAWN"
+)
+
+// ApplicationOffer — предложение банка по кредитной заявке
+type ApplicationOffer struct {
+	OfferID        int64      `json:"offer_id" db:"offer_id"`
+	ApplicationID  int64      `json:"application_id" db:"application_id"`
+	BankID         int16      `json:"bank_id" db:"bank_id"`
+	Amount         string     `json:"amount" db:"amount"`
+	InterestRate   string     `json:"interest_rate" db:"interest_rate"`
+	TermMonths     int        `json:"term_months" db:"term_months"`
+	RepaymentType  string     `json:"repayment_type" db:"repayment_type"`
+	Psk            *string    `json:"psk" db:"psk"`               // ПСК предложения, % годовых
+	PskAmount      *string    `json:"psk_amount" db:"psk_amount"` // ПСК в денежном выражении
+	Status         string     `json:"status" db:"status"`
+	ExpiresAt      time.Time  `json:"expires_at" db:"expires_at"`
+	CreatedBy      *int64     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	DecidedAt      *time.Time `json:"decided_at" db:"decided_at"`
+	DecisionReason *string    `json:"decision_reason" db:"decision_reason"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// Статусы участия банка в заявке
+const (
+	ParticipantStatusInvited  = "INVITED"  // ждём предложения банка
+	ParticipantStatusOffered  = "OFFERED"  // у банка есть действующее предложение
+	ParticipantStatusDeclined = "DECLINED" // банк отказался участвовать
+	ParticipantStatusAccepted = "ACCEPTED" // доля банка вошла в кредит
+	ParticipantStatusClosed   = "CLOSED"   // заявка завершена без этого банка
+)
+
+// Сводный статус участия банков в заявке
+const (
+	ParticipationAwaitingOffers = "AWAITING_OFFERS"
+	ParticipationOffersReceived = "OFFERS_RECEIVED"
+	ParticipationAllDeclined    = "ALL_DECLINED"
+	ParticipationAccepted       = "ACCEPTED"
+	ParticipationClosed         = "CLOSED"
+)
+
+// ApplicationParticipant — банк, которому адресована заявка
+type ApplicationParticipant struct {
+	ApplicationID int64     `json:"application_id" db:"application_id"`
+	BankID        int16     `json:"bank_id" db:"bank_id"`
+	BankName      string    `json:"bank_name" db:"bank_name"`
+	Status        string    `json:"status" db:"status"`
+	DeclineReason *string   `json:"decline_reason" db:"decline_reason"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// ParticipationSummary — сводка участия банков по заявке
+type ParticipationSummary struct {
+	Status   string `json:"status"`
+	Total    int    `json:"total"`
+	Invited  int    `json:"invited"`
+	Offered  int    `json:"offered"`
+	Declined int    `json:"declined"`
+	Accepted int    `json:"accepted"`
+	Closed   int    `json:"closed"`
+}
+
+// ApplicationDetailDTO — заявка с участниками, сводным статусом участия и предскорингом
+type ApplicationDetailDTO struct {
+	CreditApplication
+	Participants  []ApplicationParticipant `json:"participants"`
+	Participation ParticipationSummary     `json:"participation"`
+	Score         *CreditScore             `json:"score"`         // nil у черновика
+	Affordability *AffordabilityAssessment `json:"affordability"` // nil у черновика
+	Decision      *ApplicationDecision     `json:"decision"`      // последнее автоматическое решение
+}
+
+// BankApplicationDTO — заявка глазами банка-участника
+type BankApplicationDTO struct {
+	CreditApplication
+	ParticipantStatus string `json:"participant_status"`
+}
@@ -2,16 +2,23 @@ package models
 
 import (
 	"time"
+)
 
+const (
+	PaymentTypeRegular    = "REGULAR"
+	PaymentTypePrepayment = "PREPAYMENT"
 )
 
 type LoanPayment struct {
 	PaymentID   int64     `json:"payment_id" db:"payment_id"`
 	LoanID      int64     `json:"loan_id" db:"loan_id"`
-	UserID      int64 `json:"user_id" db:"user_id"`
+	UserID      int64     `json:"user_id" db:"user_id"`
 	PaidAt      time.Time `json:"paid_at" db:"paid_at"`
 	TotalAmount string    `json:"total_amount" db:"total_amount"`
 	Comment     string    `json:"comment" db:"comment"`
+	// PRO_RATA | HIGHEST_RATE_FIRST | BANK_PRIORITY
+	AllocationStrategy string `json:"allocation_strategy" db:"allocation_strategy"`
+	PaymentType        string `json:"payment_type" db:"payment_type"` // REGULAR | PREPAYMENT
 }
 
 type PaymentAllocation struct {
@@ -20,4 +27,21 @@ type PaymentAllocation struct {
 	SplitID       int64  `json:"split_id" db:"split_id"`
 	PrincipalPaid string `json:"principal_paid" db:"principal_paid"`
 	InterestPaid  string `json:"interest_paid" db:"interest_paid"`
+	PenaltyPaid   string `json:"penalty_paid" db:"penalty_paid"`
+	FeePaid       string `json:"fee_paid" db:"fee_paid"` // плата за обслуживание
+}
+
+type PaymentResultDTO struct {
+	Payment       *LoanPayment        `json:"payment"`
+	Allocations   []PaymentAllocation `json:"allocations"`
+	RemainingDebt string              `json:"remaining_debt"`
+	LoanStatus    string              `json:"loan_status"`
+}
+
+type PrepaymentResultDTO struct {
+	PaymentResultDTO
+	Mode     string           `json:"mode"`
+	Schedule *LoanScheduleDTO `json:"schedule"`
+	// RegularPayment — часть суммы, зачтённая в плановые платежи со сроком по дату погашения
+	RegularPayment *PaymentResultDTO `json:"regular_payment,omitempty"`
 }
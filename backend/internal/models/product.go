@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// LoanProduct — кредитный продукт каталога: ограничения по сумме, сроку и ставке,
+// комиссии и банки, которые его выдают
+type LoanProduct struct {
+	ProductID             int64     `json:"product_id" db:"product_id"`
+	Code                  string    `json:"code" db:"code"`
+	Name                  string    `json:"name" db:"name"`
+	Description           string    `json:"description" db:"description"`
+	TypeCode              string    `json:"type_code" db:"type_code"`
+	Currency              string    `json:"currency" db:"currency"`
+	MinAmount             string    `json:"min_amount" db:"min_amount"`
+	MaxAmount             string    `json:"max_amount" db:"max_amount"`
+	MinTermMonths         int       `json:"min_term_months" db:"min_term_months"`
+	MaxTermMonths         int       `json:"max_term_months" db:"max_term_months"`
+	MinRate               string    `json:"min_rate" db:"min_rate"` // % годовых
+	MaxRate               string    `json:"max_rate" db:"max_rate"`
+	OriginationFeePercent string    `json:"origination_fee_percent" db:"origination_fee_percent"` // % от суммы, при выдаче
+	OriginationFeeFixed   string    `json:"origination_fee_fixed" db:"origination_fee_fixed"`     // руб., при выдаче
+	MonthlyFee            string    `json:"monthly_fee" db:"monthly_fee"`                         // руб. в месяц за обслуживание
+	InsurancePercent      string    `json:"insurance_percent" db:"insurance_percent"`             // % от суммы, страховая премия при выдаче
+	GracePeriodDays       int       `json:"grace_period_days" db:"grace_period_days"`             // дней после даты платежа без пени
+	BankIDs               []int16   `json:"bank_ids" db:"-"`                                      // банки, выдающие продукт
+	Active                bool      `json:"active" db:"active"`
+	CreatedAt             time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at" db:"updated_at"`
+}
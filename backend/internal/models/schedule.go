@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+type LoanScheduleItem struct {
+	ItemID           int64     `json:"item_id" db:"item_id"`
+	SplitID          int64     `json:"split_id" db:"split_id"`
+	InstallmentNo    int       `json:"installment_no" db:"installment_no"`
+	DueDate          time.Time `json:"due_date" db:"due_date"`
+	Principal        string    `json:"principal" db:"principal"`
+	Interest         string    `json:"interest" db:"interest"`
+	Fee              string    `json:"fee" db:"fee"` // плата за обслуживание; в total_payment не входит
+	TotalPayment     string    `json:"total_payment" db:"total_payment"`
+	RemainingBalance string    `json:"remaining_balance" db:"remaining_balance"`
+}
+
+type SplitScheduleDTO struct {
+	SplitID int64              `json:"split_id"`
+	BankID  int16              `json:"bank_id"`
+	Items   []LoanScheduleItem `json:"items"`
+}
+
+type LoanScheduleDTO struct {
+	Loan   *Loan              `json:"loan"`
+	Splits []SplitScheduleDTO `json:"splits"`
+	// Combined — сводный график по всем банкам (суммы по номеру платежа)
+	Combined []LoanScheduleItem `json:"combined"`
+}
@@ -0,0 +1,28 @@
+package models
+
+// Форматы банковских выписок
+const (
+	StatementCSV     = "CSV"
+	StatementOFX     = "OFX"
+	StatementCAMT053 = "CAMT053" // ISO 20022 camt.053
+	StatementMT940   = "MT940"
+)
+
+// StatementLineError — строка выписки, которую не удалось импортировать
+type StatementLineError struct {
+	Line  int    `json:"line"` // номер строки файла, с 1
+	Error string `json:"error"`
+}
+
+// StatementImportReport — результат импорта выписки
+type StatementImportReport struct {
+	UserID     int64                `json:"user_id"`
+	BankID     int16                `json:"bank_id"`
+	Format     string               `json:"format"`
+	Account    string               `json:"account"` // номер счёта из выписки, если формат его содержит
+	Total      int                  `json:"total"`   // операций в выписке, включая ошибочные
+	Imported   int                  `json:"imported"`
+	Duplicates int                  `json:"duplicates"` // уже были в transactions
+	Failed     int                  `json:"failed"`
+	Errors     []StatementLineError `json:"errors"`
+}
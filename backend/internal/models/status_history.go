@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	StatusEntityLoan        = "LOAN"
+	StatusEntityApplication = "APPLICATION"
+)
+
+const (
+	ApplicationStatusDraft     = "DRAFT" // черновик, банкам ещё не отправлен
+	ApplicationStatusPending   = "PENDING"
+	ApplicationStatusApproved  = "APPROVED"
+	ApplicationStatusRejected  = "REJECTED"
+	ApplicationStatusCancelled = "CANCELLED"
+)
+
+type StatusHistoryEntry struct {
+	HistoryID  int64     `json:"history_id" db:"history_id"`
+	EntityType string    `json:"entity_type" db:"entity_type"` // LOAN | APPLICATION
+	EntityID   int64     `json:"entity_id" db:"entity_id"`
+	FromStatus *string   `json:"from_status" db:"from_status"` // nil — создание
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	ChangedBy  *int64    `json:"changed_by" db:"changed_by"` // nil — системное изменение
+	Reason     string    `json:"reason" db:"reason"`
+	ChangedAt  time.Time `json:"changed_at" db:"changed_at"`
+}
@@ -4,6 +4,20 @@ import (
 	"time"
 )
 
+// Категории транзакций, которые создаёт сама платформа
+const (
+	TransactionCategoryLoanDisbursement = "loan_disbursement"
+	TransactionCategoryTransfer         = "transfer"      // перевод между своими счетами: не доход и не расход
+	TransactionCategoryUncategorized    = "uncategorized" // импорт из выписки без категории
+)
+
+// Источники транзакций
+const (
+	TransactionSourcePlatform  = "PLATFORM"  // создана платформой: выдача кредита, перевод
+	TransactionSourceBankAPI   = "BANK_API"  // получена из API банка
+	TransactionSourceStatement = "STATEMENT" // из выписки, загруженной клиентом: в скоринг и ПДН не входит
+)
+
 type Transaction struct {
 	TransactionID int64     `json:"transaction_id" db:"transaction_id"`
 	UserID        int64     `json:"user_id" db:"user_id"`
@@ -12,4 +26,6 @@ type Transaction struct {
 	Amount        string    `json:"amount" db:"amount"`
 	Category      string    `json:"category" db:"category"`
 	Description   string    `json:"description" db:"description"`
-}
\ No newline at end of file
+	ExternalID    *string   `json:"external_id,omitempty" db:"external_id"` // ключ операции из выписки для дедупликации
+	Source        string    `json:"source" db:"source"`                     // PLATFORM | BANK_API | STATEMENT
+}
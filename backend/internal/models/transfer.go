@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+)
+
+// Статусы перевода
+const (
+	TransferStatusScheduled = "SCHEDULED" // ожидает следующего запуска
+	TransferStatusCompleted = "COMPLETED" // разовый перевод выполнен или регулярный завершён
+	TransferStatusFailed    = "FAILED"    // разовый перевод не выполнен
+	TransferStatusCancelled = "CANCELLED"
+)
+
+// Периодичность перевода
+const (
+	TransferOnce    = "ONCE"
+	TransferDaily   = "DAILY"
+	TransferWeekly  = "WEEKLY"
+	TransferMonthly = "MONTHLY"
+)
+
+const (
+	ExecutionCompleted = "COMPLETED"
+	ExecutionFailed    = "FAILED"
+)
+
+// Transfer — перевод между собственными счетами пользователя
+type Transfer struct {
+	TransferID    int64      `json:"transfer_id" db:"transfer_id"`
+	UserID        int64      `json:"user_id" db:"user_id"`
+	FromAccountID int64      `json:"from_account_id" db:"from_account_id"`
+	ToAccountID   int64      `json:"to_account_id" db:"to_account_id"`
+	Amount        string     `json:"amount" db:"amount"`
+	Currency      string     `json:"currency" db:"currency"`
+	Description   string     `json:"description" db:"description"`
+	Status        string     `json:"status" db:"status"`
+	Recurrence    string     `json:"recurrence" db:"recurrence"` // ONCE | DAILY | WEEKLY | MONTHLY
+	StartsAt      time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at" db:"ends_at"`         // последний возможный запуск регулярного перевода
+	NextRunAt     *time.Time `json:"next_run_at" db:"next_run_at"` // nil — запусков больше не будет
+	Runs          int        `json:"runs" db:"runs"`
+	LastError     *string    `json:"last_error" db:"last_error"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+
+	Executions []TransferExecution `json:"executions,omitempty"`
+}
+
+// TransferExecution — один запуск перевода
+type TransferExecution struct {
+	ExecutionID         int64     `json:"execution_id" db:"execution_id"`
+	TransferID          int64     `json:"transfer_id" db:"transfer_id"`
+	Status              string    `json:"status" db:"status"` // COMPLETED | FAILED
+	Amount              string    `json:"amount" db:"amount"`
+	ScheduledFor        time.Time `json:"scheduled_for" db:"scheduled_for"`
+	ExecutedAt          time.Time `json:"executed_at" db:"executed_at"`
+	DebitTransactionID  *int64    `json:"debit_transaction_id" db:"debit_transaction_id"`
+	CreditTransactionID *int64    `json:"credit_transaction_id" db:"credit_transaction_id"`
+	LedgerEntryID       *int64    `json:"ledger_entry_id" db:"ledger_entry_id"`
+	Error               *string   `json:"error" db:"error"`
+}
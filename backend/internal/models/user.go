@@ -4,12 +4,21 @@ import (
 	"time"
 )
 
+// Роли пользователей
+const (
+	RoleClient = "CLIENT"
+	RoleAdmin  = "ADMIN"
+	RoleBank   = "BANK" // сотрудник банка, см. User.BankID
+)
+
 type User struct {
 	UserID       int64     `json:"user_id" db:"user_id"`
 	FullName     string    `json:"full_name" db:"full_name"`
 	Email        string    `json:"email" db:"email"`
 	Phone        string    `json:"phone" db:"phone"`
 	PasswordHash string    `json:"-" db:"password_hash"`
+	Role         string    `json:"role" db:"role"`
+	BankID       *int16    `json:"bank_id,omitempty" db:"bank_id"` // только для роли BANK
 	CreatedAt    time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
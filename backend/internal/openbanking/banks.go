@@ -0,0 +1,20 @@
+package openbanking
+
+// bankAPI — особенности API конкретного банка. Ресурсы у всех одинаковые
+// (customers/{id}/accounts, …/balances, …/transactions, customers/{id}/payments),
+// различаются префикс пути, имя параметра курсора и вид остатка, который банк считает основным
+type bankAPI struct {
+	BasePath    string
+	CursorParam string
+	BalanceType string
+	PageSize    int // операций на страницу; 0 — по умолчанию банка
+}
+
+// bankAPIs — API банков по коду из справочника banks
+var bankAPIs = map[string]bankAPI{
+	"ALFA":  {BasePath: "/open-banking/v1", CursorParam: "cursor", BalanceType: "CLBD", PageSize: 100},
+	"VTB":   {BasePath: "/openapi/v1", CursorParam: "page_token", BalanceType: "CLBD", PageSize: 200},
+	"SBER":  {BasePath: "/fintech/api/v1", CursorParam: "cursor", BalanceType: "ITAV", PageSize: 100},
+	"TBANK": {BasePath: "/api/v1", CursorParam: "after", BalanceType: "ITAV", PageSize: 50},
+	"OPT":   {BasePath: "/ob/v1", CursorParam: "cursor", BalanceType: "CLBD"},
+}
@@ -0,0 +1,132 @@
+package openbanking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+var (
+	// ErrNotFound — банк не знает клиента, счёт или платёж
+	ErrNotFound = errors.New("not found in bank")
+	// ErrUnauthorized — банк отклонил токен или согласие клиента
+	ErrUnauthorized = errors.New("bank rejected credentials")
+)
+
+// Account — счёт клиента в банке
+type Account struct {
+	AccountID string `json:"account_id"` // идентификатор счёта в API банка
+	Number    string `json:"number"`     // номер счёта
+	Currency  string `json:"currency"`
+	Name      string `json:"name"`
+}
+
+// Balance — остаток счёта; Type — вид остатка по ISO 20022: CLBD (закрытый), ITAV (доступный) и т.п.
+type Balance struct {
+	Type     string    `json:"type"`
+	Amount   string    `json:"amount"`
+	Currency string    `json:"currency"`
+	AsOf     time.Time `json:"as_of"`
+}
+
+// Transaction — проведённая операция по счёту; Amount со знаком: списание отрицательное
+type Transaction struct {
+	TransactionID string    `json:"transaction_id"`
+	BookedAt      time.Time `json:"booked_at"`
+	Amount        string    `json:"amount"`
+	Currency      string    `json:"currency"`
+	Description   string    `json:"description"`
+	Category      string    `json:"category,omitempty"`
+}
+
+// TransactionPage — страница операций; NextCursor передаётся в следующий запрос,
+// после последней страницы по нему запрашиваются только новые операции
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"next_cursor"`
+	HasMore      bool          `json:"has_more"`
+}
+
+// PaymentRequest — исходящий платёж со счёта клиента
+type PaymentRequest struct {
+	FromAccountID   string `json:"from_account_id"`
+	ToAccountNumber string `json:"to_account_number"`
+	ToBIC           string `json:"to_bic,omitempty"`
+	Amount          string `json:"amount"`
+	Currency        string `json:"currency"`
+	Description     string `json:"description"`
+	IdempotencyKey  string `json:"-"` // повтор запроса с тем же ключом не создаёт второй платёж
+}
+
+// Payment — принятый банком платёж
+type Payment struct {
+	PaymentID string    `json:"payment_id"`
+	Status    string    `json:"status"` // ACCEPTED | COMPLETED | REJECTED
+	CreatedAt time.Time `json:"created_at"`
+}
+
+const (
+	PaymentAccepted  = "ACCEPTED"
+	PaymentCompleted = "COMPLETED"
+	PaymentRejected  = "REJECTED"
+)
+
+// BankConnector — доступ к API банка от имени клиента customerID (идентификатор клиента,
+// под которым банк выдал согласие на доступ к счетам)
+type BankConnector interface {
+	BankCode() string
+	ListAccounts(ctx context.Context, customerID string) ([]Account, error)
+	// GetBalance — остаток счёта того вида, который банк считает основным
+	GetBalance(ctx context.Context, customerID, accountID string) (*Balance, error)
+	// ListTransactions — операции после cursor; пустой cursor — с начала истории
+	ListTransactions(ctx context.Context, customerID, accountID, cursor string) (*TransactionPage, error)
+	InitiatePayment(ctx context.Context, customerID string, req *PaymentRequest) (*Payment, error)
+}
+
+// Connectors — коннекторы по коду банка
+type Connectors map[string]BankConnector
+
+// For — коннектор банка; nil, если API банка не настроено
+func (c Connectors) For(bankCode string) BankConnector {
+	return c[bankCode]
+}
+
+// Codes — коды банков с настроенным API по алфавиту
+func (c Connectors) Codes() []string {
+	codes := make([]string, 0, len(c))
+	for code := range c {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// NewConnectors создаёт коннекторы банков из конфигурации. Если включён mock, банки без адреса
+// направляются на mockURL
+func NewConnectors(cfg config.OpenBankingConfig, mockURL string) (Connectors, error) {
+	connectors := make(Connectors, len(bankAPIs))
+	for code, api := range bankAPIs {
+		endpoint, ok := cfg.Banks[code]
+		if !ok && cfg.Mock && mockURL != "" {
+			endpoint, ok = config.BankAPIConfig{BaseURL: mockURL, Token: "mock-" + code}, true
+		}
+		if !ok {
+			continue
+		}
+		c, err := newHTTPConnector(code, api, endpoint, cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("bank %s: %w", code, err)
+		}
+		connectors[code] = c
+	}
+	for code := range cfg.Banks {
+		if _, ok := bankAPIs[code]; !ok {
+			return nil, fmt.Errorf("bank %s: no connector for this bank", code)
+		}
+	}
+	return connectors, nil
+}
@@ -0,0 +1,166 @@
+package openbanking
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+// maxResponseBytes — ограничение на размер ответа банка
+const maxResponseBytes = 8 << 20
+
+// httpConnector ходит в JSON API банка с токеном в заголовке Authorization
+type httpConnector struct {
+	code    string
+	api     bankAPI
+	baseURL *url.URL
+	token   string
+	client  *http.Client
+}
+
+func newHTTPConnector(code string, api bankAPI, endpoint config.BankAPIConfig, timeout time.Duration) (*httpConnector, error) {
+	base, err := url.Parse(endpoint.BaseURL)
+	if err != nil || base.Host == "" {
+		return nil, fmt.Errorf("invalid API URL %q", endpoint.BaseURL)
+	}
+	if timeout <= 0 {
+		timeout = 15 * time.Second
+	}
+	return &httpConnector{
+		code:    code,
+		api:     api,
+		baseURL: base,
+		token:   endpoint.Token,
+		client:  &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (c *httpConnector) BankCode() string {
+	return c.code
+}
+
+func (c *httpConnector) ListAccounts(ctx context.Context, customerID string) ([]Account, error) {
+	var resp struct {
+		Accounts []Account `json:"accounts"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.path("customers", customerID, "accounts"), nil, nil, "", &resp); err != nil {
+		return nil, err
+	}
+	return resp.Accounts, nil
+}
+
+func (c *httpConnector) GetBalance(ctx context.Context, customerID, accountID string) (*Balance, error) {
+	var resp struct {
+		Balances []Balance `json:"balances"`
+	}
+	if err := c.do(ctx, http.MethodGet, c.path("customers", customerID, "accounts", accountID, "balances"), nil, nil, "", &resp); err != nil {
+		return nil, err
+	}
+	for i := range resp.Balances {
+		if resp.Balances[i].Type == c.api.BalanceType {
+			return &resp.Balances[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s: no %s balance for account %s", c.code, c.api.BalanceType, accountID)
+}
+
+func (c *httpConnector) ListTransactions(ctx context.Context, customerID, accountID, cursor string) (*TransactionPage, error) {
+	query := url.Values{}
+	if cursor != "" {
+		query.Set(c.api.CursorParam, cursor)
+	}
+	if c.api.PageSize > 0 {
+		query.Set("limit", strconv.Itoa(c.api.PageSize))
+	}
+	page := &TransactionPage{}
+	if err := c.do(ctx, http.MethodGet, c.path("customers", customerID, "accounts", accountID, "transactions"), query, nil, "", page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (c *httpConnector) InitiatePayment(ctx context.Context, customerID string, req *PaymentRequest) (*Payment, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	payment := &Payment{}
+	if err := c.do(ctx, http.MethodPost, c.path("customers", customerID, "payments"), nil, body, req.IdempotencyKey, payment); err != nil {
+		return nil, err
+	}
+	return payment, nil
+}
+
+// path — путь ресурса под префиксом API банка; сегменты экранируются
+func (c *httpConnector) path(segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, s := range segments {
+		escaped[i] = url.PathEscape(s)
+	}
+	return strings.TrimSuffix(c.baseURL.Path, "/") + c.api.BasePath + "/" + strings.Join(escaped, "/")
+}
+
+func (c *httpConnector) do(ctx context.Context, method, path string, query url.Values, body []byte, idempotencyKey string, out any) error {
+	u := *c.baseURL
+	u.Path, _ = url.PathUnescape(path)
+	u.RawPath = path
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s %s: %w", c.code, method, path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("%s %s %s: %w", c.code, method, path, err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s %s: %w", c.code, path, ErrNotFound)
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s %s: %w", c.code, path, ErrUnauthorized)
+	case resp.StatusCode >= 300:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		if apiErr.Error == "" {
+			apiErr.Error = strings.TrimSpace(string(data))
+		}
+		return fmt.Errorf("%s %s %s: status %d: %s", c.code, method, path, resp.StatusCode, apiErr.Error)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s %s: invalid response: %w", c.code, method, path, err)
+	}
+	return nil
+}
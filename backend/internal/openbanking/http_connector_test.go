@@ -0,0 +1,196 @@
+package openbanking
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+func mockConnectors(t *testing.T) (*MockServer, Connectors) {
+	t.Helper()
+	mock := NewMockServer()
+	t.Cleanup(mock.Close)
+	connectors, err := NewConnectors(config.OpenBankingConfig{Mock: true, Timeout: 5 * time.Second}, mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mock, connectors
+}
+
+// allTransactions листает ленту операций с cursor до конца; возвращает операции, последний курсор и число страниц
+func allTransactions(t *testing.T, c BankConnector, customerID, accountID, cursor string) ([]Transaction, string, int) {
+	t.Helper()
+	var res []Transaction
+	pages := 0
+	for {
+		p, err := c.ListTransactions(context.Background(), customerID, accountID, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages++
+		res = append(res, p.Transactions...)
+		cursor = p.NextCursor
+		if !p.HasMore {
+			return res, cursor, pages
+		}
+	}
+}
+
+func TestConnectorPagination(t *testing.T) {
+	mock, connectors := mockConnectors(t)
+	ctx := context.Background()
+
+	for _, code := range connectors.Codes() {
+		t.Run(code, func(t *testing.T) {
+			c := connectors.For(code)
+			accounts, err := c.ListAccounts(ctx, "client-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(accounts) != 1 || accounts[0].Currency != "RUB" {
+				t.Fatalf("accounts = %+v", accounts)
+			}
+			accountID := accounts[0].AccountID
+
+			feed, cursor, pages := allTransactions(t, c, "client-1", accountID, "")
+			if pageSize := bankAPIs[code].PageSize; pageSize > 0 && pages != (len(feed)+pageSize-1)/pageSize {
+				t.Errorf("%d transactions in %d pages of %d", len(feed), pages, pageSize)
+			}
+			seen := make(map[string]bool, len(feed))
+			for i, tr := range feed {
+				if seen[tr.TransactionID] {
+					t.Errorf("transaction %s returned twice", tr.TransactionID)
+				}
+				seen[tr.TransactionID] = true
+				if i > 0 && tr.BookedAt.Before(feed[i-1].BookedAt) {
+					t.Errorf("transaction %s is out of order", tr.TransactionID)
+				}
+			}
+
+			// По последнему курсору приходят только новые операции
+			more, _, _ := allTransactions(t, c, "client-1", accountID, cursor)
+			if len(more) != 0 {
+				t.Errorf("got %d transactions after the last cursor", len(more))
+			}
+			mock.AddTransaction(code, "client-1", Transaction{Amount: "1500.00", Description: "Перевод"})
+			more, _, _ = allTransactions(t, c, "client-1", accountID, cursor)
+			if len(more) != 1 || more[0].Amount != "1500.00" || seen[more[0].TransactionID] {
+				t.Errorf("after the last cursor = %+v, want the new transaction", more)
+			}
+		})
+	}
+}
+
+func TestConnectorBalance(t *testing.T) {
+	_, connectors := mockConnectors(t)
+	ctx := context.Background()
+	for _, code := range connectors.Codes() {
+		c := connectors.For(code)
+		accounts, err := c.ListAccounts(ctx, "client-2")
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := c.GetBalance(ctx, "client-2", accounts[0].AccountID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if b.Type != bankAPIs[code].BalanceType || b.Currency != "RUB" {
+			t.Errorf("%s balance = %+v, want type %s", code, b, bankAPIs[code].BalanceType)
+		}
+	}
+}
+
+func TestConnectorErrors(t *testing.T) {
+	mock, connectors := mockConnectors(t)
+	ctx := context.Background()
+	c := connectors.For("ALFA")
+
+	noToken, err := NewConnectors(config.OpenBankingConfig{Banks: map[string]config.BankAPIConfig{"ALFA": {BaseURL: mock.URL()}}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wrongPath, err := NewConnectors(config.OpenBankingConfig{Banks: map[string]config.BankAPIConfig{"ALFA": {BaseURL: mock.URL() + "/gateway", Token: "t"}}}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantErr error
+		message string
+	}{
+		{"unknown account", func() error {
+			_, err := c.GetBalance(ctx, "client-3", "no-such-account")
+			return err
+		}, ErrNotFound, ""},
+		{"missing token", func() error {
+			_, err := noToken.For("ALFA").ListAccounts(ctx, "client-3")
+			return err
+		}, ErrUnauthorized, ""},
+		{"unknown API path", func() error {
+			_, err := wrongPath.For("ALFA").ListAccounts(ctx, "client-3")
+			return err
+		}, ErrNotFound, ""},
+		{"invalid cursor", func() error {
+			accounts, err := c.ListAccounts(ctx, "client-3")
+			if err != nil {
+				return err
+			}
+			_, err = c.ListTransactions(ctx, "client-3", accounts[0].AccountID, "not-a-cursor")
+			return err
+		}, nil, "status 400: invalid cursor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.message != "" && !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestConnectorPaymentIdempotency(t *testing.T) {
+	_, connectors := mockConnectors(t)
+	ctx := context.Background()
+	c := connectors.For("SBER")
+	accounts, err := c.ListAccounts(ctx, "client-4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	accountID := accounts[0].AccountID
+	before, _, _ := allTransactions(t, c, "client-4", accountID, "")
+
+	req := &PaymentRequest{FromAccountID: accountID, ToAccountNumber: "40817810000000000001", Amount: "100.00", Currency: "RUB", IdempotencyKey: "repay-1"}
+	first, err := c.InitiatePayment(ctx, "client-4", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := c.InitiatePayment(ctx, "client-4", req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.PaymentID != second.PaymentID || first.Status != PaymentCompleted {
+		t.Errorf("payments %+v and %+v, want one completed payment", first, second)
+	}
+	after, _, _ := allTransactions(t, c, "client-4", accountID, "")
+	if len(after) != len(before)+1 || after[len(after)-1].Amount != "-100.00" {
+		t.Errorf("got %d new transactions, want one debit", len(after)-len(before))
+	}
+
+	tooMuch := &PaymentRequest{FromAccountID: accountID, Amount: "100000000.00", IdempotencyKey: "repay-2"}
+	if _, err := c.InitiatePayment(ctx, "client-4", tooMuch); err == nil || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Errorf("payment above balance error = %v", err)
+	}
+}
@@ -0,0 +1,321 @@
+package openbanking
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockServer — API всех банков из bankAPIs в одном процессе: для тестов и локальной разработки.
+// Клиент с любым идентификатором существует: при первом обращении ему создаётся рублёвый счёт
+// с детерминированной историей операций за 60 дней
+type MockServer struct {
+	mu        sync.Mutex
+	server    *httptest.Server
+	customers map[string][]*mockAccount // банк|клиент → счета
+	payments  map[string]*Payment       // банк|ключ идемпотентности → платёж
+	now       time.Time
+}
+
+type mockAccount struct {
+	Account
+	opening      int64 // входящий остаток, копейки
+	transactions []Transaction
+}
+
+// NewMockServer запускает mock-сервер на локальном порту; адрес — URL()
+func NewMockServer() *MockServer {
+	m := &MockServer{
+		customers: make(map[string][]*mockAccount),
+		payments:  make(map[string]*Payment),
+		now:       time.Now().UTC(),
+	}
+	m.server = httptest.NewServer(m)
+	return m
+}
+
+func (m *MockServer) URL() string {
+	return m.server.URL
+}
+
+func (m *MockServer) Close() {
+	m.server.Close()
+}
+
+// AddTransaction добавляет операцию на основной счёт клиента, как если бы она прошла в банке
+func (m *MockServer) AddTransaction(bankCode, customerID string, t Transaction) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc := m.accounts(bankCode, customerID)[0]
+	if t.TransactionID == "" {
+		t.TransactionID = fmt.Sprintf("%s-%d", acc.AccountID, len(acc.transactions)+1)
+	}
+	if t.BookedAt.IsZero() {
+		t.BookedAt = time.Now().UTC()
+	}
+	if t.Currency == "" {
+		t.Currency = acc.Currency
+	}
+	acc.transactions = append(acc.transactions, t)
+}
+
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var err error
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		mockError(w, http.StatusUnauthorized, "missing bearer token")
+		return
+	}
+	code, api, rest, ok := matchBankAPI(r.URL.EscapedPath())
+	if !ok {
+		mockError(w, http.StatusNotFound, "unknown API path")
+		return
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	for i := range parts {
+		if parts[i], err = url.PathUnescape(parts[i]); err != nil {
+			mockError(w, http.StatusBadRequest, "invalid path")
+			return
+		}
+	}
+	if len(parts) < 3 || parts[0] != "customers" || parts[1] == "" {
+		mockError(w, http.StatusNotFound, "unknown resource")
+		return
+	}
+	customerID := parts[1]
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case len(parts) == 3 && parts[2] == "accounts" && r.Method == http.MethodGet:
+		accounts := make([]Account, 0)
+		for _, a := range m.accounts(code, customerID) {
+			accounts = append(accounts, a.Account)
+		}
+		mockJSON(w, http.StatusOK, map[string]any{"accounts": accounts})
+	case len(parts) == 3 && parts[2] == "payments" && r.Method == http.MethodPost:
+		m.pay(w, r, code, customerID)
+	case len(parts) == 5 && parts[2] == "accounts" && r.Method == http.MethodGet:
+		acc := m.account(code, customerID, parts[3])
+		if acc == nil {
+			mockError(w, http.StatusNotFound, "account not found")
+			return
+		}
+		switch parts[4] {
+		case "balances":
+			amount := mockMoney(acc.balance())
+			mockJSON(w, http.StatusOK, map[string]any{"balances": []Balance{
+				{Type: "CLBD", Amount: amount, Currency: acc.Currency, AsOf: time.Now().UTC()},
+				{Type: "ITAV", Amount: amount, Currency: acc.Currency, AsOf: time.Now().UTC()},
+			}})
+		case "transactions":
+			m.transactions(w, r, api, acc)
+		default:
+			mockError(w, http.StatusNotFound, "unknown resource")
+		}
+	default:
+		mockError(w, http.StatusNotFound, "unknown resource")
+	}
+}
+
+// transactions отдаёт страницу операций; курсор — номер следующей операции
+func (m *MockServer) transactions(w http.ResponseWriter, r *http.Request, api bankAPI, acc *mockAccount) {
+	from := 0
+	if c := r.URL.Query().Get(api.CursorParam); c != "" {
+		n, err := strconv.Atoi(c)
+		if err != nil || n < 0 || n > len(acc.transactions) {
+			mockError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		from = n
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = 100
+	}
+	to := min(from+limit, len(acc.transactions))
+	mockJSON(w, http.StatusOK, TransactionPage{
+		Transactions: append([]Transaction{}, acc.transactions[from:to]...),
+		NextCursor:   strconv.Itoa(to),
+		HasMore:      to < len(acc.transactions),
+	})
+}
+
+// pay списывает платёж с основного счёта; повтор с тем же Idempotency-Key возвращает прежний платёж
+func (m *MockServer) pay(w http.ResponseWriter, r *http.Request, code, customerID string) {
+	key := r.Header.Get("Idempotency-Key")
+	if p, ok := m.payments[code+"|"+key]; ok && key != "" {
+		mockJSON(w, http.StatusOK, p)
+		return
+	}
+	var req PaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mockError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	acc := m.account(code, customerID, req.FromAccountID)
+	if acc == nil {
+		mockError(w, http.StatusNotFound, "account not found")
+		return
+	}
+	amount, ok := parseMockMoney(req.Amount)
+	if !ok || amount <= 0 {
+		mockError(w, http.StatusBadRequest, "amount must be positive")
+		return
+	}
+	if req.Currency != "" && req.Currency != acc.Currency {
+		mockError(w, http.StatusUnprocessableEntity, "currency differs from account currency")
+		return
+	}
+	if acc.balance() < amount {
+		mockError(w, http.StatusUnprocessableEntity, "insufficient funds")
+		return
+	}
+
+	now := time.Now().UTC()
+	p := &Payment{
+		PaymentID: fmt.Sprintf("%s-pay-%d", acc.AccountID, len(m.payments)+1),
+		Status:    PaymentCompleted,
+		CreatedAt: now,
+	}
+	acc.transactions = append(acc.transactions, Transaction{
+		TransactionID: p.PaymentID,
+		BookedAt:      now,
+		Amount:        mockMoney(-amount),
+		Currency:      acc.Currency,
+		Description:   strings.TrimSpace("Платёж на счёт " + req.ToAccountNumber + " " + req.Description),
+		Category:      "payments",
+	})
+	if key != "" {
+		m.payments[code+"|"+key] = p
+	}
+	mockJSON(w, http.StatusCreated, p)
+}
+
+func (m *MockServer) account(code, customerID, accountID string) *mockAccount {
+	for _, a := range m.accounts(code, customerID) {
+		if a.AccountID == accountID {
+			return a
+		}
+	}
+	return nil
+}
+
+// accounts — счета клиента; при первом обращении создаются
+func (m *MockServer) accounts(code, customerID string) []*mockAccount {
+	key := code + "|" + customerID
+	if accounts, ok := m.customers[key]; ok {
+		return accounts
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	seed := h.Sum64()
+	rnd := rand.New(rand.NewSource(int64(seed)))
+
+	acc := &mockAccount{
+		Account: Account{
+			AccountID: fmt.Sprintf("%s-%012d", strings.ToLower(code), seed%1e12),
+			Number:    fmt.Sprintf("40817810%012d", seed%1e12),
+			Currency:  "RUB",
+			Name:      "Текущий счёт",
+		},
+		opening: int64(10_000+rnd.Intn(190_000)) * 100,
+	}
+	spending := []struct{ category, description string }{
+		{"groceries", "Покупка в супермаркете"},
+		{"transport", "Оплата проезда"},
+		{"restaurants", "Кафе"},
+		{"utilities", "Коммунальные платежи"},
+		{"entertainment", "Кинотеатр"},
+	}
+	day := time.Date(m.now.Year(), m.now.Month(), m.now.Day(), 0, 0, 0, 0, time.UTC)
+	for d := 60; d >= 1; d-- {
+		date := day.AddDate(0, 0, -d)
+		if date.Day() == 5 || date.Day() == 20 {
+			acc.transactions = append(acc.transactions, Transaction{
+				BookedAt:    date.Add(10 * time.Hour),
+				Amount:      mockMoney(int64(40_000+rnd.Intn(40_000)) * 100),
+				Description: "Зачисление заработной платы",
+				Category:    "salary",
+			})
+		}
+		for n := rnd.Intn(3); n > 0; n-- {
+			s := spending[rnd.Intn(len(spending))]
+			acc.transactions = append(acc.transactions, Transaction{
+				BookedAt:    date.Add(time.Duration(9+rnd.Intn(12)) * time.Hour).Add(time.Duration(rnd.Intn(60)) * time.Minute),
+				Amount:      mockMoney(-int64(100 + rnd.Intn(500000))),
+				Description: s.description,
+				Category:    s.category,
+			})
+		}
+	}
+	sort.SliceStable(acc.transactions, func(i, j int) bool {
+		return acc.transactions[i].BookedAt.Before(acc.transactions[j].BookedAt)
+	})
+	for i := range acc.transactions {
+		acc.transactions[i].TransactionID = fmt.Sprintf("%s-%d", acc.AccountID, i+1)
+		acc.transactions[i].Currency = acc.Currency
+	}
+
+	m.customers[key] = []*mockAccount{acc}
+	return m.customers[key]
+}
+
+// balance — входящий остаток плюс все операции, копейки
+func (a *mockAccount) balance() int64 {
+	total := a.opening
+	for _, t := range a.transactions {
+		k, _ := parseMockMoney(t.Amount)
+		total += k
+	}
+	return total
+}
+
+// matchBankAPI находит банк по префиксу пути; rest — путь ресурса после префикса
+func matchBankAPI(path string) (string, bankAPI, string, bool) {
+	for code, api := range bankAPIs {
+		if rest, ok := strings.CutPrefix(path, api.BasePath+"/"); ok {
+			return code, api, rest, true
+		}
+	}
+	return "", bankAPI{}, "", false
+}
+
+func mockMoney(k int64) string {
+	sign := ""
+	if k < 0 {
+		sign, k = "-", -k
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, k/100, k%100)
+}
+
+func parseMockMoney(s string) (int64, bool) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok {
+		return 0, false
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	if !r.IsInt() || !r.Num().IsInt64() {
+		return 0, false
+	}
+	return r.Num().Int64(), true
+}
+
+func mockJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func mockError(w http.ResponseWriter, status int, msg string) {
+	mockJSON(w, status, map[string]string{"error": msg})
+}
@@ -10,8 +10,11 @@ import (
 type UserBankAccountRepository interface {
 	CreateAccount(ctx context.Context, account *models.UserBankAccount) error
 	GetAccountByID(ctx context.Context, accountID int64) (*models.UserBankAccount, error)
+	// GetAccountForUpdate блокирует счёт до конца транзакции
+	GetAccountForUpdate(ctx context.Context, accountID int64) (*models.UserBankAccount, error)
+	// EnsureUserBankAccount возвращает счёт пользователя в банке, открывая пустой счёт при отсутствии
+	EnsureUserBankAccount(ctx context.Context, userID int64, bankID int16, currency string) (*models.UserBankAccount, error)
 	GetUserAccounts(ctx context.Context, userID int64) ([]models.UserBankAccount, error)
-	UpdateBalance(ctx context.Context, accountID int64, balance string) error
 	GetTotalBalance(ctx context.Context, userID int64) (string, error)
 }
 
@@ -27,9 +30,11 @@ func (r *userBankAccountRepositoryImpl) CreateAccount(ctx context.Context, accou
 	const q = `
 		INSERT INTO user_bank_accounts (user_id, bank_id, balance, currency, created_at)
 		VALUES ($1, $2, $3, $4, $5)
+		RETURNING account_id
 	`
-	_, err := r.db.ExecContext(ctx, q, account.UserID, account.BankID, account.Balance, account.Currency, account.CreatedAt)
-	return err
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		account.UserID, account.BankID, account.Balance, account.Currency, account.CreatedAt,
+	).Scan(&account.AccountID)
 }
 
 func (r *userBankAccountRepositoryImpl) GetAccountByID(ctx context.Context, accountID int64) (*models.UserBankAccount, error) {
@@ -38,7 +43,41 @@ func (r *userBankAccountRepositoryImpl) GetAccountByID(ctx context.Context, acco
 		FROM user_bank_accounts WHERE account_id = $1
 	`
 	acc := &models.UserBankAccount{}
-	err := r.db.QueryRowContext(ctx, q, accountID).Scan(
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, accountID).Scan(
+		&acc.AccountID, &acc.UserID, &acc.BankID, &acc.Balance, &acc.Currency, &acc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (r *userBankAccountRepositoryImpl) GetAccountForUpdate(ctx context.Context, accountID int64) (*models.UserBankAccount, error) {
+	const q = `
+		SELECT account_id, user_id, bank_id, balance, currency, created_at
+		FROM user_bank_accounts WHERE account_id = $1
+		FOR UPDATE
+	`
+	acc := &models.UserBankAccount{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, accountID).Scan(
+		&acc.AccountID, &acc.UserID, &acc.BankID, &acc.Balance, &acc.Currency, &acc.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return acc, nil
+}
+
+func (r *userBankAccountRepositoryImpl) EnsureUserBankAccount(ctx context.Context, userID int64, bankID int16, currency string) (*models.UserBankAccount, error) {
+	// DO UPDATE без изменений нужен, чтобы RETURNING вернул существующую строку
+	const q = `
+		INSERT INTO user_bank_accounts (user_id, bank_id, currency)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, bank_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING account_id, user_id, bank_id, balance, currency, created_at
+	`
+	acc := &models.UserBankAccount{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, userID, bankID, currency).Scan(
 		&acc.AccountID, &acc.UserID, &acc.BankID, &acc.Balance, &acc.Currency, &acc.CreatedAt,
 	)
 	if err != nil {
@@ -53,7 +92,7 @@ func (r *userBankAccountRepositoryImpl) GetUserAccounts(ctx context.Context, use
 		FROM user_bank_accounts WHERE user_id = $1
 		ORDER BY bank_id
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -70,15 +109,9 @@ func (r *userBankAccountRepositoryImpl) GetUserAccounts(ctx context.Context, use
 	return accounts, rows.Err()
 }
 
-func (r *userBankAccountRepositoryImpl) UpdateBalance(ctx context.Context, accountID int64, balance string) error {
-	const q = `UPDATE user_bank_accounts SET balance = $1 WHERE account_id = $2`
-	_, err := r.db.ExecContext(ctx, q, balance, accountID)
-	return err
-}
-
 func (r *userBankAccountRepositoryImpl) GetTotalBalance(ctx context.Context, userID int64) (string, error) {
 	const q = `SELECT COALESCE(TO_CHAR(SUM(balance), 'FM9999999999990.00'), '0.00') FROM user_bank_accounts WHERE user_id = $1`
 	var total string
-	err := r.db.QueryRowContext(ctx, q, userID).Scan(&total)
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, userID).Scan(&total)
 	return total, err
-}
\ No newline at end of file
+}
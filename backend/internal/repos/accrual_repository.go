@@ -0,0 +1,55 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type InterestAccrualRepository interface {
+	CreateAccrual(ctx context.Context, accrual *models.InterestAccrual) error
+	ListSplitAccruals(ctx context.Context, splitID int64) ([]models.InterestAccrual, error)
+}
+
+type interestAccrualRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewInterestAccrualRepository(db *sql.DB) InterestAccrualRepository {
+	return &interestAccrualRepositoryImpl{db: db}
+}
+
+func (r *interestAccrualRepositoryImpl) CreateAccrual(ctx context.Context, a *models.InterestAccrual) error {
+	const q = `
+		INSERT INTO loan_interest_accruals (split_id, accrual_date, principal_base, interest_rate, day_count_convention, amount, accrual_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING accrual_id
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		a.SplitID, a.AccrualDate, a.PrincipalBase, a.InterestRate, a.DayCountConvention, a.Amount, a.AccrualType,
+	).Scan(&a.AccrualID)
+}
+
+func (r *interestAccrualRepositoryImpl) ListSplitAccruals(ctx context.Context, splitID int64) ([]models.InterestAccrual, error) {
+	const q = `
+		SELECT accrual_id, split_id, accrual_date, principal_base, interest_rate, day_count_convention, amount, accrual_type
+		FROM loan_interest_accruals WHERE split_id = $1
+		ORDER BY accrual_date, accrual_type
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, splitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.InterestAccrual
+	for rows.Next() {
+		var a models.InterestAccrual
+		if err := rows.Scan(&a.AccrualID, &a.SplitID, &a.AccrualDate, &a.PrincipalBase, &a.InterestRate, &a.DayCountConvention, &a.Amount, &a.AccrualType); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
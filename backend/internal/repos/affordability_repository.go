@@ -0,0 +1,114 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type AffordabilityRepository interface {
+	// LoadIncome — сумма поступлений на счета пользователя с момента since
+	LoadIncome(ctx context.Context, userID int64, since, now time.Time) (string, error)
+	// LoadMonthlyPayments — среднемесячный платёж по действующим кредитам:
+	// по каждому кредиту — среднее оставшихся платежей графика начиная с now
+	LoadMonthlyPayments(ctx context.Context, userID int64, now time.Time) (string, error)
+	CreateAssessment(ctx context.Context, a *models.AffordabilityAssessment) error
+	ListApplicationAssessments(ctx context.Context, appIDs ...int64) ([]models.AffordabilityAssessment, error)
+}
+
+type affordabilityRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewAffordabilityRepository(db *sql.DB) AffordabilityRepository {
+	return &affordabilityRepositoryImpl{db: db}
+}
+
+func (r *affordabilityRepositoryImpl) LoadIncome(ctx context.Context, userID int64, since, now time.Time) (string, error) {
+	const q = `
+		SELECT COALESCE(SUM(amount), 0)
+		FROM transactions
+		WHERE user_id = $1 AND amount > 0 AND occurred_at >= $2 AND occurred_at <= $3
+		  AND category NOT IN ('loan_disbursement', 'transfer')
+	`
+	var income string
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, userID, since, now).Scan(&income)
+	return income, err
+}
+
+func (r *affordabilityRepositoryImpl) LoadMonthlyPayments(ctx context.Context, userID int64, now time.Time) (string, error) {
+	const q = `
+		WITH remaining AS (
+		  SELECT l.loan_id, s.due_date, SUM(s.total_payment) AS amount
+		  FROM loans l
+		  JOIN loan_splits ls ON ls.loan_id = l.loan_id
+		  JOIN loan_schedule_items s ON s.split_id = ls.split_id
+		  WHERE l.user_id = $1 AND l.status IN ('ACTIVE', 'OVERDUE', 'DEFAULTED') AND s.due_date >= $2::date
+		  GROUP BY l.loan_id, s.due_date
+		)
+		SELECT COALESCE(ROUND(SUM(payment), 2), 0)
+		FROM (SELECT AVG(amount) AS payment FROM remaining GROUP BY loan_id) p
+	`
+	var payments string
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, userID, now).Scan(&payments)
+	return payments, err
+}
+
+func (r *affordabilityRepositoryImpl) CreateAssessment(ctx context.Context, a *models.AffordabilityAssessment) error {
+	reasons, err := json.Marshal(a.Reasons)
+	if err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO application_affordability (application_id, status, monthly_income, existing_payments, new_payment,
+		                                       assumed_rate, term_months, pdn, flag_threshold, reject_threshold, reasons)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (application_id) DO UPDATE
+		SET status = EXCLUDED.status, monthly_income = EXCLUDED.monthly_income,
+		    existing_payments = EXCLUDED.existing_payments, new_payment = EXCLUDED.new_payment,
+		    assumed_rate = EXCLUDED.assumed_rate, term_months = EXCLUDED.term_months, pdn = EXCLUDED.pdn,
+		    flag_threshold = EXCLUDED.flag_threshold, reject_threshold = EXCLUDED.reject_threshold,
+		    reasons = EXCLUDED.reasons, checked_at = now()
+		RETURNING checked_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		a.ApplicationID, a.Status, a.MonthlyIncome, a.ExistingPayments, a.NewPayment,
+		a.AssumedRate, a.TermMonths, a.PDN, a.FlagThreshold, a.RejectThreshold, reasons,
+	).Scan(&a.CheckedAt)
+}
+
+func (r *affordabilityRepositoryImpl) ListApplicationAssessments(ctx context.Context, appIDs ...int64) ([]models.AffordabilityAssessment, error) {
+	const q = `
+		SELECT application_id, status, monthly_income, existing_payments, new_payment,
+		       assumed_rate, term_months, pdn, flag_threshold, reject_threshold, reasons, checked_at
+		FROM application_affordability
+		WHERE application_id = ANY($1)
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, pq.Array(appIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.AffordabilityAssessment{}
+	for rows.Next() {
+		var a models.AffordabilityAssessment
+		var reasons []byte
+		if err := rows.Scan(
+			&a.ApplicationID, &a.Status, &a.MonthlyIncome, &a.ExistingPayments, &a.NewPayment,
+			&a.AssumedRate, &a.TermMonths, &a.PDN, &a.FlagThreshold, &a.RejectThreshold, &reasons, &a.CheckedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(reasons, &a.Reasons); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
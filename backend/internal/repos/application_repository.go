@@ -10,8 +10,14 @@ import (
 type CreditApplicationRepository interface {
 	CreateApplication(ctx context.Context, app *models.CreditApplication) (int64, error)
 	GetApplicationByID(ctx context.Context, appID int64) (*models.CreditApplication, error)
+	// GetApplicationByIDForUpdate блокирует заявку до конца транзакции
+	GetApplicationByIDForUpdate(ctx context.Context, appID int64) (*models.CreditApplication, error)
 	ListUserApplications(ctx context.Context, userID int64) ([]models.CreditApplication, error)
-	UpdateApplicationStatus(ctx context.Context, appID int64, status string) error
+	// UpdateApplicationStatus меняет статус, только если текущий равен from; возвращает false, если строка не обновлена.
+	// При отправке черновика submitted_at сдвигается на момент отправки
+	UpdateApplicationStatus(ctx context.Context, appID int64, from, to string) (bool, error)
+	// UpdateDraft меняет условия заявки, только пока она черновик; возвращает false, если строка не обновлена
+	UpdateDraft(ctx context.Context, app *models.CreditApplication) (bool, error)
 	UpdateApplicationLoanID(ctx context.Context, appID int64, loanID int64) error
 }
 
@@ -25,25 +31,38 @@ func NewCreditApplicationRepository(db *sql.DB) CreditApplicationRepository {
 
 func (r *creditApplicationRepositoryImpl) CreateApplication(ctx context.Context, app *models.CreditApplication) (int64, error) {
 	const q = `
-		INSERT INTO credit_applications (user_id, bank_id, type_code, status_code, requested_amount, loan_id, submitted_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO credit_applications (user_id, bank_id, any_bank, type_code, status_code, requested_amount, term_months, product_id, loan_id, submitted_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING application_id
 	`
 	var id int64
-	err := r.db.QueryRowContext(ctx, q,
-		app.UserID, app.BankID, app.TypeCode, app.StatusCode, app.RequestedAmount, app.LoanID, app.SubmittedAt, app.UpdatedAt,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		app.UserID, app.BankID, app.AnyBank, app.TypeCode, app.StatusCode, app.RequestedAmount, app.TermMonths, app.ProductID, app.LoanID, app.SubmittedAt, app.UpdatedAt,
 	).Scan(&id)
 	return id, err
 }
 
 func (r *creditApplicationRepositoryImpl) GetApplicationByID(ctx context.Context, appID int64) (*models.CreditApplication, error) {
 	const q = `
-		SELECT application_id, user_id, bank_id, type_code, status_code, requested_amount, loan_id, submitted_at, updated_at
+		SELECT application_id, user_id, bank_id, any_bank, type_code, status_code, requested_amount, term_months, product_id, loan_id, submitted_at, updated_at
 		FROM credit_applications WHERE application_id = $1
 	`
+	return r.getApplication(ctx, q, appID)
+}
+
+func (r *creditApplicationRepositoryImpl) GetApplicationByIDForUpdate(ctx context.Context, appID int64) (*models.CreditApplication, error) {
+	const q = `
+		SELECT application_id, user_id, bank_id, any_bank, type_code, status_code, requested_amount, term_months, product_id, loan_id, submitted_at, updated_at
+		FROM credit_applications WHERE application_id = $1
+		FOR UPDATE
+	`
+	return r.getApplication(ctx, q, appID)
+}
+
+func (r *creditApplicationRepositoryImpl) getApplication(ctx context.Context, q string, appID int64) (*models.CreditApplication, error) {
 	a := &models.CreditApplication{}
-	err := r.db.QueryRowContext(ctx, q, appID).Scan(
-		&a.ApplicationID, &a.UserID, &a.BankID, &a.TypeCode, &a.StatusCode, &a.RequestedAmount, &a.LoanID, &a.SubmittedAt, &a.UpdatedAt,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, appID).Scan(
+		&a.ApplicationID, &a.UserID, &a.BankID, &a.AnyBank, &a.TypeCode, &a.StatusCode, &a.RequestedAmount, &a.TermMonths, &a.ProductID, &a.LoanID, &a.SubmittedAt, &a.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -53,11 +72,15 @@ func (r *creditApplicationRepositoryImpl) GetApplicationByID(ctx context.Context
 
 func (r *creditApplicationRepositoryImpl) ListUserApplications(ctx context.Context, userID int64) ([]models.CreditApplication, error) {
 	const q = `
-		SELECT application_id, user_id, bank_id, type_code, status_code, requested_amount, loan_id, submitted_at, updated_at
+		SELECT application_id, user_id, bank_id, any_bank, type_code, status_code, requested_amount, term_months, product_id, loan_id, submitted_at, updated_at
 		FROM credit_applications WHERE user_id = $1
 		ORDER BY submitted_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	return r.listApplications(ctx, q, userID)
+}
+
+func (r *creditApplicationRepositoryImpl) listApplications(ctx context.Context, q string, arg any) ([]models.CreditApplication, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, arg)
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +90,7 @@ func (r *creditApplicationRepositoryImpl) ListUserApplications(ctx context.Conte
 	for rows.Next() {
 		var a models.CreditApplication
 		if err := rows.Scan(
-			&a.ApplicationID, &a.UserID, &a.BankID, &a.TypeCode, &a.StatusCode, &a.RequestedAmount, &a.LoanID, &a.SubmittedAt, &a.UpdatedAt,
+			&a.ApplicationID, &a.UserID, &a.BankID, &a.AnyBank, &a.TypeCode, &a.StatusCode, &a.RequestedAmount, &a.TermMonths, &a.ProductID, &a.LoanID, &a.SubmittedAt, &a.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -76,14 +99,38 @@ func (r *creditApplicationRepositoryImpl) ListUserApplications(ctx context.Conte
 	return res, rows.Err()
 }
 
-func (r *creditApplicationRepositoryImpl) UpdateApplicationStatus(ctx context.Context, appID int64, status string) error {
-	const q = `UPDATE credit_applications SET status_code = $1, updated_at = NOW() WHERE application_id = $2`
-	_, err := r.db.ExecContext(ctx, q, status, appID)
-	return err
+func (r *creditApplicationRepositoryImpl) UpdateApplicationStatus(ctx context.Context, appID int64, from, to string) (bool, error) {
+	const q = `
+		UPDATE credit_applications
+		SET status_code = $1,
+		    submitted_at = CASE WHEN $3 = 'DRAFT' THEN NOW() ELSE submitted_at END,
+		    updated_at = NOW()
+		WHERE application_id = $2 AND status_code = $3
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, to, appID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *creditApplicationRepositoryImpl) UpdateDraft(ctx context.Context, app *models.CreditApplication) (bool, error) {
+	const q = `
+		UPDATE credit_applications
+		SET bank_id = $1, any_bank = $2, type_code = $3, requested_amount = $4, term_months = $5, product_id = $6, updated_at = NOW()
+		WHERE application_id = $7 AND status_code = 'DRAFT'
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, app.BankID, app.AnyBank, app.TypeCode, app.RequestedAmount, app.TermMonths, app.ProductID, app.ApplicationID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
 }
 
 func (r *creditApplicationRepositoryImpl) UpdateApplicationLoanID(ctx context.Context, appID int64, loanID int64) error {
 	const q = `UPDATE credit_applications SET loan_id = $1, updated_at = NOW() WHERE application_id = $2`
-	_, err := r.db.ExecContext(ctx, q, loanID, appID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, loanID, appID)
 	return err
-}
\ No newline at end of file
+}
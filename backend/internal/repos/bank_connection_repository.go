@@ -0,0 +1,152 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type BankConnectionRepository interface {
+	// UpsertConnection подключает банк или переподключает отключённый с новым клиентом банка
+	UpsertConnection(ctx context.Context, c *models.BankConnection) error
+	GetConnection(ctx context.Context, connectionID int64) (*models.BankConnection, error)
+	GetUserBankConnection(ctx context.Context, userID int64, bankID int16) (*models.BankConnection, error)
+	// GetConnectionForUpdate блокирует подключение до конца транзакции: одна синхронизация за раз
+	GetConnectionForUpdate(ctx context.Context, connectionID int64) (*models.BankConnection, error)
+	ListUserConnections(ctx context.Context, userID int64) ([]models.BankConnection, error)
+	// ListActiveConnectionIDs — активные подключения, давно синхронизированные первыми
+	ListActiveConnectionIDs(ctx context.Context) ([]int64, error)
+	// UpdateSync сохраняет результат синхронизации: счёт, курсор, остаток, время и ошибку
+	UpdateSync(ctx context.Context, c *models.BankConnection) error
+	UpdateStatus(ctx context.Context, connectionID int64, status string) error
+}
+
+type bankConnectionRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewBankConnectionRepository(db *sql.DB) BankConnectionRepository {
+	return &bankConnectionRepositoryImpl{db: db}
+}
+
+const bankConnectionSelect = `
+	SELECT connection_id, user_id, bank_id, customer_id, external_account_id, sync_cursor, bank_balance, status,
+	       last_synced_at, last_error, created_at, updated_at
+	FROM bank_connections
+`
+
+func scanBankConnection(row interface{ Scan(...any) error }) (*models.BankConnection, error) {
+	c := &models.BankConnection{}
+	err := row.Scan(
+		&c.ConnectionID, &c.UserID, &c.BankID, &c.CustomerID, &c.ExternalAccountID, &c.Cursor, &c.BankBalance, &c.Status,
+		&c.LastSyncedAt, &c.LastError, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (r *bankConnectionRepositoryImpl) UpsertConnection(ctx context.Context, c *models.BankConnection) error {
+	// Смена клиента банка сбрасывает счёт и курсор: это другая лента операций
+	const q = `
+		INSERT INTO bank_connections (user_id, bank_id, customer_id, status)
+		VALUES ($1, $2, $3, 'ACTIVE')
+		ON CONFLICT (user_id, bank_id) DO UPDATE SET
+			customer_id = EXCLUDED.customer_id,
+			status = 'ACTIVE',
+			external_account_id = CASE WHEN bank_connections.customer_id = EXCLUDED.customer_id
+				THEN bank_connections.external_account_id END,
+			sync_cursor = CASE WHEN bank_connections.customer_id = EXCLUDED.customer_id
+				THEN bank_connections.sync_cursor ELSE '' END,
+			last_error = NULL,
+			updated_at = now()
+		RETURNING connection_id, user_id, bank_id, customer_id, external_account_id, sync_cursor, bank_balance, status,
+		          last_synced_at, last_error, created_at, updated_at
+	`
+	saved, err := scanBankConnection(conn(ctx, r.db).QueryRowContext(ctx, q, c.UserID, c.BankID, c.CustomerID))
+	if err != nil {
+		return err
+	}
+	*c = *saved
+	return nil
+}
+
+func (r *bankConnectionRepositoryImpl) GetConnection(ctx context.Context, connectionID int64) (*models.BankConnection, error) {
+	return scanBankConnection(conn(ctx, r.db).QueryRowContext(ctx, bankConnectionSelect+` WHERE connection_id = $1`, connectionID))
+}
+
+func (r *bankConnectionRepositoryImpl) GetUserBankConnection(ctx context.Context, userID int64, bankID int16) (*models.BankConnection, error) {
+	return scanBankConnection(conn(ctx, r.db).QueryRowContext(ctx, bankConnectionSelect+` WHERE user_id = $1 AND bank_id = $2`, userID, bankID))
+}
+
+func (r *bankConnectionRepositoryImpl) GetConnectionForUpdate(ctx context.Context, connectionID int64) (*models.BankConnection, error) {
+	return scanBankConnection(conn(ctx, r.db).QueryRowContext(ctx, bankConnectionSelect+` WHERE connection_id = $1 FOR UPDATE`, connectionID))
+}
+
+func (r *bankConnectionRepositoryImpl) ListUserConnections(ctx context.Context, userID int64) ([]models.BankConnection, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, bankConnectionSelect+` WHERE user_id = $1 ORDER BY bank_id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var connections []models.BankConnection
+	for rows.Next() {
+		c, err := scanBankConnection(rows)
+		if err != nil {
+			return nil, err
+		}
+		connections = append(connections, *c)
+	}
+	return connections, rows.Err()
+}
+
+func (r *bankConnectionRepositoryImpl) ListActiveConnectionIDs(ctx context.Context) ([]int64, error) {
+	const q = `
+		SELECT connection_id FROM bank_connections
+		WHERE status = 'ACTIVE'
+		ORDER BY last_synced_at NULLS FIRST, connection_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *bankConnectionRepositoryImpl) UpdateSync(ctx context.Context, c *models.BankConnection) error {
+	const q = `
+		UPDATE bank_connections
+		SET external_account_id = $2, sync_cursor = $3, bank_balance = $4, last_synced_at = $5, last_error = $6,
+		    updated_at = now()
+		WHERE connection_id = $1
+		RETURNING updated_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		c.ConnectionID, c.ExternalAccountID, c.Cursor, c.BankBalance, c.LastSyncedAt, c.LastError,
+	).Scan(&c.UpdatedAt)
+}
+
+func (r *bankConnectionRepositoryImpl) UpdateStatus(ctx context.Context, connectionID int64, status string) error {
+	const q = `UPDATE bank_connections SET status = $2, updated_at = now() WHERE connection_id = $1`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, connectionID, status)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type BankRepository interface {
+	ListBanks(ctx context.Context) ([]models.Bank, error)
+	GetBankByID(ctx context.Context, bankID int16) (*models.Bank, error)
+}
+
+type bankRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewBankRepository(db *sql.DB) BankRepository {
+	return &bankRepositoryImpl{db: db}
+}
+
+func (r *bankRepositoryImpl) ListBanks(ctx context.Context) ([]models.Bank, error) {
+	const q = `
+		SELECT bank_id, code, name, payment_priority
+		FROM banks
+		ORDER BY bank_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Bank
+	for rows.Next() {
+		var b models.Bank
+		if err := rows.Scan(&b.BankID, &b.Code, &b.Name, &b.PaymentPriority); err != nil {
+			return nil, err
+		}
+		res = append(res, b)
+	}
+	return res, rows.Err()
+}
+
+func (r *bankRepositoryImpl) GetBankByID(ctx context.Context, bankID int16) (*models.Bank, error) {
+	const q = `
+		SELECT bank_id, code, name, payment_priority
+		FROM banks WHERE bank_id = $1
+	`
+	b := &models.Bank{}
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, bankID).Scan(&b.BankID, &b.Code, &b.Name, &b.PaymentPriority)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,169 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type DecisionRepository interface {
+	// SaveRuleSet регистрирует набор правил; уже известный по checksum набор не дублируется
+	SaveRuleSet(ctx context.Context, rs *models.DecisionRuleSet) error
+	CreateDecision(ctx context.Context, d *models.ApplicationDecision) error
+	// ListDecisions — все решения по заявке, от новых к старым
+	ListDecisions(ctx context.Context, appID int64) ([]models.ApplicationDecision, error)
+	// ListLatestDecisions — последнее решение по каждой из заявок
+	ListLatestDecisions(ctx context.Context, appIDs ...int64) ([]models.ApplicationDecision, error)
+	// ListHistory — отправленные заявки за [from, to) с данными для решения и последним решением
+	ListHistory(ctx context.Context, from, to time.Time, limit int) ([]models.DryRunResult, error)
+}
+
+type decisionRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewDecisionRepository(db *sql.DB) DecisionRepository {
+	return &decisionRepositoryImpl{db: db}
+}
+
+func (r *decisionRepositoryImpl) SaveRuleSet(ctx context.Context, rs *models.DecisionRuleSet) error {
+	rules, err := json.Marshal(struct {
+		Version string                `json:"version"`
+		Default string                `json:"default"`
+		Rules   []models.DecisionRule `json:"rules"`
+	}{rs.Version, rs.Default, rs.Rules})
+	if err != nil {
+		return err
+	}
+	// DO UPDATE без изменений нужен, чтобы RETURNING вернул существующую строку
+	const q = `
+		INSERT INTO decision_rule_sets (version, checksum, rules)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (checksum) DO UPDATE SET checksum = EXCLUDED.checksum
+		RETURNING rule_set_id, loaded_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q, rs.Version, rs.Checksum, rules).Scan(&rs.RuleSetID, &rs.LoadedAt)
+}
+
+func (r *decisionRepositoryImpl) CreateDecision(ctx context.Context, d *models.ApplicationDecision) error {
+	fired, err := json.Marshal(d.FiredRules)
+	if err != nil {
+		return err
+	}
+	inputs, err := json.Marshal(d.Inputs)
+	if err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO application_decisions (application_id, rule_set_id, outcome, fired_rules, inputs, enforced)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING decision_id, decided_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		d.ApplicationID, d.RuleSetID, d.Outcome, fired, inputs, d.Enforced,
+	).Scan(&d.DecisionID, &d.DecidedAt)
+}
+
+const decisionColumns = `d.decision_id, d.application_id, d.rule_set_id, rs.version, d.outcome, d.fired_rules, d.inputs, d.enforced, d.decided_at`
+
+func (r *decisionRepositoryImpl) ListDecisions(ctx context.Context, appID int64) ([]models.ApplicationDecision, error) {
+	q := `
+		SELECT ` + decisionColumns + `
+		FROM application_decisions d
+		JOIN decision_rule_sets rs ON rs.rule_set_id = d.rule_set_id
+		WHERE d.application_id = $1
+		ORDER BY d.decided_at DESC, d.decision_id DESC
+	`
+	return r.listDecisions(ctx, q, appID)
+}
+
+func (r *decisionRepositoryImpl) ListLatestDecisions(ctx context.Context, appIDs ...int64) ([]models.ApplicationDecision, error) {
+	q := `
+		SELECT DISTINCT ON (d.application_id) ` + decisionColumns + `
+		FROM application_decisions d
+		JOIN decision_rule_sets rs ON rs.rule_set_id = d.rule_set_id
+		WHERE d.application_id = ANY($1)
+		ORDER BY d.application_id, d.decided_at DESC, d.decision_id DESC
+	`
+	return r.listDecisions(ctx, q, pq.Array(appIDs))
+}
+
+func (r *decisionRepositoryImpl) listDecisions(ctx context.Context, q string, args ...any) ([]models.ApplicationDecision, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.ApplicationDecision{}
+	for rows.Next() {
+		var d models.ApplicationDecision
+		var fired, inputs []byte
+		if err := rows.Scan(
+			&d.DecisionID, &d.ApplicationID, &d.RuleSetID, &d.RuleVersion, &d.Outcome, &fired, &inputs, &d.Enforced, &d.DecidedAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(fired, &d.FiredRules); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(inputs, &d.Inputs); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
+
+func (r *decisionRepositoryImpl) ListHistory(ctx context.Context, from, to time.Time, limit int) ([]models.DryRunResult, error) {
+	// Оценка и ПДН — те, что были посчитаны при подаче заявки
+	const q = `
+		SELECT a.application_id, a.status_code, a.user_id, a.type_code, a.requested_amount, a.term_months,
+		       COALESCE((SELECT array_agg(p.bank_id ORDER BY p.bank_id)
+		                 FROM application_participants p WHERE p.application_id = a.application_id), '{}'),
+		       (SELECT s.score FROM credit_scores s WHERE s.application_id = a.application_id
+		        ORDER BY s.computed_at DESC, s.score_id DESC LIMIT 1),
+		       f.pdn,
+		       (SELECT d.outcome FROM application_decisions d WHERE d.application_id = a.application_id
+		        ORDER BY d.decided_at DESC, d.decision_id DESC LIMIT 1)
+		FROM credit_applications a
+		LEFT JOIN application_affordability f ON f.application_id = a.application_id
+		WHERE a.status_code <> 'DRAFT' AND a.submitted_at >= $1 AND a.submitted_at < $2
+		ORDER BY a.submitted_at DESC, a.application_id DESC
+		LIMIT $3
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.DryRunResult{}
+	for rows.Next() {
+		var d models.DryRunResult
+		var banks []int64
+		var score sql.NullInt64
+		in := &d.Inputs
+		if err := rows.Scan(
+			&d.ApplicationID, &d.StatusCode, &in.UserID, &in.TypeCode, &in.RequestedAmount, &in.TermMonths,
+			pq.Array(&banks), &score, &in.PDN, &d.PreviousOutcome,
+		); err != nil {
+			return nil, err
+		}
+		in.BankIDs = make([]int16, 0, len(banks))
+		for _, b := range banks {
+			in.BankIDs = append(in.BankIDs, int16(b))
+		}
+		if score.Valid {
+			v := int(score.Int64)
+			in.Score = &v
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
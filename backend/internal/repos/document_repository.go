@@ -0,0 +1,87 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type DocumentRepository interface {
+	CreateDocument(ctx context.Context, doc *models.ApplicationDocument) error
+	GetDocument(ctx context.Context, appID, docID int64) (*models.ApplicationDocument, error)
+	ListDocuments(ctx context.Context, appID int64) ([]models.ApplicationDocument, error)
+	// DeleteDocument возвращает false, если документа нет
+	DeleteDocument(ctx context.Context, appID, docID int64) (bool, error)
+}
+
+type documentRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewDocumentRepository(db *sql.DB) DocumentRepository {
+	return &documentRepositoryImpl{db: db}
+}
+
+const documentColumns = `
+	document_id, application_id, doc_type, file_name, content_type, size_bytes,
+	checksum_sha256, storage_key, uploaded_by, created_at
+`
+
+func scanDocument(row interface{ Scan(...any) error }) (*models.ApplicationDocument, error) {
+	d := &models.ApplicationDocument{}
+	err := row.Scan(
+		&d.DocumentID, &d.ApplicationID, &d.DocType, &d.FileName, &d.ContentType, &d.SizeBytes,
+		&d.ChecksumSHA256, &d.StorageKey, &d.UploadedBy, &d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *documentRepositoryImpl) CreateDocument(ctx context.Context, d *models.ApplicationDocument) error {
+	const q = `
+		INSERT INTO application_documents
+		  (application_id, doc_type, file_name, content_type, size_bytes, checksum_sha256, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING document_id, created_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		d.ApplicationID, d.DocType, d.FileName, d.ContentType, d.SizeBytes, d.ChecksumSHA256, d.StorageKey, d.UploadedBy,
+	).Scan(&d.DocumentID, &d.CreatedAt)
+}
+
+func (r *documentRepositoryImpl) GetDocument(ctx context.Context, appID, docID int64) (*models.ApplicationDocument, error) {
+	q := `SELECT ` + documentColumns + ` FROM application_documents WHERE application_id = $1 AND document_id = $2`
+	return scanDocument(conn(ctx, r.db).QueryRowContext(ctx, q, appID, docID))
+}
+
+func (r *documentRepositoryImpl) ListDocuments(ctx context.Context, appID int64) ([]models.ApplicationDocument, error) {
+	q := `SELECT ` + documentColumns + ` FROM application_documents WHERE application_id = $1 ORDER BY created_at, document_id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, appID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.ApplicationDocument{}
+	for rows.Next() {
+		d, err := scanDocument(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *d)
+	}
+	return res, rows.Err()
+}
+
+func (r *documentRepositoryImpl) DeleteDocument(ctx context.Context, appID, docID int64) (bool, error) {
+	const q = `DELETE FROM application_documents WHERE application_id = $1 AND document_id = $2`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, appID, docID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
@@ -0,0 +1,160 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type LedgerRepository interface {
+	// EnsureAccount возвращает счёт с кодом a.Code, создавая его при отсутствии
+	EnsureAccount(ctx context.Context, a *models.LedgerAccount) error
+	// CreateEntry сохраняет запись вместе с движениями; баланс записи проверяется при фиксации транзакции
+	CreateEntry(ctx context.Context, e *models.LedgerEntry) error
+	// RefreshCustomerBalances пересчитывает user_bank_accounts.balance по движениям счетов CUSTOMER_FUNDS
+	RefreshCustomerBalances(ctx context.Context, ledgerAccountIDs []int64) error
+
+	// Сверка главной книги
+	CountEntries(ctx context.Context) (entries, postings int64, err error)
+	TrialBalance(ctx context.Context) (string, error)
+	ListUnbalancedEntries(ctx context.Context) ([]models.LedgerImbalance, error)
+	// ListMismatches — счета клиентов и доли кредитов, чей хранимый остаток расходится с проводками
+	ListMismatches(ctx context.Context) ([]models.LedgerMismatch, error)
+}
+
+type ledgerRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewLedgerRepository(db *sql.DB) LedgerRepository {
+	return &ledgerRepositoryImpl{db: db}
+}
+
+func (r *ledgerRepositoryImpl) EnsureAccount(ctx context.Context, a *models.LedgerAccount) error {
+	// DO UPDATE без изменений нужен, чтобы RETURNING вернул существующую строку
+	const q = `
+		INSERT INTO ledger_accounts (code, kind, normal_balance, bank_id, account_id, split_id, currency)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (code) DO UPDATE SET code = EXCLUDED.code
+		RETURNING ledger_account_id, created_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		a.Code, a.Kind, a.NormalBalance, a.BankID, a.AccountID, a.SplitID, a.Currency,
+	).Scan(&a.LedgerAccountID, &a.CreatedAt)
+}
+
+func (r *ledgerRepositoryImpl) CreateEntry(ctx context.Context, e *models.LedgerEntry) error {
+	const q = `
+		INSERT INTO ledger_entries (entry_type, description, loan_id, payment_id, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING entry_id, created_at
+	`
+	db := conn(ctx, r.db)
+	err := db.QueryRowContext(ctx, q, e.EntryType, e.Description, e.LoanID, e.PaymentID, e.OccurredAt).Scan(&e.EntryID, &e.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	const qp = `
+		INSERT INTO ledger_postings (entry_id, ledger_account_id, amount)
+		VALUES ($1, $2, $3)
+		RETURNING posting_id
+	`
+	for i := range e.Postings {
+		p := &e.Postings[i]
+		p.EntryID = e.EntryID
+		if err := db.QueryRowContext(ctx, qp, p.EntryID, p.LedgerAccountID, p.Amount).Scan(&p.PostingID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *ledgerRepositoryImpl) RefreshCustomerBalances(ctx context.Context, ledgerAccountIDs []int64) error {
+	const q = `
+		UPDATE user_bank_accounts u
+		SET balance = -COALESCE((SELECT SUM(p.amount) FROM ledger_postings p WHERE p.ledger_account_id = a.ledger_account_id), 0)
+		FROM ledger_accounts a
+		WHERE a.ledger_account_id = ANY($1) AND a.kind = 'CUSTOMER_FUNDS' AND u.account_id = a.account_id
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, pq.Array(ledgerAccountIDs))
+	return err
+}
+
+func (r *ledgerRepositoryImpl) CountEntries(ctx context.Context) (int64, int64, error) {
+	const q = `SELECT (SELECT COUNT(*) FROM ledger_entries), (SELECT COUNT(*) FROM ledger_postings)`
+	var entries, postings int64
+	err := conn(ctx, r.db).QueryRowContext(ctx, q).Scan(&entries, &postings)
+	return entries, postings, err
+}
+
+func (r *ledgerRepositoryImpl) TrialBalance(ctx context.Context) (string, error) {
+	const q = `SELECT COALESCE(TO_CHAR(SUM(amount), 'FM9999999999990.00'), '0.00') FROM ledger_postings`
+	var total string
+	err := conn(ctx, r.db).QueryRowContext(ctx, q).Scan(&total)
+	return total, err
+}
+
+func (r *ledgerRepositoryImpl) ListUnbalancedEntries(ctx context.Context) ([]models.LedgerImbalance, error) {
+	const q = `
+		SELECT entry_id, TO_CHAR(SUM(amount), 'FM9999999999990.00')
+		FROM ledger_postings
+		GROUP BY entry_id
+		HAVING SUM(amount) <> 0
+		ORDER BY entry_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.LedgerImbalance{}
+	for rows.Next() {
+		var it models.LedgerImbalance
+		if err := rows.Scan(&it.EntryID, &it.Difference); err != nil {
+			return nil, err
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
+
+func (r *ledgerRepositoryImpl) ListMismatches(ctx context.Context) ([]models.LedgerMismatch, error) {
+	const q = `
+		WITH balances AS (
+		  SELECT a.kind, COALESCE(a.account_id, a.split_id) AS ref_id, SUM(p.amount) AS total
+		  FROM ledger_accounts a
+		  JOIN ledger_postings p ON p.ledger_account_id = a.ledger_account_id
+		  WHERE a.kind IN ('CUSTOMER_FUNDS', 'LOAN_PRINCIPAL')
+		  GROUP BY a.kind, COALESCE(a.account_id, a.split_id)
+		), stored AS (
+		  SELECT 'CUSTOMER_FUNDS' AS kind, account_id AS ref_id, balance AS stored, -1 AS sign FROM user_bank_accounts
+		  UNION ALL
+		  SELECT 'LOAN_PRINCIPAL', split_id, remaining_principal, 1 FROM loan_splits
+		)
+		SELECT s.kind, s.ref_id, s.stored, s.sign * COALESCE(b.total, 0)
+		FROM stored s
+		LEFT JOIN balances b ON b.kind = s.kind AND b.ref_id = s.ref_id
+		WHERE s.stored <> s.sign * COALESCE(b.total, 0)
+		ORDER BY s.kind, s.ref_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.LedgerMismatch{}
+	for rows.Next() {
+		var it models.LedgerMismatch
+		if err := rows.Scan(&it.Kind, &it.RefID, &it.Stored, &it.Ledger); err != nil {
+			return nil, err
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,188 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type LendingSettingsRepository interface {
+	// ListSettings возвращает настройки банков вместе с текущим непогашенным долгом по каждому
+	ListSettings(ctx context.Context) ([]models.BankLendingSettings, error)
+	GetSettings(ctx context.Context, bankID int16) (*models.BankLendingSettings, error)
+	CreateSettings(ctx context.Context, s *models.BankLendingSettings) error
+	// UpdateSettings возвращает false, если профиля банка нет
+	UpdateSettings(ctx context.Context, s *models.BankLendingSettings) (bool, error)
+	DeleteSettings(ctx context.Context, bankID int16) (bool, error)
+
+	ListAppetite(ctx context.Context, typeCode string) ([]models.BankProductAppetite, error)
+	ListBankAppetite(ctx context.Context, bankID int16) ([]models.BankProductAppetite, error)
+	// ReplaceBankAppetite заменяет все продукты банка на items
+	ReplaceBankAppetite(ctx context.Context, bankID int16, items []models.BankProductAppetite) error
+}
+
+type lendingSettingsRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewLendingSettingsRepository(db *sql.DB) LendingSettingsRepository {
+	return &lendingSettingsRepositoryImpl{db: db}
+}
+
+const lendingSettingsSelect = `
+	SELECT s.bank_id, s.min_ticket, s.max_ticket, s.exposure_limit, s.target_share,
+	       s.currencies, s.risk_appetite, s.active, s.updated_at,
+	       COALESCE((
+	         SELECT SUM(ls.remaining_principal)
+	         FROM loan_splits ls
+	         JOIN loans l ON l.loan_id = ls.loan_id
+	         WHERE ls.bank_id = s.bank_id AND l.status <> 'CLOSED'
+	       ), 0)
+	FROM bank_lending_settings s
+`
+
+func scanLendingSettings(row interface{ Scan(...any) error }) (*models.BankLendingSettings, error) {
+	s := &models.BankLendingSettings{}
+	err := row.Scan(
+		&s.BankID, &s.MinTicket, &s.MaxTicket, &s.ExposureLimit, &s.TargetShare,
+		pq.Array(&s.Currencies), &s.RiskAppetite, &s.Active, &s.UpdatedAt, &s.CurrentExposure,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *lendingSettingsRepositoryImpl) ListSettings(ctx context.Context) ([]models.BankLendingSettings, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, lendingSettingsSelect+` ORDER BY s.bank_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.BankLendingSettings
+	for rows.Next() {
+		s, err := scanLendingSettings(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
+
+func (r *lendingSettingsRepositoryImpl) GetSettings(ctx context.Context, bankID int16) (*models.BankLendingSettings, error) {
+	row := conn(ctx, r.db).QueryRowContext(ctx, lendingSettingsSelect+` WHERE s.bank_id = $1`, bankID)
+	return scanLendingSettings(row)
+}
+
+func (r *lendingSettingsRepositoryImpl) CreateSettings(ctx context.Context, s *models.BankLendingSettings) error {
+	const q = `
+		INSERT INTO bank_lending_settings
+		  (bank_id, min_ticket, max_ticket, exposure_limit, target_share, currencies, risk_appetite, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING updated_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		s.BankID, s.MinTicket, s.MaxTicket, s.ExposureLimit, s.TargetShare,
+		pq.Array(s.Currencies), s.RiskAppetite, s.Active,
+	).Scan(&s.UpdatedAt)
+}
+
+func (r *lendingSettingsRepositoryImpl) UpdateSettings(ctx context.Context, s *models.BankLendingSettings) (bool, error) {
+	const q = `
+		UPDATE bank_lending_settings
+		SET min_ticket = $2, max_ticket = $3, exposure_limit = $4, target_share = $5,
+		    currencies = $6, risk_appetite = $7, active = $8, updated_at = now()
+		WHERE bank_id = $1
+		RETURNING updated_at
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		s.BankID, s.MinTicket, s.MaxTicket, s.ExposureLimit, s.TargetShare,
+		pq.Array(s.Currencies), s.RiskAppetite, s.Active,
+	).Scan(&s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *lendingSettingsRepositoryImpl) DeleteSettings(ctx context.Context, bankID int16) (bool, error) {
+	c := conn(ctx, r.db)
+	if _, err := c.ExecContext(ctx, `DELETE FROM bank_product_appetite WHERE bank_id = $1`, bankID); err != nil {
+		return false, err
+	}
+	res, err := c.ExecContext(ctx, `DELETE FROM bank_lending_settings WHERE bank_id = $1`, bankID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *lendingSettingsRepositoryImpl) ListAppetite(ctx context.Context, typeCode string) ([]models.BankProductAppetite, error) {
+	const q = `
+		SELECT bank_id, type_code, weight, base_rate
+		FROM bank_product_appetite WHERE type_code = $1
+		ORDER BY bank_id
+	`
+	return r.listAppetite(ctx, q, typeCode)
+}
+
+func (r *lendingSettingsRepositoryImpl) ListBankAppetite(ctx context.Context, bankID int16) ([]models.BankProductAppetite, error) {
+	const q = `
+		SELECT bank_id, type_code, weight, base_rate
+		FROM bank_product_appetite WHERE bank_id = $1
+		ORDER BY type_code
+	`
+	return r.listAppetite(ctx, q, bankID)
+}
+
+func (r *lendingSettingsRepositoryImpl) listAppetite(ctx context.Context, q string, arg any) ([]models.BankProductAppetite, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, arg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.BankProductAppetite
+	for rows.Next() {
+		var a models.BankProductAppetite
+		var rate sql.NullString
+		if err := rows.Scan(&a.BankID, &a.TypeCode, &a.Weight, &rate); err != nil {
+			return nil, err
+		}
+		if rate.Valid {
+			a.BaseRate = &rate.String
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+func (r *lendingSettingsRepositoryImpl) ReplaceBankAppetite(ctx context.Context, bankID int16, items []models.BankProductAppetite) error {
+	c := conn(ctx, r.db)
+	if _, err := c.ExecContext(ctx, `DELETE FROM bank_product_appetite WHERE bank_id = $1`, bankID); err != nil {
+		return err
+	}
+
+	const q = `
+		INSERT INTO bank_product_appetite (bank_id, type_code, weight, base_rate)
+		VALUES ($1, $2, $3, $4)
+	`
+	for _, a := range items {
+		if _, err := c.ExecContext(ctx, q, bankID, a.TypeCode, a.Weight, a.BaseRate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
 
 	"github.com/Arlandaren/easyfund/internal/models"
 )
@@ -14,8 +17,23 @@ type LoanRepository interface {
 
 	CreateLoanSplit(ctx context.Context, split *models.LoanSplit) error
 	GetLoanSplits(ctx context.Context, loanID int64) ([]models.LoanSplit, error)
+	// GetLoanSplitsForUpdate блокирует доли кредита до конца транзакции (SELECT ... FOR UPDATE)
+	GetLoanSplitsForUpdate(ctx context.Context, loanID int64) ([]models.LoanSplit, error)
 	UpdateLoanSplitPrincipal(ctx context.Context, splitID int64, remainingPrincipal string) error
-	UpdateLoanStatus(ctx context.Context, loanID int64, status string) error
+	UpdateLoanSplitAccrual(ctx context.Context, splitID int64, accruedInterest, penaltyInterest string, accruedThrough time.Time) error
+	// UpdateLoanSplitRemainders сохраняет доли копейки, перенесённые на следующий день начисления
+	UpdateLoanSplitRemainders(ctx context.Context, splitID int64, interestRemainder, penaltyRemainder float64) error
+	UpdateLoanSplitDelinquency(ctx context.Context, splitID int64, daysPastDue int, overdueAmount string) error
+	// UpdateLoanSplitDisbursement сохраняет статус зачисления доли и увеличивает счётчик попыток
+	UpdateLoanSplitDisbursement(ctx context.Context, split *models.LoanSplit) error
+	// ListLoanIDsPendingDisbursement — кредиты с незачисленными долями, у которых меньше maxAttempts попыток
+	ListLoanIDsPendingDisbursement(ctx context.Context, maxAttempts int) ([]int64, error)
+	// UpdateLoanStatus меняет статус, только если текущий равен from; возвращает false, если строка не обновлена
+	UpdateLoanStatus(ctx context.Context, loanID int64, from, to string) (bool, error)
+	UpdateLoanTerm(ctx context.Context, loanID int64, termMonths int) error
+	ListLoanIDsByStatus(ctx context.Context, statuses ...string) ([]int64, error)
+	// ListBankDelinquencies — просроченные доли банка с просрочкой не меньше minDPD дней
+	ListBankDelinquencies(ctx context.Context, bankID int16, minDPD, maxDPD int) ([]models.BankDelinquencyItem, error)
 }
 
 type loanRepositoryImpl struct {
@@ -28,25 +46,29 @@ func NewLoanRepository(db *sql.DB) LoanRepository {
 
 func (r *loanRepositoryImpl) CreateLoan(ctx context.Context, loan *models.Loan) (int64, error) {
 	const q = `
-		INSERT INTO loans (user_id, original_amount, taken_at, interest_rate, status, purpose, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO loans (user_id, original_amount, taken_at, interest_rate, status, purpose, term_months, repayment_type, day_count_convention, product_id,
+		                   origination_fee, insurance_premium, monthly_fee, grace_period_days, psk, psk_amount, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 		RETURNING loan_id
 	`
 	var id int64
-	err := r.db.QueryRowContext(ctx, q,
-		loan.UserID, loan.OriginalAmount, loan.TakenAt, loan.InterestRate, loan.Status, loan.Purpose, loan.CreatedAt,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		loan.UserID, loan.OriginalAmount, loan.TakenAt, loan.InterestRate, loan.Status, loan.Purpose, loan.TermMonths, loan.RepaymentType, loan.DayCountConvention, loan.ProductID,
+		loan.OriginationFee, loan.InsurancePremium, loan.MonthlyFee, loan.GracePeriodDays, loan.Psk, loan.PskAmount, loan.CreatedAt,
 	).Scan(&id)
 	return id, err
 }
 
 func (r *loanRepositoryImpl) GetLoanByID(ctx context.Context, loanID int64) (*models.Loan, error) {
 	const q = `
-		SELECT loan_id, user_id, original_amount, taken_at, interest_rate, status, purpose, created_at
+		SELECT loan_id, user_id, original_amount, taken_at, interest_rate, status, COALESCE(purpose, ''), term_months, repayment_type, day_count_convention, product_id,
+		       origination_fee, insurance_premium, monthly_fee, grace_period_days, psk, psk_amount, created_at
 		FROM loans WHERE loan_id = $1
 	`
 	l := &models.Loan{}
-	err := r.db.QueryRowContext(ctx, q, loanID).Scan(
-		&l.LoanID, &l.UserID, &l.OriginalAmount, &l.TakenAt, &l.InterestRate, &l.Status, &l.Purpose, &l.CreatedAt,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, loanID).Scan(
+		&l.LoanID, &l.UserID, &l.OriginalAmount, &l.TakenAt, &l.InterestRate, &l.Status, &l.Purpose, &l.TermMonths, &l.RepaymentType, &l.DayCountConvention, &l.ProductID,
+		&l.OriginationFee, &l.InsurancePremium, &l.MonthlyFee, &l.GracePeriodDays, &l.Psk, &l.PskAmount, &l.CreatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -56,11 +78,12 @@ func (r *loanRepositoryImpl) GetLoanByID(ctx context.Context, loanID int64) (*mo
 
 func (r *loanRepositoryImpl) ListUserLoans(ctx context.Context, userID int64) ([]models.Loan, error) {
 	const q = `
-		SELECT loan_id, user_id, original_amount, taken_at, interest_rate, status, purpose, created_at
+		SELECT loan_id, user_id, original_amount, taken_at, interest_rate, status, COALESCE(purpose, ''), term_months, repayment_type, day_count_convention, product_id,
+		       origination_fee, insurance_premium, monthly_fee, grace_period_days, psk, psk_amount, created_at
 		FROM loans WHERE user_id = $1
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -69,7 +92,10 @@ func (r *loanRepositoryImpl) ListUserLoans(ctx context.Context, userID int64) ([
 	var loans []models.Loan
 	for rows.Next() {
 		var l models.Loan
-		if err := rows.Scan(&l.LoanID, &l.UserID, &l.OriginalAmount, &l.TakenAt, &l.InterestRate, &l.Status, &l.Purpose, &l.CreatedAt); err != nil {
+		if err := rows.Scan(
+			&l.LoanID, &l.UserID, &l.OriginalAmount, &l.TakenAt, &l.InterestRate, &l.Status, &l.Purpose, &l.TermMonths, &l.RepaymentType, &l.DayCountConvention, &l.ProductID,
+			&l.OriginationFee, &l.InsurancePremium, &l.MonthlyFee, &l.GracePeriodDays, &l.Psk, &l.PskAmount, &l.CreatedAt,
+		); err != nil {
 			return nil, err
 		}
 		loans = append(loans, l)
@@ -79,20 +105,46 @@ func (r *loanRepositoryImpl) ListUserLoans(ctx context.Context, userID int64) ([
 
 func (r *loanRepositoryImpl) CreateLoanSplit(ctx context.Context, split *models.LoanSplit) error {
 	const q = `
-		INSERT INTO loan_splits (loan_id, bank_id, split_amount, remaining_principal)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO loan_splits (loan_id, bank_id, split_amount, remaining_principal, interest_rate, accrued_interest, accrued_through, psk, psk_amount, disbursement_status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING split_id
 	`
-	_, err := r.db.ExecContext(ctx, q, split.LoanID, split.BankID, split.SplitAmount, split.RemainingPrincipal)
+	if split.AccruedInterest == "" {
+		split.AccruedInterest = "0.00"
+	}
+	if split.DisbursementStatus == "" {
+		split.DisbursementStatus = models.DisbursementPending
+	}
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		split.LoanID, split.BankID, split.SplitAmount, split.RemainingPrincipal, split.InterestRate, split.AccruedInterest, split.AccruedThrough, split.Psk, split.PskAmount,
+		split.DisbursementStatus,
+	).Scan(&split.SplitID)
 	return err
 }
 
 func (r *loanRepositoryImpl) GetLoanSplits(ctx context.Context, loanID int64) ([]models.LoanSplit, error) {
 	const q = `
-		SELECT split_id, loan_id, bank_id, split_amount, remaining_principal
+		SELECT split_id, loan_id, bank_id, split_amount, remaining_principal, interest_rate, accrued_interest, accrued_through, days_past_due, overdue_amount, penalty_interest, interest_remainder, penalty_remainder, psk, psk_amount,
+		       disbursement_status, disbursement_attempts, disbursement_error, disbursed_at, account_id, transaction_id
+		FROM loan_splits WHERE loan_id = $1
+		ORDER BY split_id
+	`
+	return r.querySplits(ctx, q, loanID)
+}
+
+func (r *loanRepositoryImpl) GetLoanSplitsForUpdate(ctx context.Context, loanID int64) ([]models.LoanSplit, error) {
+	const q = `
+		SELECT split_id, loan_id, bank_id, split_amount, remaining_principal, interest_rate, accrued_interest, accrued_through, days_past_due, overdue_amount, penalty_interest, interest_remainder, penalty_remainder, psk, psk_amount,
+		       disbursement_status, disbursement_attempts, disbursement_error, disbursed_at, account_id, transaction_id
 		FROM loan_splits WHERE loan_id = $1
 		ORDER BY split_id
+		FOR UPDATE
 	`
-	rows, err := r.db.QueryContext(ctx, q, loanID)
+	return r.querySplits(ctx, q, loanID)
+}
+
+func (r *loanRepositoryImpl) querySplits(ctx context.Context, q string, args ...any) ([]models.LoanSplit, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -101,7 +153,11 @@ func (r *loanRepositoryImpl) GetLoanSplits(ctx context.Context, loanID int64) ([
 	var splits []models.LoanSplit
 	for rows.Next() {
 		var s models.LoanSplit
-		if err := rows.Scan(&s.SplitID, &s.LoanID, &s.BankID, &s.SplitAmount, &s.RemainingPrincipal); err != nil {
+		if err := rows.Scan(
+			&s.SplitID, &s.LoanID, &s.BankID, &s.SplitAmount, &s.RemainingPrincipal, &s.InterestRate, &s.AccruedInterest, &s.AccruedThrough,
+			&s.DaysPastDue, &s.OverdueAmount, &s.PenaltyInterest, &s.InterestRemainder, &s.PenaltyRemainder, &s.Psk, &s.PskAmount,
+			&s.DisbursementStatus, &s.DisbursementAttempts, &s.DisbursementError, &s.DisbursedAt, &s.AccountID, &s.TransactionID,
+		); err != nil {
 			return nil, err
 		}
 		splits = append(splits, s)
@@ -111,12 +167,122 @@ func (r *loanRepositoryImpl) GetLoanSplits(ctx context.Context, loanID int64) ([
 
 func (r *loanRepositoryImpl) UpdateLoanSplitPrincipal(ctx context.Context, splitID int64, remainingPrincipal string) error {
 	const q = `UPDATE loan_splits SET remaining_principal = $1 WHERE split_id = $2`
-	_, err := r.db.ExecContext(ctx, q, remainingPrincipal, splitID)
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, remainingPrincipal, splitID)
+	return err
+}
+
+func (r *loanRepositoryImpl) UpdateLoanSplitAccrual(ctx context.Context, splitID int64, accruedInterest, penaltyInterest string, accruedThrough time.Time) error {
+	const q = `UPDATE loan_splits SET accrued_interest = $1, penalty_interest = $2, accrued_through = $3 WHERE split_id = $4`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, accruedInterest, penaltyInterest, accruedThrough, splitID)
+	return err
+}
+
+func (r *loanRepositoryImpl) UpdateLoanSplitRemainders(ctx context.Context, splitID int64, interestRemainder, penaltyRemainder float64) error {
+	const q = `UPDATE loan_splits SET interest_remainder = $1, penalty_remainder = $2 WHERE split_id = $3`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, interestRemainder, penaltyRemainder, splitID)
 	return err
 }
 
-func (r *loanRepositoryImpl) UpdateLoanStatus(ctx context.Context, loanID int64, status string) error {
-	const q = `UPDATE loans SET status = $1 WHERE loan_id = $2`
-	_, err := r.db.ExecContext(ctx, q, status, loanID)
+func (r *loanRepositoryImpl) UpdateLoanSplitDelinquency(ctx context.Context, splitID int64, daysPastDue int, overdueAmount string) error {
+	const q = `UPDATE loan_splits SET days_past_due = $1, overdue_amount = $2 WHERE split_id = $3`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, daysPastDue, overdueAmount, splitID)
+	return err
+}
+
+func (r *loanRepositoryImpl) UpdateLoanSplitDisbursement(ctx context.Context, split *models.LoanSplit) error {
+	const q = `
+		UPDATE loan_splits
+		SET disbursement_status = $1, disbursement_error = $2, disbursed_at = $3, account_id = $4, transaction_id = $5,
+		    disbursement_attempts = disbursement_attempts + 1
+		WHERE split_id = $6
+		RETURNING disbursement_attempts
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		split.DisbursementStatus, split.DisbursementError, split.DisbursedAt, split.AccountID, split.TransactionID, split.SplitID,
+	).Scan(&split.DisbursementAttempts)
+}
+
+func (r *loanRepositoryImpl) ListLoanIDsPendingDisbursement(ctx context.Context, maxAttempts int) ([]int64, error) {
+	const q = `
+		SELECT DISTINCT loan_id FROM loan_splits
+		WHERE disbursement_status <> 'DISBURSED' AND disbursement_attempts < $1
+		ORDER BY loan_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, maxAttempts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *loanRepositoryImpl) UpdateLoanStatus(ctx context.Context, loanID int64, from, to string) (bool, error) {
+	const q = `UPDATE loans SET status = $1 WHERE loan_id = $2 AND status = $3`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, to, loanID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *loanRepositoryImpl) UpdateLoanTerm(ctx context.Context, loanID int64, termMonths int) error {
+	const q = `UPDATE loans SET term_months = $1 WHERE loan_id = $2`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, termMonths, loanID)
 	return err
-}
\ No newline at end of file
+}
+
+func (r *loanRepositoryImpl) ListLoanIDsByStatus(ctx context.Context, statuses ...string) ([]int64, error) {
+	const q = `SELECT loan_id FROM loans WHERE status = ANY($1) ORDER BY loan_id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, pq.Array(statuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *loanRepositoryImpl) ListBankDelinquencies(ctx context.Context, bankID int16, minDPD, maxDPD int) ([]models.BankDelinquencyItem, error) {
+	const q = `
+		SELECT l.loan_id, l.user_id, ls.split_id, ls.bank_id, l.status, ls.days_past_due, ls.overdue_amount, ls.penalty_interest, ls.remaining_principal
+		FROM loan_splits ls
+		JOIN loans l ON l.loan_id = ls.loan_id
+		WHERE ls.bank_id = $1 AND ls.days_past_due >= $2 AND ls.days_past_due <= $3
+		ORDER BY ls.days_past_due DESC, ls.split_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, bankID, minDPD, maxDPD)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.BankDelinquencyItem
+	for rows.Next() {
+		var it models.BankDelinquencyItem
+		if err := rows.Scan(
+			&it.LoanID, &it.UserID, &it.SplitID, &it.BankID, &it.LoanStatus, &it.DaysPastDue, &it.OverdueAmount, &it.PenaltyInterest, &it.RemainingPrincipal,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,150 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type OfferRepository interface {
+	CreateOffer(ctx context.Context, offer *models.ApplicationOffer) error
+	GetOfferByID(ctx context.Context, offerID int64) (*models.ApplicationOffer, error)
+	ListApplicationOffers(ctx context.Context, appID int64) ([]models.ApplicationOffer, error)
+	// ListOffersForUpdate блокирует предложения до конца транзакции
+	ListOffersForUpdate(ctx context.Context, offerIDs []int64) ([]models.ApplicationOffer, error)
+	// UpdateOfferStatus меняет статус, только если текущий равен from; возвращает false, если строка не обновлена
+	UpdateOfferStatus(ctx context.Context, offerID int64, from, to, reason string) (bool, error)
+	// DeclinePendingOffers отклоняет все действующие предложения по заявке
+	DeclinePendingOffers(ctx context.Context, appID int64, reason string) (int64, error)
+	// ExpireApplicationOffers переводит все действующие предложения по заявке в EXPIRED
+	ExpireApplicationOffers(ctx context.Context, appID int64, reason string) (int64, error)
+	// ExpireOffers переводит просроченные действующие предложения в EXPIRED
+	ExpireOffers(ctx context.Context, now time.Time) (int64, error)
+}
+
+type offerRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewOfferRepository(db *sql.DB) OfferRepository {
+	return &offerRepositoryImpl{db: db}
+}
+
+const offerColumns = `
+	offer_id, application_id, bank_id, amount, interest_rate, term_months, repayment_type, psk, psk_amount,
+	status, expires_at, created_by, created_at, decided_at, decision_reason
+`
+
+func scanOffer(row interface{ Scan(...any) error }) (*models.ApplicationOffer, error) {
+	o := &models.ApplicationOffer{}
+	err := row.Scan(
+		&o.OfferID, &o.ApplicationID, &o.BankID, &o.Amount, &o.InterestRate, &o.TermMonths, &o.RepaymentType, &o.Psk, &o.PskAmount,
+		&o.Status, &o.ExpiresAt, &o.CreatedBy, &o.CreatedAt, &o.DecidedAt, &o.DecisionReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (r *offerRepositoryImpl) CreateOffer(ctx context.Context, o *models.ApplicationOffer) error {
+	const q = `
+		INSERT INTO application_offers
+		  (application_id, bank_id, amount, interest_rate, term_months, repayment_type, psk, psk_amount, status, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING offer_id, created_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		o.ApplicationID, o.BankID, o.Amount, o.InterestRate, o.TermMonths, o.RepaymentType, o.Psk, o.PskAmount, o.Status, o.ExpiresAt, o.CreatedBy,
+	).Scan(&o.OfferID, &o.CreatedAt)
+}
+
+func (r *offerRepositoryImpl) GetOfferByID(ctx context.Context, offerID int64) (*models.ApplicationOffer, error) {
+	q := `SELECT ` + offerColumns + ` FROM application_offers WHERE offer_id = $1`
+	return scanOffer(conn(ctx, r.db).QueryRowContext(ctx, q, offerID))
+}
+
+func (r *offerRepositoryImpl) ListApplicationOffers(ctx context.Context, appID int64) ([]models.ApplicationOffer, error) {
+	q := `SELECT ` + offerColumns + ` FROM application_offers WHERE application_id = $1 ORDER BY created_at, offer_id`
+	return r.listOffers(ctx, q, appID)
+}
+
+func (r *offerRepositoryImpl) ListOffersForUpdate(ctx context.Context, offerIDs []int64) ([]models.ApplicationOffer, error) {
+	q := `SELECT ` + offerColumns + ` FROM application_offers WHERE offer_id = ANY($1) ORDER BY offer_id FOR UPDATE`
+	return r.listOffers(ctx, q, pq.Array(offerIDs))
+}
+
+func (r *offerRepositoryImpl) listOffers(ctx context.Context, q string, args ...any) ([]models.ApplicationOffer, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.ApplicationOffer
+	for rows.Next() {
+		o, err := scanOffer(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *o)
+	}
+	return res, rows.Err()
+}
+
+func (r *offerRepositoryImpl) UpdateOfferStatus(ctx context.Context, offerID int64, from, to, reason string) (bool, error) {
+	const q = `
+		UPDATE application_offers
+		SET status = $1, decided_at = now(), decision_reason = NULLIF($2, '')
+		WHERE offer_id = $3 AND status = $4
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, to, reason, offerID, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *offerRepositoryImpl) DeclinePendingOffers(ctx context.Context, appID int64, reason string) (int64, error) {
+	const q = `
+		UPDATE application_offers
+		SET status = 'DECLINED', decided_at = now(), decision_reason = NULLIF($1, '')
+		WHERE application_id = $2 AND status = 'PENDING'
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, reason, appID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *offerRepositoryImpl) ExpireApplicationOffers(ctx context.Context, appID int64, reason string) (int64, error) {
+	const q = `
+		UPDATE application_offers
+		SET status = 'EXPIRED', decided_at = now(), decision_reason = NULLIF($1, '')
+		WHERE application_id = $2 AND status = 'PENDING'
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, reason, appID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+func (r *offerRepositoryImpl) ExpireOffers(ctx context.Context, now time.Time) (int64, error) {
+	const q = `
+		UPDATE application_offers
+		SET status = 'EXPIRED', decided_at = expires_at, decision_reason = 'offer expired'
+		WHERE status = 'PENDING' AND expires_at <= $1
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
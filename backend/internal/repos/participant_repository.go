@@ -0,0 +1,161 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type ParticipantRepository interface {
+	CreateParticipants(ctx context.Context, appID int64, bankIDs []int16) error
+	// ReplaceParticipants заменяет список банков заявки (для черновиков)
+	ReplaceParticipants(ctx context.Context, appID int64, bankIDs []int16) error
+	GetParticipant(ctx context.Context, appID int64, bankID int16) (*models.ApplicationParticipant, error)
+	ListParticipants(ctx context.Context, appIDs ...int64) ([]models.ApplicationParticipant, error)
+	// UpdateParticipantStatus меняет статус, только если текущий входит в from; возвращает false, если строка не обновлена
+	UpdateParticipantStatus(ctx context.Context, appID int64, bankID int16, from []string, to, reason string) (bool, error)
+	// CloseOpenParticipants закрывает участников, ещё не давших окончательный ответ
+	CloseOpenParticipants(ctx context.Context, appID int64) error
+	// SyncOfferedParticipants возвращает в INVITED участников, у которых не осталось действующих предложений
+	SyncOfferedParticipants(ctx context.Context) error
+	// ListBankApplications — заявки, адресованные банку; statuses пусто — все
+	ListBankApplications(ctx context.Context, bankID int16, statuses []string) ([]models.BankApplicationDTO, error)
+}
+
+type participantRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewParticipantRepository(db *sql.DB) ParticipantRepository {
+	return &participantRepositoryImpl{db: db}
+}
+
+func (r *participantRepositoryImpl) CreateParticipants(ctx context.Context, appID int64, bankIDs []int16) error {
+	const q = `INSERT INTO application_participants (application_id, bank_id) VALUES ($1, $2)`
+	c := conn(ctx, r.db)
+	for _, bankID := range bankIDs {
+		if _, err := c.ExecContext(ctx, q, appID, bankID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *participantRepositoryImpl) ReplaceParticipants(ctx context.Context, appID int64, bankIDs []int16) error {
+	const q = `DELETE FROM application_participants WHERE application_id = $1`
+	if _, err := conn(ctx, r.db).ExecContext(ctx, q, appID); err != nil {
+		return err
+	}
+	return r.CreateParticipants(ctx, appID, bankIDs)
+}
+
+const participantSelect = `
+	SELECT p.application_id, p.bank_id, b.name, p.status, p.decline_reason, p.created_at, p.updated_at
+	FROM application_participants p
+	JOIN banks b ON b.bank_id = p.bank_id
+`
+
+func scanParticipant(row interface{ Scan(...any) error }) (*models.ApplicationParticipant, error) {
+	p := &models.ApplicationParticipant{}
+	err := row.Scan(&p.ApplicationID, &p.BankID, &p.BankName, &p.Status, &p.DeclineReason, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (r *participantRepositoryImpl) GetParticipant(ctx context.Context, appID int64, bankID int16) (*models.ApplicationParticipant, error) {
+	q := participantSelect + ` WHERE p.application_id = $1 AND p.bank_id = $2`
+	return scanParticipant(conn(ctx, r.db).QueryRowContext(ctx, q, appID, bankID))
+}
+
+func (r *participantRepositoryImpl) ListParticipants(ctx context.Context, appIDs ...int64) ([]models.ApplicationParticipant, error) {
+	q := participantSelect + ` WHERE p.application_id = ANY($1) ORDER BY p.application_id, p.bank_id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, pq.Array(appIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.ApplicationParticipant
+	for rows.Next() {
+		p, err := scanParticipant(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *p)
+	}
+	return res, rows.Err()
+}
+
+func (r *participantRepositoryImpl) UpdateParticipantStatus(ctx context.Context, appID int64, bankID int16, from []string, to, reason string) (bool, error) {
+	const q = `
+		UPDATE application_participants
+		SET status = $1, decline_reason = COALESCE(NULLIF($2, ''), decline_reason), updated_at = now()
+		WHERE application_id = $3 AND bank_id = $4 AND status = ANY($5)
+	`
+	res, err := conn(ctx, r.db).ExecContext(ctx, q, to, reason, appID, bankID, pq.Array(from))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n == 1, err
+}
+
+func (r *participantRepositoryImpl) CloseOpenParticipants(ctx context.Context, appID int64) error {
+	const q = `
+		UPDATE application_participants
+		SET status = 'CLOSED', updated_at = now()
+		WHERE application_id = $1 AND status IN ('INVITED', 'OFFERED')
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, appID)
+	return err
+}
+
+func (r *participantRepositoryImpl) SyncOfferedParticipants(ctx context.Context) error {
+	const q = `
+		UPDATE application_participants p
+		SET status = 'INVITED', updated_at = now()
+		WHERE p.status = 'OFFERED'
+		  AND NOT EXISTS (
+		    SELECT 1 FROM application_offers o
+		    WHERE o.application_id = p.application_id AND o.bank_id = p.bank_id AND o.status = 'PENDING'
+		  )
+	`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q)
+	return err
+}
+
+func (r *participantRepositoryImpl) ListBankApplications(ctx context.Context, bankID int16, statuses []string) ([]models.BankApplicationDTO, error) {
+	const q = `
+		SELECT a.application_id, a.user_id, a.bank_id, a.any_bank, a.type_code, a.status_code, a.requested_amount,
+		       a.term_months, a.product_id, a.loan_id, a.submitted_at, a.updated_at, p.status
+		FROM application_participants p
+		JOIN credit_applications a ON a.application_id = p.application_id
+		WHERE p.bank_id = $1 AND a.status_code <> 'DRAFT'
+		  AND (COALESCE(cardinality($2::text[]), 0) = 0 OR p.status = ANY($2))
+		ORDER BY a.submitted_at DESC
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, bankID, pq.Array(statuses))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.BankApplicationDTO
+	for rows.Next() {
+		var d models.BankApplicationDTO
+		a := &d.CreditApplication
+		if err := rows.Scan(
+			&a.ApplicationID, &a.UserID, &a.BankID, &a.AnyBank, &a.TypeCode, &a.StatusCode, &a.RequestedAmount,
+			&a.TermMonths, &a.ProductID, &a.LoanID, &a.SubmittedAt, &a.UpdatedAt, &d.ParticipantStatus,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+	return res, rows.Err()
+}
@@ -15,6 +15,10 @@ type LoanPaymentRepository interface {
 
 	CreatePaymentAllocation(ctx context.Context, alloc *models.PaymentAllocation) error
 	GetPaymentAllocations(ctx context.Context, paymentID int64) ([]models.PaymentAllocation, error)
+	// SumSplitPaid — уплачено по каждой доле кредита (основной долг, проценты и плата за обслуживание) платежами заданного типа
+	SumSplitPaid(ctx context.Context, loanID int64, paymentType string) (map[int64]string, error)
+	// SumSplitFeesPaid — уплаченная по каждой доле кредита плата за обслуживание, всеми платежами
+	SumSplitFeesPaid(ctx context.Context, loanID int64) (map[int64]string, error)
 }
 
 type loanPaymentRepositoryImpl struct {
@@ -27,23 +31,23 @@ func NewLoanPaymentRepository(db *sql.DB) LoanPaymentRepository {
 
 func (r *loanPaymentRepositoryImpl) CreatePayment(ctx context.Context, p *models.LoanPayment) (int64, error) {
 	const q = `
-		INSERT INTO loan_payments (loan_id, user_id, paid_at, total_amount, comment)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO loan_payments (loan_id, user_id, paid_at, total_amount, comment, allocation_strategy, payment_type)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING payment_id
 	`
 	var id int64
-	err := r.db.QueryRowContext(ctx, q, p.LoanID, p.UserID, p.PaidAt, p.TotalAmount, p.Comment).Scan(&id)
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, p.LoanID, p.UserID, p.PaidAt, p.TotalAmount, p.Comment, p.AllocationStrategy, p.PaymentType).Scan(&id)
 	return id, err
 }
 
 func (r *loanPaymentRepositoryImpl) GetPaymentByID(ctx context.Context, id int64) (*models.LoanPayment, error) {
 	const q = `
-		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment
+		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment, allocation_strategy, payment_type
 		FROM loan_payments WHERE payment_id = $1
 	`
 	p := &models.LoanPayment{}
-	err := r.db.QueryRowContext(ctx, q, id).Scan(
-		&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, id).Scan(
+		&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment, &p.AllocationStrategy, &p.PaymentType,
 	)
 	if err != nil {
 		return nil, err
@@ -53,11 +57,11 @@ func (r *loanPaymentRepositoryImpl) GetPaymentByID(ctx context.Context, id int64
 
 func (r *loanPaymentRepositoryImpl) ListLoanPayments(ctx context.Context, loanID int64) ([]models.LoanPayment, error) {
 	const q = `
-		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment
+		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment, allocation_strategy, payment_type
 		FROM loan_payments WHERE loan_id = $1
 		ORDER BY paid_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, loanID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, loanID)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +70,7 @@ func (r *loanPaymentRepositoryImpl) ListLoanPayments(ctx context.Context, loanID
 	var res []models.LoanPayment
 	for rows.Next() {
 		var p models.LoanPayment
-		if err := rows.Scan(&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment); err != nil {
+		if err := rows.Scan(&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment, &p.AllocationStrategy, &p.PaymentType); err != nil {
 			return nil, err
 		}
 		res = append(res, p)
@@ -76,11 +80,11 @@ func (r *loanPaymentRepositoryImpl) ListLoanPayments(ctx context.Context, loanID
 
 func (r *loanPaymentRepositoryImpl) ListUserPayments(ctx context.Context, userID int64) ([]models.LoanPayment, error) {
 	const q = `
-		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment
+		SELECT payment_id, loan_id, user_id, paid_at, total_amount, comment, allocation_strategy, payment_type
 		FROM loan_payments WHERE user_id = $1
 		ORDER BY paid_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +93,7 @@ func (r *loanPaymentRepositoryImpl) ListUserPayments(ctx context.Context, userID
 	var res []models.LoanPayment
 	for rows.Next() {
 		var p models.LoanPayment
-		if err := rows.Scan(&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment); err != nil {
+		if err := rows.Scan(&p.PaymentID, &p.LoanID, &p.UserID, &p.PaidAt, &p.TotalAmount, &p.Comment, &p.AllocationStrategy, &p.PaymentType); err != nil {
 			return nil, err
 		}
 		res = append(res, p)
@@ -99,20 +103,27 @@ func (r *loanPaymentRepositoryImpl) ListUserPayments(ctx context.Context, userID
 
 func (r *loanPaymentRepositoryImpl) CreatePaymentAllocation(ctx context.Context, a *models.PaymentAllocation) error {
 	const q = `
-		INSERT INTO payment_allocations (payment_id, split_id, principal_paid, interest_paid)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO payment_allocations (payment_id, split_id, principal_paid, interest_paid, penalty_paid, fee_paid)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING allocation_id
 	`
-	_, err := r.db.ExecContext(ctx, q, a.PaymentID, a.SplitID, a.PrincipalPaid, a.InterestPaid)
+	if a.PenaltyPaid == "" {
+		a.PenaltyPaid = "0.00"
+	}
+	if a.FeePaid == "" {
+		a.FeePaid = "0.00"
+	}
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, a.PaymentID, a.SplitID, a.PrincipalPaid, a.InterestPaid, a.PenaltyPaid, a.FeePaid).Scan(&a.AllocationID)
 	return err
 }
 
 func (r *loanPaymentRepositoryImpl) GetPaymentAllocations(ctx context.Context, paymentID int64) ([]models.PaymentAllocation, error) {
 	const q = `
-		SELECT allocation_id, payment_id, split_id, principal_paid, interest_paid
+		SELECT allocation_id, payment_id, split_id, principal_paid, interest_paid, penalty_paid, fee_paid
 		FROM payment_allocations WHERE payment_id = $1
 		ORDER BY allocation_id
 	`
-	rows, err := r.db.QueryContext(ctx, q, paymentID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, paymentID)
 	if err != nil {
 		return nil, err
 	}
@@ -121,10 +132,62 @@ func (r *loanPaymentRepositoryImpl) GetPaymentAllocations(ctx context.Context, p
 	var res []models.PaymentAllocation
 	for rows.Next() {
 		var a models.PaymentAllocation
-		if err := rows.Scan(&a.AllocationID, &a.PaymentID, &a.SplitID, &a.PrincipalPaid, &a.InterestPaid); err != nil {
+		if err := rows.Scan(&a.AllocationID, &a.PaymentID, &a.SplitID, &a.PrincipalPaid, &a.InterestPaid, &a.PenaltyPaid, &a.FeePaid); err != nil {
 			return nil, err
 		}
 		res = append(res, a)
 	}
 	return res, rows.Err()
-}
\ No newline at end of file
+}
+
+func (r *loanPaymentRepositoryImpl) SumSplitPaid(ctx context.Context, loanID int64, paymentType string) (map[int64]string, error) {
+	const q = `
+		SELECT pa.split_id, SUM(pa.principal_paid + pa.interest_paid + pa.fee_paid)
+		FROM payment_allocations pa
+		JOIN loan_payments p ON p.payment_id = pa.payment_id
+		WHERE p.loan_id = $1 AND p.payment_type = $2
+		GROUP BY pa.split_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, loanID, paymentType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[int64]string)
+	for rows.Next() {
+		var splitID int64
+		var paid string
+		if err := rows.Scan(&splitID, &paid); err != nil {
+			return nil, err
+		}
+		res[splitID] = paid
+	}
+	return res, rows.Err()
+}
+
+func (r *loanPaymentRepositoryImpl) SumSplitFeesPaid(ctx context.Context, loanID int64) (map[int64]string, error) {
+	const q = `
+		SELECT pa.split_id, SUM(pa.fee_paid)
+		FROM payment_allocations pa
+		JOIN loan_payments p ON p.payment_id = pa.payment_id
+		WHERE p.loan_id = $1
+		GROUP BY pa.split_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := make(map[int64]string)
+	for rows.Next() {
+		var splitID int64
+		var paid string
+		if err := rows.Scan(&splitID, &paid); err != nil {
+			return nil, err
+		}
+		res[splitID] = paid
+	}
+	return res, rows.Err()
+}
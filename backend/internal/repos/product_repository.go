@@ -0,0 +1,163 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type ProductRepository interface {
+	// ListProducts возвращает продукты вместе с банками; activeOnly — только действующие
+	ListProducts(ctx context.Context, activeOnly bool) ([]models.LoanProduct, error)
+	GetProduct(ctx context.Context, productID int64) (*models.LoanProduct, error)
+	GetProductByCode(ctx context.Context, code string) (*models.LoanProduct, error)
+	CreateProduct(ctx context.Context, p *models.LoanProduct) error
+	// UpdateProduct возвращает false, если продукта нет
+	UpdateProduct(ctx context.Context, p *models.LoanProduct) (bool, error)
+	DeleteProduct(ctx context.Context, productID int64) (bool, error)
+	// ReplaceProductBanks заменяет банки продукта на bankIDs
+	ReplaceProductBanks(ctx context.Context, productID int64, bankIDs []int16) error
+	// ProductInUse — есть ли заявки или кредиты по продукту
+	ProductInUse(ctx context.Context, productID int64) (bool, error)
+}
+
+type productRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewProductRepository(db *sql.DB) ProductRepository {
+	return &productRepositoryImpl{db: db}
+}
+
+const productSelect = `
+	SELECT p.product_id, p.code, p.name, p.description, p.type_code, p.currency,
+	       p.min_amount, p.max_amount, p.min_term_months, p.max_term_months, p.min_rate, p.max_rate,
+	       p.origination_fee_percent, p.origination_fee_fixed, p.monthly_fee, p.insurance_percent, p.grace_period_days,
+	       p.active, p.created_at, p.updated_at,
+	       COALESCE((SELECT array_agg(b.bank_id ORDER BY b.bank_id)
+	                 FROM loan_product_banks b WHERE b.product_id = p.product_id), '{}')
+	FROM loan_products p
+`
+
+func scanProduct(row interface{ Scan(...any) error }) (*models.LoanProduct, error) {
+	p := &models.LoanProduct{}
+	var banks []int64
+	err := row.Scan(
+		&p.ProductID, &p.Code, &p.Name, &p.Description, &p.TypeCode, &p.Currency,
+		&p.MinAmount, &p.MaxAmount, &p.MinTermMonths, &p.MaxTermMonths, &p.MinRate, &p.MaxRate,
+		&p.OriginationFeePercent, &p.OriginationFeeFixed, &p.MonthlyFee, &p.InsurancePercent, &p.GracePeriodDays,
+		&p.Active, &p.CreatedAt, &p.UpdatedAt, pq.Array(&banks),
+	)
+	if err != nil {
+		return nil, err
+	}
+	p.BankIDs = make([]int16, 0, len(banks))
+	for _, b := range banks {
+		p.BankIDs = append(p.BankIDs, int16(b))
+	}
+	return p, nil
+}
+
+func (r *productRepositoryImpl) ListProducts(ctx context.Context, activeOnly bool) ([]models.LoanProduct, error) {
+	q := productSelect + ` WHERE p.active OR NOT $1 ORDER BY p.type_code, p.product_id`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.LoanProduct{}
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *p)
+	}
+	return res, rows.Err()
+}
+
+func (r *productRepositoryImpl) GetProduct(ctx context.Context, productID int64) (*models.LoanProduct, error) {
+	return scanProduct(conn(ctx, r.db).QueryRowContext(ctx, productSelect+` WHERE p.product_id = $1`, productID))
+}
+
+func (r *productRepositoryImpl) GetProductByCode(ctx context.Context, code string) (*models.LoanProduct, error) {
+	return scanProduct(conn(ctx, r.db).QueryRowContext(ctx, productSelect+` WHERE p.code = $1`, code))
+}
+
+func (r *productRepositoryImpl) CreateProduct(ctx context.Context, p *models.LoanProduct) error {
+	const q = `
+		INSERT INTO loan_products (code, name, description, type_code, currency, min_amount, max_amount,
+		                           min_term_months, max_term_months, min_rate, max_rate,
+		                           origination_fee_percent, origination_fee_fixed, monthly_fee, insurance_percent, grace_period_days, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		RETURNING product_id, created_at, updated_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		p.Code, p.Name, p.Description, p.TypeCode, p.Currency, p.MinAmount, p.MaxAmount,
+		p.MinTermMonths, p.MaxTermMonths, p.MinRate, p.MaxRate,
+		p.OriginationFeePercent, p.OriginationFeeFixed, p.MonthlyFee, p.InsurancePercent, p.GracePeriodDays, p.Active,
+	).Scan(&p.ProductID, &p.CreatedAt, &p.UpdatedAt)
+}
+
+func (r *productRepositoryImpl) UpdateProduct(ctx context.Context, p *models.LoanProduct) (bool, error) {
+	const q = `
+		UPDATE loan_products
+		SET code = $2, name = $3, description = $4, type_code = $5, currency = $6, min_amount = $7, max_amount = $8,
+		    min_term_months = $9, max_term_months = $10, min_rate = $11, max_rate = $12,
+		    origination_fee_percent = $13, origination_fee_fixed = $14, monthly_fee = $15, insurance_percent = $16,
+		    grace_period_days = $17, active = $18, updated_at = now()
+		WHERE product_id = $1
+		RETURNING created_at, updated_at
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		p.ProductID, p.Code, p.Name, p.Description, p.TypeCode, p.Currency, p.MinAmount, p.MaxAmount,
+		p.MinTermMonths, p.MaxTermMonths, p.MinRate, p.MaxRate,
+		p.OriginationFeePercent, p.OriginationFeeFixed, p.MonthlyFee, p.InsurancePercent, p.GracePeriodDays, p.Active,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *productRepositoryImpl) DeleteProduct(ctx context.Context, productID int64) (bool, error) {
+	res, err := conn(ctx, r.db).ExecContext(ctx, `DELETE FROM loan_products WHERE product_id = $1`, productID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (r *productRepositoryImpl) ReplaceProductBanks(ctx context.Context, productID int64, bankIDs []int16) error {
+	c := conn(ctx, r.db)
+	if _, err := c.ExecContext(ctx, `DELETE FROM loan_product_banks WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+	for _, bankID := range bankIDs {
+		if _, err := c.ExecContext(ctx, `INSERT INTO loan_product_banks (product_id, bank_id) VALUES ($1, $2)`, productID, bankID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *productRepositoryImpl) ProductInUse(ctx context.Context, productID int64) (bool, error) {
+	const q = `
+		SELECT EXISTS (SELECT 1 FROM credit_applications WHERE product_id = $1)
+		    OR EXISTS (SELECT 1 FROM loans WHERE product_id = $1)
+	`
+	var inUse bool
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, productID).Scan(&inUse)
+	return inUse, err
+}
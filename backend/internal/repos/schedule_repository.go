@@ -0,0 +1,98 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type LoanScheduleRepository interface {
+	CreateScheduleItems(ctx context.Context, items []models.LoanScheduleItem) error
+	GetLoanSchedule(ctx context.Context, loanID int64) ([]models.LoanScheduleItem, error)
+	GetSplitSchedule(ctx context.Context, splitID int64) ([]models.LoanScheduleItem, error)
+	// DeleteScheduleItemsAfter удаляет платежи доли со сроком позже after (для перестроения графика)
+	DeleteScheduleItemsAfter(ctx context.Context, splitID int64, after time.Time) error
+}
+
+type loanScheduleRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewLoanScheduleRepository(db *sql.DB) LoanScheduleRepository {
+	return &loanScheduleRepositoryImpl{db: db}
+}
+
+func (r *loanScheduleRepositoryImpl) CreateScheduleItems(ctx context.Context, items []models.LoanScheduleItem) error {
+	const q = `
+		INSERT INTO loan_schedule_items (split_id, installment_no, due_date, principal, interest, fee, total_payment, remaining_balance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING item_id
+	`
+	for i := range items {
+		it := &items[i]
+		if it.Fee == "" {
+			it.Fee = "0.00"
+		}
+		err := conn(ctx, r.db).QueryRowContext(ctx, q,
+			it.SplitID, it.InstallmentNo, it.DueDate, it.Principal, it.Interest, it.Fee, it.TotalPayment, it.RemainingBalance,
+		).Scan(&it.ItemID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *loanScheduleRepositoryImpl) GetLoanSchedule(ctx context.Context, loanID int64) ([]models.LoanScheduleItem, error) {
+	const q = `
+		SELECT si.item_id, si.split_id, si.installment_no, si.due_date, si.principal, si.interest, si.fee, si.total_payment, si.remaining_balance
+		FROM loan_schedule_items si
+		JOIN loan_splits ls ON ls.split_id = si.split_id
+		WHERE ls.loan_id = $1
+		ORDER BY si.split_id, si.installment_no
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, loanID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduleItems(rows)
+}
+
+func (r *loanScheduleRepositoryImpl) GetSplitSchedule(ctx context.Context, splitID int64) ([]models.LoanScheduleItem, error) {
+	const q = `
+		SELECT item_id, split_id, installment_no, due_date, principal, interest, fee, total_payment, remaining_balance
+		FROM loan_schedule_items WHERE split_id = $1
+		ORDER BY installment_no
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, splitID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanScheduleItems(rows)
+}
+
+func (r *loanScheduleRepositoryImpl) DeleteScheduleItemsAfter(ctx context.Context, splitID int64, after time.Time) error {
+	const q = `DELETE FROM loan_schedule_items WHERE split_id = $1 AND due_date > $2`
+	_, err := conn(ctx, r.db).ExecContext(ctx, q, splitID, after)
+	return err
+}
+
+func scanScheduleItems(rows *sql.Rows) ([]models.LoanScheduleItem, error) {
+	var res []models.LoanScheduleItem
+	for rows.Next() {
+		var it models.LoanScheduleItem
+		if err := rows.Scan(
+			&it.ItemID, &it.SplitID, &it.InstallmentNo, &it.DueDate, &it.Principal, &it.Interest, &it.Fee, &it.TotalPayment, &it.RemainingBalance,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, it)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,167 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type ScoringRepository interface {
+	// LoadInputs собирает данные для скоринга; обороты по счетам — с момента since
+	LoadInputs(ctx context.Context, userID int64, since, now time.Time) (*models.ScoringInputs, error)
+	CreateScore(ctx context.Context, score *models.CreditScore) error
+	// GetLatestScore возвращает sql.ErrNoRows, если оценок ещё нет
+	GetLatestScore(ctx context.Context, userID int64) (*models.CreditScore, error)
+	ListScores(ctx context.Context, userID int64, limit int) ([]models.CreditScore, error)
+	// ListApplicationScores — последняя оценка по каждой из заявок
+	ListApplicationScores(ctx context.Context, appIDs ...int64) ([]models.CreditScore, error)
+}
+
+type scoringRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewScoringRepository(db *sql.DB) ScoringRepository {
+	return &scoringRepositoryImpl{db: db}
+}
+
+func (r *scoringRepositoryImpl) LoadInputs(ctx context.Context, userID int64, since, now time.Time) (*models.ScoringInputs, error) {
+	c := conn(ctx, r.db)
+	in := &models.ScoringInputs{}
+
+	// Поступления — положительные транзакции, списания — отрицательные;
+	// зачисления кредитов и переводы между своими счетами не считаются ни доходом, ни расходом
+	const turnoverQ = `
+		SELECT COALESCE(SUM(amount) FILTER (WHERE amount > 0), 0),
+		       COALESCE(-SUM(amount) FILTER (WHERE amount < 0), 0),
+		       COUNT(DISTINCT date_trunc('month', occurred_at)) FILTER (WHERE amount > 0)
+		FROM transactions
+		WHERE user_id = $1 AND occurred_at >= $2 AND occurred_at <= $3
+		  AND category NOT IN ('loan_disbursement', 'transfer')
+	`
+	if err := c.QueryRowContext(ctx, turnoverQ, userID, since, now).Scan(&in.Income, &in.Expenses, &in.IncomeMonths); err != nil {
+		return nil, err
+	}
+
+	const balanceQ = `SELECT COALESCE(SUM(balance), 0) FROM user_bank_accounts WHERE user_id = $1`
+	if err := c.QueryRowContext(ctx, balanceQ, userID).Scan(&in.Balance); err != nil {
+		return nil, err
+	}
+
+	const debtQ = `
+		SELECT COALESCE(SUM(ls.remaining_principal + ls.accrued_interest + ls.penalty_interest), 0),
+		       COALESCE(MAX(ls.days_past_due), 0),
+		       COUNT(DISTINCT l.loan_id) FILTER (WHERE l.status = 'DEFAULTED')
+		FROM loans l
+		JOIN loan_splits ls ON ls.loan_id = l.loan_id
+		WHERE l.user_id = $1 AND l.status IN ('ACTIVE', 'OVERDUE', 'DEFAULTED')
+	`
+	if err := c.QueryRowContext(ctx, debtQ, userID).Scan(&in.Debt, &in.MaxDaysPastDue, &in.DefaultedLoans); err != nil {
+		return nil, err
+	}
+
+	// Плановый платёж считается оплаченным к сроку, если к концу дня платежа
+	// сумма платежей по кредиту покрыла сумму всех наступивших платежей графика
+	const punctualityQ = `
+		WITH due AS (
+		  SELECT l.loan_id, s.due_date, SUM(s.total_payment) AS amount
+		  FROM loans l
+		  JOIN loan_splits ls ON ls.loan_id = l.loan_id
+		  JOIN loan_schedule_items s ON s.split_id = ls.split_id
+		  WHERE l.user_id = $1 AND s.due_date < $2::date
+		  GROUP BY l.loan_id, s.due_date
+		), cum AS (
+		  SELECT loan_id, due_date, SUM(amount) OVER (PARTITION BY loan_id ORDER BY due_date) AS scheduled
+		  FROM due
+		)
+		SELECT COUNT(*),
+		       COUNT(*) FILTER (WHERE (
+		         SELECT COALESCE(SUM(p.total_amount), 0) FROM loan_payments p
+		         WHERE p.loan_id = cum.loan_id AND p.paid_at < cum.due_date + 1
+		       ) >= cum.scheduled - 1)
+		FROM cum
+	`
+	if err := c.QueryRowContext(ctx, punctualityQ, userID, now).Scan(&in.DueInstallments, &in.OnTimeInstallments); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+func (r *scoringRepositoryImpl) CreateScore(ctx context.Context, s *models.CreditScore) error {
+	factors, err := json.Marshal(s.Factors)
+	if err != nil {
+		return err
+	}
+	inputs, err := json.Marshal(s.Inputs)
+	if err != nil {
+		return err
+	}
+	const q = `
+		INSERT INTO credit_scores (user_id, application_id, score, grade, model_version, factors, inputs)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING score_id, computed_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		s.UserID, s.ApplicationID, s.Score, s.Grade, s.ModelVersion, factors, inputs,
+	).Scan(&s.ScoreID, &s.ComputedAt)
+}
+
+const scoreColumns = `score_id, user_id, application_id, score, grade, model_version, factors, inputs, computed_at`
+
+func scanScore(row interface{ Scan(...any) error }) (*models.CreditScore, error) {
+	s := &models.CreditScore{}
+	var factors, inputs []byte
+	if err := row.Scan(&s.ScoreID, &s.UserID, &s.ApplicationID, &s.Score, &s.Grade, &s.ModelVersion, &factors, &inputs, &s.ComputedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(factors, &s.Factors); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(inputs, &s.Inputs); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *scoringRepositoryImpl) GetLatestScore(ctx context.Context, userID int64) (*models.CreditScore, error) {
+	q := `SELECT ` + scoreColumns + ` FROM credit_scores WHERE user_id = $1 ORDER BY computed_at DESC, score_id DESC LIMIT 1`
+	return scanScore(conn(ctx, r.db).QueryRowContext(ctx, q, userID))
+}
+
+func (r *scoringRepositoryImpl) ListScores(ctx context.Context, userID int64, limit int) ([]models.CreditScore, error) {
+	q := `SELECT ` + scoreColumns + ` FROM credit_scores WHERE user_id = $1 ORDER BY computed_at DESC, score_id DESC LIMIT $2`
+	return r.listScores(ctx, q, userID, limit)
+}
+
+func (r *scoringRepositoryImpl) ListApplicationScores(ctx context.Context, appIDs ...int64) ([]models.CreditScore, error) {
+	q := `
+		SELECT DISTINCT ON (application_id) ` + scoreColumns + `
+		FROM credit_scores
+		WHERE application_id = ANY($1)
+		ORDER BY application_id, computed_at DESC, score_id DESC
+	`
+	return r.listScores(ctx, q, pq.Array(appIDs))
+}
+
+func (r *scoringRepositoryImpl) listScores(ctx context.Context, q string, args ...any) ([]models.CreditScore, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.CreditScore{}
+	for rows.Next() {
+		s, err := scanScore(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *s)
+	}
+	return res, rows.Err()
+}
@@ -0,0 +1,55 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type StatusHistoryRepository interface {
+	CreateEntry(ctx context.Context, entry *models.StatusHistoryEntry) error
+	ListEntries(ctx context.Context, entityType string, entityID int64) ([]models.StatusHistoryEntry, error)
+}
+
+type statusHistoryRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewStatusHistoryRepository(db *sql.DB) StatusHistoryRepository {
+	return &statusHistoryRepositoryImpl{db: db}
+}
+
+func (r *statusHistoryRepositoryImpl) CreateEntry(ctx context.Context, e *models.StatusHistoryEntry) error {
+	const q = `
+		INSERT INTO status_history (entity_type, entity_id, from_status, to_status, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING history_id, changed_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		e.EntityType, e.EntityID, e.FromStatus, e.ToStatus, e.ChangedBy, e.Reason,
+	).Scan(&e.HistoryID, &e.ChangedAt)
+}
+
+func (r *statusHistoryRepositoryImpl) ListEntries(ctx context.Context, entityType string, entityID int64) ([]models.StatusHistoryEntry, error) {
+	const q = `
+		SELECT history_id, entity_type, entity_id, from_status, to_status, changed_by, reason, changed_at
+		FROM status_history WHERE entity_type = $1 AND entity_id = $2
+		ORDER BY changed_at, history_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.StatusHistoryEntry
+	for rows.Next() {
+		var e models.StatusHistoryEntry
+		if err := rows.Scan(&e.HistoryID, &e.EntityType, &e.EntityID, &e.FromStatus, &e.ToStatus, &e.ChangedBy, &e.Reason, &e.ChangedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
@@ -3,6 +3,9 @@ package repos
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
+
 	"github.com/Arlandaren/easyfund/internal/models"
 )
 
@@ -12,6 +15,10 @@ type TransactionRepository interface {
 	ListUserTransactions(ctx context.Context, userID int64) ([]models.Transaction, error)
 	ListBankTransactions(ctx context.Context, userID int64, bankID int16) ([]models.Transaction, error)
 	GetUserTotalSpent(ctx context.Context, userID int64) (string, error)
+	// ImportTransaction сохраняет операцию из выписки; false — операция с тем же external_id уже есть
+	ImportTransaction(ctx context.Context, t *models.Transaction) (bool, error)
+	// ListBankTransactionsBetween — операции пользователя в банке с from по to (не включая)
+	ListBankTransactionsBetween(ctx context.Context, userID int64, bankID int16, from, to time.Time) ([]models.Transaction, error)
 }
 
 type transactionRepositoryImpl struct {
@@ -24,24 +31,24 @@ func NewTransactionRepository(db *sql.DB) TransactionRepository {
 
 func (r *transactionRepositoryImpl) CreateTransaction(ctx context.Context, t *models.Transaction) error {
 	const q = `
-		INSERT INTO transactions (user_id, bank_id, occurred_at, amount, category, description)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO transactions (user_id, bank_id, occurred_at, amount, category, description, external_id, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING transaction_id
 	`
-	err := r.db.QueryRowContext(ctx, q, 
-		t.UserID, t.BankID, t.OccurredAt, t.Amount, t.Category, t.Description,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		t.UserID, t.BankID, t.OccurredAt, t.Amount, t.Category, t.Description, t.ExternalID, transactionSource(t),
 	).Scan(&t.TransactionID)
 	return err
 }
 
 func (r *transactionRepositoryImpl) GetTransactionByID(ctx context.Context, id int64) (*models.Transaction, error) {
 	const q = `
-		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description
+		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description, external_id, source
 		FROM transactions WHERE transaction_id = $1
 	`
 	t := &models.Transaction{}
-	err := r.db.QueryRowContext(ctx, q, id).Scan(
-		&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description,
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, id).Scan(
+		&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description, &t.ExternalID, &t.Source,
 	)
 	if err != nil {
 		return nil, err
@@ -51,11 +58,11 @@ func (r *transactionRepositoryImpl) GetTransactionByID(ctx context.Context, id i
 
 func (r *transactionRepositoryImpl) ListUserTransactions(ctx context.Context, userID int64) ([]models.Transaction, error) {
 	const q = `
-		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description
+		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description, external_id, source
 		FROM transactions WHERE user_id = $1
 		ORDER BY occurred_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +71,7 @@ func (r *transactionRepositoryImpl) ListUserTransactions(ctx context.Context, us
 	var res []models.Transaction
 	for rows.Next() {
 		var t models.Transaction
-		if err := rows.Scan(&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description); err != nil {
+		if err := rows.Scan(&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description, &t.ExternalID, &t.Source); err != nil {
 			return nil, err
 		}
 		res = append(res, t)
@@ -74,11 +81,11 @@ func (r *transactionRepositoryImpl) ListUserTransactions(ctx context.Context, us
 
 func (r *transactionRepositoryImpl) ListBankTransactions(ctx context.Context, userID int64, bankID int16) ([]models.Transaction, error) {
 	const q = `
-		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description
+		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, description, external_id, source
 		FROM transactions WHERE user_id = $1 AND bank_id = $2
 		ORDER BY occurred_at DESC
 	`
-	rows, err := r.db.QueryContext(ctx, q, userID, bankID)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID, bankID)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +94,7 @@ func (r *transactionRepositoryImpl) ListBankTransactions(ctx context.Context, us
 	var res []models.Transaction
 	for rows.Next() {
 		var t models.Transaction
-		if err := rows.Scan(&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description); err != nil {
+		if err := rows.Scan(&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description, &t.ExternalID, &t.Source); err != nil {
 			return nil, err
 		}
 		res = append(res, t)
@@ -98,6 +105,57 @@ func (r *transactionRepositoryImpl) ListBankTransactions(ctx context.Context, us
 func (r *transactionRepositoryImpl) GetUserTotalSpent(ctx context.Context, userID int64) (string, error) {
 	const q = `SELECT COALESCE(TO_CHAR(SUM(amount), 'FM9999999999990.00'), '0.00') FROM transactions WHERE user_id = $1`
 	var total string
-	err := r.db.QueryRowContext(ctx, q, userID).Scan(&total)
+	err := conn(ctx, r.db).QueryRowContext(ctx, q, userID).Scan(&total)
 	return total, err
-}
\ No newline at end of file
+}
+
+func (r *transactionRepositoryImpl) ImportTransaction(ctx context.Context, t *models.Transaction) (bool, error) {
+	const q = `
+		INSERT INTO transactions (user_id, bank_id, occurred_at, amount, category, description, external_id, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id, bank_id, external_id) WHERE external_id IS NOT NULL DO NOTHING
+		RETURNING transaction_id
+	`
+	err := conn(ctx, r.db).QueryRowContext(ctx, q,
+		t.UserID, t.BankID, t.OccurredAt, t.Amount, t.Category, t.Description, t.ExternalID, transactionSource(t),
+	).Scan(&t.TransactionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *transactionRepositoryImpl) ListBankTransactionsBetween(ctx context.Context, userID int64, bankID int16, from, to time.Time) ([]models.Transaction, error) {
+	const q = `
+		SELECT transaction_id, user_id, bank_id, occurred_at, amount, category, COALESCE(description, ''), external_id, source
+		FROM transactions
+		WHERE user_id = $1 AND bank_id = $2 AND occurred_at >= $3 AND occurred_at < $4
+		ORDER BY occurred_at, transaction_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, userID, bankID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []models.Transaction
+	for rows.Next() {
+		var t models.Transaction
+		if err := rows.Scan(&t.TransactionID, &t.UserID, &t.BankID, &t.OccurredAt, &t.Amount, &t.Category, &t.Description, &t.ExternalID, &t.Source); err != nil {
+			return nil, err
+		}
+		res = append(res, t)
+	}
+	return res, rows.Err()
+}
+
+// transactionSource — источник операции; не заданный — платформа
+func transactionSource(t *models.Transaction) string {
+	if t.Source == "" {
+		t.Source = models.TransactionSourcePlatform
+	}
+	return t.Source
+}
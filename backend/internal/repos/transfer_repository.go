@@ -0,0 +1,163 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+type TransferRepository interface {
+	CreateTransfer(ctx context.Context, t *models.Transfer) error
+	GetTransfer(ctx context.Context, transferID int64) (*models.Transfer, error)
+	// GetTransferForUpdate блокирует перевод до конца транзакции
+	GetTransferForUpdate(ctx context.Context, transferID int64) (*models.Transfer, error)
+	ListUserTransfers(ctx context.Context, userID int64) ([]models.Transfer, error)
+	// UpdateTransferRun сохраняет статус и расписание после запуска или отмены
+	UpdateTransferRun(ctx context.Context, t *models.Transfer) error
+	// ListDueTransferIDs — переводы в статусе SCHEDULED, чей следующий запуск не позже now
+	ListDueTransferIDs(ctx context.Context, now time.Time) ([]int64, error)
+
+	CreateExecution(ctx context.Context, e *models.TransferExecution) error
+	ListExecutions(ctx context.Context, transferID int64) ([]models.TransferExecution, error)
+}
+
+type transferRepositoryImpl struct {
+	db *sql.DB
+}
+
+func NewTransferRepository(db *sql.DB) TransferRepository {
+	return &transferRepositoryImpl{db: db}
+}
+
+const transferSelect = `
+	SELECT transfer_id, user_id, from_account_id, to_account_id, amount, currency, description, status, recurrence,
+	       starts_at, ends_at, next_run_at, runs, last_error, created_at, updated_at
+	FROM transfers
+`
+
+func scanTransfer(row interface{ Scan(...any) error }) (*models.Transfer, error) {
+	t := &models.Transfer{}
+	err := row.Scan(
+		&t.TransferID, &t.UserID, &t.FromAccountID, &t.ToAccountID, &t.Amount, &t.Currency, &t.Description, &t.Status, &t.Recurrence,
+		&t.StartsAt, &t.EndsAt, &t.NextRunAt, &t.Runs, &t.LastError, &t.CreatedAt, &t.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *transferRepositoryImpl) CreateTransfer(ctx context.Context, t *models.Transfer) error {
+	const q = `
+		INSERT INTO transfers (user_id, from_account_id, to_account_id, amount, currency, description, status, recurrence,
+		                       starts_at, ends_at, next_run_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING transfer_id, runs, created_at, updated_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		t.UserID, t.FromAccountID, t.ToAccountID, t.Amount, t.Currency, t.Description, t.Status, t.Recurrence,
+		t.StartsAt, t.EndsAt, t.NextRunAt,
+	).Scan(&t.TransferID, &t.Runs, &t.CreatedAt, &t.UpdatedAt)
+}
+
+func (r *transferRepositoryImpl) GetTransfer(ctx context.Context, transferID int64) (*models.Transfer, error) {
+	return scanTransfer(conn(ctx, r.db).QueryRowContext(ctx, transferSelect+` WHERE transfer_id = $1`, transferID))
+}
+
+func (r *transferRepositoryImpl) GetTransferForUpdate(ctx context.Context, transferID int64) (*models.Transfer, error) {
+	return scanTransfer(conn(ctx, r.db).QueryRowContext(ctx, transferSelect+` WHERE transfer_id = $1 FOR UPDATE`, transferID))
+}
+
+func (r *transferRepositoryImpl) ListUserTransfers(ctx context.Context, userID int64) ([]models.Transfer, error) {
+	rows, err := conn(ctx, r.db).QueryContext(ctx, transferSelect+` WHERE user_id = $1 ORDER BY created_at DESC, transfer_id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.Transfer{}
+	for rows.Next() {
+		t, err := scanTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *t)
+	}
+	return res, rows.Err()
+}
+
+func (r *transferRepositoryImpl) UpdateTransferRun(ctx context.Context, t *models.Transfer) error {
+	const q = `
+		UPDATE transfers
+		SET status = $2, next_run_at = $3, runs = $4, last_error = $5, updated_at = now()
+		WHERE transfer_id = $1
+		RETURNING updated_at
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q, t.TransferID, t.Status, t.NextRunAt, t.Runs, t.LastError).Scan(&t.UpdatedAt)
+}
+
+func (r *transferRepositoryImpl) ListDueTransferIDs(ctx context.Context, now time.Time) ([]int64, error) {
+	const q = `
+		SELECT transfer_id FROM transfers
+		WHERE status = 'SCHEDULED' AND next_run_at <= $1
+		ORDER BY next_run_at, transfer_id
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func (r *transferRepositoryImpl) CreateExecution(ctx context.Context, e *models.TransferExecution) error {
+	const q = `
+		INSERT INTO transfer_executions (transfer_id, status, amount, scheduled_for, executed_at,
+		                                 debit_transaction_id, credit_transaction_id, ledger_entry_id, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING execution_id
+	`
+	return conn(ctx, r.db).QueryRowContext(ctx, q,
+		e.TransferID, e.Status, e.Amount, e.ScheduledFor, e.ExecutedAt,
+		e.DebitTransactionID, e.CreditTransactionID, e.LedgerEntryID, e.Error,
+	).Scan(&e.ExecutionID)
+}
+
+func (r *transferRepositoryImpl) ListExecutions(ctx context.Context, transferID int64) ([]models.TransferExecution, error) {
+	const q = `
+		SELECT execution_id, transfer_id, status, amount, scheduled_for, executed_at,
+		       debit_transaction_id, credit_transaction_id, ledger_entry_id, error
+		FROM transfer_executions
+		WHERE transfer_id = $1
+		ORDER BY scheduled_for
+	`
+	rows, err := conn(ctx, r.db).QueryContext(ctx, q, transferID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	res := []models.TransferExecution{}
+	for rows.Next() {
+		var e models.TransferExecution
+		if err := rows.Scan(
+			&e.ExecutionID, &e.TransferID, &e.Status, &e.Amount, &e.ScheduledFor, &e.ExecutedAt,
+			&e.DebitTransactionID, &e.CreditTransactionID, &e.LedgerEntryID, &e.Error,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, e)
+	}
+	return res, rows.Err()
+}
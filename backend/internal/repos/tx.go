@@ -0,0 +1,95 @@
+package repos
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+)
+
+// DBTX — общее подмножество *sql.DB и *sql.Tx, которым пользуются репозитории
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type txKey struct{}
+
+// TxManager выполняет функцию в одной транзакции БД.
+// Транзакция передаётся через контекст, поэтому репозитории не меняют сигнатуры:
+// любой вызов с этим ctx внутри fn попадает в ту же транзакцию.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+	// WithinSavepoint — как WithinTx, но внутри открытой транзакции ошибка fn откатывает
+	// только изменения fn (до точки сохранения), и транзакцию можно продолжать
+	WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManagerImpl struct {
+	db        *sql.DB
+	savepoint atomic.Int64
+}
+
+func NewTxManager(db *sql.DB) TxManager {
+	return &txManagerImpl{db: db}
+}
+
+func (m *txManagerImpl) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Вложенный вызов — присоединяемся к уже открытой транзакции
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (m *txManagerImpl) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	if !ok {
+		return m.WithinTx(ctx, fn)
+	}
+
+	name := fmt.Sprintf("sp_%d", m.savepoint.Add(1))
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if err := fn(ctx); err != nil {
+		if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil {
+			return fmt.Errorf("%w (rollback to savepoint failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// conn возвращает транзакцию из контекста, если она открыта, иначе пул соединений
+func conn(ctx context.Context, db *sql.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
@@ -30,22 +30,22 @@ func (r *userRepositoryImpl) CreateUser(ctx context.Context, user *models.User)
 	const q = `
 		INSERT INTO users (full_name, email, phone, password_hash, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING user_id
+		RETURNING user_id, role
 	`
 	err := r.db.QueryRowContext(ctx, q,
 		user.FullName, user.Email, user.Phone, user.PasswordHash, user.CreatedAt, user.UpdatedAt,
-	).Scan(&user.UserID)
+	).Scan(&user.UserID, &user.Role)
 	return err
 }
 
 func (r *userRepositoryImpl) GetUserByID(ctx context.Context, userID int64) (*models.User, error) {
 	const q = `
-		SELECT user_id, full_name, email, phone, password_hash, created_at, updated_at
+		SELECT user_id, full_name, email, phone, password_hash, role, bank_id, created_at, updated_at
 		FROM users WHERE user_id = $1
 	`
 	u := &models.User{}
 	err := r.db.QueryRowContext(ctx, q, userID).Scan(
-		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.Role, &u.BankID, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -55,12 +55,12 @@ func (r *userRepositoryImpl) GetUserByID(ctx context.Context, userID int64) (*mo
 
 func (r *userRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	const q = `
-		SELECT user_id, full_name, email, phone, password_hash, created_at, updated_at
+		SELECT user_id, full_name, email, phone, password_hash, role, bank_id, created_at, updated_at
 		FROM users WHERE email = $1
 	`
 	u := &models.User{}
 	err := r.db.QueryRowContext(ctx, q, email).Scan(
-		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.Role, &u.BankID, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -70,12 +70,12 @@ func (r *userRepositoryImpl) GetUserByEmail(ctx context.Context, email string) (
 
 func (r *userRepositoryImpl) GetRandomUser(ctx context.Context) (*models.User, error) {
 	const q = `
-		SELECT user_id, full_name, email, phone, password_hash, created_at, updated_at
+		SELECT user_id, full_name, email, phone, password_hash, role, bank_id, created_at, updated_at
 		FROM users ORDER BY RANDOM() LIMIT 1
 	`
 	u := &models.User{}
 	err := r.db.QueryRowContext(ctx, q).Scan(
-		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+		&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.Role, &u.BankID, &u.CreatedAt, &u.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -85,7 +85,7 @@ func (r *userRepositoryImpl) GetRandomUser(ctx context.Context) (*models.User, e
 
 func (r *userRepositoryImpl) ListUsers(ctx context.Context, limit int) ([]models.User, error) {
 	const q = `
-		SELECT user_id, full_name, email, phone, password_hash, created_at, updated_at
+		SELECT user_id, full_name, email, phone, password_hash, role, bank_id, created_at, updated_at
 		FROM users LIMIT $1
 	`
 	rows, err := r.db.QueryContext(ctx, q, limit)
@@ -98,7 +98,7 @@ func (r *userRepositoryImpl) ListUsers(ctx context.Context, limit int) ([]models
 	for rows.Next() {
 		var u models.User
 		if err := rows.Scan(
-			&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.CreatedAt, &u.UpdatedAt,
+			&u.UserID, &u.FullName, &u.Email, &u.Phone, &u.PasswordHash, &u.Role, &u.BankID, &u.CreatedAt, &u.UpdatedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -126,4 +126,4 @@ func (r *userRepositoryImpl) DeleteUser(ctx context.Context, userID int64) error
 	const q = `DELETE FROM users WHERE user_id = $1`
 	_, err := r.db.ExecContext(ctx, q, userID)
 	return err
-}
\ No newline at end of file
+}
@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
 	"github.com/Arlandaren/easyfund/internal/models"
 	"github.com/Arlandaren/easyfund/internal/repos"
 )
@@ -10,15 +13,18 @@ import (
 type UserBankAccountService interface {
 	GetTotalBalance(ctx context.Context, userID int64) (string, error)
 	GetUserAccounts(ctx context.Context, userID int64) ([]models.UserBankAccount, error)
+	// CreateAccount открывает счёт; ненулевой account.Balance проводится через главную книгу как входящий остаток
 	CreateAccount(ctx context.Context, account *models.UserBankAccount) error
 }
 
 type userBankAccountServiceImpl struct {
-	repo repos.UserBankAccountRepository
+	repo      repos.UserBankAccountRepository
+	ledger    LedgerService
+	txManager repos.TxManager
 }
 
-func NewUserBankAccountService(repo repos.UserBankAccountRepository) UserBankAccountService {
-	return &userBankAccountServiceImpl{repo: repo}
+func NewUserBankAccountService(repo repos.UserBankAccountRepository, ledger LedgerService, txManager repos.TxManager) UserBankAccountService {
+	return &userBankAccountServiceImpl{repo: repo, ledger: ledger, txManager: txManager}
 }
 
 func (s *userBankAccountServiceImpl) GetTotalBalance(ctx context.Context, userID int64) (string, error) {
@@ -30,5 +36,30 @@ func (s *userBankAccountServiceImpl) GetUserAccounts(ctx context.Context, userID
 }
 
 func (s *userBankAccountServiceImpl) CreateAccount(ctx context.Context, account *models.UserBankAccount) error {
-	return s.repo.CreateAccount(ctx, account)
-}
\ No newline at end of file
+	opening, err := parseOptionalMoney(account.Balance)
+	if err != nil || opening < 0 {
+		return fmt.Errorf("%w: invalid opening balance %q", apperrors.ErrBadRequest, account.Balance)
+	}
+	if account.Currency == "" {
+		account.Currency = models.CurrencyRUB
+	}
+	if account.CreatedAt.IsZero() {
+		account.CreatedAt = time.Now()
+	}
+
+	// Остаток счёта выводится из проводок: счёт создаётся пустым
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		account.Balance = formatMoney(0)
+		if err := s.repo.CreateAccount(ctx, account); err != nil {
+			return fmt.Errorf("failed to create account: %w", err)
+		}
+		if opening == 0 {
+			return nil
+		}
+		if err := s.ledger.RecordOpeningBalance(ctx, account, formatMoney(opening)); err != nil {
+			return err
+		}
+		account.Balance = formatMoney(opening)
+		return nil
+	})
+}
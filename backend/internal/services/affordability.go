@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// affordabilityInputs — данные проверки долговой нагрузки в копейках, пороги — в процентах
+type affordabilityInputs struct {
+	income, existing, newPayment int64
+	flagPDN, rejectPDN           float64
+}
+
+// assessAffordability считает ПДН = (существующие платежи + новый платёж) / доход
+// и решает, принять заявку, пометить её или отклонить. Без подтверждённого дохода
+// ПДН не считается, заявка помечается для ручной проверки
+func assessAffordability(in affordabilityInputs) (string, *float64, []string) {
+	total := in.existing + in.newPayment
+	if in.income <= 0 {
+		return models.AffordabilityFlagged, nil, []string{
+			"monthly income could not be confirmed from account transactions",
+			fmt.Sprintf("monthly payments including the new loan would be %s", formatMoney(total)),
+		}
+	}
+
+	pdn := float64(total) / float64(in.income) * 100
+	burden := fmt.Sprintf("monthly payments of %s (existing %s + new %s) against monthly income of %s",
+		formatMoney(total), formatMoney(in.existing), formatMoney(in.newPayment), formatMoney(in.income))
+	switch {
+	case pdn > in.rejectPDN:
+		return models.AffordabilityRejected, &pdn, []string{
+			fmt.Sprintf("debt-to-income ratio %.2f%% exceeds the limit of %.2f%%", pdn, in.rejectPDN),
+			burden,
+		}
+	case pdn > in.flagPDN:
+		return models.AffordabilityFlagged, &pdn, []string{
+			fmt.Sprintf("debt-to-income ratio %.2f%% is above the review threshold of %.2f%%", pdn, in.flagPDN),
+			burden,
+		}
+	default:
+		return models.AffordabilityOK, &pdn, []string{}
+	}
+}
+
+// AffordabilityError — заявка отклонена проверкой долговой нагрузки;
+// Assessment объясняет клиенту расчёт. Сводится к ErrBadRequest
+type AffordabilityError struct {
+	Assessment *models.AffordabilityAssessment
+}
+
+func (e *AffordabilityError) Error() string {
+	return "application rejected by affordability check: " + strings.Join(e.Assessment.Reasons, "; ")
+}
+
+func (e *AffordabilityError) Unwrap() error {
+	return apperrors.ErrBadRequest
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+type AffordabilityService interface {
+	// AssessApplication проверяет долговую нагрузку по заявке app и сохраняет оценку.
+	// Если ПДН выше порога отказа, возвращает *AffordabilityError и ничего не сохраняет
+	AssessApplication(ctx context.Context, app *models.CreditApplication) (*models.AffordabilityAssessment, error)
+	// ApplicationAssessments — оценки заявок: application_id → оценка
+	ApplicationAssessments(ctx context.Context, appIDs ...int64) (map[int64]*models.AffordabilityAssessment, error)
+}
+
+type affordabilityServiceImpl struct {
+	repo         repos.AffordabilityRepository
+	distribution DistributionService
+	cfg          config.AffordabilityConfig
+}
+
+func NewAffordabilityService(repo repos.AffordabilityRepository, distribution DistributionService, cfg config.AffordabilityConfig) AffordabilityService {
+	return &affordabilityServiceImpl{
+		repo:         repo,
+		distribution: distribution,
+		cfg:          cfg,
+	}
+}
+
+func (s *affordabilityServiceImpl) AssessApplication(ctx context.Context, app *models.CreditApplication) (*models.AffordabilityAssessment, error) {
+	flagPDN, err := parseRate(s.cfg.FlagPDN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid affordability flag threshold: %w", err)
+	}
+	rejectPDN, err := parseRate(s.cfg.RejectPDN)
+	if err != nil {
+		return nil, fmt.Errorf("invalid affordability reject threshold: %w", err)
+	}
+	months := s.cfg.IncomeMonths
+	if months <= 0 {
+		months = 6
+	}
+
+	now := time.Now()
+	income, err := s.repo.LoadIncome(ctx, app.UserID, now.AddDate(0, -months, 0), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load income: %w", err)
+	}
+	incomeK, err := parseMoney(income)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.LoadMonthlyPayments(ctx, app.UserID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load monthly payments: %w", err)
+	}
+	existingK, err := parseMoney(existing)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := s.assumedRate(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseMoney(app.RequestedAmount)
+	if err != nil {
+		return nil, err
+	}
+	newPayment := annuityPayment(amount, rate/12/100, app.TermMonths)
+
+	in := affordabilityInputs{
+		income:     incomeK / int64(months),
+		existing:   existingK,
+		newPayment: newPayment,
+		flagPDN:    flagPDN,
+		rejectPDN:  rejectPDN,
+	}
+	status, pdn, reasons := assessAffordability(in)
+
+	res := &models.AffordabilityAssessment{
+		ApplicationID:    app.ApplicationID,
+		Status:           status,
+		MonthlyIncome:    formatMoney(in.income),
+		ExistingPayments: formatMoney(in.existing),
+		NewPayment:       formatMoney(in.newPayment),
+		AssumedRate:      formatRate(rate),
+		TermMonths:       app.TermMonths,
+		FlagThreshold:    formatRate(flagPDN),
+		RejectThreshold:  formatRate(rejectPDN),
+		Reasons:          reasons,
+		CheckedAt:        now,
+	}
+	if pdn != nil {
+		v := formatRate(*pdn)
+		res.PDN = &v
+	}
+	if status == models.AffordabilityRejected {
+		return nil, &AffordabilityError{Assessment: res}
+	}
+	if err := s.repo.CreateAssessment(ctx, res); err != nil {
+		return nil, fmt.Errorf("failed to save affordability assessment: %w", err)
+	}
+	return res, nil
+}
+
+// assumedRate — ставка, по которой оценивается платёж: средневзвешенная ставка
+// автораспределения, а если банки не могут её котировать — ставка из конфигурации
+func (s *affordabilityServiceImpl) assumedRate(ctx context.Context, app *models.CreditApplication) (float64, error) {
+	proposal, err := s.distribution.ProposeSplits(ctx, app.RequestedAmount, app.TypeCode)
+	switch {
+	case err == nil:
+		return parseRate(proposal.InterestRate)
+	case !errors.Is(err, apperrors.ErrBadRequest):
+		return 0, err
+	}
+	rate, err := parseRate(s.cfg.AssumedRate)
+	if err != nil {
+		return 0, fmt.Errorf("invalid affordability assumed rate: %w", err)
+	}
+	return rate, nil
+}
+
+func (s *affordabilityServiceImpl) ApplicationAssessments(ctx context.Context, appIDs ...int64) (map[int64]*models.AffordabilityAssessment, error) {
+	items, err := s.repo.ListApplicationAssessments(ctx, appIDs...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]*models.AffordabilityAssessment, len(items))
+	for i := range items {
+		res[items[i].ApplicationID] = &items[i]
+	}
+	return res, nil
+}
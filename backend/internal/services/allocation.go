@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+const (
+	AllocationProRata          = "PRO_RATA"
+	AllocationHighestRateFirst = "HIGHEST_RATE_FIRST"
+	AllocationBankPriority     = "BANK_PRIORITY"
+)
+
+func isValidAllocationStrategy(s string) bool {
+	switch s {
+	case AllocationProRata, AllocationHighestRateFirst, AllocationBankPriority:
+		return true
+	}
+	return false
+}
+
+// splitDue — задолженность по доле на момент платежа (в копейках)
+type splitDue struct {
+	Split     models.LoanSplit
+	Rate      float64
+	Principal int64
+	Interest  int64
+	Penalty   int64
+	Fee       int64 // плата за обслуживание к уплате
+	Priority  int16
+}
+
+func (d splitDue) total() int64 {
+	return d.Principal + d.Interest + d.Penalty + d.Fee
+}
+
+// allocatePayment раскладывает amount по долям согласно стратегии.
+// Возвращает сумму для каждой доли в порядке dues; ни одна часть не превышает задолженность доли.
+func allocatePayment(amount int64, dues []splitDue, strategy string) ([]int64, error) {
+	var outstanding int64
+	for _, d := range dues {
+		outstanding += d.total()
+	}
+	if amount > outstanding {
+		return nil, fmt.Errorf("payment %s exceeds outstanding debt %s", formatMoney(amount), formatMoney(outstanding))
+	}
+
+	switch strategy {
+	case AllocationProRata:
+		return allocateProRata(amount, dues), nil
+	case AllocationHighestRateFirst:
+		order := sortedIndexes(dues, func(a, b splitDue) bool { return a.Rate > b.Rate })
+		return allocateSequential(amount, dues, order), nil
+	case AllocationBankPriority:
+		order := sortedIndexes(dues, func(a, b splitDue) bool { return a.Priority < b.Priority })
+		return allocateSequential(amount, dues, order), nil
+	default:
+		return nil, fmt.Errorf("unknown allocation strategy %q", strategy)
+	}
+}
+
+// allocateScheduled отдаёт каждой доле её плановый платёж к уплате scheduled[split_id], но не больше задолженности доли;
+// остаток суммы, если он есть, гасит доли по порядку. Возвращает сумму для каждой доли в порядке dues
+func allocateScheduled(amount int64, dues []splitDue, scheduled map[int64]int64) ([]int64, error) {
+	parts := make([]int64, len(dues))
+	for i, d := range dues {
+		parts[i] = min(scheduled[d.Split.SplitID], d.total(), amount)
+		amount -= parts[i]
+	}
+	for i, d := range dues {
+		if amount == 0 {
+			break
+		}
+		extra := min(amount, d.total()-parts[i])
+		parts[i] += extra
+		amount -= extra
+	}
+	if amount > 0 {
+		return nil, fmt.Errorf("payment exceeds outstanding debt by %s", formatMoney(amount))
+	}
+	return parts, nil
+}
+
+// sortedIndexes — индексы dues, упорядоченные по less (при равенстве — по split_id)
+func sortedIndexes(dues []splitDue, less func(a, b splitDue) bool) []int {
+	order := make([]int, len(dues))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := dues[order[i]], dues[order[j]]
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return a.Split.SplitID < b.Split.SplitID
+	})
+	return order
+}
+
+// allocateSequential гасит доли по очереди, каждую до нуля
+func allocateSequential(amount int64, dues []splitDue, order []int) []int64 {
+	parts := make([]int64, len(dues))
+	for _, i := range order {
+		if amount == 0 {
+			break
+		}
+		part := min(amount, dues[i].total())
+		parts[i] = part
+		amount -= part
+	}
+	return parts
+}
+
+// allocateProRata делит сумму пропорционально остатку основного долга.
+// Доля, которая погашается полностью, выбывает, а излишек перераспределяется между остальными.
+func allocateProRata(amount int64, dues []splitDue) []int64 {
+	parts := make([]int64, len(dues))
+	for amount > 0 {
+		var open []int
+		var weightSum float64
+		for i, d := range dues {
+			if parts[i] < d.total() {
+				open = append(open, i)
+				weightSum += float64(proRataWeight(d))
+			}
+		}
+		if len(open) == 0 {
+			break
+		}
+
+		var distributed int64
+		for _, i := range open {
+			share := int64(math.Floor(float64(amount) * float64(proRataWeight(dues[i])) / weightSum))
+			share = min(share, dues[i].total()-parts[i])
+			parts[i] += share
+			distributed += share
+		}
+
+		// Копейки от округления отдаём крупнейшим долям по одной
+		if distributed == 0 {
+			order := sortedIndexes(dues, func(a, b splitDue) bool { return proRataWeight(a) > proRataWeight(b) })
+			for _, i := range order {
+				if amount-distributed == 0 {
+					break
+				}
+				if parts[i] < dues[i].total() {
+					parts[i]++
+					distributed++
+				}
+			}
+		}
+		amount -= distributed
+	}
+	return parts
+}
+
+// proRataWeight — вес доли: остаток основного долга, а если он погашен — оставшиеся проценты, пени и плата
+func proRataWeight(d splitDue) int64 {
+	if d.Principal > 0 {
+		return d.Principal
+	}
+	return d.Interest + d.Penalty + d.Fee
+}
@@ -0,0 +1,107 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func TestAllocateScheduled(t *testing.T) {
+	dues := []splitDue{
+		{Split: models.LoanSplit{SplitID: 1}, Principal: 10000, Interest: 500},
+		{Split: models.LoanSplit{SplitID: 2}, Principal: 2000, Interest: 100},
+	}
+	tests := []struct {
+		name      string
+		amount    int64
+		scheduled map[int64]int64
+		want      []int64
+		wantErr   bool
+	}{
+		{"exact scheduled amounts", 4000, map[int64]int64{1: 3000, 2: 1000}, []int64{3000, 1000}, false},
+		{"only one split due", 3000, map[int64]int64{1: 3000}, []int64{3000, 0}, false},
+		{"scheduled above debt is capped", 10600, map[int64]int64{1: 8000, 2: 2600}, []int64{8500, 2100}, false},
+		{"rest goes in split order", 5000, map[int64]int64{2: 1000}, []int64{4000, 1000}, false},
+		{"exceeds outstanding debt", 13000, map[int64]int64{1: 3000}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocateScheduled(tt.amount, dues, tt.scheduled)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("allocateScheduled error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateScheduled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocatePayment(t *testing.T) {
+	dues := []splitDue{
+		{Split: models.LoanSplit{SplitID: 1}, Rate: 12, Principal: 60000, Interest: 1000, Priority: 2},
+		{Split: models.LoanSplit{SplitID: 2}, Rate: 15, Principal: 30000, Interest: 500, Priority: 1},
+		{Split: models.LoanSplit{SplitID: 3}, Rate: 15, Principal: 10000, Priority: 3},
+	}
+	tests := []struct {
+		name     string
+		amount   int64
+		strategy string
+		want     []int64
+		wantErr  bool
+	}{
+		{"pro rata by principal", 10000, AllocationProRata, []int64{6000, 3000, 1000}, false},
+		{"pro rata small amount", 10, AllocationProRata, []int64{6, 3, 1}, false},
+		{"pro rata single kopeck", 1, AllocationProRata, []int64{1, 0, 0}, false},
+		{"pro rata full repayment", 101500, AllocationProRata, []int64{61000, 30500, 10000}, false},
+		{"highest rate first, ties by split", 35000, AllocationHighestRateFirst, []int64{0, 30500, 4500}, false},
+		{"bank priority", 35000, AllocationBankPriority, []int64{4500, 30500, 0}, false},
+		{"zero amount", 0, AllocationProRata, []int64{0, 0, 0}, false},
+		{"exceeds outstanding debt", 101501, AllocationProRata, nil, true},
+		{"unknown strategy", 1000, "NEWEST_FIRST", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := allocatePayment(tt.amount, dues, tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("allocatePayment error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocatePayment = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllocateProRata(t *testing.T) {
+	dues := []splitDue{
+		{Split: models.LoanSplit{SplitID: 1}, Principal: 1000},
+		{Split: models.LoanSplit{SplitID: 2}, Principal: 9000, Interest: 1000},
+		{Split: models.LoanSplit{SplitID: 3}, Interest: 300, Fee: 200},
+	}
+	tests := []struct {
+		name   string
+		amount int64
+		want   []int64
+	}{
+		{"proportional to weights", 5250, []int64{500, 4500, 250}},
+		{"capped splits drop out", 10600, []int64{1000, 9100, 500}},
+		{"full repayment", 11500, []int64{1000, 10000, 500}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateProRata(tt.amount, dues)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("allocateProRata = %v, want %v", got, tt.want)
+			}
+			var sum int64
+			for _, p := range got {
+				sum += p
+			}
+			if sum != tt.amount {
+				t.Errorf("allocated %d of %d", sum, tt.amount)
+			}
+		})
+	}
+}
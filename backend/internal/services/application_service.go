@@ -2,88 +2,764 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
 	"github.com/Arlandaren/easyfund/internal/models"
 	"github.com/Arlandaren/easyfund/internal/repos"
 )
 
 type CreditApplicationService interface {
-	SubmitApplication(ctx context.Context, app *models.CreditApplication) (int64, error)
-	GetApplications(ctx context.Context, userID int64) ([]models.CreditApplication, error)
-	ApproveApplication(ctx context.Context, appID int64, splits []map[int16]string) error
-	RejectApplication(ctx context.Context, appID int64) error
+	// SubmitApplication создаёт заявку и приглашает банки bankIDs; при app.AnyBank —
+	// все активные банки, выдающие этот тип кредита. Заявка в статусе DRAFT сохраняется
+	// черновиком: банки можно не указывать до отправки
+	SubmitApplication(ctx context.Context, app *models.CreditApplication, bankIDs []int16) (*models.ApplicationDetailDTO, error)
+	// UpdateDraft меняет условия и банки черновика; править может только заёмщик app.UserID
+	UpdateDraft(ctx context.Context, app *models.CreditApplication, bankIDs []int16) (*models.ApplicationDetailDTO, error)
+	// SubmitDraft отправляет черновик в банки
+	SubmitDraft(ctx context.Context, appID int64, userID int64) (*models.ApplicationDetailDTO, error)
+	// CancelApplication — отмена заявки заёмщиком из DRAFT или PENDING; действующие предложения истекают
+	CancelApplication(ctx context.Context, appID int64, userID int64, reason string) (*models.ApplicationDetailDTO, error)
+	GetApplication(ctx context.Context, appID int64) (*models.CreditApplication, error)
+	GetApplicationDetail(ctx context.Context, appID int64) (*models.ApplicationDetailDTO, error)
+	GetApplications(ctx context.Context, userID int64) ([]models.ApplicationDetailDTO, error)
+	// ListBankApplications — заявки, адресованные банку; status — статус участия банка или пусто
+	ListBankApplications(ctx context.Context, bankID int16, status string) ([]models.BankApplicationDTO, error)
+	// DeclineParticipation — отказ банка от участия; если отказались все банки, заявка отклоняется
+	DeclineParticipation(ctx context.Context, appID int64, bankID int16, actorID int64, reason string) (*models.ApplicationDetailDTO, error)
+	// ApproveApplication и RejectApplication: actorID — пользователь, принявший решение
+	ApproveApplication(ctx context.Context, appID int64, terms *models.ApprovalTerms, actorID int64) (*models.LoanDetailDTO, error)
+	RejectApplication(ctx context.Context, appID int64, actorID int64) error
+	GetApplicationHistory(ctx context.Context, appID int64) ([]models.StatusHistoryEntry, error)
 }
 
 type creditApplicationServiceImpl struct {
-	appRepo  repos.CreditApplicationRepository
-	loanRepo repos.LoanRepository
+	appRepo         repos.CreditApplicationRepository
+	offerRepo       repos.OfferRepository
+	participantRepo repos.ParticipantRepository
+	bankRepo        repos.BankRepository
+	productRepo     repos.ProductRepository
+	loanService     LoanService
+	distribution    DistributionService
+	scoring         ScoringService
+	affordability   AffordabilityService
+	decisions       DecisionService
+	status          StatusService
+	txManager       repos.TxManager
 }
 
-func NewCreditApplicationService(appRepo repos.CreditApplicationRepository, loanRepo repos.LoanRepository) CreditApplicationService {
+func NewCreditApplicationService(
+	appRepo repos.CreditApplicationRepository,
+	offerRepo repos.OfferRepository,
+	participantRepo repos.ParticipantRepository,
+	bankRepo repos.BankRepository,
+	productRepo repos.ProductRepository,
+	loanService LoanService,
+	distribution DistributionService,
+	scoring ScoringService,
+	affordability AffordabilityService,
+	decisions DecisionService,
+	status StatusService,
+	txManager repos.TxManager,
+) CreditApplicationService {
 	return &creditApplicationServiceImpl{
-		appRepo:  appRepo,
-		loanRepo: loanRepo,
+		appRepo:         appRepo,
+		offerRepo:       offerRepo,
+		participantRepo: participantRepo,
+		bankRepo:        bankRepo,
+		productRepo:     productRepo,
+		loanService:     loanService,
+		distribution:    distribution,
+		scoring:         scoring,
+		affordability:   affordability,
+		decisions:       decisions,
+		status:          status,
+		txManager:       txManager,
 	}
 }
 
-func (s *creditApplicationServiceImpl) SubmitApplication(ctx context.Context, app *models.CreditApplication) (int64, error) {
-	return s.appRepo.CreateApplication(ctx, app)
-}
+func (s *creditApplicationServiceImpl) SubmitApplication(ctx context.Context, app *models.CreditApplication, bankIDs []int16) (*models.ApplicationDetailDTO, error) {
+	product, err := s.prepareApplication(ctx, app)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *creditApplicationServiceImpl) GetApplications(ctx context.Context, userID int64) ([]models.CreditApplication, error) {
-	return s.appRepo.ListUserApplications(ctx, userID)
-}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var banks []int16
+		var err error
+		if app.StatusCode == models.ApplicationStatusDraft {
+			banks, err = s.draftBanks(ctx, app, product, bankIDs)
+		} else {
+			banks, err = s.targetBanks(ctx, app, product, bankIDs)
+		}
+		if err != nil {
+			return err
+		}
+		setApplicationBank(app, banks)
 
-func (s *creditApplicationServiceImpl) ApproveApplication(ctx context.Context, appID int64, splits []map[int16]string) error {
-	app, err := s.appRepo.GetApplicationByID(ctx, appID)
+		app.ApplicationID, err = s.appRepo.CreateApplication(ctx, app)
+		if err != nil {
+			return fmt.Errorf("failed to create application: %w", err)
+		}
+		if err := s.participantRepo.CreateParticipants(ctx, app.ApplicationID, banks); err != nil {
+			return fmt.Errorf("failed to create participants: %w", err)
+		}
+		if err := s.status.RecordApplicationCreated(ctx, app.ApplicationID, app.StatusCode, &app.UserID); err != nil {
+			return err
+		}
+		// Банки получают заявку уже с предскорингом и оценкой ПДН; черновик оценивается при отправке
+		if app.StatusCode == models.ApplicationStatusDraft {
+			return nil
+		}
+		return s.assessSubmitted(ctx, app, banks)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to get application: %w", err)
+		return nil, err
 	}
+	return s.GetApplicationDetail(ctx, app.ApplicationID)
+}
 
-	// Создаём кредит на основе заявки
-	loan := &models.Loan{
-		UserID:        app.UserID,
-		OriginalAmount: app.RequestedAmount,
-		Status:        "ACTIVE",
-		Purpose:       app.TypeCode,
+func (s *creditApplicationServiceImpl) UpdateDraft(ctx context.Context, app *models.CreditApplication, bankIDs []int16) (*models.ApplicationDetailDTO, error) {
+	product, err := s.prepareApplication(ctx, app)
+	if err != nil {
+		return nil, err
 	}
 
-	loanID, err := s.loanRepo.CreateLoan(ctx, loan)
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		current, err := s.lockOwnApplication(ctx, app.ApplicationID, app.UserID)
+		if err != nil {
+			return err
+		}
+		if current.StatusCode != models.ApplicationStatusDraft {
+			return fmt.Errorf("%w: application %d is %s and can no longer be edited", apperrors.ErrConflict, app.ApplicationID, current.StatusCode)
+		}
+
+		banks, err := s.draftBanks(ctx, app, product, bankIDs)
+		if err != nil {
+			return err
+		}
+		setApplicationBank(app, banks)
+
+		ok, err := s.appRepo.UpdateDraft(ctx, app)
+		if err != nil {
+			return fmt.Errorf("failed to update draft: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: application %d status changed concurrently", apperrors.ErrConflict, app.ApplicationID)
+		}
+		if err := s.participantRepo.ReplaceParticipants(ctx, app.ApplicationID, banks); err != nil {
+			return fmt.Errorf("failed to replace participants: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create loan: %w", err)
+		return nil, err
 	}
+	return s.GetApplicationDetail(ctx, app.ApplicationID)
+}
+
+func (s *creditApplicationServiceImpl) SubmitDraft(ctx context.Context, appID int64, userID int64) (*models.ApplicationDetailDTO, error) {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockOwnApplication(ctx, appID, userID)
+		if err != nil {
+			return err
+		}
+		if app.StatusCode != models.ApplicationStatusDraft {
+			return fmt.Errorf("%w: application %d is already %s", apperrors.ErrConflict, appID, app.StatusCode)
+		}
 
-	// Создаём splits
-	for _, splitData := range splits {
-		for bankID, amount := range splitData {
-			split := &models.LoanSplit{
-				LoanID:             loanID,
-				BankID:             bankID,
-				SplitAmount:        amount,
-				RemainingPrincipal: amount,
+		// Продукт мог измениться или быть отключён, пока заявка лежала черновиком
+		product, err := s.applicationProduct(ctx, app)
+		if err != nil {
+			return err
+		}
+		if product != nil {
+			if err := checkApplicationProduct(product, app); err != nil {
+				return err
 			}
-			err := s.loanRepo.CreateLoanSplit(ctx, split)
+		}
+
+		// Банки проверяем заново: «любой банк» определяется на момент отправки,
+		// а профили банков могли измениться, пока заявка лежала черновиком
+		var bankIDs []int16
+		if !app.AnyBank {
+			participants, err := s.participantRepo.ListParticipants(ctx, appID)
 			if err != nil {
-				return fmt.Errorf("failed to create loan split: %w", err)
+				return fmt.Errorf("failed to list participants: %w", err)
 			}
+			for _, p := range participants {
+				bankIDs = append(bankIDs, p.BankID)
+			}
+		}
+		banks, err := s.targetBanks(ctx, app, product, bankIDs)
+		if err != nil {
+			return err
+		}
+		if err := s.participantRepo.ReplaceParticipants(ctx, appID, banks); err != nil {
+			return fmt.Errorf("failed to replace participants: %w", err)
+		}
+		if err := s.status.TransitionApplication(ctx, appID, models.ApplicationStatusPending, &userID, "submitted"); err != nil {
+			return err
 		}
+		// Доход и платежи могли измениться, пока заявка лежала черновиком, — оцениваем при отправке
+		app.StatusCode = models.ApplicationStatusPending
+		return s.assessSubmitted(ctx, app, banks)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetApplicationDetail(ctx, appID)
+}
+
+func (s *creditApplicationServiceImpl) CancelApplication(ctx context.Context, appID int64, userID int64, reason string) (*models.ApplicationDetailDTO, error) {
+	if reason == "" {
+		reason = "cancelled by borrower"
 	}
 
-	// Обновляем статус заявки и привязываем кредит
-	err = s.appRepo.UpdateApplicationStatus(ctx, appID, "APPROVED")
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockOwnApplication(ctx, appID, userID)
+		if err != nil {
+			return err
+		}
+		if !canTransition(applicationTransitions, app.StatusCode, models.ApplicationStatusCancelled) {
+			return fmt.Errorf("%w: application %d is %s and cannot be cancelled", apperrors.ErrConflict, appID, app.StatusCode)
+		}
+
+		if err := s.status.TransitionApplication(ctx, appID, models.ApplicationStatusCancelled, &userID, reason); err != nil {
+			return err
+		}
+		if _, err := s.offerRepo.ExpireApplicationOffers(ctx, appID, "application cancelled"); err != nil {
+			return fmt.Errorf("failed to expire offers: %w", err)
+		}
+		if err := s.participantRepo.CloseOpenParticipants(ctx, appID); err != nil {
+			return fmt.Errorf("failed to close participants: %w", err)
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("failed to update application status: %w", err)
+		return nil, err
 	}
+	return s.GetApplicationDetail(ctx, appID)
+}
 
-	err = s.appRepo.UpdateApplicationLoanID(ctx, appID, loanID)
+// assessSubmitted проверяет долговую нагрузку, считает предскоринг и принимает
+// автоматическое решение по только что отправленной заявке
+func (s *creditApplicationServiceImpl) assessSubmitted(ctx context.Context, app *models.CreditApplication, banks []int16) error {
+	assessment, err := s.affordability.AssessApplication(ctx, app)
+	if err != nil {
+		return err
+	}
+	score, err := s.scoring.ScoreUser(ctx, app.UserID, &app.ApplicationID)
 	if err != nil {
-		return fmt.Errorf("failed to update application loan_id: %w", err)
+		return err
 	}
 
+	d, err := s.decisions.Evaluate(ctx, app.ApplicationID, models.DecisionInputs{
+		UserID:          app.UserID,
+		TypeCode:        app.TypeCode,
+		RequestedAmount: app.RequestedAmount,
+		TermMonths:      app.TermMonths,
+		BankIDs:         banks,
+		Score:           &score.Score,
+		PDN:             assessment.PDN,
+	})
+	if err != nil {
+		return err
+	}
+	if s.decisions.Enforcing() {
+		if err := s.enforceDecision(ctx, app, d); err != nil {
+			return err
+		}
+	}
+	return s.decisions.RecordDecision(ctx, d)
+}
+
+// enforceDecision применяет решение: REJECT отклоняет заявку, APPROVE одобряет её
+// с автоматическим распределением между банками. Если банки не могут разместить сумму,
+// заявка остаётся на рассмотрении, а решение записывается как неприменённое
+func (s *creditApplicationServiceImpl) enforceDecision(ctx context.Context, app *models.CreditApplication, d *models.ApplicationDecision) error {
+	switch d.Outcome {
+	case models.DecisionReject:
+		var rules []string
+		for _, f := range d.FiredRules {
+			if f.Outcome == models.DecisionReject {
+				rules = append(rules, f.RuleID)
+			}
+		}
+		d.Enforced = true
+		return s.reject(ctx, app.ApplicationID, nil, "automatic decision: "+strings.Join(rules, ", "))
+	case models.DecisionApprove:
+		// Банки, в стоп-листе которых заёмщик, исключаются, сумма делится между остальными
+		proposal, err := s.proposeSplits(ctx, app, excludedBanks(d.FiredRules)...)
+		if errors.Is(err, apperrors.ErrBadRequest) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		d.Enforced = true
+		terms := &models.ApprovalTerms{Splits: proposal.AsSplits(), InterestRate: proposal.InterestRate}
+		_, err = s.approve(ctx, app.ApplicationID, terms, nil, "automatic decision")
+		return err
+	}
+	return nil
+}
+
+// lockApplication блокирует заявку до конца транзакции
+func (s *creditApplicationServiceImpl) lockApplication(ctx context.Context, appID int64) (*models.CreditApplication, error) {
+	app, err := s.appRepo.GetApplicationByIDForUpdate(ctx, appID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: application %d", apperrors.ErrNotFound, appID)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+	return app, nil
+}
+
+// lockOwnApplication блокирует заявку и проверяет, что она принадлежит заёмщику userID
+func (s *creditApplicationServiceImpl) lockOwnApplication(ctx context.Context, appID int64, userID int64) (*models.CreditApplication, error) {
+	app, err := s.lockApplication(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app.UserID != userID {
+		return nil, fmt.Errorf("%w: application %d belongs to another user", apperrors.ErrForbidden, appID)
+	}
+	return app, nil
+}
+
+// prepareApplication проверяет условия новой заявки или черновика; если указан продукт,
+// тип и срок по умолчанию берутся из него, а сумма и срок проверяются по его ограничениям
+func (s *creditApplicationServiceImpl) prepareApplication(ctx context.Context, app *models.CreditApplication) (*models.LoanProduct, error) {
+	if app.ProductID == nil {
+		return nil, normalizeApplication(app)
+	}
+	product, err := loadProduct(ctx, s.productRepo, *app.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if app.TypeCode == "" {
+		app.TypeCode = product.TypeCode
+	}
+	if app.TermMonths == 0 {
+		app.TermMonths = min(max(defaultTermMonths, product.MinTermMonths), product.MaxTermMonths)
+	}
+	if err := normalizeApplication(app); err != nil {
+		return nil, err
+	}
+	if err := checkApplicationProduct(product, app); err != nil {
+		return nil, err
+	}
+	return product, nil
+}
+
+// applicationProduct — продукт заявки или nil, если заявка без продукта
+func (s *creditApplicationServiceImpl) applicationProduct(ctx context.Context, app *models.CreditApplication) (*models.LoanProduct, error) {
+	if app.ProductID == nil {
+		return nil, nil
+	}
+	return loadProduct(ctx, s.productRepo, *app.ProductID)
+}
+
+// proposeSplits распределяет сумму заявки между банками, кроме exclude; для заявки
+// по продукту — только между банками продукта
+func (s *creditApplicationServiceImpl) proposeSplits(ctx context.Context, app *models.CreditApplication, exclude ...int16) (*models.SplitProposal, error) {
+	product, err := s.applicationProduct(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	if product != nil {
+		return s.distribution.ProposeProductSplits(ctx, app.RequestedAmount, product, exclude...)
+	}
+	return s.distribution.ProposeSplits(ctx, app.RequestedAmount, app.TypeCode, exclude...)
+}
+
+// decisionExcludedBanks — банки, исключённые последним автоматическим решением по заявке
+func (s *creditApplicationServiceImpl) decisionExcludedBanks(ctx context.Context, appID int64) ([]int16, error) {
+	decisions, err := s.decisions.ApplicationDecisions(ctx, appID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get application decision: %w", err)
+	}
+	d, ok := decisions[appID]
+	if !ok {
+		return nil, nil
+	}
+	return excludedBanks(d.FiredRules), nil
+}
+
+// checkApplicationProduct проверяет заявку по продукту: продукт действует,
+// тип кредита и валюта совпадают, сумма и срок в его пределах
+func checkApplicationProduct(p *models.LoanProduct, app *models.CreditApplication) error {
+	if !p.Active {
+		return fmt.Errorf("%w: product %s is not available", apperrors.ErrBadRequest, p.Code)
+	}
+	if p.TypeCode != app.TypeCode {
+		return fmt.Errorf("%w: product %s is a %s loan, not %s", apperrors.ErrBadRequest, p.Code, p.TypeCode, app.TypeCode)
+	}
+	if p.Currency != models.CurrencyRUB {
+		return fmt.Errorf("%w: product %s is issued in %s, applications are accepted in %s", apperrors.ErrBadRequest, p.Code, p.Currency, models.CurrencyRUB)
+	}
+	return checkProductTerms(p, app.RequestedAmount, app.TermMonths)
+}
+
+// normalizeApplication проверяет тип кредита и срок и приводит сумму к виду 0.00
+func normalizeApplication(app *models.CreditApplication) error {
+	if !validLoanType(app.TypeCode) {
+		return fmt.Errorf("%w: unknown loan type %q", apperrors.ErrBadRequest, app.TypeCode)
+	}
+	if app.TermMonths == 0 {
+		app.TermMonths = defaultTermMonths
+	}
+	if app.TermMonths < 1 || app.TermMonths > 360 {
+		return fmt.Errorf("%w: invalid term %d months", apperrors.ErrBadRequest, app.TermMonths)
+	}
+	amount, err := parseMoney(app.RequestedAmount)
+	if err != nil || amount <= 0 {
+		return fmt.Errorf("%w: invalid requested amount %q", apperrors.ErrBadRequest, app.RequestedAmount)
+	}
+	app.RequestedAmount = formatMoney(amount)
 	return nil
 }
 
-func (s *creditApplicationServiceImpl) RejectApplication(ctx context.Context, appID int64) error {
-	return s.appRepo.UpdateApplicationStatus(ctx, appID, "REJECTED")
-}
\ No newline at end of file
+// setApplicationBank заполняет bank_id только у заявки в один банк
+func setApplicationBank(app *models.CreditApplication, banks []int16) {
+	app.BankID = nil
+	if len(banks) == 1 && !app.AnyBank {
+		app.BankID = &banks[0]
+	}
+}
+
+// draftBanks проверяет банки черновика: список можно не заполнять до отправки,
+// а для «любого банка» участники определяются при отправке
+func (s *creditApplicationServiceImpl) draftBanks(ctx context.Context, app *models.CreditApplication, product *models.LoanProduct, bankIDs []int16) ([]int16, error) {
+	if app.AnyBank || len(bankIDs) == 0 {
+		return nil, nil
+	}
+	return s.targetBanks(ctx, app, product, bankIDs)
+}
+
+// targetBanks проверяет банки заявки; для «любого банка» берёт все подходящие по профилям.
+// Для заявки по продукту допускаются только банки, выдающие продукт
+func (s *creditApplicationServiceImpl) targetBanks(ctx context.Context, app *models.CreditApplication, product *models.LoanProduct, bankIDs []int16) ([]int16, error) {
+	if app.AnyBank {
+		banks, err := s.distribution.EligibleBanks(ctx, app.TypeCode)
+		if err != nil {
+			return nil, err
+		}
+		if product != nil {
+			banks = slices.DeleteFunc(banks, func(b int16) bool { return !slices.Contains(product.BankIDs, b) })
+			if len(banks) == 0 {
+				return nil, fmt.Errorf("%w: no bank offers product %s", apperrors.ErrBadRequest, product.Code)
+			}
+		}
+		if len(banks) == 0 {
+			return nil, fmt.Errorf("%w: no bank offers %s loans", apperrors.ErrBadRequest, app.TypeCode)
+		}
+		return banks, nil
+	}
+
+	if len(bankIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one bank is required", apperrors.ErrBadRequest)
+	}
+	seen := make(map[int16]bool, len(bankIDs))
+	for _, bankID := range bankIDs {
+		if seen[bankID] {
+			return nil, fmt.Errorf("%w: duplicate bank %d", apperrors.ErrBadRequest, bankID)
+		}
+		seen[bankID] = true
+		if product != nil {
+			if err := checkProductBank(product, bankID); err != nil {
+				return nil, err
+			}
+		}
+		if _, err := s.bankRepo.GetBankByID(ctx, bankID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("%w: bank %d does not exist", apperrors.ErrBadRequest, bankID)
+			}
+			return nil, fmt.Errorf("failed to get bank: %w", err)
+		}
+	}
+	return bankIDs, nil
+}
+
+func (s *creditApplicationServiceImpl) GetApplication(ctx context.Context, appID int64) (*models.CreditApplication, error) {
+	return s.appRepo.GetApplicationByID(ctx, appID)
+}
+
+func (s *creditApplicationServiceImpl) GetApplicationDetail(ctx context.Context, appID int64) (*models.ApplicationDetailDTO, error) {
+	app, err := s.appRepo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: application %d", apperrors.ErrNotFound, appID)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+	details, err := s.withParticipants(ctx, []models.CreditApplication{*app})
+	if err != nil {
+		return nil, err
+	}
+	return &details[0], nil
+}
+
+func (s *creditApplicationServiceImpl) GetApplications(ctx context.Context, userID int64) ([]models.ApplicationDetailDTO, error) {
+	apps, err := s.appRepo.ListUserApplications(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withParticipants(ctx, apps)
+}
+
+// withParticipants дополняет заявки участниками и сводным статусом участия
+func (s *creditApplicationServiceImpl) withParticipants(ctx context.Context, apps []models.CreditApplication) ([]models.ApplicationDetailDTO, error) {
+	ids := make([]int64, 0, len(apps))
+	for _, a := range apps {
+		ids = append(ids, a.ApplicationID)
+	}
+	participants, err := s.participantRepo.ListParticipants(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list participants: %w", err)
+	}
+
+	scores, err := s.scoring.ApplicationScores(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list application scores: %w", err)
+	}
+	assessments, err := s.affordability.ApplicationAssessments(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list affordability assessments: %w", err)
+	}
+	decisions, err := s.decisions.ApplicationDecisions(ctx, ids...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list decisions: %w", err)
+	}
+
+	byApp := make(map[int64][]models.ApplicationParticipant, len(apps))
+	for _, p := range participants {
+		byApp[p.ApplicationID] = append(byApp[p.ApplicationID], p)
+	}
+
+	res := make([]models.ApplicationDetailDTO, 0, len(apps))
+	for _, a := range apps {
+		ps := byApp[a.ApplicationID]
+		if ps == nil {
+			ps = []models.ApplicationParticipant{}
+		}
+		res = append(res, models.ApplicationDetailDTO{
+			CreditApplication: a,
+			Participants:      ps,
+			Participation:     summarizeParticipation(ps),
+			Score:             scores[a.ApplicationID],
+			Affordability:     assessments[a.ApplicationID],
+			Decision:          decisions[a.ApplicationID],
+		})
+	}
+	return res, nil
+}
+
+func (s *creditApplicationServiceImpl) ListBankApplications(ctx context.Context, bankID int16, status string) ([]models.BankApplicationDTO, error) {
+	var statuses []string
+	switch status {
+	case "":
+	case models.ParticipantStatusInvited, models.ParticipantStatusOffered, models.ParticipantStatusDeclined,
+		models.ParticipantStatusAccepted, models.ParticipantStatusClosed:
+		statuses = []string{status}
+	default:
+		return nil, fmt.Errorf("%w: unknown participant status %q", apperrors.ErrBadRequest, status)
+	}
+	return s.participantRepo.ListBankApplications(ctx, bankID, statuses)
+}
+
+func (s *creditApplicationServiceImpl) DeclineParticipation(ctx context.Context, appID int64, bankID int16, actorID int64, reason string) (*models.ApplicationDetailDTO, error) {
+	if reason == "" {
+		reason = "declined by bank"
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockApplication(ctx, appID)
+		if err != nil {
+			return err
+		}
+		if app.StatusCode != models.ApplicationStatusPending {
+			return fmt.Errorf("%w: application %d is %s", apperrors.ErrConflict, appID, app.StatusCode)
+		}
+
+		ok, err := s.participantRepo.UpdateParticipantStatus(ctx, appID, bankID,
+			[]string{models.ParticipantStatusInvited, models.ParticipantStatusOffered}, models.ParticipantStatusDeclined, reason)
+		if err != nil {
+			return fmt.Errorf("failed to update participant: %w", err)
+		}
+		if !ok {
+			if _, err := s.participantRepo.GetParticipant(ctx, appID, bankID); errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: bank %d is not a participant of application %d", apperrors.ErrNotFound, bankID, appID)
+			}
+			return fmt.Errorf("%w: bank %d has already responded to application %d", apperrors.ErrConflict, bankID, appID)
+		}
+
+		// Действующее предложение банка отзывается вместе с отказом
+		offers, err := s.offerRepo.ListApplicationOffers(ctx, appID)
+		if err != nil {
+			return fmt.Errorf("failed to list offers: %w", err)
+		}
+		for _, o := range offers {
+			if o.BankID == bankID && o.Status == models.OfferStatusPending {
+				if _, err := s.offerRepo.UpdateOfferStatus(ctx, o.OfferID, models.OfferStatusPending, models.OfferStatusWithdrawn, reason); err != nil {
+					return fmt.Errorf("failed to withdraw offer %d: %w", o.OfferID, err)
+				}
+			}
+		}
+
+		participants, err := s.participantRepo.ListParticipants(ctx, appID)
+		if err != nil {
+			return fmt.Errorf("failed to list participants: %w", err)
+		}
+		if summarizeParticipation(participants).Status == models.ParticipationAllDeclined {
+			return s.status.TransitionApplication(ctx, appID, models.ApplicationStatusRejected, nil, "all banks declined")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetApplicationDetail(ctx, appID)
+}
+
+func (s *creditApplicationServiceImpl) ApproveApplication(ctx context.Context, appID int64, terms *models.ApprovalTerms, actorID int64) (*models.LoanDetailDTO, error) {
+	return s.approve(ctx, appID, terms, &actorID, "")
+}
+
+// approve выдаёт кредит по заявке; actorID nil — системное решение, note дополняет причину в истории
+func (s *creditApplicationServiceImpl) approve(ctx context.Context, appID int64, terms *models.ApprovalTerms, actorID *int64, note string) (*models.LoanDetailDTO, error) {
+	var detail *models.LoanDetailDTO
+
+	// Кредит, доли, графики, статус заявки и привязка кредита — одна транзакция
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockApplication(ctx, appID)
+		if err != nil {
+			return err
+		}
+
+		// Проверяем переход до создания кредита, чтобы повторное одобрение не создало второй кредит
+		if !canTransition(applicationTransitions, app.StatusCode, models.ApplicationStatusApproved) {
+			return fmt.Errorf("%w: application %d is already %s", apperrors.ErrConflict, appID, app.StatusCode)
+		}
+
+		// Создаём кредит на основе заявки; CreateLoan проверяет банки и сумму долей
+		now := time.Now()
+		loan := &models.Loan{
+			UserID:             app.UserID,
+			OriginalAmount:     app.RequestedAmount,
+			TakenAt:            now,
+			InterestRate:       terms.InterestRate,
+			Status:             models.LoanStatusActive,
+			Purpose:            app.TypeCode,
+			TermMonths:         terms.TermMonths,
+			RepaymentType:      terms.RepaymentType,
+			DayCountConvention: DayCountAct365,
+			ProductID:          app.ProductID,
+			CreatedAt:          now,
+		}
+		// Срок не задан — берём запрошенный заёмщиком
+		if loan.TermMonths == 0 {
+			loan.TermMonths = app.TermMonths
+		}
+		// Если доли не заданы, распределяем сумму между банками автоматически
+		splits := terms.Splits
+		if len(splits) == 0 {
+			exclude, err := s.decisionExcludedBanks(ctx, appID)
+			if err != nil {
+				return err
+			}
+			proposal, err := s.proposeSplits(ctx, app, exclude...)
+			if err != nil {
+				return err
+			}
+			splits = proposal.AsSplits()
+			if loan.InterestRate == "" {
+				loan.InterestRate = proposal.InterestRate
+			}
+		}
+		// Ставка не задана — котируем по базовым ставкам банков-участников
+		// в пределах диапазона продукта
+		if loan.InterestRate == "" {
+			product, err := s.applicationProduct(ctx, app)
+			if err != nil {
+				return err
+			}
+			if product != nil {
+				loan.InterestRate, err = s.distribution.QuoteProductRate(ctx, product, splits)
+			} else {
+				loan.InterestRate, err = s.distribution.QuoteRate(ctx, app.TypeCode, splits)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		detail, err = s.loanService.CreateLoan(ctx, loan, splits)
+		if err != nil {
+			return err
+		}
+
+		// Обновляем статус заявки и привязываем кредит
+		reason := fmt.Sprintf("loan %d issued", loan.LoanID)
+		if note != "" {
+			reason = note + ": " + reason
+		}
+		if err := s.status.TransitionApplication(ctx, appID, models.ApplicationStatusApproved, actorID, reason); err != nil {
+			return fmt.Errorf("failed to update application status: %w", err)
+		}
+		if err := s.appRepo.UpdateApplicationLoanID(ctx, appID, loan.LoanID); err != nil {
+			return fmt.Errorf("failed to update application loan_id: %w", err)
+		}
+		if _, err := s.offerRepo.DeclinePendingOffers(ctx, appID, "application approved directly"); err != nil {
+			return fmt.Errorf("failed to decline offers: %w", err)
+		}
+
+		// Банки-участники, вошедшие в кредит, — ACCEPTED, остальные закрываются
+		open := []string{models.ParticipantStatusInvited, models.ParticipantStatusOffered}
+		for _, split := range detail.Splits {
+			if _, err := s.participantRepo.UpdateParticipantStatus(ctx, appID, split.BankID, open, models.ParticipantStatusAccepted, ""); err != nil {
+				return fmt.Errorf("failed to update participant: %w", err)
+			}
+		}
+		if err := s.participantRepo.CloseOpenParticipants(ctx, appID); err != nil {
+			return fmt.Errorf("failed to close participants: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+func (s *creditApplicationServiceImpl) RejectApplication(ctx context.Context, appID int64, actorID int64) error {
+	return s.reject(ctx, appID, &actorID, "rejected")
+}
+
+// reject отклоняет заявку; actorID nil — системное решение
+func (s *creditApplicationServiceImpl) reject(ctx context.Context, appID int64, actorID *int64, reason string) error {
+	// Отказ по заявке закрывает и все действующие предложения банков
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.status.TransitionApplication(ctx, appID, models.ApplicationStatusRejected, actorID, reason); err != nil {
+			return err
+		}
+		if _, err := s.offerRepo.DeclinePendingOffers(ctx, appID, "application rejected"); err != nil {
+			return fmt.Errorf("failed to decline offers: %w", err)
+		}
+		if err := s.participantRepo.CloseOpenParticipants(ctx, appID); err != nil {
+			return fmt.Errorf("failed to close participants: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *creditApplicationServiceImpl) GetApplicationHistory(ctx context.Context, appID int64) ([]models.StatusHistoryEntry, error) {
+	return s.status.ApplicationHistory(ctx, appID)
+}
@@ -0,0 +1,398 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/openbanking"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+const (
+	// bankFeedFormat — источник операций из API банка в ключах дедупликации (external_id "api:…")
+	bankFeedFormat = "API"
+	// maxSyncPages — страниц ленты операций за одну синхронизацию; остальное догрузится в следующий раз
+	maxSyncPages = 50
+)
+
+// BankSyncService подключает счета пользователя через API банков и синхронизирует их:
+// новые операции попадают в transactions, остаток user_bank_accounts выравнивается по банку
+// записью главной книги BANK_SYNC
+type BankSyncService interface {
+	// Connect подключает банк для клиента банка customerID и сразу синхронизирует счёт;
+	// неудачная первая синхронизация видна в last_error подключения
+	Connect(ctx context.Context, userID int64, bankID int16, customerID string) (*models.BankConnection, error)
+	Disconnect(ctx context.Context, userID int64, bankID int16) error
+	ListUserConnections(ctx context.Context, userID int64) ([]models.BankConnection, error)
+	SyncUserBank(ctx context.Context, userID int64, bankID int16) (*models.BankSyncReport, error)
+	Sync(ctx context.Context, connectionID int64) (*models.BankSyncReport, error)
+	// SyncAll синхронизирует все активные подключения; возвращает число успешных
+	SyncAll(ctx context.Context) (int, error)
+}
+
+type bankSyncServiceImpl struct {
+	connectionRepo  repos.BankConnectionRepository
+	accountRepo     repos.UserBankAccountRepository
+	transactionRepo repos.TransactionRepository
+	bankRepo        repos.BankRepository
+	ledger          LedgerService
+	txManager       repos.TxManager
+	connectors      openbanking.Connectors
+}
+
+func NewBankSyncService(
+	connectionRepo repos.BankConnectionRepository,
+	accountRepo repos.UserBankAccountRepository,
+	transactionRepo repos.TransactionRepository,
+	bankRepo repos.BankRepository,
+	ledger LedgerService,
+	txManager repos.TxManager,
+	connectors openbanking.Connectors,
+) BankSyncService {
+	return &bankSyncServiceImpl{
+		connectionRepo:  connectionRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		bankRepo:        bankRepo,
+		ledger:          ledger,
+		txManager:       txManager,
+		connectors:      connectors,
+	}
+}
+
+func (s *bankSyncServiceImpl) Connect(ctx context.Context, userID int64, bankID int16, customerID string) (*models.BankConnection, error) {
+	customerID = strings.TrimSpace(customerID)
+	if customerID == "" {
+		return nil, fmt.Errorf("%w: customer_id is required", apperrors.ErrBadRequest)
+	}
+	connector, err := s.connector(ctx, bankID)
+	if err != nil {
+		return nil, err
+	}
+	// Проверяем клиента до сохранения: банк должен знать его и принять токен
+	if _, err := connector.ListAccounts(ctx, customerID); err != nil {
+		if errors.Is(err, openbanking.ErrNotFound) || errors.Is(err, openbanking.ErrUnauthorized) {
+			return nil, fmt.Errorf("%w: bank rejected customer %s: %v", apperrors.ErrBadRequest, customerID, err)
+		}
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadGateway, err)
+	}
+
+	c := &models.BankConnection{UserID: userID, BankID: bankID, CustomerID: customerID}
+	if err := s.connectionRepo.UpsertConnection(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save bank connection: %w", err)
+	}
+	if _, err := s.Sync(ctx, c.ConnectionID); err != nil {
+		logger.Log.Warnf("Initial sync of bank connection %d failed: %v", c.ConnectionID, err)
+	}
+	return s.connectionRepo.GetConnection(ctx, c.ConnectionID)
+}
+
+func (s *bankSyncServiceImpl) Disconnect(ctx context.Context, userID int64, bankID int16) error {
+	c, err := s.connectionRepo.GetUserBankConnection(ctx, userID, bankID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: bank %d is not connected", apperrors.ErrNotFound, bankID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get bank connection: %w", err)
+	}
+	if err := s.connectionRepo.UpdateStatus(ctx, c.ConnectionID, models.BankConnectionDisconnected); err != nil {
+		return fmt.Errorf("failed to disconnect bank: %w", err)
+	}
+	return nil
+}
+
+func (s *bankSyncServiceImpl) ListUserConnections(ctx context.Context, userID int64) ([]models.BankConnection, error) {
+	connections, err := s.connectionRepo.ListUserConnections(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank connections: %w", err)
+	}
+	if connections == nil {
+		connections = []models.BankConnection{}
+	}
+	return connections, nil
+}
+
+func (s *bankSyncServiceImpl) SyncUserBank(ctx context.Context, userID int64, bankID int16) (*models.BankSyncReport, error) {
+	c, err := s.connectionRepo.GetUserBankConnection(ctx, userID, bankID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: bank %d is not connected", apperrors.ErrNotFound, bankID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank connection: %w", err)
+	}
+	return s.Sync(ctx, c.ConnectionID)
+}
+
+// Sync загружает данные из банка без блокировок, затем блокирует подключение и применяет их.
+// Если за время запросов к банку подключение синхронизировали повторно, возвращается конфликт
+func (s *bankSyncServiceImpl) Sync(ctx context.Context, connectionID int64) (*models.BankSyncReport, error) {
+	c, err := s.activeConnection(ctx, s.connectionRepo.GetConnection, connectionID)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := s.connector(ctx, c.BankID)
+	if err != nil {
+		return nil, err
+	}
+	feed, failure := s.fetch(ctx, c, connector)
+
+	var report *models.BankSyncReport
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := s.activeConnection(ctx, s.connectionRepo.GetConnectionForUpdate, connectionID)
+		if err != nil {
+			return err
+		}
+		if locked.Cursor != c.Cursor || !sameExternalAccount(locked.ExternalAccountID, c.ExternalAccountID) {
+			return fmt.Errorf("%w: bank connection %d was synced concurrently", apperrors.ErrConflict, connectionID)
+		}
+
+		// Ошибка синхронизации откатывает только её изменения; сама ошибка сохраняется в подключении
+		if failure == nil {
+			synced := *locked
+			failure = s.txManager.WithinSavepoint(ctx, func(ctx context.Context) error {
+				report, err = s.apply(ctx, &synced, feed)
+				return err
+			})
+		}
+		if failure == nil {
+			return nil
+		}
+		reason := failure.Error()
+		locked.LastError = &reason
+		if err := s.connectionRepo.UpdateSync(ctx, locked); err != nil {
+			return fmt.Errorf("failed to record sync failure: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if failure != nil {
+		return nil, fmt.Errorf("failed to sync bank connection %d: %w", connectionID, failure)
+	}
+	return report, nil
+}
+
+// activeConnection читает подключение через get и проверяет, что оно активно
+func (s *bankSyncServiceImpl) activeConnection(ctx context.Context, get func(context.Context, int64) (*models.BankConnection, error), connectionID int64) (*models.BankConnection, error) {
+	c, err := get(ctx, connectionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: bank connection %d", apperrors.ErrNotFound, connectionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank connection: %w", err)
+	}
+	if c.Status != models.BankConnectionActive {
+		return nil, fmt.Errorf("%w: bank connection %d is %s", apperrors.ErrConflict, connectionID, c.Status)
+	}
+	return c, nil
+}
+
+func sameExternalAccount(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// bankFeed — данные банка для одной синхронизации
+type bankFeed struct {
+	account      *openbanking.Account
+	transactions []openbanking.Transaction
+	cursor       string // курсор ленты после загруженных страниц
+	balance      *openbanking.Balance
+	balanceK     int64
+}
+
+// fetch загружает счёт, новые операции с курсора подключения и остаток. Вызывается вне транзакции,
+// чтобы подключение и счёт пользователя не были заблокированы на время сетевых вызовов
+func (s *bankSyncServiceImpl) fetch(ctx context.Context, c *models.BankConnection, connector openbanking.BankConnector) (*bankFeed, error) {
+	external, err := s.externalAccount(ctx, c, connector)
+	if err != nil {
+		return nil, err
+	}
+
+	feed := &bankFeed{account: external, cursor: c.Cursor}
+	for page := 0; page < maxSyncPages; page++ {
+		p, err := connector.ListTransactions(ctx, c.CustomerID, external.AccountID, feed.cursor)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", apperrors.ErrBadGateway, err)
+		}
+		feed.transactions = append(feed.transactions, p.Transactions...)
+		if p.NextCursor != "" {
+			feed.cursor = p.NextCursor
+		}
+		if !p.HasMore {
+			break
+		}
+	}
+	if feed.balance, err = connector.GetBalance(ctx, c.CustomerID, external.AccountID); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadGateway, err)
+	}
+	if feed.balance.Currency != "" && feed.balance.Currency != external.Currency {
+		return nil, fmt.Errorf("%w: balance currency %s differs from account currency %s", apperrors.ErrBadGateway, feed.balance.Currency, external.Currency)
+	}
+	if feed.balanceK, err = parseMoney(feed.balance.Amount); err != nil {
+		return nil, fmt.Errorf("%w: invalid balance: %v", apperrors.ErrBadGateway, err)
+	}
+	return feed, nil
+}
+
+// apply записывает загруженные данные внутри транзакции: новые операции, запись BANK_SYNC
+// к остатку банка и состояние подключения
+func (s *bankSyncServiceImpl) apply(ctx context.Context, c *models.BankConnection, feed *bankFeed) (*models.BankSyncReport, error) {
+	external := feed.account
+	account, err := s.accountRepo.EnsureUserBankAccount(ctx, c.UserID, c.BankID, external.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ensure account: %w", err)
+	}
+	if account.Currency != external.Currency {
+		return nil, fmt.Errorf("%w: bank account currency %s differs from account %d currency %s",
+			apperrors.ErrConflict, external.Currency, account.AccountID, account.Currency)
+	}
+	if account, err = s.accountRepo.GetAccountForUpdate(ctx, account.AccountID); err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	now := time.Now()
+	report := &models.BankSyncReport{
+		ConnectionID: c.ConnectionID,
+		UserID:       c.UserID,
+		BankID:       c.BankID,
+		AccountID:    account.AccountID,
+		Fetched:      len(feed.transactions),
+		Skipped:      []models.StatementLineError{},
+		Balance:      formatMoney(feed.balanceK),
+		SyncedAt:     now,
+	}
+	entries := make([]statementEntry, 0, len(feed.transactions))
+	for i, t := range feed.transactions {
+		amount, err := parseMoney(t.Amount)
+		if err != nil {
+			report.Skipped = append(report.Skipped, models.StatementLineError{Line: i + 1, Error: fmt.Sprintf("transaction %s: %v", t.TransactionID, err)})
+			continue
+		}
+		if t.Currency != "" && t.Currency != external.Currency {
+			report.Skipped = append(report.Skipped, models.StatementLineError{
+				Line:  i + 1,
+				Error: fmt.Sprintf("transaction %s: currency %s differs from account currency %s", t.TransactionID, t.Currency, external.Currency),
+			})
+			continue
+		}
+		entries = append(entries, statementEntry{
+			Line:        i + 1,
+			OccurredAt:  t.BookedAt,
+			Amount:      amount,
+			Description: strings.TrimSpace(t.Description),
+			Category:    t.Category,
+			Reference:   t.TransactionID,
+		})
+	}
+	if report.Imported, report.Duplicates, err = importStatementEntries(ctx, s.transactionRepo, c.UserID, c.BankID, bankFeedFormat, models.TransactionSourceBankAPI, entries); err != nil {
+		return nil, err
+	}
+
+	asOf := feed.balance.AsOf
+	if asOf.IsZero() {
+		asOf = now
+	}
+	if report.Adjustment, err = s.ledger.RecordBankSync(ctx, account, report.Balance, asOf); err != nil {
+		return nil, fmt.Errorf("failed to record bank balance: %w", err)
+	}
+
+	c.ExternalAccountID = &external.AccountID
+	c.Cursor = feed.cursor
+	c.BankBalance = &report.Balance
+	c.LastSyncedAt = &now
+	c.LastError = nil
+	if err := s.connectionRepo.UpdateSync(ctx, c); err != nil {
+		return nil, fmt.Errorf("failed to save sync state: %w", err)
+	}
+	return report, nil
+}
+
+// externalAccount — счёт в банке, отражаемый в user_bank_accounts. При первой синхронизации
+// выбирается рублёвый счёт, если он есть, иначе первый
+func (s *bankSyncServiceImpl) externalAccount(ctx context.Context, c *models.BankConnection, connector openbanking.BankConnector) (*openbanking.Account, error) {
+	accounts, err := connector.ListAccounts(ctx, c.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadGateway, err)
+	}
+	if c.ExternalAccountID != nil {
+		for i := range accounts {
+			if accounts[i].AccountID == *c.ExternalAccountID {
+				return &accounts[i], nil
+			}
+		}
+		return nil, fmt.Errorf("%w: bank account %s is no longer available", apperrors.ErrBadGateway, *c.ExternalAccountID)
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("%w: bank returned no accounts for customer %s", apperrors.ErrBadGateway, c.CustomerID)
+	}
+	for i := range accounts {
+		if accounts[i].Currency == models.CurrencyRUB {
+			return &accounts[i], nil
+		}
+	}
+	return &accounts[0], nil
+}
+
+func (s *bankSyncServiceImpl) SyncAll(ctx context.Context) (int, error) {
+	ids, err := s.connectionRepo.ListActiveConnectionIDs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list bank connections: %w", err)
+	}
+	n := 0
+	for _, id := range ids {
+		if _, err := s.Sync(ctx, id); err != nil {
+			logger.Log.Warnf("Bank connection %d sync failed: %v", id, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// connector — коннектор API банка; ошибка, если банка нет или API для него не настроено
+func (s *bankSyncServiceImpl) connector(ctx context.Context, bankID int16) (openbanking.BankConnector, error) {
+	bank, err := s.bankRepo.GetBankByID(ctx, bankID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: bank %d", apperrors.ErrNotFound, bankID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank: %w", err)
+	}
+	connector := s.connectors.For(bank.Code)
+	if connector == nil {
+		return nil, fmt.Errorf("%w: open banking API is not configured for bank %s", apperrors.ErrConflict, bank.Code)
+	}
+	return connector, nil
+}
+
+// RunBankSync периодически синхронизирует активные подключения к банкам
+func RunBankSync(ctx context.Context, svc BankSyncService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := svc.SyncAll(ctx)
+		if err != nil {
+			logger.Log.Errorf("Bank sync failed: %v", err)
+		} else if n > 0 {
+			logger.Log.Infof("Synced %d bank connections", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
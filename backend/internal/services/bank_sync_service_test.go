@@ -0,0 +1,412 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/openbanking"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// Репозитории синхронизации в памяти; реализованы только методы, которые вызывает BankSyncService
+
+type memConnections struct {
+	repos.BankConnectionRepository
+	rows map[int64]models.BankConnection
+}
+
+func (r *memConnections) UpsertConnection(_ context.Context, c *models.BankConnection) error {
+	c.ConnectionID = int64(len(r.rows) + 1)
+	c.Status = models.BankConnectionActive
+	r.rows[c.ConnectionID] = *c
+	return nil
+}
+
+func (r *memConnections) GetConnection(_ context.Context, id int64) (*models.BankConnection, error) {
+	c, ok := r.rows[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &c, nil
+}
+
+func (r *memConnections) GetConnectionForUpdate(ctx context.Context, id int64) (*models.BankConnection, error) {
+	return r.GetConnection(ctx, id)
+}
+
+func (r *memConnections) UpdateSync(_ context.Context, c *models.BankConnection) error {
+	r.rows[c.ConnectionID] = *c
+	return nil
+}
+
+type memAccounts struct {
+	repos.UserBankAccountRepository
+	account *models.UserBankAccount
+}
+
+func (r *memAccounts) EnsureUserBankAccount(_ context.Context, userID int64, bankID int16, currency string) (*models.UserBankAccount, error) {
+	if r.account == nil {
+		r.account = &models.UserBankAccount{AccountID: 1, UserID: userID, BankID: bankID, Balance: "0.00", Currency: currency}
+	}
+	a := *r.account
+	return &a, nil
+}
+
+func (r *memAccounts) GetAccountForUpdate(_ context.Context, accountID int64) (*models.UserBankAccount, error) {
+	a := *r.account
+	return &a, nil
+}
+
+type memTransactions struct {
+	repos.TransactionRepository
+	rows []models.Transaction
+}
+
+func (r *memTransactions) ListBankTransactionsBetween(_ context.Context, userID int64, bankID int16, from, to time.Time) ([]models.Transaction, error) {
+	var res []models.Transaction
+	for _, t := range r.rows {
+		if !t.OccurredAt.Before(from) && t.OccurredAt.Before(to) {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func (r *memTransactions) ImportTransaction(_ context.Context, t *models.Transaction) (bool, error) {
+	for _, row := range r.rows {
+		if *row.ExternalID == *t.ExternalID {
+			return false, nil
+		}
+	}
+	t.TransactionID = int64(len(r.rows) + 1)
+	r.rows = append(r.rows, *t)
+	return true, nil
+}
+
+type memBanks struct {
+	repos.BankRepository
+}
+
+func (memBanks) GetBankByID(_ context.Context, bankID int16) (*models.Bank, error) {
+	if bankID != 1 {
+		return nil, sql.ErrNoRows
+	}
+	return &models.Bank{BankID: 1, Code: "TBANK"}, nil
+}
+
+// memLedger хранит записи и, как и БД, пересчитывает остаток счёта клиента по его проводкам
+type memLedger struct {
+	repos.LedgerRepository
+	accounts *memAccounts
+	codes    map[string]int64
+	customer map[int64]bool
+	entries  []models.LedgerEntry
+}
+
+func (r *memLedger) EnsureAccount(_ context.Context, a *models.LedgerAccount) error {
+	if _, ok := r.codes[a.Code]; !ok {
+		r.codes[a.Code] = int64(len(r.codes) + 1)
+	}
+	a.LedgerAccountID = r.codes[a.Code]
+	r.customer[a.LedgerAccountID] = a.Kind == models.LedgerCustomerFunds
+	return nil
+}
+
+func (r *memLedger) CreateEntry(_ context.Context, e *models.LedgerEntry) error {
+	r.entries = append(r.entries, *e)
+	return nil
+}
+
+func (r *memLedger) RefreshCustomerBalances(context.Context, []int64) error {
+	var credit int64
+	for _, e := range r.entries {
+		for _, p := range e.Postings {
+			if r.customer[p.LedgerAccountID] {
+				k, err := parseMoney(p.Amount)
+				if err != nil {
+					return err
+				}
+				credit -= k
+			}
+		}
+	}
+	r.accounts.account.Balance = formatMoney(credit)
+	return nil
+}
+
+type bankSyncFixture struct {
+	svc          BankSyncService
+	connections  *memConnections
+	accounts     *memAccounts
+	transactions *memTransactions
+	ledger       *memLedger
+}
+
+func newBankSyncFixture(connectors openbanking.Connectors) *bankSyncFixture {
+	f := &bankSyncFixture{
+		connections:  &memConnections{rows: map[int64]models.BankConnection{}},
+		accounts:     &memAccounts{},
+		transactions: &memTransactions{},
+	}
+	f.ledger = &memLedger{accounts: f.accounts, codes: map[string]int64{}, customer: map[int64]bool{}}
+	f.svc = NewBankSyncService(f.connections, f.accounts, f.transactions, memBanks{},
+		NewLedgerService(f.ledger, passTxManager{}), passTxManager{}, connectors)
+	return f
+}
+
+func TestBankSyncConnectErrors(t *testing.T) {
+	mock := openbanking.NewMockServer()
+	defer mock.Close()
+	down := httptest.NewServer(nil)
+	down.Close()
+
+	connectors := func(baseURL, token string) openbanking.Connectors {
+		c, err := openbanking.NewConnectors(config.OpenBankingConfig{
+			Banks: map[string]config.BankAPIConfig{"TBANK": {BaseURL: baseURL, Token: token}},
+		}, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	tests := []struct {
+		name       string
+		connectors openbanking.Connectors
+		bankID     int16
+		customerID string
+		wantErr    error
+	}{
+		{"bank rejects the token", connectors(mock.URL(), ""), 1, "client-1", apperrors.ErrBadRequest},
+		{"bank does not know the customer", connectors(mock.URL()+"/gateway", "token"), 1, "client-1", apperrors.ErrBadRequest},
+		{"bank is unavailable", connectors(down.URL, "token"), 1, "client-1", apperrors.ErrBadGateway},
+		{"API is not configured", openbanking.Connectors{}, 1, "client-1", apperrors.ErrConflict},
+		{"unknown bank", connectors(mock.URL(), "token"), 2, "client-1", apperrors.ErrNotFound},
+		{"no customer", connectors(mock.URL(), "token"), 1, " ", apperrors.ErrBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBankSyncFixture(tt.connectors)
+			_, err := f.svc.Connect(context.Background(), 7, tt.bankID, tt.customerID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Connect error = %v, want %v", err, tt.wantErr)
+			}
+			if len(f.connections.rows) != 0 {
+				t.Error("connection saved after a failed check")
+			}
+		})
+	}
+}
+
+func TestBankSyncIncremental(t *testing.T) {
+	mock := openbanking.NewMockServer()
+	defer mock.Close()
+	connectors, err := openbanking.NewConnectors(config.OpenBankingConfig{Mock: true}, mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	f := newBankSyncFixture(connectors)
+
+	// История в банке длиннее страницы ленты TBANK (50 операций)
+	bank := connectors.For("TBANK")
+	accounts, err := bank.ListAccounts(ctx, "client-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := 0
+	for cursor := ""; ; {
+		p, err := bank.ListTransactions(ctx, "client-1", accounts[0].AccountID, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		history += len(p.Transactions)
+		cursor = p.NextCursor
+		if !p.HasMore {
+			break
+		}
+	}
+	if history <= 50 {
+		t.Fatalf("mock history has %d transactions, want more than one page", history)
+	}
+
+	// Подключение сразу загружает всю историю и выравнивает остаток по банку
+	c, err := f.svc.Connect(ctx, 7, 1, "client-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LastError != nil {
+		t.Fatalf("initial sync failed: %s", *c.LastError)
+	}
+	if len(f.transactions.rows) != history {
+		t.Errorf("imported %d transactions, want %d", len(f.transactions.rows), history)
+	}
+	if c.Cursor != strconv.Itoa(history) {
+		t.Errorf("cursor = %q, want %d", c.Cursor, history)
+	}
+	if c.BankBalance == nil || *c.BankBalance != f.accounts.account.Balance {
+		t.Errorf("account balance %s, bank balance %v", f.accounts.account.Balance, c.BankBalance)
+	}
+	if len(f.ledger.entries) != 1 || f.ledger.entries[0].EntryType != models.LedgerEntryBankSync {
+		t.Fatalf("ledger entries = %+v, want one BANK_SYNC", f.ledger.entries)
+	}
+	before, _ := parseMoney(f.accounts.account.Balance)
+
+	// Следующая синхронизация продолжает с курсора: только новые операции, валютная пропускается
+	mock.AddTransaction("TBANK", "client-1", openbanking.Transaction{Amount: "1000.00", Description: "Перевод от друга"})
+	mock.AddTransaction("TBANK", "client-1", openbanking.Transaction{Amount: "-15.00", Currency: "USD", Description: "Подписка"})
+	report, err := f.svc.Sync(ctx, c.ConnectionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Fetched != 2 || report.Imported != 1 || report.Duplicates != 0 {
+		t.Errorf("report fetched %d, imported %d, duplicates %d; want 2, 1, 0", report.Fetched, report.Imported, report.Duplicates)
+	}
+	if len(report.Skipped) != 1 || report.Skipped[0].Line != 2 {
+		t.Errorf("skipped = %+v, want the USD transaction", report.Skipped)
+	}
+	// Остаток банка учитывает и валютное списание: главная книга доводит счёт до него одной записью
+	after, _ := parseMoney(report.Balance)
+	if report.Adjustment != formatMoney(after-before) || report.Adjustment != "985.00" {
+		t.Errorf("adjustment = %s, want 985.00", report.Adjustment)
+	}
+	if f.accounts.account.Balance != report.Balance {
+		t.Errorf("account balance %s, want %s", f.accounts.account.Balance, report.Balance)
+	}
+	if len(f.ledger.entries) != 2 {
+		t.Errorf("%d ledger entries, want 2", len(f.ledger.entries))
+	}
+
+	// Банк прислал ленту заново: известные операции не дублируются, остаток не меняется
+	stale := f.connections.rows[c.ConnectionID]
+	stale.Cursor = ""
+	f.connections.rows[c.ConnectionID] = stale
+	report, err = f.svc.Sync(ctx, c.ConnectionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Fetched != history+2 || report.Imported != 0 || report.Duplicates != history+1 {
+		t.Errorf("resync fetched %d, imported %d, duplicates %d; want %d, 0, %d",
+			report.Fetched, report.Imported, report.Duplicates, history+2, history+1)
+	}
+	if report.Adjustment != "0.00" || len(f.ledger.entries) != 2 {
+		t.Errorf("resync adjustment %s with %d ledger entries", report.Adjustment, len(f.ledger.entries))
+	}
+	if got := f.connections.rows[c.ConnectionID].Cursor; got != strconv.Itoa(history+2) {
+		t.Errorf("cursor = %q, want %d", got, history+2)
+	}
+}
+
+// trackingTx отмечает, открыта ли транзакция
+type trackingTx struct{ open *bool }
+
+func (m trackingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	*m.open = true
+	defer func() { *m.open = false }()
+	return fn(ctx)
+}
+
+func (trackingTx) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// racingConnector проверяет, что к банку не обращаются внутри транзакции, и перед загрузкой ленты
+// вызывает race — параллельную синхронизацию того же подключения
+type racingConnector struct {
+	openbanking.BankConnector
+	t    *testing.T
+	inTx *bool
+	race func()
+}
+
+func (c racingConnector) ListAccounts(ctx context.Context, customerID string) ([]openbanking.Account, error) {
+	c.checkUnlocked()
+	return c.BankConnector.ListAccounts(ctx, customerID)
+}
+
+func (c racingConnector) GetBalance(ctx context.Context, customerID, accountID string) (*openbanking.Balance, error) {
+	c.checkUnlocked()
+	return c.BankConnector.GetBalance(ctx, customerID, accountID)
+}
+
+func (c racingConnector) ListTransactions(ctx context.Context, customerID, accountID, cursor string) (*openbanking.TransactionPage, error) {
+	c.checkUnlocked()
+	if c.race != nil {
+		c.race()
+	}
+	return c.BankConnector.ListTransactions(ctx, customerID, accountID, cursor)
+}
+
+func (c racingConnector) checkUnlocked() {
+	if *c.inTx {
+		c.t.Error("bank API called inside the sync transaction")
+	}
+}
+
+func TestBankSyncConcurrent(t *testing.T) {
+	mock := openbanking.NewMockServer()
+	defer mock.Close()
+	connectors, err := openbanking.NewConnectors(config.OpenBankingConfig{Mock: true}, mock.URL())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		race    func(c *models.BankConnection)
+		wantErr error
+	}{
+		{"no concurrent sync", nil, nil},
+		{"cursor moved", func(c *models.BankConnection) { c.Cursor = "1" }, apperrors.ErrConflict},
+		{"account chosen", func(c *models.BankConnection) { id := "acc"; c.ExternalAccountID = &id }, apperrors.ErrConflict},
+		{"disconnected", func(c *models.BankConnection) { c.Status = models.BankConnectionDisconnected }, apperrors.ErrConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBankSyncFixture(nil)
+			c := &models.BankConnection{UserID: 7, BankID: 1, CustomerID: "client-1"}
+			if err := f.connections.UpsertConnection(ctx, c); err != nil {
+				t.Fatal(err)
+			}
+
+			inTx := false
+			bank := racingConnector{BankConnector: connectors.For("TBANK"), t: t, inTx: &inTx}
+			if tt.race != nil {
+				bank.race = func() {
+					row := f.connections.rows[c.ConnectionID]
+					tt.race(&row)
+					f.connections.rows[c.ConnectionID] = row
+				}
+			}
+			svc := NewBankSyncService(f.connections, f.accounts, f.transactions, memBanks{},
+				NewLedgerService(f.ledger, passTxManager{}), trackingTx{open: &inTx},
+				openbanking.Connectors{"TBANK": bank})
+
+			_, err := svc.Sync(ctx, c.ConnectionID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Sync error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil {
+				if len(f.transactions.rows) == 0 || len(f.ledger.entries) != 1 {
+					t.Errorf("imported %d transactions with %d ledger entries", len(f.transactions.rows), len(f.ledger.entries))
+				}
+				return
+			}
+			// Проигравшая синхронизация ничего не записывает и не затирает состояние подключения
+			if len(f.transactions.rows) != 0 || len(f.ledger.entries) != 0 {
+				t.Errorf("conflicting sync imported %d transactions with %d ledger entries", len(f.transactions.rows), len(f.ledger.entries))
+			}
+			if got := f.connections.rows[c.ConnectionID]; got.LastError != nil {
+				t.Errorf("conflict recorded as last_error %q", *got.LastError)
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// buildQuote строит графики долей предложенного распределения и сводит их в расчёт;
+// каждая доля идёт по базовой ставке своего банка. fees — комиссии и страховка на всю сумму
+func buildQuote(proposal *models.SplitProposal, termMonths int, repaymentType string, start time.Time, fees creditFees) (*models.LoanQuote, error) {
+	q := &models.LoanQuote{
+		Amount:        proposal.Amount,
+		Currency:      proposal.Currency,
+		TypeCode:      proposal.TypeCode,
+		TermMonths:    termMonths,
+		RepaymentType: repaymentType,
+		InterestRate:  proposal.InterestRate,
+		Banks:         make([]models.BankQuote, 0, len(proposal.Splits)),
+	}
+
+	amount, err := parseMoney(proposal.Amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []models.LoanScheduleItem
+	var totalInterest, totalPrincipal int64
+	for _, sp := range proposal.Splits {
+		principal, err := parseMoney(sp.Amount)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := parseRate(sp.Rate)
+		if err != nil {
+			return nil, err
+		}
+		items, err := buildSchedule(scheduleParams{
+			Principal:     principal,
+			AnnualRate:    rate,
+			TermMonths:    termMonths,
+			Start:         start,
+			RepaymentType: repaymentType,
+			DayCount:      DayCountAct365,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("bank %d: %w", sp.BankID, err)
+		}
+
+		var interest int64
+		for _, it := range items {
+			k, err := parseMoney(it.Interest)
+			if err != nil {
+				return nil, err
+			}
+			interest += k
+		}
+		totalInterest += interest
+		totalPrincipal += principal
+		all = append(all, items...)
+		psk, _, err := creditCost(principal, items, fees.share(principal, amount))
+		if err != nil {
+			return nil, err
+		}
+
+		q.Banks = append(q.Banks, models.BankQuote{
+			BankID:         sp.BankID,
+			BankName:       sp.BankName,
+			Amount:         sp.Amount,
+			Share:          sp.Share,
+			Rate:           sp.Rate,
+			MonthlyPayment: items[0].TotalPayment,
+			TotalInterest:  formatMoney(interest),
+			TotalPayment:   formatMoney(principal + interest),
+			Psk:            psk,
+		})
+	}
+
+	combined, err := combineSchedules(all)
+	if err != nil {
+		return nil, err
+	}
+	payments := make([]int64, 0, len(combined))
+	for _, it := range combined {
+		k, err := parseMoney(it.TotalPayment)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, k)
+	}
+	if len(combined) > 0 {
+		q.MonthlyPayment = combined[0].TotalPayment
+	}
+	q.Schedule = combined
+	q.TotalInterest = formatMoney(totalInterest)
+	q.TotalPayment = formatMoney(totalPrincipal + totalInterest)
+	q.EffectiveRate = formatRate((math.Pow(1+monthlyIRR(totalPrincipal, payments), 12) - 1) * 100)
+	q.Fees = formatMoney(fees.Origination + fees.Monthly*int64(termMonths))
+	q.Insurance = formatMoney(fees.Insurance)
+	if q.Psk, q.PskAmount, err = creditCost(totalPrincipal, combined, fees); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// monthlyIRR — месячная ставка i, при которой сумма платежей payments[k],
+// дисконтированных на (1+i)^(k+1), равна principal. Ищется делением пополам
+func monthlyIRR(principal int64, payments []int64) float64 {
+	var total int64
+	for _, p := range payments {
+		total += p
+	}
+	if principal <= 0 || total <= principal {
+		return 0
+	}
+
+	pv := func(i float64) float64 {
+		var sum float64
+		d := 1.0
+		for _, p := range payments {
+			d /= 1 + i
+			sum += float64(p) * d
+		}
+		return sum
+	}
+	lo, hi := 0.0, 1.0
+	for pv(hi) > float64(principal) {
+		hi *= 2
+	}
+	for range 100 {
+		mid := (lo + hi) / 2
+		if pv(mid) > float64(principal) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
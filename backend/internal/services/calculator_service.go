@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// CalculatorService — публичный калькулятор: ориентировочный расчёт платежа,
+// переплаты и распределения между банками до регистрации заёмщика
+type CalculatorService interface {
+	Quote(ctx context.Context, req models.QuoteRequest) (*models.LoanQuote, error)
+}
+
+type calculatorServiceImpl struct {
+	distribution DistributionService
+	productRepo  repos.ProductRepository
+}
+
+func NewCalculatorService(distribution DistributionService, productRepo repos.ProductRepository) CalculatorService {
+	return &calculatorServiceImpl{
+		distribution: distribution,
+		productRepo:  productRepo,
+	}
+}
+
+func (s *calculatorServiceImpl) Quote(ctx context.Context, req models.QuoteRequest) (*models.LoanQuote, error) {
+	if req.RepaymentType == "" {
+		req.RepaymentType = RepaymentAnnuity
+	}
+	if req.RepaymentType != RepaymentAnnuity && req.RepaymentType != RepaymentDifferentiated {
+		return nil, fmt.Errorf("%w: unknown repayment type %q", apperrors.ErrBadRequest, req.RepaymentType)
+	}
+	amount, err := parseMoney(req.Amount)
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("%w: invalid amount %q", apperrors.ErrBadRequest, req.Amount)
+	}
+	req.Amount = formatMoney(amount)
+
+	var proposal *models.SplitProposal
+	var fees creditFees
+	if req.ProductID != nil {
+		product, err := loadProduct(ctx, s.productRepo, *req.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if !product.Active {
+			return nil, fmt.Errorf("%w: product %s is not available", apperrors.ErrBadRequest, product.Code)
+		}
+		if req.TermMonths == 0 {
+			req.TermMonths = min(max(defaultTermMonths, product.MinTermMonths), product.MaxTermMonths)
+		}
+		if err := checkProductTerms(product, req.Amount, req.TermMonths); err != nil {
+			return nil, err
+		}
+		proposal, err = s.distribution.ProposeProductSplits(ctx, req.Amount, product)
+		if err != nil {
+			return nil, err
+		}
+		if fees, err = productFees(product, amount); err != nil {
+			return nil, fmt.Errorf("invalid product fees: %w", err)
+		}
+	} else {
+		if req.TypeCode == "" {
+			req.TypeCode = models.LoanTypePersonal
+		}
+		if req.TermMonths == 0 {
+			req.TermMonths = defaultTermMonths
+		}
+		if req.TermMonths < 1 || req.TermMonths > maxTermMonths {
+			return nil, fmt.Errorf("%w: term must be between 1 and %d months", apperrors.ErrBadRequest, maxTermMonths)
+		}
+		proposal, err = s.distribution.ProposeSplits(ctx, req.Amount, req.TypeCode)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	quote, err := buildQuote(proposal, req.TermMonths, req.RepaymentType, dateOnly(time.Now()), fees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build quote: %w", err)
+	}
+	quote.ProductID = req.ProductID
+	return quote, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DayCountAct365 = "ACT_365"
+	DayCountActAct = "ACT_ACT"
+	DayCount30360  = "30_360"
+)
+
+func isValidDayCount(c string) bool {
+	switch c {
+	case DayCountAct365, DayCountActAct, DayCount30360:
+		return true
+	}
+	return false
+}
+
+// dateOnly отбрасывает время, оставляя календарную дату в UTC
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// dailyYearFraction — доля года, приходящаяся на один календарный день day
+// (период [day, day+1)) по выбранной конвенции.
+func dailyYearFraction(convention string, day time.Time) (float64, error) {
+	switch convention {
+	case DayCountAct365:
+		return 1.0 / 365, nil
+	case DayCountActAct:
+		return 1.0 / float64(daysInYear(day.Year())), nil
+	case DayCount30360:
+		return float64(days30360(day, day.AddDate(0, 0, 1))) / 360, nil
+	default:
+		return 0, fmt.Errorf("unknown day count convention %q", convention)
+	}
+}
+
+func daysInYear(y int) int {
+	if time.Date(y, time.December, 31, 0, 0, 0, 0, time.UTC).YearDay() == 366 {
+		return 366
+	}
+	return 365
+}
+
+// days30360 — число дней между датами по конвенции 30/360 (US / Bond basis).
+// Каждый месяц считается за 30 дней, поэтому 31-е число даёт 0, а 28 февраля → 1 марта — 3 дня.
+func days30360(from, to time.Time) int {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 == 30 {
+		d2 = 30
+	}
+	return 360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1)
+}
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestDailyYearFraction(t *testing.T) {
+	tests := []struct {
+		name       string
+		convention string
+		day        time.Time
+		want       float64
+		wantErr    bool
+	}{
+		{"ACT/365", DayCountAct365, date(2024, 7, 1), 1.0 / 365, false},
+		{"ACT/ACT leap year", DayCountActAct, date(2024, 7, 1), 1.0 / 366, false},
+		{"ACT/ACT common year", DayCountActAct, date(2025, 7, 1), 1.0 / 365, false},
+		{"30/360 mid-month", DayCount30360, date(2025, 1, 15), 1.0 / 360, false},
+		{"30/360 the 30th of a 31-day month", DayCount30360, date(2025, 1, 30), 0, false},
+		{"30/360 the 31st", DayCount30360, date(2025, 1, 31), 1.0 / 360, false},
+		{"30/360 end of February", DayCount30360, date(2025, 2, 28), 3.0 / 360, false},
+		{"unknown convention", "ACT_360", date(2025, 1, 1), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := dailyYearFraction(tt.convention, tt.day)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dailyYearFraction error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if math.Abs(got-tt.want) > 1e-12 {
+				t.Errorf("dailyYearFraction = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDays30360(t *testing.T) {
+	tests := []struct {
+		from, to time.Time
+		want     int
+	}{
+		{date(2025, 1, 1), date(2026, 1, 1), 360},
+		{date(2025, 1, 31), date(2025, 3, 31), 60},
+		{date(2025, 2, 28), date(2025, 3, 31), 33},
+		{date(2024, 2, 29), date(2024, 3, 1), 2},
+	}
+	for _, tt := range tests {
+		if got := days30360(tt.from, tt.to); got != tt.want {
+			t.Errorf("days30360(%s, %s) = %d, want %d", tt.from.Format(time.DateOnly), tt.to.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestDateOnly(t *testing.T) {
+	msk := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		in   time.Time
+		want time.Time
+	}{
+		{time.Date(2025, 3, 2, 15, 30, 0, 0, time.UTC), date(2025, 3, 2)},
+		{time.Date(2025, 3, 2, 1, 0, 0, 0, msk), date(2025, 3, 1)},
+		{time.Date(2025, 3, 2, 23, 59, 59, 0, msk), date(2025, 3, 2)},
+	}
+	for _, tt := range tests {
+		if got := dateOnly(tt.in); !got.Equal(tt.want) || got.Location() != time.UTC {
+			t.Errorf("dateOnly(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// debtTotals — задолженность в копейках (по кредиту или по банку)
+type debtTotals struct {
+	Original  int64
+	Principal int64
+	Interest  int64
+	Penalty   int64
+}
+
+func (t *debtTotals) add(o debtTotals) {
+	t.Original += o.Original
+	t.Principal += o.Principal
+	t.Interest += o.Interest
+	t.Penalty += o.Penalty
+}
+
+func (t debtTotals) outstanding() int64 {
+	return t.Principal + t.Interest + t.Penalty
+}
+
+// percentPaid — доля погашенного основного долга, %, с точностью до сотых
+func (t debtTotals) percentPaid() float64 {
+	if t.Original == 0 {
+		return 0
+	}
+	p := float64(t.Original-t.Principal) / float64(t.Original) * 100
+	return math.Round(p*100) / 100
+}
+
+func splitTotals(sp models.LoanSplit) (debtTotals, error) {
+	var t debtTotals
+	var err error
+	if t.Original, err = parseMoney(sp.SplitAmount); err != nil {
+		return t, err
+	}
+	if t.Principal, err = parseMoney(sp.RemainingPrincipal); err != nil {
+		return t, err
+	}
+	if t.Interest, err = parseMoney(sp.AccruedInterest); err != nil {
+		return t, err
+	}
+	if t.Penalty, err = parseMoney(sp.PenaltyInterest); err != nil {
+		return t, err
+	}
+	return t, nil
+}
+
+// nextDue считает ближайший платёж по кредиту на дату today: срок — ближайший из сроков долей,
+// сумма — всё, что нужно внести по этот срок включительно (с учётом просрочки и уже уплаченного).
+func (s *loanServiceImpl) nextDue(ctx context.Context, loan *models.Loan, splits []models.LoanSplit, today time.Time) (*models.NextDueDTO, error) {
+	if !isOpenLoan(loan.Status) {
+		return nil, nil
+	}
+
+	schedule, err := s.scheduleRepo.GetLoanSchedule(ctx, loan.LoanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan schedule: %w", err)
+	}
+	paid, err := s.paymentRepo.SumSplitPaid(ctx, loan.LoanID, models.PaymentTypeRegular)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum split payments: %w", err)
+	}
+
+	repayments := make([]splitRepayments, 0, len(splits))
+	var next time.Time
+	for _, sp := range splits {
+		r, err := newSplitRepayments(sp.SplitID, schedule, paid)
+		if err != nil {
+			return nil, err
+		}
+		repayments = append(repayments, r)
+		if d, ok := r.nextDueDate(today); ok && (next.IsZero() || d.Before(next)) {
+			next = d
+		}
+	}
+	// График исчерпан — к оплате только просрочка, срок уже наступил
+	if next.IsZero() {
+		next = dateOnly(today)
+	}
+
+	var amount int64
+	for _, r := range repayments {
+		due, err := r.dueThrough(next)
+		if err != nil {
+			return nil, err
+		}
+		amount += due
+	}
+	if amount == 0 {
+		return nil, nil
+	}
+	return &models.NextDueDTO{DueDate: next, Amount: formatMoney(amount)}, nil
+}
+
+// mergeNextDue выбирает более ранний из двух платежей, а при совпадении сроков складывает суммы
+func mergeNextDue(a, b *models.NextDueDTO) (*models.NextDueDTO, error) {
+	switch {
+	case a == nil || b.DueDate.Before(a.DueDate):
+		return b, nil
+	case a.DueDate.Before(b.DueDate):
+		return a, nil
+	}
+	x, err := parseMoney(a.Amount)
+	if err != nil {
+		return nil, err
+	}
+	y, err := parseMoney(b.Amount)
+	if err != nil {
+		return nil, err
+	}
+	return &models.NextDueDTO{DueDate: a.DueDate, Amount: formatMoney(x + y)}, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// defaultDecisionRules — набор правил, если DECISION_RULES_FILE не задан
+//
+//go:embed decision_rules.json
+var defaultDecisionRules []byte
+
+// parseRuleSet разбирает и проверяет набор правил; checksum считается по содержимому
+func parseRuleSet(data []byte) (*models.DecisionRuleSet, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	rs := &models.DecisionRuleSet{}
+	if err := dec.Decode(rs); err != nil {
+		return nil, fmt.Errorf("invalid rule set: %w", err)
+	}
+	if err := prepareRuleSet(rs); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+// prepareRuleSet проверяет правила и вычисляет checksum набора
+func prepareRuleSet(rs *models.DecisionRuleSet) error {
+	if rs.Version == "" {
+		return fmt.Errorf("rule set version is required")
+	}
+	if rs.Default == "" {
+		rs.Default = models.DecisionManualReview
+	}
+	if rs.Default != models.DecisionApprove && rs.Default != models.DecisionManualReview {
+		return fmt.Errorf("rule set default must be APPROVE or MANUAL_REVIEW, got %q", rs.Default)
+	}
+
+	seen := make(map[string]bool, len(rs.Rules))
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if r.ID == "" {
+			return fmt.Errorf("rule #%d: id is required", i+1)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("rule %s: duplicate id", r.ID)
+		}
+		seen[r.ID] = true
+		if err := validateRule(r); err != nil {
+			return fmt.Errorf("rule %s: %w", r.ID, err)
+		}
+	}
+
+	canonical, err := json.Marshal(struct {
+		Version string                `json:"version"`
+		Default string                `json:"default"`
+		Rules   []models.DecisionRule `json:"rules"`
+	}{rs.Version, rs.Default, rs.Rules})
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(canonical)
+	rs.Checksum = hex.EncodeToString(sum[:])
+	return nil
+}
+
+func validateRule(r *models.DecisionRule) error {
+	if r.Outcome != models.DecisionReject && r.Outcome != models.DecisionManualReview {
+		return fmt.Errorf("outcome must be REJECT or MANUAL_REVIEW, got %q", r.Outcome)
+	}
+	if r.TypeCode != "" && !validLoanType(r.TypeCode) {
+		return fmt.Errorf("unknown loan type %q", r.TypeCode)
+	}
+
+	switch r.Kind {
+	case models.RuleMinScore:
+		if r.MinScore < scoreMin || r.MinScore > scoreMax {
+			return fmt.Errorf("min_score must be within %d–%d", scoreMin, scoreMax)
+		}
+	case models.RuleMaxPDN:
+		if _, err := parseRate(r.MaxPDN); err != nil {
+			return fmt.Errorf("max_pdn: %w", err)
+		}
+	case models.RuleMaxAmount:
+		amount, err := parseMoney(r.MaxAmount)
+		if err != nil || amount <= 0 {
+			return fmt.Errorf("invalid max_amount %q", r.MaxAmount)
+		}
+	case models.RuleBankBlacklist:
+		if r.BankID <= 0 {
+			return fmt.Errorf("bank_id is required")
+		}
+		if len(r.UserIDs) == 0 {
+			return fmt.Errorf("user_ids are required")
+		}
+	default:
+		return fmt.Errorf("unknown kind %q", r.Kind)
+	}
+	return nil
+}
+
+// evaluateRules применяет правила к заявке. Итог — самый строгий среди сработавших
+// (REJECT > MANUAL_REVIEW), если не сработало ни одно — rs.Default.
+// Правило, которому не хватает данных (нет оценки, не подтверждён доход),
+// срабатывает не строже MANUAL_REVIEW. BANK_BLACKLIST исключает банк из распределения
+// (EXCLUDE_BANK) и влияет на итог, только если исключены все банки заявки
+func evaluateRules(rs *models.DecisionRuleSet, in models.DecisionInputs) (string, []models.FiredRule, error) {
+	amount, err := parseMoney(in.RequestedAmount)
+	if err != nil {
+		return "", nil, err
+	}
+
+	fired := []models.FiredRule{}
+	for _, r := range rs.Rules {
+		if r.TypeCode != "" && r.TypeCode != in.TypeCode {
+			continue
+		}
+
+		outcome := r.Outcome
+		var reason string
+		switch r.Kind {
+		case models.RuleMinScore:
+			switch {
+			case in.Score == nil:
+				outcome, reason = models.DecisionManualReview, "no credit score"
+			case *in.Score < r.MinScore:
+				reason = fmt.Sprintf("score %d is below %d", *in.Score, r.MinScore)
+			}
+		case models.RuleMaxPDN:
+			limit, _ := parseRate(r.MaxPDN)
+			switch {
+			case in.PDN == nil:
+				outcome, reason = models.DecisionManualReview, "debt-to-income ratio unknown: income not confirmed"
+			default:
+				pdn, err := parseRate(*in.PDN)
+				if err != nil {
+					return "", nil, err
+				}
+				if pdn > limit {
+					reason = fmt.Sprintf("debt-to-income ratio %.2f%% exceeds %.2f%%", pdn, limit)
+				}
+			}
+		case models.RuleMaxAmount:
+			limit, _ := parseMoney(r.MaxAmount)
+			if amount > limit {
+				reason = fmt.Sprintf("requested %s exceeds the %s cap of %s", formatMoney(amount), in.TypeCode, formatMoney(limit))
+			}
+		case models.RuleBankBlacklist:
+			if slices.Contains(in.BankIDs, r.BankID) && slices.Contains(r.UserIDs, in.UserID) {
+				reason = fmt.Sprintf("borrower is on the stop list of bank %d", r.BankID)
+			}
+		}
+		if reason == "" {
+			continue
+		}
+		if r.Description != "" {
+			reason += " (" + r.Description + ")"
+		}
+		fired = append(fired, models.FiredRule{RuleID: r.ID, Kind: r.Kind, Outcome: outcome, Reason: reason})
+		if r.Kind == models.RuleBankBlacklist {
+			fired[len(fired)-1].BankID = r.BankID
+		}
+	}
+
+	// Стоп-лист банка исключает только этот банк; решение по нему принимается,
+	// лишь когда у заявки не осталось ни одного банка
+	excluded := excludedBanks(fired)
+	if slices.ContainsFunc(in.BankIDs, func(b int16) bool { return !slices.Contains(excluded, b) }) {
+		for i := range fired {
+			if fired[i].Kind == models.RuleBankBlacklist {
+				fired[i].Outcome = models.DecisionExcludeBank
+			}
+		}
+	}
+
+	res := rs.Default
+	for _, f := range fired {
+		if f.Outcome == models.DecisionExcludeBank {
+			continue
+		}
+		if f.Outcome == models.DecisionReject {
+			return models.DecisionReject, fired, nil
+		}
+		res = models.DecisionManualReview
+	}
+	return res, fired, nil
+}
+
+// excludedBanks — банки, исключённые из распределения сработавшими BANK_BLACKLIST
+func excludedBanks(fired []models.FiredRule) []int16 {
+	var res []int16
+	for _, f := range fired {
+		if f.Kind == models.RuleBankBlacklist && !slices.Contains(res, f.BankID) {
+			res = append(res, f.BankID)
+		}
+	}
+	return res
+}
@@ -0,0 +1,154 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func TestParseRuleSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{"minimal", `{"version": "v1", "rules": []}`, false},
+		{"blacklist", `{"version": "v1", "rules": [{"id": "b", "kind": "BANK_BLACKLIST", "outcome": "REJECT", "bank_id": 2, "user_ids": [7]}]}`, false},
+		{"missing version", `{"rules": []}`, true},
+		{"reject by default", `{"version": "v1", "default": "REJECT", "rules": []}`, true},
+		{"unknown field", `{"version": "v1", "rules": [], "extra": 1}`, true},
+		{"rule without id", `{"version": "v1", "rules": [{"kind": "MIN_SCORE", "outcome": "REJECT", "min_score": 500}]}`, true},
+		{"duplicate id", `{"version": "v1", "rules": [
+			{"id": "a", "kind": "MIN_SCORE", "outcome": "REJECT", "min_score": 500},
+			{"id": "a", "kind": "MIN_SCORE", "outcome": "MANUAL_REVIEW", "min_score": 600}]}`, true},
+		{"approve outcome", `{"version": "v1", "rules": [{"id": "a", "kind": "MIN_SCORE", "outcome": "APPROVE", "min_score": 500}]}`, true},
+		{"score out of range", `{"version": "v1", "rules": [{"id": "a", "kind": "MIN_SCORE", "outcome": "REJECT", "min_score": 1000}]}`, true},
+		{"unknown loan type", `{"version": "v1", "rules": [{"id": "a", "kind": "MAX_AMOUNT", "outcome": "REJECT", "type_code": "BOAT", "max_amount": "1.00"}]}`, true},
+		{"zero max amount", `{"version": "v1", "rules": [{"id": "a", "kind": "MAX_AMOUNT", "outcome": "REJECT", "max_amount": "0"}]}`, true},
+		{"blacklist without users", `{"version": "v1", "rules": [{"id": "a", "kind": "BANK_BLACKLIST", "outcome": "REJECT", "bank_id": 2}]}`, true},
+		{"unknown kind", `{"version": "v1", "rules": [{"id": "a", "kind": "MAX_AGE", "outcome": "REJECT"}]}`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := parseRuleSet([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRuleSet error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if rs.Default != models.DecisionManualReview {
+				t.Errorf("default = %s, want %s", rs.Default, models.DecisionManualReview)
+			}
+			if len(rs.Checksum) != 64 {
+				t.Errorf("checksum = %q", rs.Checksum)
+			}
+		})
+	}
+}
+
+func firedRuleIDs(fired []models.FiredRule) []string {
+	ids := []string{}
+	for _, f := range fired {
+		ids = append(ids, f.RuleID)
+	}
+	return ids
+}
+
+func TestEvaluateRules(t *testing.T) {
+	rs, err := parseRuleSet(defaultDecisionRules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	score := func(v int) *int { return &v }
+	pdn := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		in      models.DecisionInputs
+		outcome string
+		fired   []string
+	}{
+		{"good borrower", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(700), PDN: pdn("30")},
+			models.DecisionApprove, []string{}},
+		{"grade D", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(550), PDN: pdn("30")},
+			models.DecisionManualReview, []string{"score-review"}},
+		{"grade E", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(500), PDN: pdn("30")},
+			models.DecisionReject, []string{"score-floor", "score-review"}},
+		{"no score is never rejected", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", PDN: pdn("30")},
+			models.DecisionManualReview, []string{"score-floor", "score-review"}},
+		{"income not confirmed", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(700)},
+			models.DecisionManualReview, []string{"pdn-review"}},
+		{"debt burden too high", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(700), PDN: pdn("50.01")},
+			models.DecisionManualReview, []string{"pdn-review"}},
+		{"debt burden at the limit", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "500000.00", Score: score(700), PDN: pdn("50")},
+			models.DecisionApprove, []string{}},
+		{"amount above the type cap", models.DecisionInputs{TypeCode: models.LoanTypePersonal, RequestedAmount: "1000000.01", Score: score(700), PDN: pdn("30")},
+			models.DecisionManualReview, []string{"personal-cap"}},
+		{"cap of another type", models.DecisionInputs{TypeCode: models.LoanTypeAuto, RequestedAmount: "1500000.00", Score: score(700), PDN: pdn("30")},
+			models.DecisionApprove, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, fired, err := evaluateRules(rs, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome != tt.outcome {
+				t.Errorf("outcome = %s, want %s", outcome, tt.outcome)
+			}
+			if got := firedRuleIDs(fired); !reflect.DeepEqual(got, tt.fired) {
+				t.Errorf("fired = %v, want %v", got, tt.fired)
+			}
+		})
+	}
+}
+
+func TestEvaluateRulesBankBlacklist(t *testing.T) {
+	rs := &models.DecisionRuleSet{
+		Version: "test",
+		Default: models.DecisionApprove,
+		Rules: []models.DecisionRule{
+			{ID: "bank-1-stop", Kind: models.RuleBankBlacklist, Outcome: models.DecisionReject, BankID: 1, UserIDs: []int64{42}},
+			{ID: "bank-2-stop", Kind: models.RuleBankBlacklist, Outcome: models.DecisionReject, BankID: 2, UserIDs: []int64{42}},
+		},
+	}
+	if err := prepareRuleSet(rs); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   int64
+		banks    []int16
+		outcome  string
+		excluded []int16
+	}{
+		{"not on stop list", 7, []int16{1, 2, 3}, models.DecisionApprove, nil},
+		{"one bank excluded", 42, []int16{1, 3}, models.DecisionApprove, []int16{1}},
+		{"two banks excluded, one remains", 42, []int16{1, 2, 3}, models.DecisionApprove, []int16{1, 2}},
+		{"blacklisted bank is not a participant", 42, []int16{3}, models.DecisionApprove, nil},
+		{"no bank remains", 42, []int16{1, 2}, models.DecisionReject, []int16{1, 2}},
+		{"single blacklisted bank", 42, []int16{2}, models.DecisionReject, []int16{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome, fired, err := evaluateRules(rs, models.DecisionInputs{
+				UserID:          tt.userID,
+				TypeCode:        models.LoanTypePersonal,
+				RequestedAmount: "100000.00",
+				BankIDs:         tt.banks,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if outcome != tt.outcome {
+				t.Errorf("outcome = %s, want %s", outcome, tt.outcome)
+			}
+			if got := excludedBanks(fired); !reflect.DeepEqual(got, tt.excluded) {
+				t.Errorf("excludedBanks = %v, want %v", got, tt.excluded)
+			}
+		})
+	}
+}
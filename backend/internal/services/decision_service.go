@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+type DecisionService interface {
+	// Evaluate принимает решение по заявке по действующему набору правил; решение не сохраняется
+	Evaluate(ctx context.Context, appID int64, in models.DecisionInputs) (*models.ApplicationDecision, error)
+	RecordDecision(ctx context.Context, d *models.ApplicationDecision) error
+	// Enforcing — применяются ли решения к заявкам (DECISION_ENFORCE)
+	Enforcing() bool
+	// RuleSet — действующий набор правил
+	RuleSet() (*models.DecisionRuleSet, error)
+	// ReloadRules перечитывает файл правил и регистрирует версию набора
+	ReloadRules(ctx context.Context) (*models.DecisionRuleSet, error)
+	// ApplicationDecisions — последнее решение по заявкам: application_id → решение
+	ApplicationDecisions(ctx context.Context, appIDs ...int64) (map[int64]*models.ApplicationDecision, error)
+	DecisionHistory(ctx context.Context, appID int64) ([]models.ApplicationDecision, error)
+	// DryRun прогоняет набор правил rules (nil — действующий) по заявкам, отправленным
+	// за [from, to), и сравнивает с принятыми решениями; ничего не сохраняет
+	DryRun(ctx context.Context, rules *models.DecisionRuleSet, from, to time.Time, limit int) (*models.DryRunReport, error)
+}
+
+type decisionServiceImpl struct {
+	repo  repos.DecisionRepository
+	cfg   config.DecisionConfig
+	rules atomic.Pointer[models.DecisionRuleSet]
+}
+
+func NewDecisionService(repo repos.DecisionRepository, cfg config.DecisionConfig) DecisionService {
+	return &decisionServiceImpl{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+func (s *decisionServiceImpl) Evaluate(ctx context.Context, appID int64, in models.DecisionInputs) (*models.ApplicationDecision, error) {
+	rs, err := s.RuleSet()
+	if err != nil {
+		return nil, err
+	}
+	outcome, fired, err := evaluateRules(rs, in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate rules: %w", err)
+	}
+	return &models.ApplicationDecision{
+		ApplicationID: appID,
+		RuleSetID:     rs.RuleSetID,
+		RuleVersion:   rs.Version,
+		Outcome:       outcome,
+		FiredRules:    fired,
+		Inputs:        in,
+	}, nil
+}
+
+func (s *decisionServiceImpl) RecordDecision(ctx context.Context, d *models.ApplicationDecision) error {
+	if err := s.repo.CreateDecision(ctx, d); err != nil {
+		return fmt.Errorf("failed to save decision: %w", err)
+	}
+	return nil
+}
+
+func (s *decisionServiceImpl) Enforcing() bool {
+	return s.cfg.Enforce
+}
+
+func (s *decisionServiceImpl) RuleSet() (*models.DecisionRuleSet, error) {
+	rs := s.rules.Load()
+	if rs == nil {
+		return nil, fmt.Errorf("decision rules are not loaded")
+	}
+	return rs, nil
+}
+
+func (s *decisionServiceImpl) ReloadRules(ctx context.Context) (*models.DecisionRuleSet, error) {
+	data := defaultDecisionRules
+	if s.cfg.RulesFile != "" {
+		var err error
+		data, err = os.ReadFile(s.cfg.RulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read decision rules: %w", err)
+		}
+	}
+	rs, err := parseRuleSet(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	if err := s.repo.SaveRuleSet(ctx, rs); err != nil {
+		return nil, fmt.Errorf("failed to save rule set: %w", err)
+	}
+	s.rules.Store(rs)
+	return rs, nil
+}
+
+func (s *decisionServiceImpl) ApplicationDecisions(ctx context.Context, appIDs ...int64) (map[int64]*models.ApplicationDecision, error) {
+	items, err := s.repo.ListLatestDecisions(ctx, appIDs...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]*models.ApplicationDecision, len(items))
+	for i := range items {
+		res[items[i].ApplicationID] = &items[i]
+	}
+	return res, nil
+}
+
+func (s *decisionServiceImpl) DecisionHistory(ctx context.Context, appID int64) ([]models.ApplicationDecision, error) {
+	return s.repo.ListDecisions(ctx, appID)
+}
+
+func (s *decisionServiceImpl) DryRun(ctx context.Context, rules *models.DecisionRuleSet, from, to time.Time, limit int) (*models.DryRunReport, error) {
+	if rules == nil {
+		current, err := s.RuleSet()
+		if err != nil {
+			return nil, err
+		}
+		rules = current
+	} else if err := prepareRuleSet(rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: from must be before to", apperrors.ErrBadRequest)
+	}
+
+	history, err := s.repo.ListHistory(ctx, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load applications: %w", err)
+	}
+
+	report := &models.DryRunReport{
+		RuleVersion: rules.Version,
+		Outcomes:    map[string]int{},
+		Results:     history,
+	}
+	for i := range report.Results {
+		r := &report.Results[i]
+		r.Outcome, r.FiredRules, err = evaluateRules(rules, r.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate application %d: %w", r.ApplicationID, err)
+		}
+		r.Changed = r.PreviousOutcome != nil && *r.PreviousOutcome != r.Outcome
+		report.Outcomes[r.Outcome]++
+		if r.Changed {
+			report.Changed++
+		}
+	}
+	report.Evaluated = len(report.Results)
+	return report, nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// openLoanStatuses — статусы непогашенного кредита: по ним начисляются проценты и принимаются платежи
+var openLoanStatuses = []string{models.LoanStatusActive, models.LoanStatusOverdue, models.LoanStatusDefaulted}
+
+func isOpenLoan(status string) bool {
+	for _, s := range openLoanStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// dpdBucket относит число дней просрочки к корзине
+func dpdBucket(days int) string {
+	switch {
+	case days <= 0:
+		return models.DPDBucketCurrent
+	case days <= 30:
+		return models.DPDBucket1To30
+	case days <= 60:
+		return models.DPDBucket31To60
+	case days <= 90:
+		return models.DPDBucket61To90
+	default:
+		return models.DPDBucket90Plus
+	}
+}
+
+// bucketRange — границы корзины в днях (включительно)
+func bucketRange(bucket string) (int, int, bool) {
+	switch bucket {
+	case models.DPDBucket1To30:
+		return 1, 30, true
+	case models.DPDBucket31To60:
+		return 31, 60, true
+	case models.DPDBucket61To90:
+		return 61, 90, true
+	case models.DPDBucket90Plus:
+		return 91, maxDaysPastDue, true
+	}
+	return 0, 0, false
+}
+
+const maxDaysPastDue = 1 << 30
+
+// splitArrears считает просрочку доли на дату day: плановые платежи со сроком раньше day
+// покрываются уплаченной суммой paid по порядку, первый непокрытый задаёт дни просрочки.
+// Возвращает просроченную сумму и дни просрочки (0, если просрочки нет).
+func splitArrears(items []models.LoanScheduleItem, paid int64, day time.Time) (int64, int, error) {
+	day = dateOnly(day)
+
+	var due int64
+	var since time.Time
+	for _, it := range items {
+		dueDate := dateOnly(it.DueDate)
+		if !dueDate.Before(day) {
+			break
+		}
+		total, err := installmentDue(it)
+		if err != nil {
+			return 0, 0, err
+		}
+		due += total
+		if due > paid && since.IsZero() {
+			since = dueDate
+		}
+	}
+
+	if due <= paid {
+		return 0, 0, nil
+	}
+	return due - paid, int(day.Sub(since).Hours() / 24), nil
+}
+
+// installmentDue — сумма планового платежа к уплате: основной долг и проценты плюс плата за обслуживание
+func installmentDue(it models.LoanScheduleItem) (int64, error) {
+	total, err := parseMoney(it.TotalPayment)
+	if err != nil {
+		return 0, err
+	}
+	fee, err := parseOptionalMoney(it.Fee)
+	if err != nil {
+		return 0, err
+	}
+	return total + fee, nil
+}
+
+// splitFeesDue — неуплаченная плата за обслуживание доли splitID по платежам со сроком по day включительно
+func splitFeesDue(splitID int64, schedule []models.LoanScheduleItem, feesPaid map[int64]string, day time.Time) (int64, error) {
+	day = dateOnly(day)
+	var due int64
+	for _, it := range schedule {
+		if it.SplitID != splitID || dateOnly(it.DueDate).After(day) {
+			continue
+		}
+		fee, err := parseOptionalMoney(it.Fee)
+		if err != nil {
+			return 0, err
+		}
+		due += fee
+	}
+	paid, err := parseOptionalMoney(feesPaid[splitID])
+	if err != nil {
+		return 0, err
+	}
+	return max(due-paid, 0), nil
+}
+
+// splitRepayments — график доли и сумма, уплаченная по нему плановыми платежами
+type splitRepayments struct {
+	items []models.LoanScheduleItem
+	paid  int64
+}
+
+// newSplitRepayments выбирает из графика кредита платежи доли splitID
+func newSplitRepayments(splitID int64, schedule []models.LoanScheduleItem, paid map[int64]string) (splitRepayments, error) {
+	r := splitRepayments{}
+	for _, it := range schedule {
+		if it.SplitID == splitID {
+			r.items = append(r.items, it)
+		}
+	}
+	if v, ok := paid[splitID]; ok {
+		p, err := parseMoney(v)
+		if err != nil {
+			return r, err
+		}
+		r.paid = p
+	}
+	return r, nil
+}
+
+func (r splitRepayments) arrears(day time.Time) (int64, int, error) {
+	return splitArrears(r.items, r.paid, day)
+}
+
+// dueThrough — сколько нужно уплатить по доле, чтобы закрыть все платежи со сроком по day включительно
+func (r splitRepayments) dueThrough(day time.Time) (int64, error) {
+	day = dateOnly(day)
+	var due int64
+	for _, it := range r.items {
+		if dateOnly(it.DueDate).After(day) {
+			break
+		}
+		total, err := installmentDue(it)
+		if err != nil {
+			return 0, err
+		}
+		due += total
+	}
+	return max(due-r.paid, 0), nil
+}
+
+// scheduledDueBySplit — неуплаченные плановые платежи каждой доли со сроком по day включительно;
+// доли без таких платежей в результат не входят
+func scheduledDueBySplit(schedule []models.LoanScheduleItem, paid map[int64]string, day time.Time) (map[int64]int64, error) {
+	due := make(map[int64]int64)
+	for _, it := range schedule {
+		if _, ok := due[it.SplitID]; ok {
+			continue
+		}
+		r, err := newSplitRepayments(it.SplitID, schedule, paid)
+		if err != nil {
+			return nil, err
+		}
+		d, err := r.dueThrough(day)
+		if err != nil {
+			return nil, err
+		}
+		due[it.SplitID] = d
+	}
+	for id, d := range due {
+		if d == 0 {
+			delete(due, id)
+		}
+	}
+	return due, nil
+}
+
+// nextDueDate — срок ближайшего платежа доли не раньше day; false, если график исчерпан
+func (r splitRepayments) nextDueDate(day time.Time) (time.Time, bool) {
+	day = dateOnly(day)
+	for _, it := range r.items {
+		if d := dateOnly(it.DueDate); !d.Before(day) {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// loanDelinquency собирает просрочку кредита из сохранённых значений долей
+func loanDelinquency(splits []models.LoanSplit) (*models.LoanDelinquency, error) {
+	res := &models.LoanDelinquency{Splits: make([]models.SplitDelinquency, 0, len(splits))}
+
+	var overdue, penalty int64
+	for _, sp := range splits {
+		o, err := parseMoney(sp.OverdueAmount)
+		if err != nil {
+			return nil, err
+		}
+		p, err := parseMoney(sp.PenaltyInterest)
+		if err != nil {
+			return nil, err
+		}
+		overdue += o
+		penalty += p
+		res.DaysPastDue = max(res.DaysPastDue, sp.DaysPastDue)
+
+		res.Splits = append(res.Splits, models.SplitDelinquency{
+			SplitID:         sp.SplitID,
+			BankID:          sp.BankID,
+			DaysPastDue:     sp.DaysPastDue,
+			Bucket:          dpdBucket(sp.DaysPastDue),
+			OverdueAmount:   formatMoney(o),
+			PenaltyInterest: formatMoney(p),
+		})
+	}
+
+	res.Bucket = dpdBucket(res.DaysPastDue)
+	res.OverdueAmount = formatMoney(overdue)
+	res.PenaltyInterest = formatMoney(penalty)
+	return res, nil
+}
+
+// nextLoanStatus — статус кредита по текущей просрочке. Закрытый кредит не меняется.
+func nextLoanStatus(current string, daysPastDue, defaultAfterDays int) string {
+	switch {
+	case current == models.LoanStatusClosed:
+		return current
+	case daysPastDue > defaultAfterDays:
+		return models.LoanStatusDefaulted
+	case daysPastDue > 0:
+		// Дефолт снимается только полным погашением просрочки
+		if current == models.LoanStatusDefaulted {
+			return current
+		}
+		return models.LoanStatusOverdue
+	default:
+		return models.LoanStatusActive
+	}
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+type DelinquencyService interface {
+	// Refresh пересчитывает просрочку долей на дату asOf и переводит кредит
+	// ACTIVE → OVERDUE → DEFAULTED или обратно в ACTIVE при погашении просрочки
+	Refresh(ctx context.Context, loanID int64, asOf time.Time) (*models.LoanDelinquency, error)
+	// RefreshAll пересчитывает просрочку по всем непогашенным кредитам, возвращает число обработанных
+	RefreshAll(ctx context.Context, asOf time.Time) (int, error)
+	// ListBankDelinquencies — просроченные доли банка; bucket пустой — все корзины
+	ListBankDelinquencies(ctx context.Context, bankID int16, bucket string) ([]models.BankDelinquencyItem, error)
+}
+
+type delinquencyServiceImpl struct {
+	loanRepo     repos.LoanRepository
+	scheduleRepo repos.LoanScheduleRepository
+	paymentRepo  repos.LoanPaymentRepository
+	txManager    repos.TxManager
+	status       StatusService
+	cfg          config.LoanConfig
+}
+
+func NewDelinquencyService(
+	loanRepo repos.LoanRepository,
+	scheduleRepo repos.LoanScheduleRepository,
+	paymentRepo repos.LoanPaymentRepository,
+	txManager repos.TxManager,
+	status StatusService,
+	cfg config.LoanConfig,
+) DelinquencyService {
+	return &delinquencyServiceImpl{
+		loanRepo:     loanRepo,
+		scheduleRepo: scheduleRepo,
+		paymentRepo:  paymentRepo,
+		txManager:    txManager,
+		status:       status,
+		cfg:          cfg,
+	}
+}
+
+func (s *delinquencyServiceImpl) Refresh(ctx context.Context, loanID int64, asOf time.Time) (*models.LoanDelinquency, error) {
+	var res *models.LoanDelinquency
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to get loan: %w", err)
+		}
+
+		splits, err := s.loanRepo.GetLoanSplitsForUpdate(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to lock loan splits: %w", err)
+		}
+		schedule, err := s.scheduleRepo.GetLoanSchedule(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to get loan schedule: %w", err)
+		}
+		paid, err := s.paymentRepo.SumSplitPaid(ctx, loanID, models.PaymentTypeRegular)
+		if err != nil {
+			return fmt.Errorf("failed to sum split payments: %w", err)
+		}
+
+		for i := range splits {
+			sp := &splits[i]
+			repayments, err := newSplitRepayments(sp.SplitID, schedule, paid)
+			if err != nil {
+				return err
+			}
+			overdue, dpd, err := repayments.arrears(asOf)
+			if err != nil {
+				return err
+			}
+
+			sp.DaysPastDue = dpd
+			sp.OverdueAmount = formatMoney(overdue)
+			if err := s.loanRepo.UpdateLoanSplitDelinquency(ctx, sp.SplitID, dpd, sp.OverdueAmount); err != nil {
+				return fmt.Errorf("failed to update split delinquency: %w", err)
+			}
+		}
+
+		res, err = loanDelinquency(splits)
+		if err != nil {
+			return err
+		}
+
+		status := nextLoanStatus(loan.Status, res.DaysPastDue, s.cfg.DefaultAfterDays)
+		if status != loan.Status {
+			reason := fmt.Sprintf("%d days past due", res.DaysPastDue)
+			if err := s.status.TransitionLoan(ctx, loanID, status, nil, reason); err != nil {
+				return fmt.Errorf("failed to update loan status: %w", err)
+			}
+			logger.Log.Infof("Loan %d status %s -> %s (%d days past due)", loanID, loan.Status, status, res.DaysPastDue)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *delinquencyServiceImpl) RefreshAll(ctx context.Context, asOf time.Time) (int, error) {
+	loanIDs, err := s.loanRepo.ListLoanIDsByStatus(ctx, openLoanStatuses...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list open loans: %w", err)
+	}
+
+	processed := 0
+	for _, id := range loanIDs {
+		if _, err := s.Refresh(ctx, id, asOf); err != nil {
+			logger.Log.Errorf("Failed to refresh delinquency for loan %d: %v", id, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+func (s *delinquencyServiceImpl) ListBankDelinquencies(ctx context.Context, bankID int16, bucket string) ([]models.BankDelinquencyItem, error) {
+	minDPD, maxDPD := 1, maxDaysPastDue
+	if bucket != "" {
+		var ok bool
+		minDPD, maxDPD, ok = bucketRange(bucket)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown DPD bucket %q", apperrors.ErrBadRequest, bucket)
+		}
+	}
+
+	items, err := s.loanRepo.ListBankDelinquencies(ctx, bankID, minDPD, maxDPD)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bank delinquencies: %w", err)
+	}
+	for i := range items {
+		items[i].Bucket = dpdBucket(items[i].DaysPastDue)
+	}
+	return items, nil
+}
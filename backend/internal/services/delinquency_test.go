@@ -0,0 +1,170 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func scheduleItem(splitID int64, no int, due time.Time, total, fee string) models.LoanScheduleItem {
+	return models.LoanScheduleItem{SplitID: splitID, InstallmentNo: no, DueDate: due, TotalPayment: total, Fee: fee}
+}
+
+func TestSplitArrears(t *testing.T) {
+	items := []models.LoanScheduleItem{
+		scheduleItem(1, 1, date(2026, 2, 10), "1000.00", "50.00"),
+		scheduleItem(1, 2, date(2026, 3, 10), "1000.00", "50.00"),
+	}
+	tests := []struct {
+		name    string
+		paid    int64
+		day     time.Time
+		overdue int64
+		dpd     int
+	}{
+		{"before first due date", 0, date(2026, 2, 9), 0, 0},
+		{"on due date", 0, date(2026, 2, 10), 0, 0},
+		{"day after due date", 0, date(2026, 2, 11), 105000, 1},
+		{"fee unpaid", 100000, date(2026, 2, 11), 5000, 1},
+		{"partially paid first", 40000, date(2026, 2, 20), 65000, 10},
+		{"first installment paid", 105000, date(2026, 3, 1), 0, 0},
+		{"partially paid second", 150000, date(2026, 3, 15), 60000, 5},
+		{"both overdue", 0, date(2026, 3, 11), 210000, 29},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overdue, dpd, err := splitArrears(items, tt.paid, tt.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if overdue != tt.overdue || dpd != tt.dpd {
+				t.Errorf("splitArrears = (%d, %d), want (%d, %d)", overdue, dpd, tt.overdue, tt.dpd)
+			}
+		})
+	}
+}
+
+func TestSplitFeesDue(t *testing.T) {
+	schedule := []models.LoanScheduleItem{
+		scheduleItem(1, 1, date(2026, 2, 10), "1000.00", "50.00"),
+		scheduleItem(1, 2, date(2026, 3, 10), "1000.00", "50.00"),
+		scheduleItem(2, 1, date(2026, 2, 10), "500.00", ""),
+	}
+	tests := []struct {
+		name     string
+		splitID  int64
+		feesPaid map[int64]string
+		day      time.Time
+		want     int64
+	}{
+		{"before first due date", 1, nil, date(2026, 2, 9), 0},
+		{"due on the payment date", 1, nil, date(2026, 2, 10), 5000},
+		{"two fees due", 1, nil, date(2026, 3, 10), 10000},
+		{"first fee paid", 1, map[int64]string{1: "50.00"}, date(2026, 3, 10), 5000},
+		{"overpaid", 1, map[int64]string{1: "150.00"}, date(2026, 3, 10), 0},
+		{"split without fee", 2, nil, date(2026, 3, 10), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitFeesDue(tt.splitID, schedule, tt.feesPaid, tt.day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("splitFeesDue = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDPDBucket(t *testing.T) {
+	tests := []struct {
+		days int
+		want string
+	}{
+		{0, models.DPDBucketCurrent},
+		{1, models.DPDBucket1To30},
+		{30, models.DPDBucket1To30},
+		{31, models.DPDBucket31To60},
+		{61, models.DPDBucket61To90},
+		{90, models.DPDBucket61To90},
+		{91, models.DPDBucket90Plus},
+	}
+	for _, tt := range tests {
+		if got := dpdBucket(tt.days); got != tt.want {
+			t.Errorf("dpdBucket(%d) = %s, want %s", tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestNextLoanStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		dpd     int
+		want    string
+	}{
+		{"current loan", models.LoanStatusActive, 0, models.LoanStatusActive},
+		{"first day overdue", models.LoanStatusActive, 1, models.LoanStatusOverdue},
+		{"arrears repaid", models.LoanStatusOverdue, 0, models.LoanStatusActive},
+		{"past default threshold", models.LoanStatusOverdue, 91, models.LoanStatusDefaulted},
+		{"default stays while overdue", models.LoanStatusDefaulted, 10, models.LoanStatusDefaulted},
+		{"default lifted by full repayment", models.LoanStatusDefaulted, 0, models.LoanStatusActive},
+		{"closed loan", models.LoanStatusClosed, 100, models.LoanStatusClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextLoanStatus(tt.current, tt.dpd, 90); got != tt.want {
+				t.Errorf("nextLoanStatus = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// Досрочное погашение в дату платежа: плановая часть, зачтённая обычным платежом, не оставляет просрочки
+func TestPrepaymentOnDueDateCoversInstallment(t *testing.T) {
+	dueDay := date(2026, 2, 10)
+	schedule := []models.LoanScheduleItem{
+		scheduleItem(1, 1, dueDay, "1000.00", "10.00"),
+		scheduleItem(1, 2, date(2026, 3, 10), "1000.00", "10.00"),
+		scheduleItem(2, 1, dueDay, "500.00", ""),
+		scheduleItem(2, 2, date(2026, 3, 10), "500.00", ""),
+	}
+
+	due, err := scheduledDueBySplit(schedule, map[int64]string{2: "200.00"}, dueDay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if due[1] != 101000 || due[2] != 30000 {
+		t.Fatalf("scheduledDueBySplit = %v, want map[1:101000 2:30000]", due)
+	}
+
+	dues := []splitDue{
+		{Split: models.LoanSplit{SplitID: 1}, Principal: 500000, Interest: 8000, Fee: 1000},
+		{Split: models.LoanSplit{SplitID: 2}, Principal: 250000, Interest: 4000},
+	}
+	parts, err := allocateScheduled(due[1]+due[2], dues, due)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parts[0] != due[1] || parts[1] != due[2] {
+		t.Fatalf("allocateScheduled = %v, want [%d %d]", parts, due[1], due[2])
+	}
+
+	// Обычный платёж засчитывается в график: на следующий день просрочки нет
+	paid := map[int64]int64{1: parts[0], 2: 20000 + parts[1]}
+	for _, splitID := range []int64{1, 2} {
+		r, err := newSplitRepayments(splitID, schedule, map[int64]string{splitID: formatMoney(paid[splitID])})
+		if err != nil {
+			t.Fatal(err)
+		}
+		overdue, dpd, err := r.arrears(dueDay.AddDate(0, 0, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if overdue != 0 || dpd != 0 {
+			t.Errorf("split %d: arrears = (%d, %d), want none", splitID, overdue, dpd)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// DisbursementService зачисляет доли кредита на счета заёмщика в банках долей.
+// Каждая доля зачисляется независимо: неудача одной не откатывает остальные,
+// а помечает долю FAILED с причиной — такие доли можно зачислить повторно
+type DisbursementService interface {
+	// DisburseSplits зачисляет незачисленные доли из splits; статусы обновляются на месте
+	DisburseSplits(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error
+	// DisburseLoan повторяет зачисление долей кредита в статусе PENDING и FAILED
+	DisburseLoan(ctx context.Context, loanID int64) (*models.DisbursementDTO, error)
+	// RetryAll повторяет зачисление по всем кредитам с незачисленными долями,
+	// кроме долей, исчерпавших maxDisbursementAttempts; возвращает число обработанных кредитов
+	RetryAll(ctx context.Context) (int, error)
+}
+
+// maxDisbursementAttempts — после стольких неудач доля зачисляется только вручную
+const maxDisbursementAttempts = 5
+
+type disbursementServiceImpl struct {
+	loanRepo        repos.LoanRepository
+	accountRepo     repos.UserBankAccountRepository
+	transactionRepo repos.TransactionRepository
+	ledger          LedgerService
+	txManager       repos.TxManager
+}
+
+func NewDisbursementService(
+	loanRepo repos.LoanRepository,
+	accountRepo repos.UserBankAccountRepository,
+	transactionRepo repos.TransactionRepository,
+	ledger LedgerService,
+	txManager repos.TxManager,
+) DisbursementService {
+	return &disbursementServiceImpl{
+		loanRepo:        loanRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		ledger:          ledger,
+		txManager:       txManager,
+	}
+}
+
+func (s *disbursementServiceImpl) DisburseSplits(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error {
+	return s.disburseSplits(ctx, loan, splits, 0)
+}
+
+// disburseSplits зачисляет незачисленные доли; при maxAttempts > 0 доли, исчерпавшие попытки, пропускаются
+func (s *disbursementServiceImpl) disburseSplits(ctx context.Context, loan *models.Loan, splits []models.LoanSplit, maxAttempts int) error {
+	// Комиссия и страховка удерживаются при выдаче: заёмщик получает долю за их вычетом
+	payouts, err := splitPayouts(loan, splits)
+	if err != nil {
+		return fmt.Errorf("failed to calculate payouts: %w", err)
+	}
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range splits {
+			split := &splits[i]
+			if split.DisbursementStatus == models.DisbursementDisbursed {
+				continue
+			}
+			if maxAttempts > 0 && split.DisbursementAttempts >= maxAttempts {
+				continue
+			}
+
+			err := s.txManager.WithinSavepoint(ctx, func(ctx context.Context) error {
+				return s.disburse(ctx, loan, split, payouts[i])
+			})
+			if err == nil {
+				continue
+			}
+
+			logger.Log.Warnf("Failed to disburse split %d of loan %d: %v", split.SplitID, loan.LoanID, err)
+			reason := err.Error()
+			split.DisbursementStatus = models.DisbursementFailed
+			split.DisbursementError = &reason
+			split.DisbursedAt, split.AccountID, split.TransactionID = nil, nil, nil
+			if err := s.loanRepo.UpdateLoanSplitDisbursement(ctx, split); err != nil {
+				return fmt.Errorf("failed to record disbursement failure: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// disburse зачисляет долю (payout — за вычетом удержаний, копейки) на счёт заёмщика в её банке,
+// открывая счёт при отсутствии: транзакция в выписке, проводка в главной книге и статус доли
+func (s *disbursementServiceImpl) disburse(ctx context.Context, loan *models.Loan, split *models.LoanSplit, payout int64) error {
+	if payout <= 0 {
+		return fmt.Errorf("fees withheld from split %d exceed its amount", split.SplitID)
+	}
+	account, err := s.accountRepo.EnsureUserBankAccount(ctx, loan.UserID, split.BankID, models.CurrencyRUB)
+	if err != nil {
+		return fmt.Errorf("failed to open account in bank %d: %w", split.BankID, err)
+	}
+	if account.Currency != models.CurrencyRUB {
+		return fmt.Errorf("account %d in bank %d is in %s, loan is issued in %s", account.AccountID, split.BankID, account.Currency, models.CurrencyRUB)
+	}
+
+	now := time.Now()
+	t := &models.Transaction{
+		UserID:      loan.UserID,
+		BankID:      split.BankID,
+		OccurredAt:  now,
+		Amount:      formatMoney(payout),
+		Category:    models.TransactionCategoryLoanDisbursement,
+		Description: fmt.Sprintf("Зачисление кредита %d", loan.LoanID),
+	}
+	if err := s.transactionRepo.CreateTransaction(ctx, t); err != nil {
+		return fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if err := s.ledger.RecordPayout(ctx, loan, split, account, formatMoney(payout)); err != nil {
+		return fmt.Errorf("failed to record payout: %w", err)
+	}
+
+	disbursed := *split
+	disbursed.DisbursementStatus = models.DisbursementDisbursed
+	disbursed.DisbursementError = nil
+	disbursed.DisbursedAt = &now
+	disbursed.AccountID = &account.AccountID
+	disbursed.TransactionID = &t.TransactionID
+	if err := s.loanRepo.UpdateLoanSplitDisbursement(ctx, &disbursed); err != nil {
+		return fmt.Errorf("failed to update split: %w", err)
+	}
+	*split = disbursed
+	return nil
+}
+
+func (s *disbursementServiceImpl) DisburseLoan(ctx context.Context, loanID int64) (*models.DisbursementDTO, error) {
+	return s.disburseLoan(ctx, loanID, 0)
+}
+
+func (s *disbursementServiceImpl) disburseLoan(ctx context.Context, loanID int64, maxAttempts int) (*models.DisbursementDTO, error) {
+	var splits []models.LoanSplit
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: loan %d not found", apperrors.ErrNotFound, loanID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get loan: %w", err)
+		}
+		if splits, err = s.loanRepo.GetLoanSplitsForUpdate(ctx, loanID); err != nil {
+			return fmt.Errorf("failed to lock loan splits: %w", err)
+		}
+		return s.disburseSplits(ctx, loan, splits, maxAttempts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &models.DisbursementDTO{
+		LoanID: loanID,
+		Status: disbursementStatus(splits),
+		Splits: splits,
+	}, nil
+}
+
+func (s *disbursementServiceImpl) RetryAll(ctx context.Context) (int, error) {
+	ids, err := s.loanRepo.ListLoanIDsPendingDisbursement(ctx, maxDisbursementAttempts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list loans pending disbursement: %w", err)
+	}
+	n := 0
+	for _, id := range ids {
+		if _, err := s.disburseLoan(ctx, id, maxDisbursementAttempts); err != nil {
+			logger.Log.Errorf("Disbursement retry failed for loan %d: %v", id, err)
+			continue
+		}
+		n++
+	}
+	return n, nil
+}
+
+// RunDisbursementRetry периодически повторяет неудавшиеся зачисления
+func RunDisbursementRetry(ctx context.Context, svc DisbursementService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := svc.RetryAll(ctx)
+		if err != nil {
+			logger.Log.Errorf("Disbursement retry failed: %v", err)
+		} else if n > 0 {
+			logger.Log.Infof("Disbursement retried for %d loans", n)
+		}
+	}
+}
+
+// disbursementStatus — сводный статус зачисления кредита по статусам долей
+func disbursementStatus(splits []models.LoanSplit) string {
+	var disbursed, failed int
+	for _, sp := range splits {
+		switch sp.DisbursementStatus {
+		case models.DisbursementDisbursed:
+			disbursed++
+		case models.DisbursementFailed:
+			failed++
+		}
+	}
+	switch {
+	case disbursed == len(splits):
+		return models.DisbursementDisbursed
+	case disbursed > 0:
+		return models.DisbursementPartial
+	case failed > 0:
+		return models.DisbursementFailed
+	default:
+		return models.DisbursementPending
+	}
+}
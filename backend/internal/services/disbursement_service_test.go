@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+func TestSplitPayouts(t *testing.T) {
+	splits := func(amounts ...string) []models.LoanSplit {
+		res := make([]models.LoanSplit, len(amounts))
+		for i, a := range amounts {
+			res[i] = models.LoanSplit{SplitID: int64(i + 1), SplitAmount: a}
+		}
+		return res
+	}
+	tests := []struct {
+		name      string
+		fee       string
+		insurance string
+		splits    []models.LoanSplit
+		want      []int64
+	}{
+		{"no fees", "", "", splits("60000.00", "40000.00"), []int64{6000000, 4000000}},
+		{"fee and insurance by split amount", "300.00", "150.00", splits("60000.00", "30000.00", "10000.00"),
+			[]int64{5973000, 2986500, 995500}},
+		{"rounding remainder on the last split", "1.00", "0.00", splits("100.00", "100.00", "100.00"),
+			[]int64{9967, 9967, 9966}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := &models.Loan{OriginationFee: tt.fee, InsurancePremium: tt.insurance}
+			got, err := splitPayouts(loan, tt.splits)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitPayouts = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type passTxManager struct{}
+
+func (passTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (passTxManager) WithinSavepoint(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// Фейки репозиториев реализуют только методы, которые вызывает зачисление
+
+type disbursedSplits struct {
+	repos.LoanRepository
+	updates []models.LoanSplit
+}
+
+func (r *disbursedSplits) UpdateLoanSplitDisbursement(_ context.Context, split *models.LoanSplit) error {
+	r.updates = append(r.updates, *split)
+	return nil
+}
+
+type bankAccounts struct {
+	repos.UserBankAccountRepository
+	unavailable int16
+}
+
+func (r *bankAccounts) EnsureUserBankAccount(_ context.Context, userID int64, bankID int16, currency string) (*models.UserBankAccount, error) {
+	if bankID == r.unavailable {
+		return nil, errors.New("bank is unavailable")
+	}
+	return &models.UserBankAccount{AccountID: int64(bankID) * 10, UserID: userID, BankID: bankID, Currency: currency}, nil
+}
+
+type transactionLog struct {
+	repos.TransactionRepository
+	amounts map[int16]string
+}
+
+func (r *transactionLog) CreateTransaction(_ context.Context, t *models.Transaction) error {
+	r.amounts[t.BankID] = t.Amount
+	return nil
+}
+
+type payoutLedger struct {
+	LedgerService
+}
+
+func (payoutLedger) RecordPayout(context.Context, *models.Loan, *models.LoanSplit, *models.UserBankAccount, string) error {
+	return nil
+}
+
+func TestDisburseSplits(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		disbursed   []int64
+		failed      []int64
+	}{
+		{"manual retry disburses every split", 0, []int64{1, 2}, []int64{4}},
+		{"scheduled retry skips exhausted splits", maxDisbursementAttempts, []int64{1}, []int64{4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loanRepo := &disbursedSplits{}
+			transactions := &transactionLog{amounts: map[int16]string{}}
+			s := &disbursementServiceImpl{
+				loanRepo:        loanRepo,
+				accountRepo:     &bankAccounts{unavailable: 4},
+				transactionRepo: transactions,
+				ledger:          payoutLedger{},
+				txManager:       passTxManager{},
+			}
+			loan := &models.Loan{LoanID: 1, UserID: 7, OriginationFee: "30.00", InsurancePremium: "10.00"}
+			splits := []models.LoanSplit{
+				{SplitID: 1, BankID: 1, SplitAmount: "1000.00", DisbursementStatus: models.DisbursementPending},
+				{SplitID: 2, BankID: 2, SplitAmount: "1000.00", DisbursementStatus: models.DisbursementFailed, DisbursementAttempts: maxDisbursementAttempts},
+				{SplitID: 3, BankID: 3, SplitAmount: "1000.00", DisbursementStatus: models.DisbursementDisbursed},
+				{SplitID: 4, BankID: 4, SplitAmount: "1000.00", DisbursementStatus: models.DisbursementFailed, DisbursementAttempts: 2},
+			}
+
+			if err := s.disburseSplits(context.Background(), loan, splits, tt.maxAttempts); err != nil {
+				t.Fatal(err)
+			}
+
+			var disbursed, failed []int64
+			for _, u := range loanRepo.updates {
+				switch u.DisbursementStatus {
+				case models.DisbursementDisbursed:
+					disbursed = append(disbursed, u.SplitID)
+				case models.DisbursementFailed:
+					failed = append(failed, u.SplitID)
+				}
+			}
+			if !slices.Equal(disbursed, tt.disbursed) || !slices.Equal(failed, tt.failed) {
+				t.Errorf("disbursed %v, failed %v; want %v, %v", disbursed, failed, tt.disbursed, tt.failed)
+			}
+			// Заёмщик получает долю за вычетом её части комиссии и страховки: 1000.00 − 10.00
+			for _, id := range tt.disbursed {
+				if got := transactions.amounts[int16(id)]; got != "990.00" {
+					t.Errorf("split %d paid out %s, want 990.00", id, got)
+				}
+			}
+			if splits[3].DisbursementError == nil {
+				t.Error("failed split has no error recorded")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// distributionCandidate — банк, готовый участвовать в кредите (суммы в копейках)
+type distributionCandidate struct {
+	BankID   int16
+	Min      int64   // минимальная доля
+	Capacity int64   // максимум, который банк может дать в этот кредит
+	Weight   float64 // целевая доля × аппетит к типу кредита
+}
+
+// distributeAmount делит amount между кандидатами пропорционально весу с учётом лимитов.
+// Доля, упёршаяся в лимит, выбывает, излишек перераспределяется между остальными.
+// Банк, чья доля вышла меньше минимальной, исключается, и распределение повторяется.
+// Возвращает сумму для каждого кандидата в порядке cands (0 — банк не участвует).
+func distributeAmount(amount int64, cands []distributionCandidate) ([]int64, error) {
+	excluded := make([]bool, len(cands))
+	for i, c := range cands {
+		excluded[i] = c.Weight <= 0 || c.Capacity <= 0 || c.Capacity < c.Min
+	}
+
+	belowMin := false
+	for {
+		var capacity int64
+		for i, c := range cands {
+			if !excluded[i] {
+				capacity += c.Capacity
+			}
+		}
+		if capacity < amount {
+			if belowMin {
+				return nil, fmt.Errorf("amount %s cannot be split without breaking lenders minimum tickets", formatMoney(amount))
+			}
+			return nil, fmt.Errorf("lenders capacity %s is not enough for %s", formatMoney(capacity), formatMoney(amount))
+		}
+
+		parts := waterFill(amount, cands, excluded)
+
+		// Исключаем самый маленький тикет ниже минимума и пробуем снова
+		worst := -1
+		for i, c := range cands {
+			if excluded[i] || parts[i] >= c.Min {
+				continue
+			}
+			if worst < 0 || parts[i] < parts[worst] {
+				worst = i
+			}
+		}
+		if worst < 0 {
+			return parts, nil
+		}
+		excluded[worst] = true
+		belowMin = true
+	}
+}
+
+// waterFill — пропорциональное распределение с ограничением сверху
+func waterFill(amount int64, cands []distributionCandidate, excluded []bool) []int64 {
+	parts := make([]int64, len(cands))
+	for amount > 0 {
+		var open []int
+		var weightSum float64
+		for i, c := range cands {
+			if !excluded[i] && parts[i] < c.Capacity {
+				open = append(open, i)
+				weightSum += c.Weight
+			}
+		}
+		if len(open) == 0 {
+			break
+		}
+
+		var distributed int64
+		for _, i := range open {
+			share := int64(math.Floor(float64(amount) * cands[i].Weight / weightSum))
+			share = min(share, cands[i].Capacity-parts[i])
+			parts[i] += share
+			distributed += share
+		}
+
+		// Копейки от округления отдаём банкам с наибольшим весом
+		if distributed == 0 {
+			sort.SliceStable(open, func(a, b int) bool { return cands[open[a]].Weight > cands[open[b]].Weight })
+			for _, i := range open {
+				if amount-distributed == 0 {
+					break
+				}
+				parts[i]++
+				distributed++
+			}
+		}
+		amount -= distributed
+	}
+	return parts
+}
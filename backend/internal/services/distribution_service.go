@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// DistributionService предлагает распределение кредита между банками-партнёрами
+// и котирует ставку по профилям кредиторов
+type DistributionService interface {
+	// ProposeSplits делит amount между банками по их лимитам, минимальному тикету,
+	// аппетиту к типу кредита typeCode и целевой доле; банки exclude в распределении не участвуют
+	ProposeSplits(ctx context.Context, amount, typeCode string, exclude ...int16) (*models.SplitProposal, error)
+	// ProposeProductSplits — как ProposeSplits, но только между банками продукта
+	// и со ставками, приведёнными к диапазону продукта
+	ProposeProductSplits(ctx context.Context, amount string, product *models.LoanProduct, exclude ...int16) (*models.SplitProposal, error)
+	// QuoteRate возвращает средневзвешенную по долям базовую ставку банков для typeCode
+	QuoteRate(ctx context.Context, typeCode string, splits []map[int16]string) (string, error)
+	// QuoteProductRate — ставка QuoteRate, приведённая к диапазону продукта
+	QuoteProductRate(ctx context.Context, product *models.LoanProduct, splits []map[int16]string) (string, error)
+	// EligibleBanks — активные банки, выдающие typeCode в рублях
+	EligibleBanks(ctx context.Context, typeCode string) ([]int16, error)
+}
+
+type distributionServiceImpl struct {
+	settingsRepo repos.LendingSettingsRepository
+	bankRepo     repos.BankRepository
+}
+
+func NewDistributionService(settingsRepo repos.LendingSettingsRepository, bankRepo repos.BankRepository) DistributionService {
+	return &distributionServiceImpl{
+		settingsRepo: settingsRepo,
+		bankRepo:     bankRepo,
+	}
+}
+
+// bankProduct — условия банка по типу кредита
+type bankProduct struct {
+	weight float64
+	rate   float64
+}
+
+func (s *distributionServiceImpl) ProposeSplits(ctx context.Context, amount, typeCode string, exclude ...int16) (*models.SplitProposal, error) {
+	return s.propose(ctx, amount, typeCode, nil, exclude)
+}
+
+func (s *distributionServiceImpl) ProposeProductSplits(ctx context.Context, amount string, product *models.LoanProduct, exclude ...int16) (*models.SplitProposal, error) {
+	return s.propose(ctx, amount, product.TypeCode, product, exclude)
+}
+
+// propose распределяет amount; product, если задан, ограничивает банки и ставки,
+// банки exclude пропускаются
+func (s *distributionServiceImpl) propose(ctx context.Context, amount, typeCode string, product *models.LoanProduct, exclude []int16) (*models.SplitProposal, error) {
+	total, err := parseMoney(amount)
+	if err != nil || total <= 0 {
+		return nil, fmt.Errorf("%w: invalid loan amount %q", apperrors.ErrBadRequest, amount)
+	}
+
+	products, err := s.products(ctx, typeCode)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.settingsRepo.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lending settings: %w", err)
+	}
+	banks, err := s.bankRepo.ListBanks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+
+	bankNames := make(map[int16]string, len(banks))
+	for _, b := range banks {
+		bankNames[b.BankID] = b.Name
+	}
+
+	cands := make([]distributionCandidate, 0, len(settings))
+	for _, st := range settings {
+		p, ok := products[st.BankID]
+		if !st.Active || !ok || !st.SupportsCurrency(models.CurrencyRUB) || slices.Contains(exclude, st.BankID) {
+			continue
+		}
+		if product != nil && !slices.Contains(product.BankIDs, st.BankID) {
+			continue
+		}
+		c, err := distributionCandidateFor(st, p.weight)
+		if err != nil {
+			return nil, fmt.Errorf("invalid lending settings for bank %d: %w", st.BankID, err)
+		}
+		cands = append(cands, c)
+	}
+
+	parts, err := distributeAmount(total, cands)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+
+	res := &models.SplitProposal{Amount: formatMoney(total), TypeCode: typeCode, Currency: models.CurrencyRUB}
+	var weighted float64
+	for i, c := range cands {
+		if parts[i] == 0 {
+			continue
+		}
+		rate := products[c.BankID].rate
+		if product != nil {
+			minRate, _ := parseRate(product.MinRate)
+			maxRate, _ := parseRate(product.MaxRate)
+			rate = min(max(rate, minRate), maxRate)
+		}
+		weighted += rate * float64(parts[i])
+		res.Splits = append(res.Splits, models.ProposedSplit{
+			BankID:   c.BankID,
+			BankName: bankNames[c.BankID],
+			Amount:   formatMoney(parts[i]),
+			Share:    math.Round(float64(parts[i])/float64(total)*10000) / 100,
+			Rate:     formatRate(rate),
+		})
+	}
+	res.InterestRate = formatRate(weighted / float64(total))
+	return res, nil
+}
+
+func (s *distributionServiceImpl) QuoteRate(ctx context.Context, typeCode string, splits []map[int16]string) (string, error) {
+	products, err := s.products(ctx, typeCode)
+	if err != nil {
+		return "", err
+	}
+
+	var total int64
+	var weighted float64
+	for _, m := range splits {
+		for bankID, amount := range m {
+			k, err := parseMoney(amount)
+			if err != nil || k <= 0 {
+				return "", fmt.Errorf("%w: invalid split amount %q for bank %d", apperrors.ErrBadRequest, amount, bankID)
+			}
+			p, ok := products[bankID]
+			if !ok {
+				return "", fmt.Errorf("%w: bank %d does not offer %s loans", apperrors.ErrBadRequest, bankID, typeCode)
+			}
+			total += k
+			weighted += p.rate * float64(k)
+		}
+	}
+	if total == 0 {
+		return "", fmt.Errorf("%w: no splits to quote", apperrors.ErrBadRequest)
+	}
+	return formatRate(weighted / float64(total)), nil
+}
+
+func (s *distributionServiceImpl) QuoteProductRate(ctx context.Context, product *models.LoanProduct, splits []map[int16]string) (string, error) {
+	rate, err := s.QuoteRate(ctx, product.TypeCode, splits)
+	if err != nil {
+		return "", err
+	}
+	return clampProductRate(product, rate)
+}
+
+func (s *distributionServiceImpl) EligibleBanks(ctx context.Context, typeCode string) ([]int16, error) {
+	products, err := s.products(ctx, typeCode)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.settingsRepo.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lending settings: %w", err)
+	}
+
+	var res []int16
+	for _, st := range settings {
+		if _, ok := products[st.BankID]; ok && st.Active && st.SupportsCurrency(models.CurrencyRUB) {
+			res = append(res, st.BankID)
+		}
+	}
+	return res, nil
+}
+
+// products возвращает банки, выдающие typeCode: с базовой ставкой и ненулевым аппетитом
+func (s *distributionServiceImpl) products(ctx context.Context, typeCode string) (map[int16]bankProduct, error) {
+	if !validLoanType(typeCode) {
+		return nil, fmt.Errorf("%w: unknown loan type %q", apperrors.ErrBadRequest, typeCode)
+	}
+
+	appetite, err := s.settingsRepo.ListAppetite(ctx, typeCode)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list product appetite: %w", err)
+	}
+
+	res := make(map[int16]bankProduct, len(appetite))
+	for _, a := range appetite {
+		if a.BaseRate == nil {
+			continue
+		}
+		w, err := strconv.ParseFloat(a.Weight, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid appetite weight for bank %d: %w", a.BankID, err)
+		}
+		rate, err := parseRate(*a.BaseRate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base rate for bank %d: %w", a.BankID, err)
+		}
+		if w > 0 {
+			res[a.BankID] = bankProduct{weight: w, rate: rate}
+		}
+	}
+	return res, nil
+}
+
+// distributionCandidateFor — лимит банка в кредите: меньшее из max_ticket и свободного лимита
+func distributionCandidateFor(st models.BankLendingSettings, appetite float64) (distributionCandidate, error) {
+	minTicket, err := parseMoney(st.MinTicket)
+	if err != nil {
+		return distributionCandidate{}, err
+	}
+	maxTicket, err := parseMoney(st.MaxTicket)
+	if err != nil {
+		return distributionCandidate{}, err
+	}
+	capacity, err := availableCapacity(st)
+	if err != nil {
+		return distributionCandidate{}, err
+	}
+	share, err := parseRate(st.TargetShare)
+	if err != nil {
+		return distributionCandidate{}, err
+	}
+
+	return distributionCandidate{
+		BankID:   st.BankID,
+		Min:      minTicket,
+		Capacity: min(maxTicket, capacity),
+		Weight:   share * appetite,
+	}, nil
+}
+
+// availableCapacity — свободный лимит банка: exposure_limit − непогашенный долг, не меньше нуля
+func availableCapacity(st models.BankLendingSettings) (int64, error) {
+	limit, err := parseMoney(st.ExposureLimit)
+	if err != nil {
+		return 0, err
+	}
+	exposure, err := parseMoney(st.CurrentExposure)
+	if err != nil {
+		return 0, err
+	}
+	return max(limit-exposure, 0), nil
+}
+
+func validLoanType(typeCode string) bool {
+	switch typeCode {
+	case models.LoanTypePersonal, models.LoanTypeAuto, models.LoanTypeMortgage, models.LoanTypeOther:
+		return true
+	}
+	return false
+}
+
+// formatRate форматирует ставку под numeric(5,2)
+func formatRate(r float64) string {
+	return strconv.FormatFloat(math.Round(r*100)/100, 'f', 2, 64)
+}
@@ -0,0 +1,254 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+	"github.com/Arlandaren/easyfund/internal/storage"
+)
+
+// Допустимые типы документов и расширения для ключа в хранилище.
+// Тип определяется по содержимому файла, а не по заголовку клиента
+var documentContentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+const maxDocumentFileName = 255
+
+type DocumentService interface {
+	// UploadDocument проверяет тип и размер файла, сохраняет его в хранилище и метаданные в БД
+	UploadDocument(ctx context.Context, upload *models.DocumentUpload) (*models.ApplicationDocument, error)
+	ListDocuments(ctx context.Context, appID int64) ([]models.ApplicationDocument, error)
+	// OpenDocument возвращает метаданные и содержимое документа; вызывающий закрывает ReadCloser
+	OpenDocument(ctx context.Context, appID, docID int64) (*models.ApplicationDocument, io.ReadCloser, error)
+	// DeleteDocument удаляет документ; удалить может только загрузивший его пользователь
+	DeleteDocument(ctx context.Context, appID, docID int64, userID int64) error
+}
+
+type documentServiceImpl struct {
+	docRepo repos.DocumentRepository
+	appRepo repos.CreditApplicationRepository
+	store   storage.BlobStore
+	cfg     config.DocumentConfig
+}
+
+func NewDocumentService(
+	docRepo repos.DocumentRepository,
+	appRepo repos.CreditApplicationRepository,
+	store storage.BlobStore,
+	cfg config.DocumentConfig,
+) DocumentService {
+	return &documentServiceImpl{
+		docRepo: docRepo,
+		appRepo: appRepo,
+		store:   store,
+		cfg:     cfg,
+	}
+}
+
+func (s *documentServiceImpl) UploadDocument(ctx context.Context, upload *models.DocumentUpload) (*models.ApplicationDocument, error) {
+	if !validDocumentType(upload.DocType) {
+		return nil, fmt.Errorf("%w: unknown document type %q", apperrors.ErrBadRequest, upload.DocType)
+	}
+	fileName := cleanFileName(upload.FileName)
+	if fileName == "" {
+		return nil, fmt.Errorf("%w: file name is required", apperrors.ErrBadRequest)
+	}
+	if err := s.checkEditable(ctx, upload.ApplicationID); err != nil {
+		return nil, err
+	}
+
+	// Читаем на байт больше лимита, чтобы отличить файл ровно в лимит от превышения
+	data, err := io.ReadAll(io.LimitReader(upload.Content, s.cfg.MaxSizeBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: document is empty", apperrors.ErrBadRequest)
+	}
+	if int64(len(data)) > s.cfg.MaxSizeBytes {
+		return nil, fmt.Errorf("%w: document exceeds %d bytes", apperrors.ErrBadRequest, s.cfg.MaxSizeBytes)
+	}
+
+	contentType, err := documentContentType(data, upload.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	key, err := documentKey(upload.ApplicationID, documentContentTypes[contentType])
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	doc := &models.ApplicationDocument{
+		ApplicationID:  upload.ApplicationID,
+		DocType:        upload.DocType,
+		FileName:       fileName,
+		ContentType:    contentType,
+		SizeBytes:      int64(len(data)),
+		ChecksumSHA256: hex.EncodeToString(sum[:]),
+		StorageKey:     key,
+		UploadedBy:     &upload.UploadedBy,
+	}
+	if err := s.docRepo.CreateDocument(ctx, doc); err != nil {
+		// Без записи в БД объект никому не виден — убираем его из хранилища
+		if delErr := s.store.Delete(ctx, key); delErr != nil {
+			logger.Log.Warnf("Failed to remove orphaned document blob %s: %v", key, delErr)
+		}
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+	return doc, nil
+}
+
+func (s *documentServiceImpl) ListDocuments(ctx context.Context, appID int64) ([]models.ApplicationDocument, error) {
+	return s.docRepo.ListDocuments(ctx, appID)
+}
+
+func (s *documentServiceImpl) OpenDocument(ctx context.Context, appID, docID int64) (*models.ApplicationDocument, io.ReadCloser, error) {
+	doc, err := s.getDocument(ctx, appID, docID)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.store.Get(ctx, doc.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, nil, fmt.Errorf("%w: content of document %d is missing", apperrors.ErrNotFound, docID)
+		}
+		return nil, nil, fmt.Errorf("failed to open document: %w", err)
+	}
+	return doc, content, nil
+}
+
+func (s *documentServiceImpl) DeleteDocument(ctx context.Context, appID, docID int64, userID int64) error {
+	doc, err := s.getDocument(ctx, appID, docID)
+	if err != nil {
+		return err
+	}
+	if doc.UploadedBy == nil || *doc.UploadedBy != userID {
+		return fmt.Errorf("%w: document %d was uploaded by another user", apperrors.ErrForbidden, docID)
+	}
+	if err := s.checkEditable(ctx, appID); err != nil {
+		return err
+	}
+
+	ok, err := s.docRepo.DeleteDocument(ctx, appID, docID)
+	if err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: document %d", apperrors.ErrNotFound, docID)
+	}
+	// Запись уже удалена: объект без записи недоступен, поэтому ошибку хранилища только логируем
+	if err := s.store.Delete(ctx, doc.StorageKey); err != nil {
+		logger.Log.Warnf("Failed to remove document blob %s: %v", doc.StorageKey, err)
+	}
+	return nil
+}
+
+func (s *documentServiceImpl) getDocument(ctx context.Context, appID, docID int64) (*models.ApplicationDocument, error) {
+	doc, err := s.docRepo.GetDocument(ctx, appID, docID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: document %d", apperrors.ErrNotFound, docID)
+		}
+		return nil, fmt.Errorf("failed to get document: %w", err)
+	}
+	return doc, nil
+}
+
+// checkEditable — документы меняются только у черновика и заявки на рассмотрении
+func (s *documentServiceImpl) checkEditable(ctx context.Context, appID int64) error {
+	app, err := s.appRepo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: application %d", apperrors.ErrNotFound, appID)
+		}
+		return fmt.Errorf("failed to get application: %w", err)
+	}
+	if app.StatusCode != models.ApplicationStatusDraft && app.StatusCode != models.ApplicationStatusPending {
+		return fmt.Errorf("%w: application %d is %s, documents can no longer be changed", apperrors.ErrConflict, appID, app.StatusCode)
+	}
+	return nil
+}
+
+func validDocumentType(docType string) bool {
+	switch docType {
+	case models.DocumentTypeIncomeProof, models.DocumentTypePassport, models.DocumentTypeCollateral, models.DocumentTypeOther:
+		return true
+	}
+	return false
+}
+
+// documentContentType определяет тип по содержимому и сверяет его с заявленным клиентом
+func documentContentType(data []byte, declared string) (string, error) {
+	detected, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "", fmt.Errorf("%w: unrecognized document content", apperrors.ErrBadRequest)
+	}
+	if _, ok := documentContentTypes[detected]; !ok {
+		return "", fmt.Errorf("%w: unsupported content type %s, allowed: PDF, JPEG, PNG", apperrors.ErrBadRequest, detected)
+	}
+
+	// application/octet-stream браузеры присылают, когда тип неизвестен, — это не противоречие
+	if declared != "" {
+		mediaType, _, err := mime.ParseMediaType(declared)
+		if err != nil {
+			return "", fmt.Errorf("%w: invalid content type %q", apperrors.ErrBadRequest, declared)
+		}
+		if mediaType != detected && mediaType != "application/octet-stream" {
+			return "", fmt.Errorf("%w: declared content type %s does not match file content %s", apperrors.ErrBadRequest, mediaType, detected)
+		}
+	}
+	return detected, nil
+}
+
+// cleanFileName оставляет от имени файла только базовое имя без управляющих символов
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "." || name == "/" {
+		return ""
+	}
+	if runes := []rune(name); len(runes) > maxDocumentFileName {
+		name = string(runes[:maxDocumentFileName])
+	}
+	return name
+}
+
+// documentKey — случайный ключ объекта: имя файла от клиента в ключ не попадает
+func documentKey(appID int64, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate document key: %w", err)
+	}
+	return fmt.Sprintf("applications/%d/%s%s", appID, hex.EncodeToString(b), ext), nil
+}
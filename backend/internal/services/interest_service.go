@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+type InterestAccrualService interface {
+	// AccrueLoan начисляет проценты по всем долям кредита за каждый день по through включительно
+	AccrueLoan(ctx context.Context, loanID int64, through time.Time) error
+	// AccrueAll начисляет проценты по всем непогашенным кредитам, возвращает число обработанных кредитов
+	AccrueAll(ctx context.Context, through time.Time) (int, error)
+}
+
+type interestAccrualServiceImpl struct {
+	loanRepo     repos.LoanRepository
+	accrualRepo  repos.InterestAccrualRepository
+	scheduleRepo repos.LoanScheduleRepository
+	paymentRepo  repos.LoanPaymentRepository
+	txManager    repos.TxManager
+	cfg          config.LoanConfig
+}
+
+func NewInterestAccrualService(
+	loanRepo repos.LoanRepository,
+	accrualRepo repos.InterestAccrualRepository,
+	scheduleRepo repos.LoanScheduleRepository,
+	paymentRepo repos.LoanPaymentRepository,
+	txManager repos.TxManager,
+	cfg config.LoanConfig,
+) InterestAccrualService {
+	return &interestAccrualServiceImpl{
+		loanRepo:     loanRepo,
+		accrualRepo:  accrualRepo,
+		scheduleRepo: scheduleRepo,
+		paymentRepo:  paymentRepo,
+		txManager:    txManager,
+		cfg:          cfg,
+	}
+}
+
+func (s *interestAccrualServiceImpl) AccrueLoan(ctx context.Context, loanID int64, through time.Time) error {
+	through = dateOnly(through)
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to get loan: %w", err)
+		}
+
+		splits, err := s.loanRepo.GetLoanSplitsForUpdate(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to lock loan splits: %w", err)
+		}
+
+		// График и уплаченные суммы нужны для пени на просроченные платежи
+		schedule, err := s.scheduleRepo.GetLoanSchedule(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to get loan schedule: %w", err)
+		}
+		paid, err := s.paymentRepo.SumSplitPaid(ctx, loanID, models.PaymentTypeRegular)
+		if err != nil {
+			return fmt.Errorf("failed to sum split payments: %w", err)
+		}
+
+		for _, sp := range splits {
+			repayments, err := newSplitRepayments(sp.SplitID, schedule, paid)
+			if err != nil {
+				return err
+			}
+			if err := s.accrueSplit(ctx, loan, sp, repayments, through); err != nil {
+				return fmt.Errorf("failed to accrue split %d: %w", sp.SplitID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// accrueSplit начисляет проценты и пени по доле за дни (accrued_through, through].
+// Основной долг между платежами не меняется, поэтому база процентов одинакова для всех дней пакета;
+// база пени — просроченная на каждый день сумма по графику; платёж не штрафуется, пока не истёк
+// льготный период кредита после даты платежа.
+func (s *interestAccrualServiceImpl) accrueSplit(ctx context.Context, loan *models.Loan, sp models.LoanSplit, repayments splitRepayments, through time.Time) error {
+	day := dateOnly(sp.AccruedThrough).AddDate(0, 0, 1)
+	if day.After(through) {
+		return nil
+	}
+
+	principal, err := parseMoney(sp.RemainingPrincipal)
+	if err != nil {
+		return err
+	}
+	accrued, err := parseMoney(sp.AccruedInterest)
+	if err != nil {
+		return err
+	}
+	penalty, err := parseMoney(sp.PenaltyInterest)
+	if err != nil {
+		return err
+	}
+	rate, err := parseRate(sp.InterestRate)
+	if err != nil {
+		return err
+	}
+	penaltyRate, err := parseRate(s.cfg.PenaltyRate)
+	if err != nil {
+		return fmt.Errorf("invalid penalty rate: %w", err)
+	}
+
+	for ; !day.After(through); day = day.AddDate(0, 0, 1) {
+		fraction, err := dailyYearFraction(loan.DayCountConvention, day)
+		if err != nil {
+			return err
+		}
+
+		if principal > 0 {
+			amount, err := s.accrueDay(ctx, loan, sp.SplitID, models.AccrualTypeInterest, day, principal, sp.InterestRate, rate, fraction, &sp.InterestRemainder)
+			if err != nil {
+				return err
+			}
+			accrued += amount
+		}
+
+		overdue, _, err := repayments.arrears(day.AddDate(0, 0, -loan.GracePeriodDays))
+		if err != nil {
+			return err
+		}
+		if overdue > 0 && penaltyRate > 0 {
+			amount, err := s.accrueDay(ctx, loan, sp.SplitID, models.AccrualTypePenalty, day, overdue, s.cfg.PenaltyRate, penaltyRate, fraction, &sp.PenaltyRemainder)
+			if err != nil {
+				return err
+			}
+			penalty += amount
+		}
+	}
+
+	if err := s.loanRepo.UpdateLoanSplitRemainders(ctx, sp.SplitID, sp.InterestRemainder, sp.PenaltyRemainder); err != nil {
+		return err
+	}
+	return s.loanRepo.UpdateLoanSplitAccrual(ctx, sp.SplitID, formatMoney(accrued), formatMoney(penalty), through)
+}
+
+// accrueDay записывает дневное начисление на base по ставке rate (в процентах) и возвращает его сумму.
+// Начисление округляется до копеек, а отброшенная доля копейки копится в remainder и входит в следующий день
+func (s *interestAccrualServiceImpl) accrueDay(ctx context.Context, loan *models.Loan, splitID int64, accrualType string, day time.Time, base int64, rateStr string, rate, fraction float64, remainder *float64) (int64, error) {
+	amount := carryKopecks(float64(base)*rate/100*fraction, remainder)
+	if amount == 0 {
+		return 0, nil
+	}
+
+	accrual := &models.InterestAccrual{
+		SplitID:            splitID,
+		AccrualDate:        day,
+		PrincipalBase:      formatMoney(base),
+		InterestRate:       rateStr,
+		DayCountConvention: loan.DayCountConvention,
+		Amount:             formatMoney(amount),
+		AccrualType:        accrualType,
+	}
+	if err := s.accrualRepo.CreateAccrual(ctx, accrual); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
+
+func (s *interestAccrualServiceImpl) AccrueAll(ctx context.Context, through time.Time) (int, error) {
+	loanIDs, err := s.loanRepo.ListLoanIDsByStatus(ctx, openLoanStatuses...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list open loans: %w", err)
+	}
+
+	// Каждый кредит в своей транзакции: ошибка по одному не блокирует остальные
+	processed := 0
+	for _, id := range loanIDs {
+		if err := s.AccrueLoan(ctx, id, through); err != nil {
+			logger.Log.Errorf("Failed to accrue interest for loan %d: %v", id, err)
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// RunDailyAccrual периодически начисляет проценты за завершившиеся дни (по вчера включительно)
+// и пересчитывает просрочку на сегодня.
+// Начисление идемпотентно по accrued_through, поэтому интервал может быть меньше суток.
+func RunDailyAccrual(ctx context.Context, svc InterestAccrualService, delinquency DelinquencyService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		today := dateOnly(time.Now())
+		through := today.AddDate(0, 0, -1)
+		n, err := svc.AccrueAll(ctx, through)
+		if err != nil {
+			logger.Log.Errorf("Interest accrual failed: %v", err)
+		} else {
+			logger.Log.Infof("Interest accrued through %s for %d loans", through.Format("2006-01-02"), n)
+		}
+
+		n, err = delinquency.RefreshAll(ctx, today)
+		if err != nil {
+			logger.Log.Errorf("Delinquency refresh failed: %v", err)
+		} else {
+			logger.Log.Infof("Delinquency refreshed as of %s for %d loans", today.Format("2006-01-02"), n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
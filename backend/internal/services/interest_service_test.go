@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+type accrualRecorder struct {
+	accruals []models.InterestAccrual
+}
+
+func (r *accrualRecorder) CreateAccrual(_ context.Context, a *models.InterestAccrual) error {
+	r.accruals = append(r.accruals, *a)
+	return nil
+}
+
+func (r *accrualRecorder) ListSplitAccruals(_ context.Context, splitID int64) ([]models.InterestAccrual, error) {
+	return r.accruals, nil
+}
+
+// splitAccrualStore запоминает итог начисления по доле; остальные методы репозитория тесту не нужны
+type splitAccrualStore struct {
+	repos.LoanRepository
+	accrued, penalty string
+	through          time.Time
+}
+
+func (r *splitAccrualStore) UpdateLoanSplitAccrual(_ context.Context, _ int64, accrued, penalty string, through time.Time) error {
+	r.accrued, r.penalty, r.through = accrued, penalty, through
+	return nil
+}
+
+func (r *splitAccrualStore) UpdateLoanSplitRemainders(context.Context, int64, float64, float64) error {
+	return nil
+}
+
+func TestAccrueSplitGracePeriod(t *testing.T) {
+	schedule := []models.LoanScheduleItem{
+		{SplitID: 1, InstallmentNo: 1, DueDate: date(2026, 2, 10), TotalPayment: "1000.00"},
+	}
+	tests := []struct {
+		name     string
+		grace    int
+		through  time.Time
+		firstDay time.Time
+		penalty  string
+	}{
+		{"no grace period", 0, date(2026, 2, 11), date(2026, 2, 11), "1.00"},
+		{"within grace period", 3, date(2026, 2, 12), time.Time{}, "0.00"},
+		{"last day of grace period", 3, date(2026, 2, 13), time.Time{}, "0.00"},
+		{"first day after grace period", 3, date(2026, 2, 14), date(2026, 2, 14), "1.00"},
+		{"after grace period", 3, date(2026, 2, 16), date(2026, 2, 14), "3.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accruals := &accrualRecorder{}
+			store := &splitAccrualStore{}
+			s := &interestAccrualServiceImpl{
+				loanRepo:    store,
+				accrualRepo: accruals,
+				cfg:         config.LoanConfig{PenaltyRate: "36.5"}, // 1.00 в день на 1000.00
+			}
+			loan := &models.Loan{DayCountConvention: DayCountAct365, GracePeriodDays: tt.grace}
+			sp := models.LoanSplit{
+				SplitID:            1,
+				RemainingPrincipal: "0.00",
+				InterestRate:       "12",
+				AccruedInterest:    "0.00",
+				PenaltyInterest:    "0.00",
+				AccruedThrough:     date(2026, 2, 10),
+			}
+			repayments, err := newSplitRepayments(1, schedule, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := s.accrueSplit(context.Background(), loan, sp, repayments, tt.through); err != nil {
+				t.Fatal(err)
+			}
+			if store.penalty != tt.penalty {
+				t.Errorf("penalty = %s, want %s", store.penalty, tt.penalty)
+			}
+			if !store.through.Equal(tt.through) {
+				t.Errorf("accrued through %s, want %s", store.through, tt.through)
+			}
+			if tt.firstDay.IsZero() {
+				if len(accruals.accruals) != 0 {
+					t.Errorf("got %d accruals during grace period", len(accruals.accruals))
+				}
+				return
+			}
+			if len(accruals.accruals) == 0 || !accruals.accruals[0].AccrualDate.Equal(tt.firstDay) {
+				t.Errorf("penalty accrued from %v, want %s", accruals.accruals, tt.firstDay)
+			}
+			for _, a := range accruals.accruals {
+				if a.AccrualType != models.AccrualTypePenalty {
+					t.Errorf("unexpected %s accrual on %s", a.AccrualType, a.AccrualDate)
+				}
+			}
+		})
+	}
+}
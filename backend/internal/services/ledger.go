@@ -0,0 +1,115 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// ledgerLeg — движение по счёту главной книги до сохранения (в копейках; > 0 — дебет)
+type ledgerLeg struct {
+	Account models.LedgerAccount
+	Amount  int64
+}
+
+// normalBalance — сторона, на которой растёт остаток счёта данного вида
+func normalBalance(kind string) string {
+	switch kind {
+	case models.LedgerLoanPrincipal, models.LedgerBankSettlement:
+		return models.LedgerDebit
+	default:
+		return models.LedgerCredit
+	}
+}
+
+// bankLedgerAccount — счёт банка: расчёты, доходы, страховые премии
+func bankLedgerAccount(kind string, bankID int16) models.LedgerAccount {
+	return models.LedgerAccount{
+		Code:          kind + ":" + strconv.Itoa(int(bankID)),
+		Kind:          kind,
+		NormalBalance: normalBalance(kind),
+		BankID:        bankID,
+		Currency:      models.CurrencyRUB,
+	}
+}
+
+func customerLedgerAccount(acc *models.UserBankAccount) models.LedgerAccount {
+	return models.LedgerAccount{
+		Code:          models.LedgerCustomerFunds + ":" + strconv.FormatInt(acc.AccountID, 10),
+		Kind:          models.LedgerCustomerFunds,
+		NormalBalance: models.LedgerCredit,
+		BankID:        acc.BankID,
+		AccountID:     &acc.AccountID,
+		Currency:      acc.Currency,
+	}
+}
+
+func principalLedgerAccount(split *models.LoanSplit) models.LedgerAccount {
+	return models.LedgerAccount{
+		Code:          models.LedgerLoanPrincipal + ":" + strconv.FormatInt(split.SplitID, 10),
+		Kind:          models.LedgerLoanPrincipal,
+		NormalBalance: models.LedgerDebit,
+		BankID:        split.BankID,
+		SplitID:       &split.SplitID,
+		Currency:      models.CurrencyRUB,
+	}
+}
+
+// prorate делит total пропорционально weights; остаток от округления достаётся последней части,
+// так что сумма частей всегда равна total
+func prorate(total int64, weights []int64) []int64 {
+	parts := make([]int64, len(weights))
+	var sum int64
+	for _, w := range weights {
+		sum += w
+	}
+	if sum == 0 || len(weights) == 0 {
+		return parts
+	}
+	var given int64
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			parts[i] = total - given
+			break
+		}
+		parts[i] = roundKopecks(float64(total) * float64(w) / float64(sum))
+		given += parts[i]
+	}
+	return parts
+}
+
+// loanFeeShares — комиссия за выдачу и страховая премия кредита по долям, пропорционально суммам долей
+func loanFeeShares(loan *models.Loan, splits []models.LoanSplit) (fees, premiums []int64, err error) {
+	origination, err := parseOptionalMoney(loan.OriginationFee)
+	if err != nil {
+		return nil, nil, err
+	}
+	insurance, err := parseOptionalMoney(loan.InsurancePremium)
+	if err != nil {
+		return nil, nil, err
+	}
+	weights := make([]int64, len(splits))
+	for i, sp := range splits {
+		if weights[i], err = parseMoney(sp.SplitAmount); err != nil {
+			return nil, nil, err
+		}
+	}
+	return prorate(origination, weights), prorate(insurance, weights), nil
+}
+
+// splitPayouts — суммы к зачислению заёмщику по долям: доля за вычетом удержанных с неё комиссии и страховки
+func splitPayouts(loan *models.Loan, splits []models.LoanSplit) ([]int64, error) {
+	fees, premiums, err := loanFeeShares(loan, splits)
+	if err != nil {
+		return nil, err
+	}
+	payouts := make([]int64, len(splits))
+	for i, sp := range splits {
+		k, err := parseMoney(sp.SplitAmount)
+		if err != nil {
+			return nil, err
+		}
+		payouts[i] = k - fees[i] - premiums[i]
+	}
+	return payouts, nil
+}
@@ -0,0 +1,297 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// LedgerService — главная книга: каждое движение денег записывается парными проводками,
+// остатки счетов клиентов (user_bank_accounts.balance) выводятся из них
+type LedgerService interface {
+	// RecordOpeningBalance — поступление на счёт клиента извне платформы
+	RecordOpeningBalance(ctx context.Context, account *models.UserBankAccount, amount string) error
+	// RecordDisbursement — выдача долей кредита за счёт средств банков
+	RecordDisbursement(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error
+	// RecordPayout — зачисление выданной доли на счёт заёмщика в банке доли; amount — доля за вычетом удержанных комиссии и страховки
+	RecordPayout(ctx context.Context, loan *models.Loan, split *models.LoanSplit, account *models.UserBankAccount, amount string) error
+	// RecordFees — комиссия за выдачу и страховая премия кредита, по банкам пропорционально долям
+	RecordFees(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error
+	// RecordRepayment — поступление платежа по кредиту с разнесением на основной долг, проценты, пени и плату за обслуживание
+	RecordRepayment(ctx context.Context, payment *models.LoanPayment, allocations []models.PaymentAllocation, splits []models.LoanSplit) error
+	// RecordTransfer — перевод между счетами клиента
+	RecordTransfer(ctx context.Context, from, to *models.UserBankAccount, amount string, occurredAt time.Time, description string) (*models.LedgerEntry, error)
+	// RecordBankSync доводит остаток счёта клиента до остатка по данным банка; возвращает разницу
+	RecordBankSync(ctx context.Context, account *models.UserBankAccount, bankBalance string, asOf time.Time) (string, error)
+	// Check сверяет главную книгу: баланс записей и совпадение хранимых остатков с проводками
+	Check(ctx context.Context) (*models.LedgerCheckReport, error)
+}
+
+type ledgerServiceImpl struct {
+	repo      repos.LedgerRepository
+	txManager repos.TxManager
+}
+
+func NewLedgerService(repo repos.LedgerRepository, txManager repos.TxManager) LedgerService {
+	return &ledgerServiceImpl{repo: repo, txManager: txManager}
+}
+
+func (s *ledgerServiceImpl) RecordOpeningBalance(ctx context.Context, account *models.UserBankAccount, amount string) error {
+	k, err := parseMoney(amount)
+	if err != nil {
+		return err
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryOpening,
+		Description: fmt.Sprintf("Входящий остаток счёта %d", account.AccountID),
+		OccurredAt:  account.CreatedAt,
+	}
+	return s.post(ctx, entry, []ledgerLeg{
+		{Account: bankLedgerAccount(models.LedgerBankSettlement, account.BankID), Amount: k},
+		{Account: customerLedgerAccount(account), Amount: -k},
+	})
+}
+
+func (s *ledgerServiceImpl) RecordDisbursement(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error {
+	legs := make([]ledgerLeg, 0, 2*len(splits))
+	for i := range splits {
+		k, err := parseMoney(splits[i].SplitAmount)
+		if err != nil {
+			return err
+		}
+		legs = append(legs,
+			ledgerLeg{Account: principalLedgerAccount(&splits[i]), Amount: k},
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerBankSettlement, splits[i].BankID), Amount: -k},
+		)
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryDisbursement,
+		Description: fmt.Sprintf("Выдача кредита %d", loan.LoanID),
+		LoanID:      &loan.LoanID,
+		OccurredAt:  loan.TakenAt,
+	}
+	return s.post(ctx, entry, legs)
+}
+
+func (s *ledgerServiceImpl) RecordPayout(ctx context.Context, loan *models.Loan, split *models.LoanSplit, account *models.UserBankAccount, amount string) error {
+	k, err := parseMoney(amount)
+	if err != nil {
+		return err
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryPayout,
+		Description: fmt.Sprintf("Зачисление доли %d кредита %d на счёт %d", split.SplitID, loan.LoanID, account.AccountID),
+		LoanID:      &loan.LoanID,
+	}
+	return s.post(ctx, entry, []ledgerLeg{
+		{Account: bankLedgerAccount(models.LedgerBankSettlement, split.BankID), Amount: k},
+		{Account: customerLedgerAccount(account), Amount: -k},
+	})
+}
+
+func (s *ledgerServiceImpl) RecordFees(ctx context.Context, loan *models.Loan, splits []models.LoanSplit) error {
+	fees, premiums, err := loanFeeShares(loan, splits)
+	if err != nil {
+		return err
+	}
+	var legs []ledgerLeg
+	for i, sp := range splits {
+		if fees[i] == 0 && premiums[i] == 0 {
+			continue
+		}
+		legs = append(legs,
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerBankSettlement, sp.BankID), Amount: fees[i] + premiums[i]},
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerFeeIncome, sp.BankID), Amount: -fees[i]},
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerInsurancePayable, sp.BankID), Amount: -premiums[i]},
+		)
+	}
+	if len(legs) == 0 {
+		return nil
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryFee,
+		Description: fmt.Sprintf("Комиссия и страховка по кредиту %d", loan.LoanID),
+		LoanID:      &loan.LoanID,
+		OccurredAt:  loan.TakenAt,
+	}
+	return s.post(ctx, entry, legs)
+}
+
+func (s *ledgerServiceImpl) RecordRepayment(ctx context.Context, payment *models.LoanPayment, allocations []models.PaymentAllocation, splits []models.LoanSplit) error {
+	byID := make(map[int64]*models.LoanSplit, len(splits))
+	for i := range splits {
+		byID[splits[i].SplitID] = &splits[i]
+	}
+
+	var legs []ledgerLeg
+	for _, a := range allocations {
+		split, ok := byID[a.SplitID]
+		if !ok {
+			return fmt.Errorf("split %d is not part of loan %d", a.SplitID, payment.LoanID)
+		}
+		principal, err := parseMoney(a.PrincipalPaid)
+		if err != nil {
+			return err
+		}
+		interest, err := parseMoney(a.InterestPaid)
+		if err != nil {
+			return err
+		}
+		penalty, err := parseMoney(a.PenaltyPaid)
+		if err != nil {
+			return err
+		}
+		fee, err := parseOptionalMoney(a.FeePaid)
+		if err != nil {
+			return err
+		}
+		legs = append(legs,
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerBankSettlement, split.BankID), Amount: principal + interest + penalty + fee},
+			ledgerLeg{Account: principalLedgerAccount(split), Amount: -principal},
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerInterestIncome, split.BankID), Amount: -interest},
+			ledgerLeg{Account: bankLedgerAccount(models.LedgerPenaltyIncome, split.BankID), Amount: -penalty},
+		)
+		if fee > 0 {
+			legs = append(legs, ledgerLeg{Account: bankLedgerAccount(models.LedgerFeeIncome, split.BankID), Amount: -fee})
+		}
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryRepayment,
+		Description: fmt.Sprintf("Платёж %d по кредиту %d", payment.PaymentID, payment.LoanID),
+		LoanID:      &payment.LoanID,
+		PaymentID:   &payment.PaymentID,
+		OccurredAt:  payment.PaidAt,
+	}
+	return s.post(ctx, entry, legs)
+}
+
+func (s *ledgerServiceImpl) RecordTransfer(ctx context.Context, from, to *models.UserBankAccount, amount string, occurredAt time.Time, description string) (*models.LedgerEntry, error) {
+	k, err := parseMoney(amount)
+	if err != nil {
+		return nil, err
+	}
+	if k <= 0 {
+		return nil, fmt.Errorf("transfer amount must be positive")
+	}
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryTransfer,
+		Description: description,
+		OccurredAt:  occurredAt,
+	}
+	err = s.post(ctx, entry, []ledgerLeg{
+		{Account: customerLedgerAccount(from), Amount: k},
+		{Account: customerLedgerAccount(to), Amount: -k},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *ledgerServiceImpl) RecordBankSync(ctx context.Context, account *models.UserBankAccount, bankBalance string, asOf time.Time) (string, error) {
+	target, err := parseMoney(bankBalance)
+	if err != nil {
+		return "", err
+	}
+	current, err := parseMoney(account.Balance)
+	if err != nil {
+		return "", err
+	}
+	diff := target - current
+	if diff == 0 {
+		return formatMoney(0), nil
+	}
+	// Расхождение — движения по счёту вне платформы, как и входящий остаток
+	entry := &models.LedgerEntry{
+		EntryType:   models.LedgerEntryBankSync,
+		Description: fmt.Sprintf("Остаток счёта %d по данным банка", account.AccountID),
+		OccurredAt:  asOf,
+	}
+	err = s.post(ctx, entry, []ledgerLeg{
+		{Account: bankLedgerAccount(models.LedgerBankSettlement, account.BankID), Amount: diff},
+		{Account: customerLedgerAccount(account), Amount: -diff},
+	})
+	if err != nil {
+		return "", err
+	}
+	return formatMoney(diff), nil
+}
+
+// post сохраняет запись: движения по одному счёту сворачиваются, нулевые отбрасываются.
+// Несбалансированная запись — ошибка программы, в БД она не попадает
+func (s *ledgerServiceImpl) post(ctx context.Context, entry *models.LedgerEntry, legs []ledgerLeg) error {
+	var total int64
+	order := make([]string, 0, len(legs))
+	byCode := make(map[string]*ledgerLeg, len(legs))
+	for _, l := range legs {
+		total += l.Amount
+		if acc, ok := byCode[l.Account.Code]; ok {
+			acc.Amount += l.Amount
+			continue
+		}
+		leg := l
+		byCode[l.Account.Code] = &leg
+		order = append(order, l.Account.Code)
+	}
+	if total != 0 {
+		return fmt.Errorf("ledger entry %q is not balanced: debit − credit = %s", entry.Description, formatMoney(total))
+	}
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = time.Now()
+	}
+
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var customers []int64
+		entry.Postings = entry.Postings[:0]
+		for _, code := range order {
+			leg := byCode[code]
+			if leg.Amount == 0 {
+				continue
+			}
+			if err := s.repo.EnsureAccount(ctx, &leg.Account); err != nil {
+				return fmt.Errorf("failed to ensure ledger account %s: %w", code, err)
+			}
+			if leg.Account.Kind == models.LedgerCustomerFunds {
+				customers = append(customers, leg.Account.LedgerAccountID)
+			}
+			entry.Postings = append(entry.Postings, models.LedgerPosting{
+				LedgerAccountID: leg.Account.LedgerAccountID,
+				Amount:          formatMoney(leg.Amount),
+			})
+		}
+		if len(entry.Postings) == 0 {
+			return nil
+		}
+
+		if err := s.repo.CreateEntry(ctx, entry); err != nil {
+			return fmt.Errorf("failed to create ledger entry: %w", err)
+		}
+		if len(customers) > 0 {
+			if err := s.repo.RefreshCustomerBalances(ctx, customers); err != nil {
+				return fmt.Errorf("failed to refresh account balances: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *ledgerServiceImpl) Check(ctx context.Context) (*models.LedgerCheckReport, error) {
+	report := &models.LedgerCheckReport{CheckedAt: time.Now()}
+	var err error
+	if report.Entries, report.Postings, err = s.repo.CountEntries(ctx); err != nil {
+		return nil, fmt.Errorf("failed to count ledger entries: %w", err)
+	}
+	if report.TrialBalance, err = s.repo.TrialBalance(ctx); err != nil {
+		return nil, fmt.Errorf("failed to get trial balance: %w", err)
+	}
+	if report.UnbalancedEntries, err = s.repo.ListUnbalancedEntries(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list unbalanced entries: %w", err)
+	}
+	if report.Mismatches, err = s.repo.ListMismatches(ctx); err != nil {
+		return nil, fmt.Errorf("failed to list balance mismatches: %w", err)
+	}
+	return report, nil
+}
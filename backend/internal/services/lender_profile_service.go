@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// LenderProfileService управляет профилями кредиторов: лимитами, валютами,
+// риск-аппетитом и базовыми ставками по продуктам
+type LenderProfileService interface {
+	ListProfiles(ctx context.Context) ([]models.LenderProfile, error)
+	GetProfile(ctx context.Context, bankID int16) (*models.LenderProfile, error)
+	CreateProfile(ctx context.Context, profile *models.LenderProfile) (*models.LenderProfile, error)
+	UpdateProfile(ctx context.Context, profile *models.LenderProfile) (*models.LenderProfile, error)
+	DeleteProfile(ctx context.Context, bankID int16) error
+}
+
+type lenderProfileServiceImpl struct {
+	settingsRepo repos.LendingSettingsRepository
+	bankRepo     repos.BankRepository
+	txManager    repos.TxManager
+}
+
+func NewLenderProfileService(settingsRepo repos.LendingSettingsRepository, bankRepo repos.BankRepository, txManager repos.TxManager) LenderProfileService {
+	return &lenderProfileServiceImpl{
+		settingsRepo: settingsRepo,
+		bankRepo:     bankRepo,
+		txManager:    txManager,
+	}
+}
+
+func (s *lenderProfileServiceImpl) ListProfiles(ctx context.Context) ([]models.LenderProfile, error) {
+	settings, err := s.settingsRepo.ListSettings(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lending settings: %w", err)
+	}
+
+	res := make([]models.LenderProfile, 0, len(settings))
+	for _, st := range settings {
+		p, err := s.buildProfile(ctx, st)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *p)
+	}
+	return res, nil
+}
+
+func (s *lenderProfileServiceImpl) GetProfile(ctx context.Context, bankID int16) (*models.LenderProfile, error) {
+	st, err := s.settingsRepo.GetSettings(ctx, bankID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: lender profile for bank %d", apperrors.ErrNotFound, bankID)
+		}
+		return nil, fmt.Errorf("failed to get lending settings: %w", err)
+	}
+	return s.buildProfile(ctx, *st)
+}
+
+func (s *lenderProfileServiceImpl) CreateProfile(ctx context.Context, profile *models.LenderProfile) (*models.LenderProfile, error) {
+	if err := validateLenderProfile(profile); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureBank(ctx, profile.BankID); err != nil {
+			return err
+		}
+		if _, err := s.settingsRepo.GetSettings(ctx, profile.BankID); err == nil {
+			return fmt.Errorf("%w: lender profile for bank %d already exists", apperrors.ErrConflict, profile.BankID)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get lending settings: %w", err)
+		}
+
+		if err := s.settingsRepo.CreateSettings(ctx, &profile.BankLendingSettings); err != nil {
+			return fmt.Errorf("failed to create lending settings: %w", err)
+		}
+		if err := s.settingsRepo.ReplaceBankAppetite(ctx, profile.BankID, profile.Products); err != nil {
+			return fmt.Errorf("failed to save lender products: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProfile(ctx, profile.BankID)
+}
+
+func (s *lenderProfileServiceImpl) UpdateProfile(ctx context.Context, profile *models.LenderProfile) (*models.LenderProfile, error) {
+	if err := validateLenderProfile(profile); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.settingsRepo.UpdateSettings(ctx, &profile.BankLendingSettings)
+		if err != nil {
+			return fmt.Errorf("failed to update lending settings: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: lender profile for bank %d", apperrors.ErrNotFound, profile.BankID)
+		}
+		if err := s.settingsRepo.ReplaceBankAppetite(ctx, profile.BankID, profile.Products); err != nil {
+			return fmt.Errorf("failed to save lender products: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProfile(ctx, profile.BankID)
+}
+
+func (s *lenderProfileServiceImpl) DeleteProfile(ctx context.Context, bankID int16) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.settingsRepo.DeleteSettings(ctx, bankID)
+		if err != nil {
+			return fmt.Errorf("failed to delete lending settings: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: lender profile for bank %d", apperrors.ErrNotFound, bankID)
+		}
+		return nil
+	})
+}
+
+func (s *lenderProfileServiceImpl) ensureBank(ctx context.Context, bankID int16) error {
+	if _, err := s.bankRepo.GetBankByID(ctx, bankID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: bank %d", apperrors.ErrNotFound, bankID)
+		}
+		return fmt.Errorf("failed to get bank: %w", err)
+	}
+	return nil
+}
+
+func (s *lenderProfileServiceImpl) buildProfile(ctx context.Context, st models.BankLendingSettings) (*models.LenderProfile, error) {
+	bank, err := s.bankRepo.GetBankByID(ctx, st.BankID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank %d: %w", st.BankID, err)
+	}
+	products, err := s.settingsRepo.ListBankAppetite(ctx, st.BankID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lender products: %w", err)
+	}
+	capacity, err := availableCapacity(st)
+	if err != nil {
+		return nil, fmt.Errorf("invalid lending settings for bank %d: %w", st.BankID, err)
+	}
+
+	return &models.LenderProfile{
+		BankLendingSettings: st,
+		BankName:            bank.Name,
+		AvailableCapacity:   formatMoney(capacity),
+		Products:            products,
+	}, nil
+}
+
+// validateLenderProfile проверяет профиль и приводит суммы к формату numeric(18,2)
+func validateLenderProfile(p *models.LenderProfile) error {
+	minTicket, err := parseMoney(p.MinTicket)
+	if err != nil || minTicket < 0 {
+		return fmt.Errorf("%w: invalid min_ticket %q", apperrors.ErrBadRequest, p.MinTicket)
+	}
+	maxTicket, err := parseMoney(p.MaxTicket)
+	if err != nil || maxTicket <= 0 || maxTicket < minTicket {
+		return fmt.Errorf("%w: max_ticket must be positive and not less than min_ticket", apperrors.ErrBadRequest)
+	}
+	limit, err := parseMoney(p.ExposureLimit)
+	if err != nil || limit < 0 {
+		return fmt.Errorf("%w: invalid exposure_limit %q", apperrors.ErrBadRequest, p.ExposureLimit)
+	}
+	share, err := parseRate(p.TargetShare)
+	if err != nil || share > 100 {
+		return fmt.Errorf("%w: target_share must be between 0 and 100", apperrors.ErrBadRequest)
+	}
+	p.MinTicket, p.MaxTicket, p.ExposureLimit = formatMoney(minTicket), formatMoney(maxTicket), formatMoney(limit)
+	p.TargetShare = formatRate(share)
+
+	if len(p.Currencies) == 0 {
+		return fmt.Errorf("%w: at least one currency is required", apperrors.ErrBadRequest)
+	}
+	for _, c := range p.Currencies {
+		if !validCurrency(c) {
+			return fmt.Errorf("%w: invalid currency %q", apperrors.ErrBadRequest, c)
+		}
+	}
+
+	switch p.RiskAppetite {
+	case "":
+		p.RiskAppetite = models.RiskAppetiteMedium
+	case models.RiskAppetiteLow, models.RiskAppetiteMedium, models.RiskAppetiteHigh:
+	default:
+		return fmt.Errorf("%w: invalid risk_appetite %q", apperrors.ErrBadRequest, p.RiskAppetite)
+	}
+
+	seen := make(map[string]bool, len(p.Products))
+	for i := range p.Products {
+		a := &p.Products[i]
+		if !validLoanType(a.TypeCode) {
+			return fmt.Errorf("%w: unknown loan type %q", apperrors.ErrBadRequest, a.TypeCode)
+		}
+		if seen[a.TypeCode] {
+			return fmt.Errorf("%w: duplicate product %s", apperrors.ErrBadRequest, a.TypeCode)
+		}
+		seen[a.TypeCode] = true
+
+		if a.Weight == "" {
+			a.Weight = "1"
+		}
+		w, err := strconv.ParseFloat(a.Weight, 64)
+		if err != nil || w < 0 || w >= 1000 {
+			return fmt.Errorf("%w: invalid weight %q for %s", apperrors.ErrBadRequest, a.Weight, a.TypeCode)
+		}
+		a.Weight = formatRate(w)
+
+		if a.BaseRate != nil {
+			r, err := parseRate(*a.BaseRate)
+			if err != nil || r <= 0 || r >= 1000 {
+				return fmt.Errorf("%w: invalid base_rate %q for %s", apperrors.ErrBadRequest, *a.BaseRate, a.TypeCode)
+			}
+			rate := formatRate(r)
+			a.BaseRate = &rate
+		}
+		a.BankID = p.BankID
+	}
+	return nil
+}
+
+// validCurrency — трёхбуквенный код ISO 4217 в верхнем регистре
+func validCurrency(c string) bool {
+	if len(c) != 3 {
+		return false
+	}
+	for _, r := range c {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -2,70 +2,345 @@ package services
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
+	"github.com/Arlandaren/easyfund/internal/config"
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
 	"github.com/Arlandaren/easyfund/internal/models"
 	"github.com/Arlandaren/easyfund/internal/repos"
 )
 
 type LoanService interface {
 	CreateLoan(ctx context.Context, loan *models.Loan, splits []map[int16]string) (*models.LoanDetailDTO, error)
+	// CreateLoanWithSplitRates — как CreateLoan, но доля банка идёт по ставке rates[bank_id];
+	// банки без ставки в rates получают loan.InterestRate
+	CreateLoanWithSplitRates(ctx context.Context, loan *models.Loan, splits []map[int16]string, rates map[int16]string) (*models.LoanDetailDTO, error)
 	GetLoanDetail(ctx context.Context, loanID int64) (*models.LoanDetailDTO, error)
 	ListUserLoans(ctx context.Context, userID int64) ([]models.Loan, error)
-	MakePayment(ctx context.Context, payment *models.LoanPayment, allocations []models.PaymentAllocation) error
-	GetTotalDebt(ctx context.Context, userID int64) (string, error)
+	// MakePayment распределяет payment.TotalAmount по долям кредита согласно payment.AllocationStrategy
+	MakePayment(ctx context.Context, payment *models.LoanPayment) (*models.PaymentResultDTO, error)
+	// Prepay — досрочное погашение с перестроением графика (mode: SHORTEN_TERM | REDUCE_INSTALLMENT)
+	Prepay(ctx context.Context, payment *models.LoanPayment, mode string) (*models.PrepaymentResultDTO, error)
+	// GetTotalDebt — задолженность пользователя по непогашенным кредитам с разбивкой по банкам и кредитам
+	GetTotalDebt(ctx context.Context, userID int64) (*models.DebtSummaryDTO, error)
+	GetLoanSchedule(ctx context.Context, loanID int64) (*models.LoanScheduleDTO, error)
+	// ListBankDelinquencies — просроченные доли банка, bucket: 1-30 | 31-60 | 61-90 | 90+ или пусто
+	ListBankDelinquencies(ctx context.Context, bankID int16, bucket string) ([]models.BankDelinquencyItem, error)
+	GetLoanHistory(ctx context.Context, loanID int64) ([]models.StatusHistoryEntry, error)
 }
 
 type loanServiceImpl struct {
-	loanRepo    repos.LoanRepository
-	accountRepo repos.UserBankAccountRepository
-	paymentRepo repos.LoanPaymentRepository
+	loanRepo     repos.LoanRepository
+	accountRepo  repos.UserBankAccountRepository
+	paymentRepo  repos.LoanPaymentRepository
+	scheduleRepo repos.LoanScheduleRepository
+	bankRepo     repos.BankRepository
+	productRepo  repos.ProductRepository
+	txManager    repos.TxManager
+	ledger       LedgerService
+	disbursement DisbursementService
+	accrual      InterestAccrualService
+	delinquency  DelinquencyService
+	status       StatusService
+	cfg          config.LoanConfig
 }
 
-func NewLoanService(loanRepo repos.LoanRepository, accountRepo repos.UserBankAccountRepository, paymentRepo repos.LoanPaymentRepository) LoanService {
+func NewLoanService(
+	loanRepo repos.LoanRepository,
+	accountRepo repos.UserBankAccountRepository,
+	paymentRepo repos.LoanPaymentRepository,
+	scheduleRepo repos.LoanScheduleRepository,
+	bankRepo repos.BankRepository,
+	productRepo repos.ProductRepository,
+	txManager repos.TxManager,
+	ledger LedgerService,
+	disbursement DisbursementService,
+	accrual InterestAccrualService,
+	delinquency DelinquencyService,
+	status StatusService,
+	cfg config.LoanConfig,
+) LoanService {
 	return &loanServiceImpl{
-		loanRepo:    loanRepo,
-		accountRepo: accountRepo,
-		paymentRepo: paymentRepo,
+		loanRepo:     loanRepo,
+		accountRepo:  accountRepo,
+		paymentRepo:  paymentRepo,
+		scheduleRepo: scheduleRepo,
+		bankRepo:     bankRepo,
+		productRepo:  productRepo,
+		txManager:    txManager,
+		ledger:       ledger,
+		disbursement: disbursement,
+		accrual:      accrual,
+		delinquency:  delinquency,
+		status:       status,
+		cfg:          cfg,
 	}
 }
 
 func (s *loanServiceImpl) CreateLoan(ctx context.Context, loan *models.Loan, splits []map[int16]string) (*models.LoanDetailDTO, error) {
-	// Создаём кредит
-	loanID, err := s.loanRepo.CreateLoan(ctx, loan)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create loan: %w", err)
-	}
+	return s.CreateLoanWithSplitRates(ctx, loan, splits, nil)
+}
 
-	loan.LoanID = loanID
+func (s *loanServiceImpl) CreateLoanWithSplitRates(ctx context.Context, loan *models.Loan, splits []map[int16]string, rates map[int16]string) (*models.LoanDetailDTO, error) {
+	if loan.TermMonths == 0 {
+		loan.TermMonths = defaultTermMonths
+	}
+	if loan.RepaymentType == "" {
+		loan.RepaymentType = RepaymentAnnuity
+	}
+	if loan.DayCountConvention == "" {
+		loan.DayCountConvention = DayCountAct365
+	}
+	if _, err := parseRate(loan.InterestRate); err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	for bankID, rate := range rates {
+		if _, err := parseRate(rate); err != nil {
+			return nil, fmt.Errorf("%w: bank %d: %v", apperrors.ErrBadRequest, bankID, err)
+		}
+	}
 
-	// Создаём splits для каждого банка
 	var loanSplits []models.LoanSplit
-	for _, splitData := range splits {
-		for bankID, amount := range splitData {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		planned, err := s.validateSplits(ctx, loan.OriginalAmount, splits)
+		if err != nil {
+			return err
+		}
+		var product *models.LoanProduct
+		if loan.ProductID != nil {
+			if product, err = s.checkProduct(ctx, loan, planned, rates); err != nil {
+				return err
+			}
+		}
+
+		// Графики долей строим до сохранения: ПСК долей и кредита фиксируется при выдаче
+		principal, _ := parseMoney(loan.OriginalAmount)
+		fees, err := productFees(product, principal)
+		if err != nil {
+			return fmt.Errorf("invalid product fees: %w", err)
+		}
+		loan.OriginationFee = formatMoney(fees.Origination)
+		loan.InsurancePremium = formatMoney(fees.Insurance)
+		loan.MonthlyFee = formatMoney(fees.Monthly)
+		if product != nil {
+			loan.GracePeriodDays = product.GracePeriodDays
+		}
+
+		// Плата за обслуживание делится между долями пропорционально суммам и входит в каждый их платёж
+		weights := make([]int64, len(planned))
+		for i, p := range planned {
+			weights[i], _ = parseMoney(p.Amount)
+		}
+		monthlyFees := prorate(fees.Monthly, weights)
+
+		schedules := make([][]models.LoanScheduleItem, 0, len(planned))
+		var all []models.LoanScheduleItem
+		for i, p := range planned {
+			rate, ok := rates[p.BankID]
+			if !ok {
+				rate = loan.InterestRate
+			}
 			split := models.LoanSplit{
-				LoanID:             loanID,
-				BankID:             bankID,
-				SplitAmount:        amount,
-				RemainingPrincipal: amount,
+				BankID:             p.BankID,
+				SplitAmount:        p.Amount,
+				RemainingPrincipal: p.Amount,
+				InterestRate:       rate,
+				AccruedThrough:     dateOnly(loan.TakenAt).AddDate(0, 0, -1),
 			}
-			err := s.loanRepo.CreateLoanSplit(ctx, &split)
+			items, err := buildSplitSchedule(loan, &split)
 			if err != nil {
-				return nil, fmt.Errorf("failed to create loan split: %w", err)
+				return err
+			}
+			for j := range items {
+				items[j].Fee = formatMoney(monthlyFees[i])
 			}
+			amount, _ := parseMoney(p.Amount)
+			psk, pskAmount, err := creditCost(amount, items, fees.share(amount, principal))
+			if err != nil {
+				return fmt.Errorf("failed to calculate split credit cost: %w", err)
+			}
+			split.Psk, split.PskAmount = &psk, &pskAmount
+
 			loanSplits = append(loanSplits, split)
+			schedules = append(schedules, items)
+			all = append(all, items...)
+		}
+		combined, err := combineSchedules(all)
+		if err != nil {
+			return fmt.Errorf("failed to combine schedules: %w", err)
+		}
+		psk, pskAmount, err := creditCost(principal, combined, fees)
+		if err != nil {
+			return fmt.Errorf("failed to calculate credit cost: %w", err)
 		}
+		loan.Psk, loan.PskAmount = &psk, &pskAmount
+
+		// Создаём кредит
+		loanID, err := s.loanRepo.CreateLoan(ctx, loan)
+		if err != nil {
+			return fmt.Errorf("failed to create loan: %w", err)
+		}
+
+		loan.LoanID = loanID
+		if err := s.status.RecordLoanCreated(ctx, loanID, loan.Status, &loan.UserID); err != nil {
+			return err
+		}
+
+		// Создаём splits для каждого банка
+		for i := range loanSplits {
+			split := &loanSplits[i]
+			split.LoanID = loanID
+			if err := s.loanRepo.CreateLoanSplit(ctx, split); err != nil {
+				return fmt.Errorf("failed to create loan split: %w", err)
+			}
+			for j := range schedules[i] {
+				schedules[i][j].SplitID = split.SplitID
+			}
+			if err := s.scheduleRepo.CreateScheduleItems(ctx, schedules[i]); err != nil {
+				return fmt.Errorf("failed to save schedule: %w", err)
+			}
+		}
+
+		if err := s.ledger.RecordDisbursement(ctx, loan, loanSplits); err != nil {
+			return fmt.Errorf("failed to record disbursement: %w", err)
+		}
+		if err := s.ledger.RecordFees(ctx, loan, loanSplits); err != nil {
+			return fmt.Errorf("failed to record fees: %w", err)
+		}
+		// Неудачное зачисление доли не отменяет выдачу: доля остаётся FAILED до повтора
+		if err := s.disbursement.DisburseSplits(ctx, loan, loanSplits); err != nil {
+			return fmt.Errorf("failed to disburse loan: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.LoanDetailDTO{
-		Loan:           loan,
-		Splits:         loanSplits,
-		PercentPaid:    0.0,
-		RemainingDebt:  loan.OriginalAmount,
-		PaymentHistory: []models.LoanPayment{},
+		Loan:               loan,
+		Splits:             loanSplits,
+		PercentPaid:        0.0,
+		RemainingDebt:      loan.OriginalAmount,
+		PaymentHistory:     []models.LoanPayment{},
+		CreditCost:         loanCreditCost(loan),
+		DisbursementStatus: disbursementStatus(loanSplits),
 	}, nil
 }
 
+// plannedSplit — доля кредита после валидации
+type plannedSplit struct {
+	BankID int16
+	Amount string
+}
+
+// validateSplits проверяет доли кредита: банки существуют и не повторяются,
+// суммы положительны и в сумме дают total. Возвращает доли по возрастанию bank_id.
+func (s *loanServiceImpl) validateSplits(ctx context.Context, total string, splits []map[int16]string) ([]plannedSplit, error) {
+	want, err := parseMoney(total)
+	if err != nil || want <= 0 {
+		return nil, fmt.Errorf("%w: invalid loan amount %q", apperrors.ErrBadRequest, total)
+	}
+
+	var planned []plannedSplit
+	seen := map[int16]bool{}
+	var sum int64
+	for _, splitData := range splits {
+		for bankID, amount := range splitData {
+			if seen[bankID] {
+				return nil, fmt.Errorf("%w: duplicate split for bank %d", apperrors.ErrBadRequest, bankID)
+			}
+			seen[bankID] = true
+
+			k, err := parseMoney(amount)
+			if err != nil || k <= 0 {
+				return nil, fmt.Errorf("%w: invalid split amount %q for bank %d", apperrors.ErrBadRequest, amount, bankID)
+			}
+			if _, err := s.bankRepo.GetBankByID(ctx, bankID); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil, fmt.Errorf("%w: bank %d does not exist", apperrors.ErrBadRequest, bankID)
+				}
+				return nil, fmt.Errorf("failed to get bank: %w", err)
+			}
+
+			sum += k
+			planned = append(planned, plannedSplit{BankID: bankID, Amount: formatMoney(k)})
+		}
+	}
+
+	if len(planned) == 0 {
+		return nil, fmt.Errorf("%w: at least one split is required", apperrors.ErrBadRequest)
+	}
+	if sum != want {
+		return nil, fmt.Errorf("%w: splits sum %s does not match loan amount %s", apperrors.ErrBadRequest, formatMoney(sum), formatMoney(want))
+	}
+
+	sort.Slice(planned, func(i, j int) bool { return planned[i].BankID < planned[j].BankID })
+	return planned, nil
+}
+
+// checkProduct проверяет кредит по ограничениям продукта: сумма, срок,
+// ставки всех долей и банки, выдающие продукт. Возвращает продукт
+func (s *loanServiceImpl) checkProduct(ctx context.Context, loan *models.Loan, planned []plannedSplit, rates map[int16]string) (*models.LoanProduct, error) {
+	p, err := loadProduct(ctx, s.productRepo, *loan.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Currency != models.CurrencyRUB {
+		return nil, fmt.Errorf("%w: product %s is issued in %s, loans are issued in %s", apperrors.ErrBadRequest, p.Code, p.Currency, models.CurrencyRUB)
+	}
+	if loan.Purpose == "" {
+		loan.Purpose = p.TypeCode
+	}
+	if err := checkProductTerms(p, loan.OriginalAmount, loan.TermMonths); err != nil {
+		return nil, err
+	}
+	if err := checkProductRate(p, loan.InterestRate); err != nil {
+		return nil, err
+	}
+	for _, sp := range planned {
+		if err := checkProductBank(p, sp.BankID); err != nil {
+			return nil, err
+		}
+		if rate, ok := rates[sp.BankID]; ok {
+			if err := checkProductRate(p, rate); err != nil {
+				return nil, fmt.Errorf("bank %d: %w", sp.BankID, err)
+			}
+		}
+	}
+	return p, nil
+}
+
+// buildSplitSchedule строит график платежей для доли кредита
+func buildSplitSchedule(loan *models.Loan, split *models.LoanSplit) ([]models.LoanScheduleItem, error) {
+	principal, err := parseMoney(split.SplitAmount)
+	if err != nil {
+		return nil, fmt.Errorf("invalid split amount for bank %d: %w", split.BankID, err)
+	}
+	rate, err := parseRate(split.InterestRate)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := buildSchedule(scheduleParams{
+		Principal:     principal,
+		AnnualRate:    rate,
+		TermMonths:    loan.TermMonths,
+		Start:         loan.TakenAt,
+		RepaymentType: loan.RepaymentType,
+		DayCount:      loan.DayCountConvention,
+		Remainder:     split.InterestRemainder,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build schedule: %w", err)
+	}
+	return items, nil
+}
+
 func (s *loanServiceImpl) GetLoanDetail(ctx context.Context, loanID int64) (*models.LoanDetailDTO, error) {
 	loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
 	if err != nil {
@@ -82,16 +357,39 @@ func (s *loanServiceImpl) GetLoanDetail(ctx context.Context, loanID int64) (*mod
 		return nil, fmt.Errorf("failed to get payments: %w", err)
 	}
 
-	// Вычисляем процент выплаченного и остаток долга
-	percentPaid := 0.0
-	remainingDebt := loan.OriginalAmount
+	delinquency, err := loanDelinquency(splits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build delinquency: %w", err)
+	}
+
+	// Процент выплаченного и остаток долга считаем по долям
+	var totals debtTotals
+	for _, sp := range splits {
+		t, err := splitTotals(sp)
+		if err != nil {
+			return nil, err
+		}
+		totals.add(t)
+	}
+
+	nextDue, err := s.nextDue(ctx, loan, splits, time.Now())
+	if err != nil {
+		return nil, err
+	}
 
 	return &models.LoanDetailDTO{
-		Loan:           loan,
-		Splits:         splits,
-		PercentPaid:    percentPaid,
-		RemainingDebt:  remainingDebt,
-		PaymentHistory: payments,
+		Loan:               loan,
+		Splits:             splits,
+		PercentPaid:        totals.percentPaid(),
+		RemainingDebt:      formatMoney(totals.outstanding()),
+		PaymentHistory:     payments,
+		Delinquency:        delinquency,
+		RemainingPrincipal: formatMoney(totals.Principal),
+		AccruedInterest:    formatMoney(totals.Interest),
+		PenaltyInterest:    formatMoney(totals.Penalty),
+		NextDue:            nextDue,
+		CreditCost:         loanCreditCost(loan),
+		DisbursementStatus: disbursementStatus(splits),
 	}, nil
 }
 
@@ -99,31 +397,543 @@ func (s *loanServiceImpl) ListUserLoans(ctx context.Context, userID int64) ([]mo
 	return s.loanRepo.ListUserLoans(ctx, userID)
 }
 
-func (s *loanServiceImpl) MakePayment(ctx context.Context, payment *models.LoanPayment, allocations []models.PaymentAllocation) error {
-	// Создаём платеж
+func (s *loanServiceImpl) MakePayment(ctx context.Context, payment *models.LoanPayment) (*models.PaymentResultDTO, error) {
+	amount, err := s.preparePayment(payment)
+	if err != nil {
+		return nil, err
+	}
+	payment.PaymentType = models.PaymentTypeRegular
+
+	var result *models.PaymentResultDTO
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.getOpenLoan(ctx, payment.LoanID)
+		if err != nil {
+			return err
+		}
+		result, _, err = s.applyPayment(ctx, loan, payment, amount, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *loanServiceImpl) Prepay(ctx context.Context, payment *models.LoanPayment, mode string) (*models.PrepaymentResultDTO, error) {
+	if mode != PrepaymentShortenTerm && mode != PrepaymentReduceInstallment {
+		return nil, fmt.Errorf("%w: unknown prepayment mode %q", apperrors.ErrBadRequest, mode)
+	}
+	amount, err := s.preparePayment(payment)
+	if err != nil {
+		return nil, err
+	}
+	payment.PaymentType = models.PaymentTypePrepayment
+
+	result := &models.PrepaymentResultDTO{Mode: mode}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.getActiveLoan(ctx, payment.LoanID)
+		if err != nil {
+			return err
+		}
+
+		// Плановые платежи со сроком по дату погашения сначала гасятся обычным платежом:
+		// досрочное погашение в них не засчитывается, и доля ушла бы в просрочку
+		due, err := s.scheduledDue(ctx, loan.LoanID, payment.PaidAt)
+		if err != nil {
+			return err
+		}
+		var dueTotal int64
+		for _, v := range due {
+			dueTotal += v
+		}
+		if dueTotal > 0 {
+			part := min(amount, dueTotal)
+			regular := *payment
+			regular.PaymentType = models.PaymentTypeRegular
+			regular.TotalAmount = formatMoney(part)
+			if part < dueTotal {
+				due = nil // суммы не хватает на плановые платежи — распределяем по стратегии
+			}
+			paid, _, err := s.applyPayment(ctx, loan, &regular, part, due)
+			if err != nil {
+				return err
+			}
+			amount -= part
+			if amount == 0 {
+				result.PaymentResultDTO = *paid
+				return nil
+			}
+			result.RegularPayment = paid
+			payment.TotalAmount = formatMoney(amount)
+		}
+
+		paid, balances, err := s.applyPayment(ctx, loan, payment, amount, nil)
+		if err != nil {
+			return err
+		}
+		result.PaymentResultDTO = *paid
+
+		// Перестраиваем хвост графика каждой доли от даты досрочного погашения
+		from := dateOnly(payment.PaidAt)
+		maxNo := 0
+		for _, b := range balances {
+			lastNo, err := s.rescheduleSplit(ctx, loan, b.Split, b.Principal, from, mode)
+			if err != nil {
+				return fmt.Errorf("failed to reschedule split %d: %w", b.Split.SplitID, err)
+			}
+			maxNo = max(maxNo, lastNo)
+		}
+
+		if maxNo > 0 && maxNo != loan.TermMonths {
+			if err := s.loanRepo.UpdateLoanTerm(ctx, loan.LoanID, maxNo); err != nil {
+				return fmt.Errorf("failed to update loan term: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Schedule, err = s.GetLoanSchedule(ctx, payment.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// preparePayment валидирует сумму и стратегию платежа, возвращает сумму в копейках
+func (s *loanServiceImpl) preparePayment(payment *models.LoanPayment) (int64, error) {
+	amount, err := parseMoney(payment.TotalAmount)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("%w: payment amount must be positive", apperrors.ErrBadRequest)
+	}
+	if payment.AllocationStrategy == "" {
+		payment.AllocationStrategy = s.cfg.AllocationStrategy
+	}
+	if !isValidAllocationStrategy(payment.AllocationStrategy) {
+		return 0, fmt.Errorf("%w: unknown allocation strategy %q", apperrors.ErrBadRequest, payment.AllocationStrategy)
+	}
+	return amount, nil
+}
+
+// getOpenLoan возвращает непогашенный кредит (в том числе просроченный)
+func (s *loanServiceImpl) getOpenLoan(ctx context.Context, loanID int64) (*models.Loan, error) {
+	loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+	if !isOpenLoan(loan.Status) {
+		return nil, fmt.Errorf("%w: loan %d is %s", apperrors.ErrBadRequest, loan.LoanID, loan.Status)
+	}
+	return loan, nil
+}
+
+// getActiveLoan возвращает кредит без просрочки: досрочное погашение возможно только при отсутствии просрочки
+func (s *loanServiceImpl) getActiveLoan(ctx context.Context, loanID int64) (*models.Loan, error) {
+	loan, err := s.getOpenLoan(ctx, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if loan.Status != models.LoanStatusActive {
+		return nil, fmt.Errorf("%w: loan %d is %s, pay the arrears first", apperrors.ErrBadRequest, loan.LoanID, loan.Status)
+	}
+	return loan, nil
+}
+
+// splitBalance — остаток основного долга по доле после платежа
+type splitBalance struct {
+	Split     models.LoanSplit
+	Principal int64
+}
+
+// applyPayment проводит платёж внутри открытой транзакции: доначисляет проценты и пени,
+// распределяет сумму по долям, обновляет остатки, пересчитывает просрочку
+// и закрывает кредит при полном погашении. scheduled — суммы плановых платежей по долям:
+// если заданы, доля получает свой платёж, иначе сумма распределяется по стратегии платежа.
+func (s *loanServiceImpl) applyPayment(ctx context.Context, loan *models.Loan, payment *models.LoanPayment, amount int64, scheduled map[int64]int64) (*models.PaymentResultDTO, []splitBalance, error) {
+	// Доначисляем проценты и пени по день перед платежом, чтобы погасить их в первую очередь
+	if err := s.accrual.AccrueLoan(ctx, loan.LoanID, dateOnly(payment.PaidAt).AddDate(0, 0, -1)); err != nil {
+		return nil, nil, fmt.Errorf("failed to accrue interest: %w", err)
+	}
+
+	splits, err := s.loanRepo.GetLoanSplitsForUpdate(ctx, loan.LoanID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lock loan splits: %w", err)
+	}
+
+	dues, err := s.splitDues(ctx, loan.LoanID, splits, payment.PaidAt)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var parts []int64
+	if scheduled != nil {
+		parts, err = allocateScheduled(amount, dues, scheduled)
+	} else {
+		parts, err = allocatePayment(amount, dues, payment.AllocationStrategy)
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+
 	paymentID, err := s.paymentRepo.CreatePayment(ctx, payment)
 	if err != nil {
-		return fmt.Errorf("failed to create payment: %w", err)
+		return nil, nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+	payment.PaymentID = paymentID
+
+	result := &models.PaymentResultDTO{Payment: payment}
+	balances := make([]splitBalance, 0, len(dues))
+
+	// Внутри доли сначала гасим начисленные проценты, затем пени, плату за обслуживание и основной долг
+	var remainingDebt int64
+	for i, d := range dues {
+		interestPaid := min(parts[i], d.Interest)
+		penaltyPaid := min(parts[i]-interestPaid, d.Penalty)
+		feePaid := min(parts[i]-interestPaid-penaltyPaid, d.Fee)
+		principalPaid := parts[i] - interestPaid - penaltyPaid - feePaid
+		newPrincipal := d.Principal - principalPaid
+		remainingDebt += newPrincipal + d.Interest - interestPaid + d.Penalty - penaltyPaid + d.Fee - feePaid
+		balances = append(balances, splitBalance{Split: d.Split, Principal: newPrincipal})
+
+		if parts[i] == 0 {
+			continue
+		}
+
+		alloc := models.PaymentAllocation{
+			PaymentID:     paymentID,
+			SplitID:       d.Split.SplitID,
+			PrincipalPaid: formatMoney(principalPaid),
+			InterestPaid:  formatMoney(interestPaid),
+			PenaltyPaid:   formatMoney(penaltyPaid),
+			FeePaid:       formatMoney(feePaid),
+		}
+		if err := s.paymentRepo.CreatePaymentAllocation(ctx, &alloc); err != nil {
+			return nil, nil, fmt.Errorf("failed to create payment allocation: %w", err)
+		}
+		result.Allocations = append(result.Allocations, alloc)
+
+		if interestPaid > 0 || penaltyPaid > 0 {
+			err := s.loanRepo.UpdateLoanSplitAccrual(ctx, d.Split.SplitID,
+				formatMoney(d.Interest-interestPaid), formatMoney(d.Penalty-penaltyPaid), d.Split.AccruedThrough)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to update split accrued interest: %w", err)
+			}
+		}
+		if principalPaid > 0 {
+			if err := s.loanRepo.UpdateLoanSplitPrincipal(ctx, d.Split.SplitID, formatMoney(newPrincipal)); err != nil {
+				return nil, nil, fmt.Errorf("failed to update split principal: %w", err)
+			}
+		}
 	}
 
-	// Создаём allocations и обновляем remaining_principal
-	for _, alloc := range allocations {
-		alloc.PaymentID = paymentID
-		err := s.paymentRepo.CreatePaymentAllocation(ctx, &alloc)
+	if err := s.ledger.RecordRepayment(ctx, payment, result.Allocations, splits); err != nil {
+		return nil, nil, fmt.Errorf("failed to record repayment: %w", err)
+	}
+
+	if remainingDebt == 0 {
+		if err := s.status.TransitionLoan(ctx, loan.LoanID, models.LoanStatusClosed, &payment.UserID, "paid off"); err != nil {
+			return nil, nil, fmt.Errorf("failed to close loan: %w", err)
+		}
+		result.LoanStatus = models.LoanStatusClosed
+	} else {
+		// Платёж мог погасить просрочку — пересчитываем её и статус кредита
+		if _, err := s.delinquency.Refresh(ctx, loan.LoanID, dateOnly(payment.PaidAt)); err != nil {
+			return nil, nil, fmt.Errorf("failed to refresh delinquency: %w", err)
+		}
+		updated, err := s.loanRepo.GetLoanByID(ctx, loan.LoanID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get loan: %w", err)
+		}
+		result.LoanStatus = updated.Status
+	}
+	result.RemainingDebt = formatMoney(remainingDebt)
+	return result, balances, nil
+}
+
+// rescheduleSplit заменяет будущие платежи доли (после from) новым графиком на остаток principal.
+// SHORTEN_TERM сохраняет размер платежа и сокращает срок, REDUCE_INSTALLMENT — наоборот.
+// Возвращает номер последнего платежа доли.
+func (s *loanServiceImpl) rescheduleSplit(ctx context.Context, loan *models.Loan, split models.LoanSplit, principal int64, from time.Time, mode string) (int, error) {
+	items, err := s.scheduleRepo.GetSplitSchedule(ctx, split.SplitID)
+	if err != nil {
+		return 0, err
+	}
+
+	lastNo := 0
+	var future []models.LoanScheduleItem
+	for _, it := range items {
+		if it.DueDate.After(from) {
+			future = append(future, it)
+		} else {
+			lastNo = it.InstallmentNo
+		}
+	}
+
+	if err := s.scheduleRepo.DeleteScheduleItemsAfter(ctx, split.SplitID, from); err != nil {
+		return 0, err
+	}
+	if principal == 0 {
+		return lastNo, nil
+	}
+
+	rate, err := parseRate(split.InterestRate)
+	if err != nil {
+		return 0, err
+	}
+	monthlyRate := rate / 100 / 12
+
+	term := max(len(future), 1)
+	if mode == PrepaymentShortenTerm && len(future) > 0 {
+		term, err = shortenedTerm(principal, monthlyRate, loan.RepaymentType, future[0])
 		if err != nil {
-			return fmt.Errorf("failed to create payment allocation: %w", err)
+			return 0, err
 		}
+		term = min(term, len(future))
+	}
 
-		// Обновляем remaining_principal в loan_split
-		// TODO: вычислить новый remaining_principal
-		// err = s.loanRepo.UpdateLoanSplitPrincipal(ctx, alloc.SplitID, newRemaining)
+	newItems, err := buildSchedule(scheduleParams{
+		Principal:     principal,
+		AnnualRate:    rate,
+		TermMonths:    term,
+		Start:         loan.TakenAt,
+		RepaymentType: loan.RepaymentType,
+		DayCount:      loan.DayCountConvention,
+		FirstNo:       lastNo + 1,
+		// Проценты по дату погашения уже начислены и уплачены
+		AccrueFrom: from,
+		Remainder:  split.InterestRemainder,
+	})
+	if err != nil {
+		return 0, err
 	}
+	// Плата за обслуживание остаётся прежней в каждом оставшемся платеже
+	fee := ""
+	if len(future) > 0 {
+		fee = future[0].Fee
+	}
+	for i := range newItems {
+		newItems[i].SplitID = split.SplitID
+		newItems[i].Fee = fee
+	}
+	if err := s.scheduleRepo.CreateScheduleItems(ctx, newItems); err != nil {
+		return 0, err
+	}
+	return lastNo + term, nil
+}
 
-	return nil
+// scheduledDue — сколько осталось уплатить по каждой доле по плановым платежам со сроком по day включительно
+func (s *loanServiceImpl) scheduledDue(ctx context.Context, loanID int64, day time.Time) (map[int64]int64, error) {
+	schedule, err := s.scheduleRepo.GetLoanSchedule(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan schedule: %w", err)
+	}
+	paid, err := s.paymentRepo.SumSplitPaid(ctx, loanID, models.PaymentTypeRegular)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum split payments: %w", err)
+	}
+	return scheduledDueBySplit(schedule, paid, day)
 }
 
-func (s *loanServiceImpl) GetTotalDebt(ctx context.Context, userID int64) (string, error) {
-	// SELECT SUM(remaining_principal) FROM loan_splits WHERE loan_id IN (SELECT loan_id FROM loans WHERE user_id = $1 AND status = 'ACTIVE')
-	// TODO: реализовать правильный запрос
-	return "0.00", nil
-}
\ No newline at end of file
+// splitDues собирает задолженность по каждой доле на дату day: остаток основного долга, начисленные проценты и пени,
+// плата за обслуживание по платежам со сроком по day включительно
+func (s *loanServiceImpl) splitDues(ctx context.Context, loanID int64, splits []models.LoanSplit, day time.Time) ([]splitDue, error) {
+	banks, err := s.bankRepo.ListBanks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+	priority := make(map[int16]int16, len(banks))
+	for _, b := range banks {
+		priority[b.BankID] = b.PaymentPriority
+	}
+	schedule, err := s.scheduleRepo.GetLoanSchedule(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan schedule: %w", err)
+	}
+	feesPaid, err := s.paymentRepo.SumSplitFeesPaid(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum split fees: %w", err)
+	}
+
+	dues := make([]splitDue, 0, len(splits))
+	for _, sp := range splits {
+		principal, err := parseMoney(sp.RemainingPrincipal)
+		if err != nil {
+			return nil, err
+		}
+		interest, err := parseMoney(sp.AccruedInterest)
+		if err != nil {
+			return nil, err
+		}
+		penalty, err := parseMoney(sp.PenaltyInterest)
+		if err != nil {
+			return nil, err
+		}
+		rate, err := parseRate(sp.InterestRate)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := splitFeesDue(sp.SplitID, schedule, feesPaid, day)
+		if err != nil {
+			return nil, err
+		}
+		dues = append(dues, splitDue{
+			Split:     sp,
+			Rate:      rate,
+			Principal: principal,
+			Interest:  interest,
+			Penalty:   penalty,
+			Fee:       fee,
+			Priority:  priority[sp.BankID],
+		})
+	}
+	return dues, nil
+}
+
+func (s *loanServiceImpl) ListBankDelinquencies(ctx context.Context, bankID int16, bucket string) ([]models.BankDelinquencyItem, error) {
+	return s.delinquency.ListBankDelinquencies(ctx, bankID, bucket)
+}
+
+func (s *loanServiceImpl) GetLoanHistory(ctx context.Context, loanID int64) ([]models.StatusHistoryEntry, error) {
+	return s.status.LoanHistory(ctx, loanID)
+}
+
+func (s *loanServiceImpl) GetTotalDebt(ctx context.Context, userID int64) (*models.DebtSummaryDTO, error) {
+	loans, err := s.loanRepo.ListUserLoans(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user loans: %w", err)
+	}
+	banks, err := s.bankRepo.ListBanks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list banks: %w", err)
+	}
+	bankNames := make(map[int16]string, len(banks))
+	for _, b := range banks {
+		bankNames[b.BankID] = b.Name
+	}
+
+	res := &models.DebtSummaryDTO{
+		UserID: userID,
+		ByBank: []models.BankDebtDTO{},
+		ByLoan: []models.LoanDebtDTO{},
+	}
+	now := time.Now()
+
+	var total debtTotals
+	byBank := map[int16]*debtTotals{}
+	var bankOrder []int16
+	for i := range loans {
+		loan := &loans[i]
+		if !isOpenLoan(loan.Status) {
+			continue
+		}
+
+		splits, err := s.loanRepo.GetLoanSplits(ctx, loan.LoanID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loan splits: %w", err)
+		}
+
+		var loanTotal debtTotals
+		for _, sp := range splits {
+			t, err := splitTotals(sp)
+			if err != nil {
+				return nil, err
+			}
+			loanTotal.add(t)
+
+			bt, ok := byBank[sp.BankID]
+			if !ok {
+				bt = &debtTotals{}
+				byBank[sp.BankID] = bt
+				bankOrder = append(bankOrder, sp.BankID)
+			}
+			bt.add(t)
+		}
+		total.add(loanTotal)
+
+		nextDue, err := s.nextDue(ctx, loan, splits, now)
+		if err != nil {
+			return nil, err
+		}
+		// Ближайший платёж пользователя — самый ранний по всем кредитам, суммы на одну дату складываются
+		if nextDue != nil {
+			res.NextDue, err = mergeNextDue(res.NextDue, nextDue)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res.ByLoan = append(res.ByLoan, models.LoanDebtDTO{
+			LoanID:          loan.LoanID,
+			Status:          loan.Status,
+			Principal:       formatMoney(loanTotal.Principal),
+			AccruedInterest: formatMoney(loanTotal.Interest),
+			PenaltyInterest: formatMoney(loanTotal.Penalty),
+			Outstanding:     formatMoney(loanTotal.outstanding()),
+			PercentPaid:     loanTotal.percentPaid(),
+			NextDue:         nextDue,
+		})
+	}
+
+	for _, bankID := range bankOrder {
+		bt := byBank[bankID]
+		res.ByBank = append(res.ByBank, models.BankDebtDTO{
+			BankID:          bankID,
+			BankName:        bankNames[bankID],
+			Principal:       formatMoney(bt.Principal),
+			AccruedInterest: formatMoney(bt.Interest),
+			PenaltyInterest: formatMoney(bt.Penalty),
+			Outstanding:     formatMoney(bt.outstanding()),
+		})
+	}
+
+	res.TotalDebt = formatMoney(total.outstanding())
+	res.Principal = formatMoney(total.Principal)
+	res.AccruedInterest = formatMoney(total.Interest)
+	res.PenaltyInterest = formatMoney(total.Penalty)
+	return res, nil
+}
+
+func (s *loanServiceImpl) GetLoanSchedule(ctx context.Context, loanID int64) (*models.LoanScheduleDTO, error) {
+	loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan: %w", err)
+	}
+
+	splits, err := s.loanRepo.GetLoanSplits(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan splits: %w", err)
+	}
+
+	items, err := s.scheduleRepo.GetLoanSchedule(ctx, loanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan schedule: %w", err)
+	}
+
+	bySplit := make(map[int64][]models.LoanScheduleItem, len(splits))
+	for _, it := range items {
+		bySplit[it.SplitID] = append(bySplit[it.SplitID], it)
+	}
+
+	dto := &models.LoanScheduleDTO{
+		Loan:   loan,
+		Splits: make([]models.SplitScheduleDTO, 0, len(splits)),
+	}
+	for _, sp := range splits {
+		dto.Splits = append(dto.Splits, models.SplitScheduleDTO{
+			SplitID: sp.SplitID,
+			BankID:  sp.BankID,
+			Items:   bySplit[sp.SplitID],
+		})
+	}
+
+	dto.Combined, err = combineSchedules(items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to combine schedule: %w", err)
+	}
+	return dto, nil
+}
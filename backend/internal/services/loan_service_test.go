@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// Репозитории кредита в памяти; реализованы только методы, которые вызывают платёж и начисление
+
+type memLoans struct {
+	repos.LoanRepository
+	loan   models.Loan
+	splits []models.LoanSplit
+}
+
+func (r *memLoans) GetLoanByID(context.Context, int64) (*models.Loan, error) {
+	l := r.loan
+	return &l, nil
+}
+
+func (r *memLoans) GetLoanSplitsForUpdate(context.Context, int64) ([]models.LoanSplit, error) {
+	return append([]models.LoanSplit(nil), r.splits...), nil
+}
+
+func (r *memLoans) split(splitID int64) *models.LoanSplit {
+	for i := range r.splits {
+		if r.splits[i].SplitID == splitID {
+			return &r.splits[i]
+		}
+	}
+	panic("unknown split")
+}
+
+func (r *memLoans) UpdateLoanSplitPrincipal(_ context.Context, splitID int64, remainingPrincipal string) error {
+	r.split(splitID).RemainingPrincipal = remainingPrincipal
+	return nil
+}
+
+func (r *memLoans) UpdateLoanSplitAccrual(_ context.Context, splitID int64, accrued, penalty string, through time.Time) error {
+	sp := r.split(splitID)
+	sp.AccruedInterest, sp.PenaltyInterest, sp.AccruedThrough = accrued, penalty, through
+	return nil
+}
+
+func (r *memLoans) UpdateLoanSplitRemainders(_ context.Context, splitID int64, interest, penalty float64) error {
+	sp := r.split(splitID)
+	sp.InterestRemainder, sp.PenaltyRemainder = interest, penalty
+	return nil
+}
+
+type memSchedule struct {
+	repos.LoanScheduleRepository
+	items []models.LoanScheduleItem
+}
+
+func (r *memSchedule) GetLoanSchedule(context.Context, int64) ([]models.LoanScheduleItem, error) {
+	return r.items, nil
+}
+
+type memPayments struct {
+	repos.LoanPaymentRepository
+	types       map[int64]string
+	allocations []models.PaymentAllocation
+}
+
+func (r *memPayments) CreatePayment(_ context.Context, p *models.LoanPayment) (int64, error) {
+	id := int64(len(r.types) + 1)
+	r.types[id] = p.PaymentType
+	return id, nil
+}
+
+func (r *memPayments) CreatePaymentAllocation(_ context.Context, a *models.PaymentAllocation) error {
+	r.allocations = append(r.allocations, *a)
+	return nil
+}
+
+func (r *memPayments) SumSplitPaid(_ context.Context, _ int64, paymentType string) (map[int64]string, error) {
+	return r.sum(func(a models.PaymentAllocation) []string {
+		if r.types[a.PaymentID] != paymentType {
+			return nil
+		}
+		return []string{a.PrincipalPaid, a.InterestPaid, a.FeePaid}
+	})
+}
+
+func (r *memPayments) SumSplitFeesPaid(context.Context, int64) (map[int64]string, error) {
+	return r.sum(func(a models.PaymentAllocation) []string { return []string{a.FeePaid} })
+}
+
+func (r *memPayments) sum(parts func(models.PaymentAllocation) []string) (map[int64]string, error) {
+	totals := map[int64]int64{}
+	for _, a := range r.allocations {
+		for _, v := range parts(a) {
+			k, err := parseMoney(v)
+			if err != nil {
+				return nil, err
+			}
+			totals[a.SplitID] += k
+		}
+	}
+	res := make(map[int64]string, len(totals))
+	for id, k := range totals {
+		res[id] = formatMoney(k)
+	}
+	return res, nil
+}
+
+type noBanks struct {
+	repos.BankRepository
+}
+
+func (noBanks) ListBanks(context.Context) ([]models.Bank, error) {
+	return nil, nil
+}
+
+type noopRepaymentLedger struct {
+	LedgerService
+}
+
+func (noopRepaymentLedger) RecordRepayment(context.Context, *models.LoanPayment, []models.PaymentAllocation, []models.LoanSplit) error {
+	return nil
+}
+
+type noopDelinquency struct {
+	DelinquencyService
+}
+
+func (noopDelinquency) Refresh(context.Context, int64, time.Time) (*models.LoanDelinquency, error) {
+	return nil, nil
+}
+
+// loanStatuses меняет статус кредита в memLoans без проверки переходов
+type loanStatuses struct {
+	StatusService
+	loans *memLoans
+}
+
+func (s loanStatuses) TransitionLoan(_ context.Context, _ int64, to string, _ *int64, _ string) error {
+	s.loans.loan.Status = to
+	return nil
+}
+
+func TestPayFullSchedule(t *testing.T) {
+	tests := []struct {
+		name          string
+		start         time.Time
+		term          int
+		rate          string
+		repaymentType string
+		dayCount      string
+		fee           string
+	}{
+		{"annuity ACT/365 from the end of month", date(2026, 1, 31), 12, "19.9", RepaymentAnnuity, DayCountAct365, ""},
+		{"differentiated ACT/ACT over a leap year", date(2027, 11, 15), 24, "23.5", RepaymentDifferentiated, DayCountActAct, ""},
+		{"annuity 30/360 with service fee", date(2026, 3, 31), 6, "14.25", RepaymentAnnuity, DayCount30360, "150.00"},
+		{"interest free", date(2026, 5, 10), 3, "0", RepaymentAnnuity, DayCountAct365, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			loan := models.Loan{
+				LoanID:             1,
+				UserID:             7,
+				TakenAt:            tt.start,
+				Status:             models.LoanStatusActive,
+				TermMonths:         tt.term,
+				RepaymentType:      tt.repaymentType,
+				DayCountConvention: tt.dayCount,
+			}
+			split := models.LoanSplit{
+				SplitID:            1,
+				LoanID:             1,
+				BankID:             1,
+				SplitAmount:        "100000.00",
+				RemainingPrincipal: "100000.00",
+				InterestRate:       tt.rate,
+				AccruedInterest:    "0.00",
+				PenaltyInterest:    "0.00",
+				AccruedThrough:     tt.start.AddDate(0, 0, -1),
+			}
+			items, err := buildSplitSchedule(&loan, &split)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range items {
+				items[i].SplitID = split.SplitID
+				items[i].Fee = tt.fee
+			}
+
+			loans := &memLoans{loan: loan, splits: []models.LoanSplit{split}}
+			schedule := &memSchedule{items: items}
+			payments := &memPayments{types: map[int64]string{}}
+			cfg := config.LoanConfig{PenaltyRate: "20", AllocationStrategy: AllocationProRata}
+			svc := &loanServiceImpl{
+				loanRepo:     loans,
+				paymentRepo:  payments,
+				scheduleRepo: schedule,
+				bankRepo:     noBanks{},
+				txManager:    passTxManager{},
+				ledger:       noopRepaymentLedger{},
+				accrual:      NewInterestAccrualService(loans, &accrualRecorder{}, schedule, payments, passTxManager{}, cfg),
+				delinquency:  noopDelinquency{},
+				status:       loanStatuses{loans: loans},
+				cfg:          cfg,
+			}
+
+			// Заёмщик вносит каждый платёж по графику в срок
+			for i, it := range items {
+				total, err := parseMoney(it.TotalPayment)
+				if err != nil {
+					t.Fatal(err)
+				}
+				fee, err := parseOptionalMoney(it.Fee)
+				if err != nil {
+					t.Fatal(err)
+				}
+				res, err := svc.MakePayment(ctx, &models.LoanPayment{
+					LoanID:      1,
+					UserID:      7,
+					PaidAt:      it.DueDate,
+					TotalAmount: formatMoney(total + fee),
+				})
+				if err != nil {
+					t.Fatalf("installment %d: %v", it.InstallmentNo, err)
+				}
+				if got := res.Allocations[0].InterestPaid; got != it.Interest {
+					t.Errorf("installment %d: interest paid %s, scheduled %s", it.InstallmentNo, got, it.Interest)
+				}
+				if i < len(items)-1 && res.LoanStatus == models.LoanStatusClosed {
+					t.Fatalf("loan closed after installment %d of %d", it.InstallmentNo, len(items))
+				}
+				if i == len(items)-1 && (res.LoanStatus != models.LoanStatusClosed || res.RemainingDebt != "0.00") {
+					t.Errorf("after the last installment status %s, remaining debt %s; want CLOSED, 0.00", res.LoanStatus, res.RemainingDebt)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/Arlandaren/easyfund/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	// Сервисы пишут предупреждения в общий логгер; в тестах он молчит
+	logger.Log = zap.NewNop().Sugar()
+	os.Exit(m.Run())
+}
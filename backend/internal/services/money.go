@@ -0,0 +1,83 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Денежные суммы в БД хранятся как numeric(18,2) и приходят строками.
+// Для расчётов переводим их в копейки (int64), чтобы не копить ошибки округления.
+
+// parseMoney переводит строку вида "12345.67" в копейки
+func parseMoney(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty amount")
+	}
+	neg := false
+	if s[0] == '-' || s[0] == '+' {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" {
+		intPart = "0"
+	}
+	if len(fracPart) > 2 {
+		return 0, fmt.Errorf("invalid amount %q: more than 2 decimal places", s)
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+	whole, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	frac, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	k := whole*100 + frac
+	if neg {
+		k = -k
+	}
+	return k, nil
+}
+
+// formatMoney переводит копейки обратно в строку для numeric(18,2)
+func formatMoney(k int64) string {
+	sign := ""
+	if k < 0 {
+		sign = "-"
+		k = -k
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, k/100, k%100)
+}
+
+// parseRate парсит годовую ставку в процентах ("14.90")
+func parseRate(s string) (float64, error) {
+	r, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %w", s, err)
+	}
+	if r < 0 {
+		return 0, fmt.Errorf("invalid rate %q: negative", s)
+	}
+	return r, nil
+}
+
+// roundKopecks округляет дробную сумму в копейках до целого
+func roundKopecks(v float64) int64 {
+	return int64(math.Round(v))
+}
+
+// carryKopecks округляет v вместе с перенесённым остатком *remainder до копеек;
+// новая доля копейки остаётся в *remainder
+func carryKopecks(v float64, remainder *float64) int64 {
+	exact := v + *remainder
+	k := roundKopecks(exact)
+	*remainder = exact - float64(k)
+	return k
+}
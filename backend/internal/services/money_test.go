@@ -0,0 +1,59 @@
+package services
+
+import "testing"
+
+func TestCarryKopecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		daily float64 // дневное начисление, копейки
+		days  int
+		want  int64
+	}{
+		{"below half a kopeck", 0.4, 30, 12},
+		{"exactly half", 0.5, 10, 5},
+		{"whole kopecks", 125, 3, 375},
+		{"fractional", 33.333333, 365, 12167},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var remainder float64
+			var total int64
+			for range tt.days {
+				total += carryKopecks(tt.daily, &remainder)
+			}
+			if total != tt.want {
+				t.Errorf("total = %d, want %d", total, tt.want)
+			}
+			if remainder < -0.5 || remainder > 0.5 {
+				t.Errorf("remainder %f outside half a kopeck", remainder)
+			}
+		})
+	}
+}
+
+func TestParseFormatMoney(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		out  string
+	}{
+		{"0", 0, "0.00"},
+		{"12.3", 1230, "12.30"},
+		{"-1234.56", -123456, "-1234.56"},
+		{"100000.00", 10000000, "100000.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			k, err := parseMoney(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if k != tt.want {
+				t.Errorf("parseMoney(%q) = %d, want %d", tt.in, k, tt.want)
+			}
+			if got := formatMoney(k); got != tt.out {
+				t.Errorf("formatMoney(%d) = %q, want %q", k, got, tt.out)
+			}
+		})
+	}
+}
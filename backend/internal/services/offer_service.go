@@ -0,0 +1,492 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// defaultOfferTTL — срок действия предложения, если банк не указал expires_at
+const defaultOfferTTL = 7 * 24 * time.Hour
+
+// OfferService — предложения банков по заявкам: банки делают предложения,
+// заёмщик выбирает комбинацию, из которой собирается синдицированный кредит
+type OfferService interface {
+	PostOffer(ctx context.Context, offer *models.ApplicationOffer) (*models.ApplicationOffer, error)
+	ListOffers(ctx context.Context, appID int64) ([]models.ApplicationOffer, error)
+	// WithdrawOffer отзывает предложение; bankID = 0 — без проверки банка (администратор)
+	WithdrawOffer(ctx context.Context, offerID int64, bankID int16) (*models.ApplicationOffer, error)
+	DeclineOffer(ctx context.Context, appID, offerID, userID int64) (*models.ApplicationOffer, error)
+	// AcceptOffers создаёт кредит с долей на каждое принятое предложение и одобряет заявку
+	AcceptOffers(ctx context.Context, appID int64, offerIDs []int64, userID int64) (*models.LoanDetailDTO, error)
+	ExpireOffers(ctx context.Context, now time.Time) (int64, error)
+}
+
+type offerServiceImpl struct {
+	offerRepo       repos.OfferRepository
+	appRepo         repos.CreditApplicationRepository
+	participantRepo repos.ParticipantRepository
+	settingsRepo    repos.LendingSettingsRepository
+	bankRepo        repos.BankRepository
+	productRepo     repos.ProductRepository
+	loanService     LoanService
+	status          StatusService
+	txManager       repos.TxManager
+}
+
+func NewOfferService(
+	offerRepo repos.OfferRepository,
+	appRepo repos.CreditApplicationRepository,
+	participantRepo repos.ParticipantRepository,
+	settingsRepo repos.LendingSettingsRepository,
+	bankRepo repos.BankRepository,
+	productRepo repos.ProductRepository,
+	loanService LoanService,
+	status StatusService,
+	txManager repos.TxManager,
+) OfferService {
+	return &offerServiceImpl{
+		offerRepo:       offerRepo,
+		appRepo:         appRepo,
+		participantRepo: participantRepo,
+		settingsRepo:    settingsRepo,
+		bankRepo:        bankRepo,
+		productRepo:     productRepo,
+		loanService:     loanService,
+		status:          status,
+		txManager:       txManager,
+	}
+}
+
+func (s *offerServiceImpl) PostOffer(ctx context.Context, offer *models.ApplicationOffer) (*models.ApplicationOffer, error) {
+	now := time.Now()
+	if offer.RepaymentType == "" {
+		offer.RepaymentType = RepaymentAnnuity
+	}
+	if offer.ExpiresAt.IsZero() {
+		offer.ExpiresAt = now.Add(defaultOfferTTL)
+	}
+	if !offer.ExpiresAt.After(now) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", apperrors.ErrBadRequest)
+	}
+	if offer.TermMonths < 1 || offer.TermMonths > maxTermMonths {
+		return nil, fmt.Errorf("%w: term_months must be between 1 and %d", apperrors.ErrBadRequest, maxTermMonths)
+	}
+	rate, err := parseRate(offer.InterestRate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	offer.InterestRate = formatRate(rate)
+	amount, err := parseMoney(offer.Amount)
+	if err != nil || amount <= 0 {
+		return nil, fmt.Errorf("%w: invalid offer amount %q", apperrors.ErrBadRequest, offer.Amount)
+	}
+	offer.Amount = formatMoney(amount)
+	offer.Status = models.OfferStatusPending
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockApplication(ctx, offer.ApplicationID)
+		if err != nil {
+			return err
+		}
+		if app.StatusCode != models.ApplicationStatusPending {
+			return fmt.Errorf("%w: application %d is %s", apperrors.ErrConflict, app.ApplicationID, app.StatusCode)
+		}
+		requested, err := parseMoney(app.RequestedAmount)
+		if err != nil {
+			return fmt.Errorf("invalid requested amount of application %d: %w", app.ApplicationID, err)
+		}
+		if amount > requested {
+			return fmt.Errorf("%w: offer amount %s exceeds requested %s", apperrors.ErrBadRequest, offer.Amount, app.RequestedAmount)
+		}
+		product, err := s.checkProduct(ctx, app, offer)
+		if err != nil {
+			return err
+		}
+		if err := offerCreditCost(offer, amount, requested, product, now); err != nil {
+			return err
+		}
+		if err := s.checkLenderLimits(ctx, offer.BankID, amount); err != nil {
+			return err
+		}
+		if err := s.expire(ctx, now); err != nil {
+			return err
+		}
+
+		// Предлагать могут только приглашённые банки, одно действующее предложение на банк
+		participant, err := s.participantRepo.GetParticipant(ctx, app.ApplicationID, offer.BankID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: bank %d is not invited to application %d", apperrors.ErrForbidden, offer.BankID, app.ApplicationID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get participant: %w", err)
+		}
+		if participant.Status != models.ParticipantStatusInvited {
+			return fmt.Errorf("%w: bank %d participation is %s", apperrors.ErrConflict, offer.BankID, participant.Status)
+		}
+
+		if err := s.offerRepo.CreateOffer(ctx, offer); err != nil {
+			return fmt.Errorf("failed to create offer: %w", err)
+		}
+		_, err = s.participantRepo.UpdateParticipantStatus(ctx, app.ApplicationID, offer.BankID,
+			[]string{models.ParticipantStatusInvited}, models.ParticipantStatusOffered, "")
+		if err != nil {
+			return fmt.Errorf("failed to update participant: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// checkProduct сверяет предложение по заявке с продуктом: срок и ставка
+// в его пределах; сумма доли проверяется при выдаче кредита. Без продукта — nil
+func (s *offerServiceImpl) checkProduct(ctx context.Context, app *models.CreditApplication, offer *models.ApplicationOffer) (*models.LoanProduct, error) {
+	if app.ProductID == nil {
+		return nil, nil
+	}
+	p, err := loadProduct(ctx, s.productRepo, *app.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.TermMonths < p.MinTermMonths || offer.TermMonths > p.MaxTermMonths {
+		return nil, fmt.Errorf("%w: term %d months is outside product %s range %d–%d",
+			apperrors.ErrBadRequest, offer.TermMonths, p.Code, p.MinTermMonths, p.MaxTermMonths)
+	}
+	if err := checkProductRate(p, offer.InterestRate); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// offerCreditCost рассчитывает ПСК предложения по его графику; комиссии и страховка
+// продукта заявки берутся в доле суммы предложения от запрошенной суммы
+func offerCreditCost(offer *models.ApplicationOffer, amount, requested int64, product *models.LoanProduct, start time.Time) error {
+	rate, err := parseRate(offer.InterestRate)
+	if err != nil {
+		return err
+	}
+	items, err := buildSchedule(scheduleParams{
+		Principal:     amount,
+		AnnualRate:    rate,
+		TermMonths:    offer.TermMonths,
+		Start:         dateOnly(start),
+		RepaymentType: offer.RepaymentType,
+		DayCount:      DayCountAct365,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	fees, err := productFees(product, requested)
+	if err != nil {
+		return fmt.Errorf("invalid product fees: %w", err)
+	}
+	psk, pskAmount, err := creditCost(amount, items, fees.share(amount, requested))
+	if err != nil {
+		return err
+	}
+	offer.Psk, offer.PskAmount = &psk, &pskAmount
+	return nil
+}
+
+// checkLenderLimits сверяет предложение с профилем кредитора, если он заведён
+func (s *offerServiceImpl) checkLenderLimits(ctx context.Context, bankID int16, amount int64) error {
+	if _, err := s.bankRepo.GetBankByID(ctx, bankID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: bank %d does not exist", apperrors.ErrBadRequest, bankID)
+		}
+		return fmt.Errorf("failed to get bank: %w", err)
+	}
+
+	st, err := s.settingsRepo.GetSettings(ctx, bankID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get lending settings: %w", err)
+	}
+	if !st.Active {
+		return fmt.Errorf("%w: bank %d is not lending", apperrors.ErrBadRequest, bankID)
+	}
+
+	c, err := distributionCandidateFor(*st, 1)
+	if err != nil {
+		return fmt.Errorf("invalid lending settings for bank %d: %w", bankID, err)
+	}
+	if amount < c.Min || amount > c.Capacity {
+		return fmt.Errorf("%w: offer amount %s is outside bank %d limits %s–%s",
+			apperrors.ErrBadRequest, formatMoney(amount), bankID, formatMoney(c.Min), formatMoney(c.Capacity))
+	}
+	return nil
+}
+
+func (s *offerServiceImpl) ListOffers(ctx context.Context, appID int64) ([]models.ApplicationOffer, error) {
+	if err := s.expire(ctx, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.offerRepo.ListApplicationOffers(ctx, appID)
+}
+
+func (s *offerServiceImpl) WithdrawOffer(ctx context.Context, offerID int64, bankID int16) (*models.ApplicationOffer, error) {
+	offer, err := s.getOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if bankID != 0 && offer.BankID != bankID {
+		return nil, fmt.Errorf("%w: offer %d belongs to bank %d", apperrors.ErrForbidden, offerID, offer.BankID)
+	}
+	return s.decide(ctx, offer, models.OfferStatusWithdrawn, "withdrawn by bank")
+}
+
+func (s *offerServiceImpl) DeclineOffer(ctx context.Context, appID, offerID, userID int64) (*models.ApplicationOffer, error) {
+	app, err := s.getApplication(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+	if app.UserID != userID {
+		return nil, fmt.Errorf("%w: application %d", apperrors.ErrForbidden, appID)
+	}
+	offer, err := s.getOffer(ctx, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if offer.ApplicationID != appID {
+		return nil, fmt.Errorf("%w: offer %d of application %d", apperrors.ErrNotFound, offerID, appID)
+	}
+	return s.decide(ctx, offer, models.OfferStatusDeclined, "declined by borrower")
+}
+
+// decide переводит действующее предложение в итоговый статус;
+// банк снова может сделать предложение по заявке
+func (s *offerServiceImpl) decide(ctx context.Context, offer *models.ApplicationOffer, to, reason string) (*models.ApplicationOffer, error) {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.expire(ctx, time.Now()); err != nil {
+			return err
+		}
+		ok, err := s.offerRepo.UpdateOfferStatus(ctx, offer.OfferID, models.OfferStatusPending, to, reason)
+		if err != nil {
+			return fmt.Errorf("failed to update offer status: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: offer %d is no longer pending", apperrors.ErrConflict, offer.OfferID)
+		}
+		if err := s.participantRepo.SyncOfferedParticipants(ctx); err != nil {
+			return fmt.Errorf("failed to sync participants: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.offerRepo.GetOfferByID(ctx, offer.OfferID)
+}
+
+func (s *offerServiceImpl) AcceptOffers(ctx context.Context, appID int64, offerIDs []int64, userID int64) (*models.LoanDetailDTO, error) {
+	if len(offerIDs) == 0 {
+		return nil, fmt.Errorf("%w: at least one offer is required", apperrors.ErrBadRequest)
+	}
+	seen := make(map[int64]bool, len(offerIDs))
+	for _, id := range offerIDs {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: duplicate offer %d", apperrors.ErrBadRequest, id)
+		}
+		seen[id] = true
+	}
+
+	var detail *models.LoanDetailDTO
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.lockApplication(ctx, appID)
+		if err != nil {
+			return err
+		}
+		if app.UserID != userID {
+			return fmt.Errorf("%w: application %d", apperrors.ErrForbidden, appID)
+		}
+		if !canTransition(applicationTransitions, app.StatusCode, models.ApplicationStatusApproved) {
+			return fmt.Errorf("%w: application %d is already %s", apperrors.ErrConflict, appID, app.StatusCode)
+		}
+
+		now := time.Now()
+		if err := s.expire(ctx, now); err != nil {
+			return err
+		}
+		offers, err := s.offerRepo.ListOffersForUpdate(ctx, offerIDs)
+		if err != nil {
+			return fmt.Errorf("failed to lock offers: %w", err)
+		}
+		if len(offers) != len(offerIDs) {
+			return fmt.Errorf("%w: some offers do not exist", apperrors.ErrNotFound)
+		}
+
+		loan, splits, rates, err := loanFromOffers(app, offers)
+		if err != nil {
+			return err
+		}
+		loan.UserID = app.UserID
+		loan.TakenAt = now
+		loan.CreatedAt = now
+
+		detail, err = s.loanService.CreateLoanWithSplitRates(ctx, loan, splits, rates)
+		if err != nil {
+			return err
+		}
+
+		reason := fmt.Sprintf("loan %d issued", loan.LoanID)
+		for _, o := range offers {
+			if _, err := s.offerRepo.UpdateOfferStatus(ctx, o.OfferID, models.OfferStatusPending, models.OfferStatusAccepted, reason); err != nil {
+				return fmt.Errorf("failed to accept offer %d: %w", o.OfferID, err)
+			}
+		}
+		if _, err := s.offerRepo.DeclinePendingOffers(ctx, appID, "not selected by borrower"); err != nil {
+			return fmt.Errorf("failed to decline remaining offers: %w", err)
+		}
+		for _, o := range offers {
+			_, err := s.participantRepo.UpdateParticipantStatus(ctx, appID, o.BankID,
+				[]string{models.ParticipantStatusOffered}, models.ParticipantStatusAccepted, "")
+			if err != nil {
+				return fmt.Errorf("failed to update participant: %w", err)
+			}
+		}
+		if err := s.participantRepo.CloseOpenParticipants(ctx, appID); err != nil {
+			return fmt.Errorf("failed to close participants: %w", err)
+		}
+
+		if err := s.status.TransitionApplication(ctx, appID, models.ApplicationStatusApproved, &userID, "offers accepted: "+joinOfferIDs(offers)); err != nil {
+			return fmt.Errorf("failed to update application status: %w", err)
+		}
+		if err := s.appRepo.UpdateApplicationLoanID(ctx, appID, loan.LoanID); err != nil {
+			return fmt.Errorf("failed to update application loan_id: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return detail, nil
+}
+
+// loanFromOffers собирает кредит из принятых предложений: сумма кредита — сумма предложений,
+// ставка кредита — средневзвешенная, у каждой доли своя ставка
+func loanFromOffers(app *models.CreditApplication, offers []models.ApplicationOffer) (*models.Loan, []map[int16]string, map[int16]string, error) {
+	requested, err := parseMoney(app.RequestedAmount)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid requested amount of application %d: %w", app.ApplicationID, err)
+	}
+
+	first := offers[0]
+	splits := make([]map[int16]string, 0, len(offers))
+	rates := make(map[int16]string, len(offers))
+	var total int64
+	var weighted float64
+	for _, o := range offers {
+		if o.ApplicationID != app.ApplicationID {
+			return nil, nil, nil, fmt.Errorf("%w: offer %d belongs to another application", apperrors.ErrBadRequest, o.OfferID)
+		}
+		if o.Status != models.OfferStatusPending {
+			return nil, nil, nil, fmt.Errorf("%w: offer %d is %s", apperrors.ErrConflict, o.OfferID, o.Status)
+		}
+		if o.TermMonths != first.TermMonths || o.RepaymentType != first.RepaymentType {
+			return nil, nil, nil, fmt.Errorf("%w: accepted offers must have the same term and repayment type", apperrors.ErrBadRequest)
+		}
+		amount, err := parseMoney(o.Amount)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid amount of offer %d: %w", o.OfferID, err)
+		}
+		rate, err := parseRate(o.InterestRate)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid rate of offer %d: %w", o.OfferID, err)
+		}
+
+		total += amount
+		weighted += rate * float64(amount)
+		splits = append(splits, map[int16]string{o.BankID: formatMoney(amount)})
+		rates[o.BankID] = o.InterestRate
+	}
+	if total > requested {
+		return nil, nil, nil, fmt.Errorf("%w: accepted offers total %s exceeds requested %s",
+			apperrors.ErrBadRequest, formatMoney(total), app.RequestedAmount)
+	}
+
+	loan := &models.Loan{
+		OriginalAmount:     formatMoney(total),
+		InterestRate:       formatRate(weighted / float64(total)),
+		Status:             models.LoanStatusActive,
+		Purpose:            app.TypeCode,
+		TermMonths:         first.TermMonths,
+		RepaymentType:      first.RepaymentType,
+		DayCountConvention: DayCountAct365,
+		ProductID:          app.ProductID,
+	}
+	return loan, splits, rates, nil
+}
+
+func joinOfferIDs(offers []models.ApplicationOffer) string {
+	ids := make([]string, 0, len(offers))
+	for _, o := range offers {
+		ids = append(ids, fmt.Sprint(o.OfferID))
+	}
+	return strings.Join(ids, ", ")
+}
+
+func (s *offerServiceImpl) ExpireOffers(ctx context.Context, now time.Time) (int64, error) {
+	var n int64
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if n, err = s.offerRepo.ExpireOffers(ctx, now); err != nil {
+			return fmt.Errorf("failed to expire offers: %w", err)
+		}
+		if err := s.participantRepo.SyncOfferedParticipants(ctx); err != nil {
+			return fmt.Errorf("failed to sync participants: %w", err)
+		}
+		return nil
+	})
+	return n, err
+}
+
+// expire закрывает просроченные предложения; их банки снова могут предлагать
+func (s *offerServiceImpl) expire(ctx context.Context, now time.Time) error {
+	_, err := s.ExpireOffers(ctx, now)
+	return err
+}
+
+func (s *offerServiceImpl) lockApplication(ctx context.Context, appID int64) (*models.CreditApplication, error) {
+	app, err := s.appRepo.GetApplicationByIDForUpdate(ctx, appID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: application %d", apperrors.ErrNotFound, appID)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+	return app, nil
+}
+
+func (s *offerServiceImpl) getApplication(ctx context.Context, appID int64) (*models.CreditApplication, error) {
+	app, err := s.appRepo.GetApplicationByID(ctx, appID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: application %d", apperrors.ErrNotFound, appID)
+		}
+		return nil, fmt.Errorf("failed to get application: %w", err)
+	}
+	return app, nil
+}
+
+func (s *offerServiceImpl) getOffer(ctx context.Context, offerID int64) (*models.ApplicationOffer, error) {
+	offer, err := s.offerRepo.GetOfferByID(ctx, offerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: offer %d", apperrors.ErrNotFound, offerID)
+		}
+		return nil, fmt.Errorf("failed to get offer: %w", err)
+	}
+	return offer, nil
+}
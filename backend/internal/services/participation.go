@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// summarizeParticipation сводит статусы банков-участников в статус заявки:
+// принятая доля важнее действующих предложений, те — важнее ожидания ответа
+func summarizeParticipation(participants []models.ApplicationParticipant) models.ParticipationSummary {
+	res := models.ParticipationSummary{Total: len(participants)}
+	for _, p := range participants {
+		switch p.Status {
+		case models.ParticipantStatusInvited:
+			res.Invited++
+		case models.ParticipantStatusOffered:
+			res.Offered++
+		case models.ParticipantStatusDeclined:
+			res.Declined++
+		case models.ParticipantStatusAccepted:
+			res.Accepted++
+		case models.ParticipantStatusClosed:
+			res.Closed++
+		}
+	}
+
+	switch {
+	case res.Accepted > 0:
+		res.Status = models.ParticipationAccepted
+	case res.Offered > 0:
+		res.Status = models.ParticipationOffersReceived
+	case res.Invited > 0:
+		res.Status = models.ParticipationAwaitingOffers
+	case res.Total > 0 && res.Declined == res.Total:
+		res.Status = models.ParticipationAllDeclined
+	default:
+		res.Status = models.ParticipationClosed
+	}
+	return res
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// validateProduct проверяет продукт и приводит суммы и ставки к формату numeric
+func validateProduct(p *models.LoanProduct) error {
+	p.Code = strings.ToUpper(strings.TrimSpace(p.Code))
+	if p.Code == "" {
+		return fmt.Errorf("%w: code is required", apperrors.ErrBadRequest)
+	}
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("%w: name is required", apperrors.ErrBadRequest)
+	}
+	if !validLoanType(p.TypeCode) {
+		return fmt.Errorf("%w: unknown loan type %q", apperrors.ErrBadRequest, p.TypeCode)
+	}
+	if p.Currency == "" {
+		p.Currency = models.CurrencyRUB
+	}
+	if !validCurrency(p.Currency) {
+		return fmt.Errorf("%w: invalid currency %q", apperrors.ErrBadRequest, p.Currency)
+	}
+
+	minAmount, err := parseMoney(p.MinAmount)
+	if err != nil || minAmount <= 0 {
+		return fmt.Errorf("%w: invalid min_amount %q", apperrors.ErrBadRequest, p.MinAmount)
+	}
+	maxAmount, err := parseMoney(p.MaxAmount)
+	if err != nil || maxAmount < minAmount {
+		return fmt.Errorf("%w: max_amount must not be less than min_amount", apperrors.ErrBadRequest)
+	}
+	p.MinAmount, p.MaxAmount = formatMoney(minAmount), formatMoney(maxAmount)
+
+	if p.MinTermMonths < 1 || p.MaxTermMonths > maxTermMonths || p.MaxTermMonths < p.MinTermMonths {
+		return fmt.Errorf("%w: term must satisfy 1 <= min_term_months <= max_term_months <= %d", apperrors.ErrBadRequest, maxTermMonths)
+	}
+
+	minRate, err := parseRate(p.MinRate)
+	if err != nil {
+		return fmt.Errorf("%w: min_rate: %v", apperrors.ErrBadRequest, err)
+	}
+	maxRate, err := parseRate(p.MaxRate)
+	if err != nil || maxRate < minRate || maxRate >= 1000 {
+		return fmt.Errorf("%w: max_rate must not be less than min_rate", apperrors.ErrBadRequest)
+	}
+	p.MinRate, p.MaxRate = formatRate(minRate), formatRate(maxRate)
+
+	// Комиссии необязательны: пустое значение — без комиссии
+	feePercent, err := parseOptionalRate(p.OriginationFeePercent)
+	if err != nil || feePercent > 100 {
+		return fmt.Errorf("%w: origination_fee_percent must be between 0 and 100", apperrors.ErrBadRequest)
+	}
+	p.OriginationFeePercent = formatRate(feePercent)
+	insurance, err := parseOptionalRate(p.InsurancePercent)
+	if err != nil || insurance > 100 {
+		return fmt.Errorf("%w: insurance_percent must be between 0 and 100", apperrors.ErrBadRequest)
+	}
+	p.InsurancePercent = formatRate(insurance)
+	for _, fee := range []*string{&p.OriginationFeeFixed, &p.MonthlyFee} {
+		k, err := parseOptionalMoney(*fee)
+		if err != nil || k < 0 {
+			return fmt.Errorf("%w: invalid fee %q", apperrors.ErrBadRequest, *fee)
+		}
+		*fee = formatMoney(k)
+	}
+
+	if p.GracePeriodDays < 0 {
+		return fmt.Errorf("%w: grace_period_days must not be negative", apperrors.ErrBadRequest)
+	}
+
+	seen := make(map[int16]bool, len(p.BankIDs))
+	for _, bankID := range p.BankIDs {
+		if seen[bankID] {
+			return fmt.Errorf("%w: duplicate bank %d", apperrors.ErrBadRequest, bankID)
+		}
+		seen[bankID] = true
+	}
+	slices.Sort(p.BankIDs)
+	return nil
+}
+
+func parseOptionalRate(s string) (float64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return parseRate(s)
+}
+
+func parseOptionalMoney(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return parseMoney(s)
+}
+
+// loadProduct возвращает продукт, на который ссылается заявка или кредит;
+// несуществующий продукт — ошибка запроса
+func loadProduct(ctx context.Context, repo repos.ProductRepository, productID int64) (*models.LoanProduct, error) {
+	p, err := repo.GetProduct(ctx, productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: product %d does not exist", apperrors.ErrBadRequest, productID)
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return p, nil
+}
+
+// checkProductTerms проверяет сумму и срок по ограничениям продукта
+func checkProductTerms(p *models.LoanProduct, amount string, termMonths int) error {
+	k, err := parseMoney(amount)
+	if err != nil {
+		return fmt.Errorf("%w: invalid amount %q", apperrors.ErrBadRequest, amount)
+	}
+	minAmount, _ := parseMoney(p.MinAmount)
+	maxAmount, _ := parseMoney(p.MaxAmount)
+	if k < minAmount || k > maxAmount {
+		return fmt.Errorf("%w: amount %s is outside product %s range %s–%s",
+			apperrors.ErrBadRequest, formatMoney(k), p.Code, p.MinAmount, p.MaxAmount)
+	}
+	if termMonths < p.MinTermMonths || termMonths > p.MaxTermMonths {
+		return fmt.Errorf("%w: term %d months is outside product %s range %d–%d",
+			apperrors.ErrBadRequest, termMonths, p.Code, p.MinTermMonths, p.MaxTermMonths)
+	}
+	return nil
+}
+
+// checkProductRate проверяет, что ставка в диапазоне продукта
+func checkProductRate(p *models.LoanProduct, rate string) error {
+	r, err := parseRate(rate)
+	if err != nil {
+		return fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+	minRate, _ := parseRate(p.MinRate)
+	maxRate, _ := parseRate(p.MaxRate)
+	if r < minRate || r > maxRate {
+		return fmt.Errorf("%w: rate %s%% is outside product %s range %s–%s%%",
+			apperrors.ErrBadRequest, formatRate(r), p.Code, p.MinRate, p.MaxRate)
+	}
+	return nil
+}
+
+// clampProductRate приводит котированную ставку к диапазону продукта
+func clampProductRate(p *models.LoanProduct, rate string) (string, error) {
+	r, err := parseRate(rate)
+	if err != nil {
+		return "", err
+	}
+	minRate, _ := parseRate(p.MinRate)
+	maxRate, _ := parseRate(p.MaxRate)
+	return formatRate(min(max(r, minRate), maxRate)), nil
+}
+
+// checkProductBank проверяет, что банк выдаёт продукт
+func checkProductBank(p *models.LoanProduct, bankID int16) error {
+	if !slices.Contains(p.BankIDs, bankID) {
+		return fmt.Errorf("%w: bank %d does not offer product %s", apperrors.ErrBadRequest, bankID, p.Code)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// ProductService — каталог кредитных продуктов: заявки и кредиты по продукту
+// проверяются по его ограничениям суммы, срока, ставки и банков
+type ProductService interface {
+	// ListProducts — продукты каталога; activeOnly — только действующие (публичный каталог)
+	ListProducts(ctx context.Context, activeOnly bool) ([]models.LoanProduct, error)
+	GetProduct(ctx context.Context, productID int64) (*models.LoanProduct, error)
+	CreateProduct(ctx context.Context, p *models.LoanProduct) (*models.LoanProduct, error)
+	UpdateProduct(ctx context.Context, p *models.LoanProduct) (*models.LoanProduct, error)
+	// DeleteProduct удаляет продукт без заявок и кредитов; иначе продукт можно только отключить
+	DeleteProduct(ctx context.Context, productID int64) error
+}
+
+type productServiceImpl struct {
+	productRepo repos.ProductRepository
+	bankRepo    repos.BankRepository
+	txManager   repos.TxManager
+}
+
+func NewProductService(productRepo repos.ProductRepository, bankRepo repos.BankRepository, txManager repos.TxManager) ProductService {
+	return &productServiceImpl{
+		productRepo: productRepo,
+		bankRepo:    bankRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *productServiceImpl) ListProducts(ctx context.Context, activeOnly bool) ([]models.LoanProduct, error) {
+	products, err := s.productRepo.ListProducts(ctx, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list products: %w", err)
+	}
+	return products, nil
+}
+
+func (s *productServiceImpl) GetProduct(ctx context.Context, productID int64) (*models.LoanProduct, error) {
+	p, err := s.productRepo.GetProduct(ctx, productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: product %d", apperrors.ErrNotFound, productID)
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	return p, nil
+}
+
+func (s *productServiceImpl) CreateProduct(ctx context.Context, p *models.LoanProduct) (*models.LoanProduct, error) {
+	if err := validateProduct(p); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureUniqueCode(ctx, p); err != nil {
+			return err
+		}
+		if err := s.ensureBanks(ctx, p.BankIDs); err != nil {
+			return err
+		}
+		if err := s.productRepo.CreateProduct(ctx, p); err != nil {
+			return fmt.Errorf("failed to create product: %w", err)
+		}
+		if err := s.productRepo.ReplaceProductBanks(ctx, p.ProductID, p.BankIDs); err != nil {
+			return fmt.Errorf("failed to save product banks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProduct(ctx, p.ProductID)
+}
+
+func (s *productServiceImpl) UpdateProduct(ctx context.Context, p *models.LoanProduct) (*models.LoanProduct, error) {
+	if err := validateProduct(p); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.ensureUniqueCode(ctx, p); err != nil {
+			return err
+		}
+		if err := s.ensureBanks(ctx, p.BankIDs); err != nil {
+			return err
+		}
+		ok, err := s.productRepo.UpdateProduct(ctx, p)
+		if err != nil {
+			return fmt.Errorf("failed to update product: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: product %d", apperrors.ErrNotFound, p.ProductID)
+		}
+		if err := s.productRepo.ReplaceProductBanks(ctx, p.ProductID, p.BankIDs); err != nil {
+			return fmt.Errorf("failed to save product banks: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetProduct(ctx, p.ProductID)
+}
+
+func (s *productServiceImpl) DeleteProduct(ctx context.Context, productID int64) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		inUse, err := s.productRepo.ProductInUse(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to check product usage: %w", err)
+		}
+		if inUse {
+			return fmt.Errorf("%w: product %d has applications or loans, deactivate it instead", apperrors.ErrConflict, productID)
+		}
+		ok, err := s.productRepo.DeleteProduct(ctx, productID)
+		if err != nil {
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: product %d", apperrors.ErrNotFound, productID)
+		}
+		return nil
+	})
+}
+
+// ensureUniqueCode проверяет, что код продукта не занят другим продуктом
+func (s *productServiceImpl) ensureUniqueCode(ctx context.Context, p *models.LoanProduct) error {
+	existing, err := s.productRepo.GetProductByCode(ctx, p.Code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if existing.ProductID != p.ProductID {
+		return fmt.Errorf("%w: product code %s is already used", apperrors.ErrConflict, p.Code)
+	}
+	return nil
+}
+
+func (s *productServiceImpl) ensureBanks(ctx context.Context, bankIDs []int16) error {
+	for _, bankID := range bankIDs {
+		if _, err := s.bankRepo.GetBankByID(ctx, bankID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: bank %d does not exist", apperrors.ErrBadRequest, bankID)
+			}
+			return fmt.Errorf("failed to get bank: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func testProduct() models.LoanProduct {
+	return models.LoanProduct{
+		Code:          " cash-12 ",
+		Name:          "Cash loan",
+		TypeCode:      models.LoanTypePersonal,
+		MinAmount:     "10000",
+		MaxAmount:     "500000.5",
+		MinTermMonths: 3,
+		MaxTermMonths: 36,
+		MinRate:       "9.9",
+		MaxRate:       "24",
+		BankIDs:       []int16{3, 1},
+	}
+}
+
+func TestValidateProduct(t *testing.T) {
+	p := testProduct()
+	if err := validateProduct(&p); err != nil {
+		t.Fatal(err)
+	}
+	want := models.LoanProduct{
+		Code:                  "CASH-12",
+		Name:                  "Cash loan",
+		TypeCode:              models.LoanTypePersonal,
+		Currency:              models.CurrencyRUB,
+		MinAmount:             "10000.00",
+		MaxAmount:             "500000.50",
+		MinTermMonths:         3,
+		MaxTermMonths:         36,
+		MinRate:               "9.90",
+		MaxRate:               "24.00",
+		OriginationFeePercent: "0.00",
+		OriginationFeeFixed:   "0.00",
+		MonthlyFee:            "0.00",
+		InsurancePercent:      "0.00",
+		BankIDs:               []int16{1, 3},
+	}
+	if !reflect.DeepEqual(p, want) {
+		t.Errorf("validateProduct normalized to %+v, want %+v", p, want)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(p *models.LoanProduct)
+	}{
+		{"no code", func(p *models.LoanProduct) { p.Code = "  " }},
+		{"no name", func(p *models.LoanProduct) { p.Name = "" }},
+		{"unknown type", func(p *models.LoanProduct) { p.TypeCode = "BOAT" }},
+		{"invalid currency", func(p *models.LoanProduct) { p.Currency = "rub" }},
+		{"zero min amount", func(p *models.LoanProduct) { p.MinAmount = "0" }},
+		{"max below min amount", func(p *models.LoanProduct) { p.MaxAmount = "9999.99" }},
+		{"zero min term", func(p *models.LoanProduct) { p.MinTermMonths = 0 }},
+		{"max below min term", func(p *models.LoanProduct) { p.MaxTermMonths = 2 }},
+		{"term too long", func(p *models.LoanProduct) { p.MaxTermMonths = maxTermMonths + 1 }},
+		{"max below min rate", func(p *models.LoanProduct) { p.MaxRate = "9" }},
+		{"fee above 100%", func(p *models.LoanProduct) { p.OriginationFeePercent = "100.01" }},
+		{"insurance above 100%", func(p *models.LoanProduct) { p.InsurancePercent = "101" }},
+		{"negative monthly fee", func(p *models.LoanProduct) { p.MonthlyFee = "-1" }},
+		{"negative grace period", func(p *models.LoanProduct) { p.GracePeriodDays = -1 }},
+		{"duplicate bank", func(p *models.LoanProduct) { p.BankIDs = []int16{1, 1} }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testProduct()
+			tt.modify(&p)
+			if err := validateProduct(&p); !errors.Is(err, apperrors.ErrBadRequest) {
+				t.Errorf("validateProduct error = %v, want ErrBadRequest", err)
+			}
+		})
+	}
+}
+
+func TestCheckProductTerms(t *testing.T) {
+	p := testProduct()
+	if err := validateProduct(&p); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		amount  string
+		term    int
+		wantErr bool
+	}{
+		{"within range", "100000.00", 12, false},
+		{"lower bounds", "10000.00", 3, false},
+		{"upper bounds", "500000.50", 36, false},
+		{"amount too small", "9999.99", 12, true},
+		{"amount too large", "500000.51", 12, true},
+		{"term too short", "100000.00", 2, true},
+		{"term too long", "100000.00", 37, true},
+		{"invalid amount", "abc", 12, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkProductTerms(&p, tt.amount, tt.term)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkProductTerms error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, apperrors.ErrBadRequest) {
+				t.Errorf("error %v is not ErrBadRequest", err)
+			}
+		})
+	}
+}
+
+func TestProductRate(t *testing.T) {
+	p := testProduct()
+	if err := validateProduct(&p); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		rate    string
+		clamped string
+		inRange bool
+	}{
+		{"12.5", "12.50", true},
+		{"9.9", "9.90", true},
+		{"24", "24.00", true},
+		{"5", "9.90", false},
+		{"30", "24.00", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rate, func(t *testing.T) {
+			if err := checkProductRate(&p, tt.rate); (err == nil) != tt.inRange {
+				t.Errorf("checkProductRate(%s) error = %v", tt.rate, err)
+			}
+			got, err := clampProductRate(&p, tt.rate)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.clamped {
+				t.Errorf("clampProductRate(%s) = %s, want %s", tt.rate, got, tt.clamped)
+			}
+		})
+	}
+}
+
+func TestCheckProductBank(t *testing.T) {
+	p := testProduct()
+	if err := checkProductBank(&p, 3); err != nil {
+		t.Errorf("bank 3: %v", err)
+	}
+	if err := checkProductBank(&p, 2); !errors.Is(err, apperrors.ErrBadRequest) {
+		t.Errorf("bank 2: error = %v, want ErrBadRequest", err)
+	}
+}
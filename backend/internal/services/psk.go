@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// creditFees — платежи заёмщика сверх процентов и основного долга (в копейках)
+type creditFees struct {
+	Origination int64 // комиссия за выдачу, удерживается в день выдачи
+	Insurance   int64 // страховая премия, уплачивается в день выдачи
+	Monthly     int64 // плата за обслуживание, вместе с каждым плановым платежом
+}
+
+// productFees — комиссии и страховка продукта для кредита на amount копеек;
+// без продукта — нулевые
+func productFees(p *models.LoanProduct, amount int64) (creditFees, error) {
+	var f creditFees
+	if p == nil {
+		return f, nil
+	}
+	feePercent, err := parseOptionalRate(p.OriginationFeePercent)
+	if err != nil {
+		return f, err
+	}
+	feeFixed, err := parseOptionalMoney(p.OriginationFeeFixed)
+	if err != nil {
+		return f, err
+	}
+	insurance, err := parseOptionalRate(p.InsurancePercent)
+	if err != nil {
+		return f, err
+	}
+	monthly, err := parseOptionalMoney(p.MonthlyFee)
+	if err != nil {
+		return f, err
+	}
+	f.Origination = roundKopecks(float64(amount)*feePercent/100) + feeFixed
+	f.Insurance = roundKopecks(float64(amount) * insurance / 100)
+	f.Monthly = monthly
+	return f, nil
+}
+
+// share — часть комиссий, приходящаяся на долю amount из total
+func (f creditFees) share(amount, total int64) creditFees {
+	if total <= 0 || amount == total {
+		return f
+	}
+	k := float64(amount) / float64(total)
+	return creditFees{
+		Origination: roundKopecks(float64(f.Origination) * k),
+		Insurance:   roundKopecks(float64(f.Insurance) * k),
+		Monthly:     roundKopecks(float64(f.Monthly) * k),
+	}
+}
+
+// creditCost — полная стоимость кредита по графику items (ст. 6 353-ФЗ).
+// Базовый период — месяц (ЧБП = 12): все платежи графика приходятся на дату выдачи
+// плюс целое число месяцев, поэтому ПСК = i·12·100, где i — ставка, при которой
+// дисконтированные платежи заёмщика равны сумме, фактически полученной на руки
+// (сумма кредита за вычетом удержанных комиссии и страховки).
+// Возвращает ПСК в % годовых и в денежном выражении: все платежи заёмщика сверх суммы кредита
+func creditCost(principal int64, items []models.LoanScheduleItem, fees creditFees) (string, string, error) {
+	payments := make([]int64, 0, len(items))
+	total := fees.Origination + fees.Insurance
+	for _, it := range items {
+		k, err := parseMoney(it.TotalPayment)
+		if err != nil {
+			return "", "", err
+		}
+		payments = append(payments, k+fees.Monthly)
+		total += k + fees.Monthly
+	}
+	received := principal - fees.Origination - fees.Insurance
+	return formatPsk(monthlyIRR(received, payments) * 12 * 100), formatMoney(total - principal), nil
+}
+
+// formatPsk — ПСК раскрывается с точностью до третьего знака
+func formatPsk(r float64) string {
+	return strconv.FormatFloat(math.Round(r*1000)/1000, 'f', 3, 64)
+}
+
+// loanCreditCost раскладывает сохранённую при выдаче ПСК кредита на проценты, комиссии и страховку
+func loanCreditCost(loan *models.Loan) *models.CreditCostDTO {
+	if loan.Psk == nil || loan.PskAmount == nil {
+		return nil
+	}
+	amount, err1 := parseMoney(*loan.PskAmount)
+	origination, err2 := parseOptionalMoney(loan.OriginationFee)
+	insurance, err3 := parseOptionalMoney(loan.InsurancePremium)
+	monthly, err4 := parseOptionalMoney(loan.MonthlyFee)
+	if err := errors.Join(err1, err2, err3, err4); err != nil {
+		return nil
+	}
+	fees := origination + monthly*int64(loan.TermMonths)
+	return &models.CreditCostDTO{
+		Psk:       *loan.Psk,
+		PskAmount: *loan.PskAmount,
+		Interest:  formatMoney(amount - fees - insurance),
+		Fees:      formatMoney(fees),
+		Insurance: formatMoney(insurance),
+	}
+}
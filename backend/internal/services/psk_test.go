@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func TestProductFees(t *testing.T) {
+	tests := []struct {
+		name    string
+		product *models.LoanProduct
+		want    creditFees
+		wantErr bool
+	}{
+		{"no product", nil, creditFees{}, false},
+		{"no fees", &models.LoanProduct{}, creditFees{}, false},
+		{"percent and fixed origination", &models.LoanProduct{OriginationFeePercent: "1.5", OriginationFeeFixed: "500.00"}, creditFees{Origination: 200000}, false},
+		{"insurance and monthly fee", &models.LoanProduct{InsurancePercent: "0.5", MonthlyFee: "300.00"}, creditFees{Insurance: 50000, Monthly: 30000}, false},
+		{"invalid percent", &models.LoanProduct{OriginationFeePercent: "abc"}, creditFees{}, true},
+		{"invalid monthly fee", &models.LoanProduct{MonthlyFee: "3.001"}, creditFees{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := productFees(tt.product, 10000000)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("productFees error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("productFees = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreditFeesShare(t *testing.T) {
+	fees := creditFees{Origination: 200001, Insurance: 50000, Monthly: 30000}
+	tests := []struct {
+		name          string
+		amount, total int64
+		want          creditFees
+	}{
+		{"whole loan", 10000000, 10000000, fees},
+		{"no total", 0, 0, fees},
+		{"half", 5000000, 10000000, creditFees{Origination: 100001, Insurance: 25000, Monthly: 15000}},
+		{"third", 1000000, 3000000, creditFees{Origination: 66667, Insurance: 16667, Monthly: 10000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fees.share(tt.amount, tt.total); got != tt.want {
+				t.Errorf("share = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreditCost(t *testing.T) {
+	const principal = 10000000
+	annuity, err := buildSchedule(scheduleParams{Principal: principal, AnnualRate: 12, TermMonths: 12, Start: date(2026, 1, 15), RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365})
+	if err != nil {
+		t.Fatal(err)
+	}
+	interestFree, err := buildSchedule(scheduleParams{Principal: principal, TermMonths: 10, Start: date(2026, 1, 15), RepaymentType: RepaymentDifferentiated, DayCount: DayCountAct365})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		items     []models.LoanScheduleItem
+		fees      creditFees
+		psk       string
+		pskAmount string
+	}{
+		{"interest only", annuity, creditFees{}, "11.950", "6589.17"},
+		{"interest free", interestFree, creditFees{}, "0.000", "0.00"},
+		{"withheld origination fee", annuity, creditFees{Origination: 200000}, "15.805", "8589.17"},
+		{"monthly fee", interestFree, creditFees{Monthly: 30000}, "6.493", "3000.00"},
+		{"fees and insurance", annuity, creditFees{Origination: 100000, Insurance: 50000, Monthly: 10000}, "16.977", "9289.17"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			psk, amount, err := creditCost(principal, tt.items, tt.fees)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if psk != tt.psk || amount != tt.pskAmount {
+				t.Errorf("creditCost = (%s, %s), want (%s, %s)", psk, amount, tt.psk, tt.pskAmount)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+const (
+	RepaymentAnnuity        = "ANNUITY"
+	RepaymentDifferentiated = "DIFFERENTIATED"
+
+	PrepaymentShortenTerm       = "SHORTEN_TERM"
+	PrepaymentReduceInstallment = "REDUCE_INSTALLMENT"
+
+	defaultTermMonths = 12
+	maxTermMonths     = 360
+)
+
+// scheduleParams — входные данные для построения графика одной доли кредита
+type scheduleParams struct {
+	Principal     int64     // остаток основного долга в копейках
+	AnnualRate    float64   // годовая ставка в процентах
+	TermMonths    int       // количество оставшихся платежей
+	Start         time.Time // дата выдачи: платёж N приходится на Start + N месяцев
+	RepaymentType string
+	DayCount      string // конвенция начисления процентов кредита
+	FirstNo       int    // номер первого платежа (для перестроения хвоста графика)
+	// AccrueFrom — первый день процентов первого платежа; пусто — срок предыдущего платежа (для первого — Start)
+	AccrueFrom time.Time
+	Remainder  float64 // доля копейки, уже перенесённая начислением процентов доли
+}
+
+// buildSchedule строит помесячный график платежей (аннуитет или дифференцированный).
+// Проценты платежа считаются так же, как их начисляет accrueSplit: по дням от срока предыдущего платежа
+// до дня перед сроком по конвенции DayCount, с переносом долей копейки. Поэтому при оплате в срок
+// по графику начисленные проценты совпадают с графиком. Размер аннуитета считается по ставке r/12,
+// последний платёж закрывает остаток полностью.
+func buildSchedule(p scheduleParams) ([]models.LoanScheduleItem, error) {
+	if p.Principal <= 0 {
+		return nil, fmt.Errorf("principal must be positive")
+	}
+	if p.TermMonths <= 0 || p.TermMonths > maxTermMonths {
+		return nil, fmt.Errorf("term must be between 1 and %d months", maxTermMonths)
+	}
+	if p.FirstNo <= 0 {
+		p.FirstNo = 1
+	}
+
+	monthlyRate := p.AnnualRate / 100 / 12
+	balance := p.Principal
+	from := p.AccrueFrom
+	if from.IsZero() {
+		from = addMonths(p.Start, p.FirstNo-1)
+	}
+	remainder := p.Remainder
+
+	var annuity int64
+	switch p.RepaymentType {
+	case RepaymentAnnuity:
+		annuity = annuityPayment(p.Principal, monthlyRate, p.TermMonths)
+	case RepaymentDifferentiated:
+	default:
+		return nil, fmt.Errorf("unknown repayment type %q", p.RepaymentType)
+	}
+
+	items := make([]models.LoanScheduleItem, 0, p.TermMonths)
+	for i := 1; i <= p.TermMonths; i++ {
+		due := addMonths(p.Start, p.FirstNo+i-1)
+		interest, err := periodInterest(balance, p.AnnualRate, p.DayCount, from, due, &remainder)
+		if err != nil {
+			return nil, err
+		}
+		from = due
+
+		var principal int64
+		switch {
+		case i == p.TermMonths:
+			principal = balance
+		case p.RepaymentType == RepaymentAnnuity:
+			principal = annuity - interest
+		default:
+			principal = p.Principal / int64(p.TermMonths)
+		}
+		if principal > balance {
+			principal = balance
+		}
+		if principal < 0 {
+			principal = 0
+		}
+		balance -= principal
+
+		items = append(items, models.LoanScheduleItem{
+			InstallmentNo:    p.FirstNo + i - 1,
+			DueDate:          due,
+			Principal:        formatMoney(principal),
+			Interest:         formatMoney(interest),
+			TotalPayment:     formatMoney(principal + interest),
+			RemainingBalance: formatMoney(balance),
+		})
+	}
+	return items, nil
+}
+
+// periodInterest — проценты на balance за дни [from, to) по годовой ставке rate (в процентах),
+// начисленные по дням с переносом долей копейки в *remainder, как в accrueDay
+func periodInterest(balance int64, rate float64, convention string, from, to time.Time, remainder *float64) (int64, error) {
+	var interest int64
+	if balance == 0 {
+		return 0, nil
+	}
+	for day := dateOnly(from); day.Before(dateOnly(to)); day = day.AddDate(0, 0, 1) {
+		fraction, err := dailyYearFraction(convention, day)
+		if err != nil {
+			return 0, err
+		}
+		interest += carryKopecks(float64(balance)*rate/100*fraction, remainder)
+	}
+	return interest, nil
+}
+
+// annuityPayment — ежемесячный аннуитетный платёж в копейках
+func annuityPayment(principal int64, monthlyRate float64, n int) int64 {
+	if monthlyRate == 0 {
+		return int64(math.Ceil(float64(principal) / float64(n)))
+	}
+	k := monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(n)))
+	return roundKopecks(float64(principal) * k)
+}
+
+// shortenedTerm — сколько платежей нужно, чтобы погасить principal,
+// сохранив размер платежа из next (первого будущего платежа по старому графику)
+func shortenedTerm(principal int64, monthlyRate float64, repaymentType string, next models.LoanScheduleItem) (int, error) {
+	if repaymentType == RepaymentDifferentiated {
+		part, err := parseMoney(next.Principal)
+		if err != nil {
+			return 0, err
+		}
+		if part <= 0 {
+			return 0, fmt.Errorf("invalid scheduled principal %s", next.Principal)
+		}
+		return int((principal + part - 1) / part), nil
+	}
+
+	payment, err := parseMoney(next.TotalPayment)
+	if err != nil {
+		return 0, err
+	}
+	if monthlyRate == 0 {
+		return int((principal + payment - 1) / payment), nil
+	}
+	// n = ln(A / (A - P·r)) / ln(1 + r)
+	rest := float64(payment) - float64(principal)*monthlyRate
+	if rest <= 0 {
+		return 0, fmt.Errorf("scheduled payment %s does not cover interest", next.TotalPayment)
+	}
+	n := math.Log(float64(payment)/rest) / math.Log(1+monthlyRate)
+	return int(math.Ceil(n - 1e-9)), nil
+}
+
+// addMonths прибавляет месяцы, прижимая день к концу месяца (31 янв + 1 мес = 28/29 фев)
+func addMonths(t time.Time, months int) time.Time {
+	y, m, d := t.Date()
+	first := time.Date(y, m+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	if d > lastDay {
+		d = lastDay
+	}
+	return time.Date(first.Year(), first.Month(), d, 0, 0, 0, 0, t.Location())
+}
+
+// combineSchedules суммирует графики долей по номеру платежа в сводный график кредита
+func combineSchedules(items []models.LoanScheduleItem) ([]models.LoanScheduleItem, error) {
+	type acc struct {
+		due                                      time.Time
+		principal, interest, fee, total, balance int64
+	}
+	byNo := map[int]*acc{}
+	maxNo := 0
+	for _, it := range items {
+		principal, err := parseMoney(it.Principal)
+		if err != nil {
+			return nil, err
+		}
+		interest, err := parseMoney(it.Interest)
+		if err != nil {
+			return nil, err
+		}
+		fee, err := parseOptionalMoney(it.Fee)
+		if err != nil {
+			return nil, err
+		}
+		balance, err := parseMoney(it.RemainingBalance)
+		if err != nil {
+			return nil, err
+		}
+		a, ok := byNo[it.InstallmentNo]
+		if !ok {
+			a = &acc{due: it.DueDate}
+			byNo[it.InstallmentNo] = a
+		}
+		if it.DueDate.After(a.due) {
+			a.due = it.DueDate
+		}
+		a.principal += principal
+		a.interest += interest
+		a.fee += fee
+		a.total += principal + interest
+		a.balance += balance
+		if it.InstallmentNo > maxNo {
+			maxNo = it.InstallmentNo
+		}
+	}
+
+	combined := make([]models.LoanScheduleItem, 0, len(byNo))
+	for no := 1; no <= maxNo; no++ {
+		a, ok := byNo[no]
+		if !ok {
+			continue
+		}
+		combined = append(combined, models.LoanScheduleItem{
+			InstallmentNo:    no,
+			DueDate:          a.due,
+			Principal:        formatMoney(a.principal),
+			Interest:         formatMoney(a.interest),
+			Fee:              formatMoney(a.fee),
+			TotalPayment:     formatMoney(a.total),
+			RemainingBalance: formatMoney(a.balance),
+		})
+	}
+	return combined, nil
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+func date(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestBuildSchedule(t *testing.T) {
+	start := date(2026, 1, 31)
+	tests := []struct {
+		name    string
+		params  scheduleParams
+		first   string // первый платёж
+		last    string // последний платёж
+		wantErr bool
+	}{
+		{"annuity", scheduleParams{Principal: 10000000, AnnualRate: 12, TermMonths: 12, Start: start, RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365}, "8884.88", "8833.57", false},
+		{"annuity at zero rate", scheduleParams{Principal: 100000, TermMonths: 3, Start: start, RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365}, "333.34", "333.32", false},
+		{"differentiated", scheduleParams{Principal: 10000000, AnnualRate: 12, TermMonths: 12, Start: start, RepaymentType: RepaymentDifferentiated, DayCount: DayCountAct365}, "9253.88", "8418.31", false},
+		{"zero principal", scheduleParams{TermMonths: 12, Start: start, RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365}, "", "", true},
+		{"zero term", scheduleParams{Principal: 100000, Start: start, RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365}, "", "", true},
+		{"term too long", scheduleParams{Principal: 100000, TermMonths: maxTermMonths + 1, Start: start, RepaymentType: RepaymentAnnuity, DayCount: DayCountAct365}, "", "", true},
+		{"unknown repayment type", scheduleParams{Principal: 100000, TermMonths: 12, Start: start, RepaymentType: "BALLOON", DayCount: DayCountAct365}, "", "", true},
+		{"unknown day count convention", scheduleParams{Principal: 100000, TermMonths: 12, Start: start, RepaymentType: RepaymentAnnuity, DayCount: "ACT_360"}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := buildSchedule(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildSchedule error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(items) != tt.params.TermMonths {
+				t.Fatalf("got %d installments, want %d", len(items), tt.params.TermMonths)
+			}
+			if items[0].TotalPayment != tt.first || items[len(items)-1].TotalPayment != tt.last {
+				t.Errorf("payments = %s … %s, want %s … %s", items[0].TotalPayment, items[len(items)-1].TotalPayment, tt.first, tt.last)
+			}
+
+			// Основной долг погашается полностью, остаток убывает до нуля
+			var principal int64
+			for i, it := range items {
+				k, err := parseMoney(it.Principal)
+				if err != nil {
+					t.Fatal(err)
+				}
+				principal += k
+				if it.InstallmentNo != i+1 {
+					t.Errorf("installment %d numbered %d", i+1, it.InstallmentNo)
+				}
+				if want := addMonths(start, i+1); !it.DueDate.Equal(want) {
+					t.Errorf("installment %d due %s, want %s", i+1, it.DueDate.Format(time.DateOnly), want.Format(time.DateOnly))
+				}
+			}
+			if principal != tt.params.Principal {
+				t.Errorf("principal repaid = %d, want %d", principal, tt.params.Principal)
+			}
+			if rest := items[len(items)-1].RemainingBalance; rest != "0.00" {
+				t.Errorf("remaining balance = %s, want 0.00", rest)
+			}
+		})
+	}
+}
+
+func TestShortenedTerm(t *testing.T) {
+	tests := []struct {
+		name          string
+		principal     int64
+		monthlyRate   float64
+		repaymentType string
+		next          models.LoanScheduleItem
+		want          int
+		wantErr       bool
+	}{
+		{"differentiated exact", 1000000, 0.01, RepaymentDifferentiated, models.LoanScheduleItem{Principal: "2500.00"}, 4, false},
+		{"differentiated rounds up", 1000001, 0.01, RepaymentDifferentiated, models.LoanScheduleItem{Principal: "2500.00"}, 5, false},
+		{"annuity keeps payment", 10000000, 0.01, RepaymentAnnuity, models.LoanScheduleItem{TotalPayment: "8884.88"}, 12, false},
+		{"annuity after prepayment", 5000000, 0.01, RepaymentAnnuity, models.LoanScheduleItem{TotalPayment: "8884.88"}, 6, false},
+		{"annuity at zero rate", 1000000, 0, RepaymentAnnuity, models.LoanScheduleItem{TotalPayment: "3333.34"}, 3, false},
+		{"payment below interest", 10000000, 0.01, RepaymentAnnuity, models.LoanScheduleItem{TotalPayment: "1000.00"}, 0, true},
+		{"zero scheduled principal", 1000000, 0.01, RepaymentDifferentiated, models.LoanScheduleItem{Principal: "0.00"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := shortenedTerm(tt.principal, tt.monthlyRate, tt.repaymentType, tt.next)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("shortenedTerm error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("shortenedTerm = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddMonths(t *testing.T) {
+	tests := []struct {
+		start  time.Time
+		months int
+		want   time.Time
+	}{
+		{date(2026, 1, 31), 1, date(2026, 2, 28)},
+		{date(2024, 1, 31), 1, date(2024, 2, 29)},
+		{date(2026, 3, 31), 1, date(2026, 4, 30)},
+		{date(2026, 1, 31), 2, date(2026, 3, 31)},
+		{date(2026, 12, 15), 2, date(2027, 2, 15)},
+		{date(2026, 5, 10), 0, date(2026, 5, 10)},
+	}
+	for _, tt := range tests {
+		if got := addMonths(tt.start, tt.months); !got.Equal(tt.want) {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.start.Format(time.DateOnly), tt.months, got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestCombineSchedules(t *testing.T) {
+	items := []models.LoanScheduleItem{
+		{SplitID: 1, InstallmentNo: 1, DueDate: date(2026, 2, 10), Principal: "800.00", Interest: "100.00", Fee: "10.00", TotalPayment: "900.00", RemainingBalance: "9200.00"},
+		{SplitID: 2, InstallmentNo: 1, DueDate: date(2026, 2, 11), Principal: "400.00", Interest: "50.00", TotalPayment: "450.00", RemainingBalance: "4600.00"},
+		{SplitID: 1, InstallmentNo: 2, DueDate: date(2026, 3, 10), Principal: "810.00", Interest: "90.00", Fee: "10.00", TotalPayment: "900.00", RemainingBalance: "8390.00"},
+	}
+	got, err := combineSchedules(items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.LoanScheduleItem{
+		{InstallmentNo: 1, DueDate: date(2026, 2, 11), Principal: "1200.00", Interest: "150.00", Fee: "10.00", TotalPayment: "1350.00", RemainingBalance: "13800.00"},
+		{InstallmentNo: 2, DueDate: date(2026, 3, 10), Principal: "810.00", Interest: "90.00", Fee: "10.00", TotalPayment: "900.00", RemainingBalance: "8390.00"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d installments, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("installment %d = %+v, want %+v", i+1, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package services
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// Модель скоринга v1: к базовой оценке прибавляются баллы факторов,
+// результат ограничивается диапазоном 300–850
+const (
+	scoringModelVersion = "v1"
+	scoringBase         = 600
+	scoreMin            = 300
+	scoreMax            = 850
+	scoringWindowMonths = 6
+)
+
+// Коды факторов скоринга
+const (
+	FactorPunctuality   = "PAYMENT_PUNCTUALITY"
+	FactorDelinquency   = "CURRENT_DELINQUENCY"
+	FactorDebtBurden    = "DEBT_BURDEN"
+	FactorCashFlow      = "CASH_FLOW"
+	FactorIncomeRegular = "INCOME_REGULARITY"
+	FactorBalanceBuffer = "BALANCE_BUFFER"
+)
+
+// scoringData — входные данные скоринга в копейках
+type scoringData struct {
+	income, expenses, balance, debt int64
+	in                              models.ScoringInputs
+}
+
+func newScoringData(in models.ScoringInputs) (*scoringData, error) {
+	d := &scoringData{in: in}
+	for _, f := range []struct {
+		dst *int64
+		src string
+	}{{&d.income, in.Income}, {&d.expenses, in.Expenses}, {&d.balance, in.Balance}, {&d.debt, in.Debt}} {
+		v, err := parseMoney(f.src)
+		if err != nil {
+			return nil, err
+		}
+		*f.dst = v
+	}
+	return d, nil
+}
+
+// monthly — среднемесячная сумма за окно скоринга
+func (d *scoringData) monthly(total int64) int64 {
+	if d.in.WindowMonths <= 0 {
+		return total
+	}
+	return total / int64(d.in.WindowMonths)
+}
+
+// computeScore считает оценку и объяснение: сумма Points факторов плюс база даёт оценку до ограничения
+func computeScore(in models.ScoringInputs) (int, string, []models.ScoreFactor, error) {
+	d, err := newScoringData(in)
+	if err != nil {
+		return 0, "", nil, err
+	}
+
+	factors := []models.ScoreFactor{
+		punctualityFactor(d),
+		delinquencyFactor(d),
+		debtBurdenFactor(d),
+		cashFlowFactor(d),
+		incomeRegularityFactor(d),
+		balanceBufferFactor(d),
+	}
+
+	score := scoringBase
+	for _, f := range factors {
+		score += f.Points
+	}
+	score = max(scoreMin, min(scoreMax, score))
+	return score, creditGrade(score), factors, nil
+}
+
+func creditGrade(score int) string {
+	switch {
+	case score >= 750:
+		return models.CreditGradeA
+	case score >= 680:
+		return models.CreditGradeB
+	case score >= 600:
+		return models.CreditGradeC
+	case score >= 520:
+		return models.CreditGradeD
+	default:
+		return models.CreditGradeE
+	}
+}
+
+// punctualityFactor — доля плановых платежей, оплаченных к сроку; 80% — нейтрально
+func punctualityFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{Code: FactorPunctuality}
+	if d.in.DueInstallments == 0 {
+		f.Description = "no repayment history yet"
+		f.Value = "0 installments due"
+		return f
+	}
+	ratio := float64(d.in.OnTimeInstallments) / float64(d.in.DueInstallments)
+	f.Value = fmt.Sprintf("%d of %d on time (%.0f%%)", d.in.OnTimeInstallments, d.in.DueInstallments, ratio*100)
+	f.Points = max(-200, min(100, int(math.Round((ratio-0.8)*500))))
+	switch {
+	case f.Points > 0:
+		f.Description = "installments are paid on time"
+	case f.Points < 0:
+		f.Description = "installments were paid late"
+	default:
+		f.Description = "some installments were paid late"
+	}
+	return f
+}
+
+// delinquencyFactor — текущая просрочка и дефолтные кредиты
+func delinquencyFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{
+		Code:  FactorDelinquency,
+		Value: fmt.Sprintf("%d days past due, %d defaulted loan(s)", d.in.MaxDaysPastDue, d.in.DefaultedLoans),
+	}
+	switch {
+	case d.in.DefaultedLoans > 0 || d.in.MaxDaysPastDue > 90:
+		f.Points = -250
+		f.Description = "loan in default"
+	case d.in.MaxDaysPastDue > 30:
+		f.Points = -150
+		f.Description = "overdue more than 30 days"
+	case d.in.MaxDaysPastDue > 0:
+		f.Points = -60
+		f.Description = "currently overdue"
+	default:
+		f.Description = "no current delinquency"
+	}
+	return f
+}
+
+// debtBurdenFactor — долг относительно годового дохода
+func debtBurdenFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{Code: FactorDebtBurden}
+	annualIncome := d.monthly(d.income) * 12
+	switch {
+	case d.debt <= 0:
+		f.Value = "no outstanding debt"
+		f.Points = 30
+		f.Description = "no outstanding debt"
+		return f
+	case annualIncome <= 0:
+		f.Value = "debt " + formatMoney(d.debt) + ", no income"
+		f.Points = -100
+		f.Description = "debt without detected income"
+		return f
+	}
+
+	ratio := float64(d.debt) / float64(annualIncome)
+	f.Value = fmt.Sprintf("debt is %.2f of annual income", ratio)
+	switch {
+	case ratio <= 0.3:
+		f.Points = 40
+		f.Description = "low debt burden"
+	case ratio <= 0.6:
+		f.Points = 10
+		f.Description = "moderate debt burden"
+	case ratio <= 1:
+		f.Points = -30
+		f.Description = "high debt burden"
+	case ratio <= 2:
+		f.Points = -80
+		f.Description = "very high debt burden"
+	default:
+		f.Points = -130
+		f.Description = "debt exceeds two annual incomes"
+	}
+	return f
+}
+
+// cashFlowFactor — доля дохода, остающаяся после расходов
+func cashFlowFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{Code: FactorCashFlow}
+	if d.income <= 0 {
+		f.Value = "no income"
+		f.Points = -60
+		f.Description = "no income detected in transactions"
+		return f
+	}
+
+	rate := float64(d.income-d.expenses) / float64(d.income)
+	f.Value = fmt.Sprintf("savings rate %.0f%%", rate*100)
+	switch {
+	case rate >= 0.2:
+		f.Points = 50
+		f.Description = "income well exceeds expenses"
+	case rate >= 0.05:
+		f.Points = 20
+		f.Description = "income exceeds expenses"
+	case rate >= 0:
+		f.Description = "income barely covers expenses"
+	default:
+		f.Points = -50
+		f.Description = "expenses exceed income"
+	}
+	return f
+}
+
+// incomeRegularityFactor — сколько месяцев окна были с поступлениями
+func incomeRegularityFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{
+		Code:  FactorIncomeRegular,
+		Value: fmt.Sprintf("income in %d of %d months", d.in.IncomeMonths, d.in.WindowMonths),
+	}
+	switch {
+	case d.in.WindowMonths > 0 && d.in.IncomeMonths >= d.in.WindowMonths:
+		f.Points = 40
+		f.Description = "regular monthly income"
+	case d.in.IncomeMonths >= 4:
+		f.Points = 20
+		f.Description = "mostly regular income"
+	default:
+		f.Description = "irregular income"
+	}
+	return f
+}
+
+// balanceBufferFactor — на сколько месяцев расходов хватит остатков на счетах
+func balanceBufferFactor(d *scoringData) models.ScoreFactor {
+	f := models.ScoreFactor{Code: FactorBalanceBuffer}
+	monthlyExpenses := d.monthly(d.expenses)
+	if d.balance <= 0 {
+		f.Value = "balance " + formatMoney(d.balance)
+		f.Points = -30
+		f.Description = "no funds on accounts"
+		return f
+	}
+	if monthlyExpenses <= 0 {
+		f.Value = "balance " + formatMoney(d.balance) + ", no expenses"
+		f.Points = 20
+		f.Description = "funds on accounts"
+		return f
+	}
+
+	months := float64(d.balance) / float64(monthlyExpenses)
+	f.Value = fmt.Sprintf("balance covers %.1f months of expenses", months)
+	switch {
+	case months >= 3:
+		f.Points = 50
+		f.Description = "strong savings buffer"
+	case months >= 1:
+		f.Points = 20
+		f.Description = "savings cover a month of expenses"
+	default:
+		f.Description = "thin savings buffer"
+	}
+	return f
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// Сохранённая оценка считается актуальной сутки; дальше пересчитывается по запросу
+const scoreTTL = 24 * time.Hour
+
+type ScoringService interface {
+	// ScoreUser считает и сохраняет новую оценку; appID — заявка, для которой делается предскоринг
+	ScoreUser(ctx context.Context, userID int64, appID *int64) (*models.CreditScore, error)
+	// GetCreditScore возвращает актуальную оценку, при refresh или её отсутствии — считает заново
+	GetCreditScore(ctx context.Context, userID int64, refresh bool) (*models.CreditScore, error)
+	ScoreHistory(ctx context.Context, userID int64, limit int) ([]models.CreditScore, error)
+	// ApplicationScores — предскоринг заявок: application_id → последняя оценка
+	ApplicationScores(ctx context.Context, appIDs ...int64) (map[int64]*models.CreditScore, error)
+	// GetUserStats — профиль, остатки, задолженность и кредитный рейтинг (грейд) пользователя
+	GetUserStats(ctx context.Context, userID int64) (*models.UserStatsDTO, error)
+}
+
+type scoringServiceImpl struct {
+	scoringRepo repos.ScoringRepository
+	userRepo    repos.UserRepository
+}
+
+func NewScoringService(scoringRepo repos.ScoringRepository, userRepo repos.UserRepository) ScoringService {
+	return &scoringServiceImpl{
+		scoringRepo: scoringRepo,
+		userRepo:    userRepo,
+	}
+}
+
+func (s *scoringServiceImpl) ScoreUser(ctx context.Context, userID int64, appID *int64) (*models.CreditScore, error) {
+	now := time.Now()
+	in, err := s.scoringRepo.LoadInputs(ctx, userID, now.AddDate(0, -scoringWindowMonths, 0), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load scoring inputs: %w", err)
+	}
+	in.WindowMonths = scoringWindowMonths
+
+	score, grade, factors, err := computeScore(*in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute score: %w", err)
+	}
+
+	res := &models.CreditScore{
+		UserID:        userID,
+		ApplicationID: appID,
+		Score:         score,
+		Grade:         grade,
+		ModelVersion:  scoringModelVersion,
+		Factors:       factors,
+		Inputs:        *in,
+	}
+	if err := s.scoringRepo.CreateScore(ctx, res); err != nil {
+		return nil, fmt.Errorf("failed to save score: %w", err)
+	}
+	return res, nil
+}
+
+func (s *scoringServiceImpl) GetCreditScore(ctx context.Context, userID int64, refresh bool) (*models.CreditScore, error) {
+	if !refresh {
+		latest, err := s.scoringRepo.GetLatestScore(ctx, userID)
+		switch {
+		case err == nil:
+			if time.Since(latest.ComputedAt) < scoreTTL && latest.ModelVersion == scoringModelVersion {
+				return latest, nil
+			}
+		case !errors.Is(err, sql.ErrNoRows):
+			return nil, fmt.Errorf("failed to get latest score: %w", err)
+		}
+	}
+	return s.ScoreUser(ctx, userID, nil)
+}
+
+func (s *scoringServiceImpl) ScoreHistory(ctx context.Context, userID int64, limit int) ([]models.CreditScore, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.scoringRepo.ListScores(ctx, userID, limit)
+}
+
+func (s *scoringServiceImpl) ApplicationScores(ctx context.Context, appIDs ...int64) (map[int64]*models.CreditScore, error) {
+	scores, err := s.scoringRepo.ListApplicationScores(ctx, appIDs...)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int64]*models.CreditScore, len(scores))
+	for i := range scores {
+		res[*scores[i].ApplicationID] = &scores[i]
+	}
+	return res, nil
+}
+
+func (s *scoringServiceImpl) GetUserStats(ctx context.Context, userID int64) (*models.UserStatsDTO, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: user %d", apperrors.ErrNotFound, userID)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Остатки и долг — на текущий момент, рейтинг — по актуальной оценке
+	now := time.Now()
+	in, err := s.scoringRepo.LoadInputs(ctx, userID, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balances: %w", err)
+	}
+	score, err := s.GetCreditScore(ctx, userID, false)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserStatsDTO{
+		User:         user,
+		TotalBalance: in.Balance,
+		TotalDebt:    in.Debt,
+		CreditRating: score.Grade,
+	}, nil
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// statementEntry — операция из выписки до сопоставления с транзакциями
+type statementEntry struct {
+	Line        int
+	OccurredAt  time.Time
+	Amount      int64 // копейки; < 0 — списание
+	Currency    string
+	Description string
+	Category    string
+	Reference   string // идентификатор операции в банке, если формат его даёт
+}
+
+// parsedStatement — разобранная выписка: операции и строки, которые разобрать не удалось
+type parsedStatement struct {
+	Account  string
+	Currency string
+	Entries  []statementEntry
+	Errors   []models.StatementLineError
+}
+
+func (p *parsedStatement) fail(line int, format string, args ...any) {
+	p.Errors = append(p.Errors, models.StatementLineError{Line: line, Error: fmt.Sprintf(format, args...)})
+}
+
+// parseStatement разбирает выписку в формате format; mapping нужен только для CSV.
+// Ошибка возвращается, если файл не читается целиком; ошибки отдельных операций — в Errors
+func parseStatement(format string, data []byte, mapping *StatementCSVMapping) (*parsedStatement, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	switch format {
+	case models.StatementCSV:
+		if mapping == nil {
+			return nil, fmt.Errorf("no CSV column mapping")
+		}
+		return parseCSVStatement(data, mapping)
+	case models.StatementOFX:
+		return parseOFXStatement(data)
+	case models.StatementCAMT053:
+		return parseCAMTStatement(data)
+	case models.StatementMT940:
+		return parseMT940Statement(data)
+	default:
+		return nil, fmt.Errorf("unknown statement format %q", format)
+	}
+}
+
+// detectStatementFormat определяет формат по содержимому, затем по расширению файла; "" — не определён
+func detectStatementFormat(filename string, data []byte) string {
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	text := strings.ToUpper(string(head))
+	switch {
+	case strings.Contains(text, "OFXHEADER") || strings.Contains(text, "<OFX>"):
+		return models.StatementOFX
+	case strings.Contains(text, "CAMT.053") || strings.Contains(text, "<BKTOCSTMRSTMT>"):
+		return models.StatementCAMT053
+	case strings.Contains(text, ":20:") && strings.Contains(text, ":61:"):
+		return models.StatementMT940
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv", ".txt":
+		return models.StatementCSV
+	case ".ofx", ".qfx":
+		return models.StatementOFX
+	case ".xml":
+		return models.StatementCAMT053
+	case ".sta", ".940", ".mt940":
+		return models.StatementMT940
+	}
+	return ""
+}
+
+// statementAmount разбирает сумму из выписки: пробелы внутри числа допускаются,
+// decimalComma — дробная часть отделяется запятой, а точки разделяют разряды
+func statementAmount(s string, decimalComma bool) (int64, error) {
+	s = strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\u00a0', '\u202f', '\'':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+	if decimalComma {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	} else {
+		s = strings.ReplaceAll(s, ",", "")
+	}
+	// Скобки и минус в конце — встречающиеся обозначения списания
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg, s = true, s[1:len(s)-1]
+	}
+	if strings.HasSuffix(s, "-") {
+		neg, s = true, strings.TrimSuffix(s, "-")
+	}
+	k, err := parseMoney(s)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		k = -k
+	}
+	return k, nil
+}
+
+// statementExternalID — ключ дедупликации операции: идентификатор банка, а без него —
+// хэш даты, суммы и описания с номером повтора n среди одинаковых операций файла
+func statementExternalID(format string, e *statementEntry, n int) string {
+	if e.Reference != "" {
+		return strings.ToLower(format) + ":" + e.Reference
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d|%s|%d",
+		e.OccurredAt.Format("2006-01-02"), e.Amount, strings.Join(strings.Fields(e.Description), " "), n)))
+	return "hash:" + hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// camtAccount — счёт выписки camt.053 (Stmt/Acct)
+type camtAccount struct {
+	IBAN     string `xml:"Id>IBAN"`
+	Other    string `xml:"Id>Othr>Id"`
+	Currency string `xml:"Ccy"`
+}
+
+// camtEntry — операция выписки camt.053 (Stmt/Ntry)
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	Indicator string `xml:"CdtDbtInd"` // CRDT | DBIT
+	// В camt.053.001.02 статус — текст элемента, в поздних версиях — Sts/Cd
+	Status struct {
+		Text string `xml:",chardata"`
+		Code string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate     string `xml:"BookgDt>Dt"`
+	BookingDateTime string `xml:"BookgDt>DtTm"`
+	ValueDate       string `xml:"ValDt>Dt"`
+	AcctSvcrRef     string `xml:"AcctSvcrRef"`
+	AdditionalInfo  string `xml:"AddtlNtryInf"`
+	Details         []struct {
+		AcctSvcrRef  string   `xml:"Refs>AcctSvcrRef"`
+		Unstructured []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+}
+
+// parseCAMTStatement разбирает ISO 20022 camt.053: в выписку попадают только проведённые (BOOK) операции
+func parseCAMTStatement(data []byte) (*parsedStatement, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if !strings.EqualFold(charset, "windows-1251") && !strings.EqualFold(charset, "cp1251") {
+			return nil, fmt.Errorf("unsupported charset %q", charset)
+		}
+		raw, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.NewReader(decodeWindows1251(raw)), nil
+	}
+
+	st := &parsedStatement{}
+	seenStatement := false
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			line, _ := dec.InputPos()
+			return nil, fmt.Errorf("invalid camt.053 XML at line %d: %w", line, err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "Stmt":
+			seenStatement = true
+		case "Acct":
+			var acc camtAccount
+			if err := dec.DecodeElement(&acc, &start); err != nil {
+				return nil, fmt.Errorf("invalid camt.053 account: %w", err)
+			}
+			if st.Account == "" {
+				st.Account = acc.IBAN
+				if st.Account == "" {
+					st.Account = acc.Other
+				}
+				st.Currency = strings.ToUpper(acc.Currency)
+			}
+		case "Ntry":
+			line, _ := dec.InputPos()
+			var ntry camtEntry
+			if err := dec.DecodeElement(&ntry, &start); err != nil {
+				return nil, fmt.Errorf("invalid camt.053 entry at line %d: %w", line, err)
+			}
+			if e, err := ntry.entry(line); err != nil {
+				st.fail(line, "%v", err)
+			} else {
+				st.Entries = append(st.Entries, *e)
+			}
+		}
+	}
+	if !seenStatement {
+		return nil, fmt.Errorf("not a camt.053 statement: no Stmt element")
+	}
+	return st, nil
+}
+
+func (n *camtEntry) entry(line int) (*statementEntry, error) {
+	status := strings.TrimSpace(n.Status.Code)
+	if status == "" {
+		status = strings.TrimSpace(n.Status.Text)
+	}
+	if status != "" && status != "BOOK" {
+		return nil, fmt.Errorf("entry is not booked (status %s)", status)
+	}
+
+	amount, err := statementAmount(n.Amount.Value, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Amt: %w", err)
+	}
+	if amount < 0 {
+		amount = -amount
+	}
+	switch strings.TrimSpace(n.Indicator) {
+	case "CRDT":
+	case "DBIT":
+		amount = -amount
+	default:
+		return nil, fmt.Errorf("invalid CdtDbtInd %q", n.Indicator)
+	}
+
+	e := &statementEntry{
+		Line:      line,
+		Amount:    amount,
+		Currency:  strings.ToUpper(n.Amount.Currency),
+		Reference: strings.TrimSpace(n.AcctSvcrRef),
+	}
+	date := n.BookingDateTime
+	if date == "" {
+		date = n.BookingDate
+	}
+	if date == "" {
+		date = n.ValueDate
+	}
+	if e.OccurredAt, err = parseStatementDate(strings.TrimSpace(date), []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02"}); err != nil {
+		return nil, err
+	}
+
+	var info []string
+	for _, d := range n.Details {
+		if e.Reference == "" {
+			e.Reference = strings.TrimSpace(d.AcctSvcrRef)
+		}
+		for _, u := range d.Unstructured {
+			if u = strings.TrimSpace(u); u != "" {
+				info = append(info, u)
+			}
+		}
+	}
+	e.Description = strings.Join(info, " ")
+	if e.Description == "" {
+		e.Description = strings.TrimSpace(n.AdditionalInfo)
+	}
+	return e, nil
+}
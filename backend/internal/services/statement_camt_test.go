@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCAMTStatement(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+<BkToCstmrStmt><Stmt>
+  <Acct><Id><Othr><Id>40817810000000000001</Id></Othr></Id><Ccy>rub</Ccy></Acct>
+  <Ntry>
+    <Amt Ccy="RUB">85000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts>
+    <BookgDt><Dt>2025-03-01</Dt></BookgDt>
+    <AcctSvcrRef>C1</AcctSvcrRef>
+    <NtryDtls><TxDtls><RmtInf><Ustrd>Зарплата</Ustrd><Ustrd>за февраль</Ustrd></RmtInf></TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="RUB">1200.50</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><DtTm>2025-03-02T14:05:00+03:00</DtTm></BookgDt>
+    <AddtlNtryInf>Оплата покупки</AddtlNtryInf>
+    <NtryDtls><TxDtls><Refs><AcctSvcrRef>C2</AcctSvcrRef></Refs></TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="RUB">10.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>PDNG</Sts>
+    <BookgDt><Dt>2025-03-03</Dt></BookgDt>
+  </Ntry>
+  <Ntry>
+    <Amt Ccy="RUB">10.00</Amt><CdtDbtInd>XXX</CdtDbtInd>
+    <ValDt><Dt>2025-03-03</Dt></ValDt>
+  </Ntry>
+</Stmt></BkToCstmrStmt>
+</Document>
+`
+	st, err := parseCAMTStatement([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Account != "40817810000000000001" || st.Currency != "RUB" {
+		t.Errorf("account = %s %s, want 40817810000000000001 RUB", st.Account, st.Currency)
+	}
+	checkEntries(t, st.Entries, []statementEntry{
+		{OccurredAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 8500000, Currency: "RUB", Description: "Зарплата за февраль", Reference: "C1"},
+		{OccurredAt: time.Date(2025, 3, 2, 11, 5, 0, 0, time.UTC), Amount: -120050, Currency: "RUB", Description: "Оплата покупки", Reference: "C2"},
+	})
+	if len(st.Errors) != 2 {
+		t.Errorf("got %d line errors, want 2: %+v", len(st.Errors), st.Errors)
+	}
+
+	for name, data := range map[string]string{
+		"not a statement": `<Document><Other/></Document>`,
+		"broken XML":      `<Document><BkToCstmrStmt><Stmt>`,
+	} {
+		if _, err := parseCAMTStatement([]byte(data)); err == nil {
+			t.Errorf("%s: parseCAMTStatement returned no error", name)
+		}
+	}
+}
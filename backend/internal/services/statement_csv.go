@@ -0,0 +1,252 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// StatementCSVMapping — разметка CSV-выписки банка. Колонки ищутся по заголовку без учёта регистра;
+// сумма берётся из AmountColumn (со знаком) либо из пары DebitColumn/CreditColumn.
+// Колонки даты и суммы обязательны, остальных в файле может не быть
+type StatementCSVMapping struct {
+	Delimiter         string   `json:"delimiter"` // по умолчанию ","
+	SkipRows          int      `json:"skip_rows"` // строк перед заголовком
+	DateColumn        string   `json:"date_column"`
+	DateFormats       []string `json:"date_formats"` // раскладки Go, пробуются по порядку
+	AmountColumn      string   `json:"amount_column"`
+	DebitColumn       string   `json:"debit_column"`
+	CreditColumn      string   `json:"credit_column"`
+	DecimalComma      bool     `json:"decimal_comma"`
+	DescriptionColumn string   `json:"description_column"`
+	CategoryColumn    string   `json:"category_column"`
+	ReferenceColumn   string   `json:"reference_column"`
+	CurrencyColumn    string   `json:"currency_column"`
+}
+
+// StatementCSVMappings — разметки по коду банка; "default" — для банков без своей разметки
+type StatementCSVMappings map[string]StatementCSVMapping
+
+// defaultStatementCSVMappings — разметки, если STATEMENT_CSV_MAPPINGS_FILE не задан
+//
+//go:embed statement_csv_mappings.json
+var defaultStatementCSVMappings []byte
+
+// LoadStatementCSVMappings читает разметки CSV-выписок из path или встроенные, если path пуст
+func LoadStatementCSVMappings(path string) (StatementCSVMappings, error) {
+	data := defaultStatementCSVMappings
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read CSV mappings: %w", err)
+		}
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var m StatementCSVMappings
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("invalid CSV mappings: %w", err)
+	}
+	for code, mapping := range m {
+		if err := mapping.validate(); err != nil {
+			return nil, fmt.Errorf("invalid CSV mapping %s: %w", code, err)
+		}
+	}
+	return m, nil
+}
+
+// For — разметка банка с кодом bankCode; nil, если нет ни её, ни разметки по умолчанию
+func (m StatementCSVMappings) For(bankCode string) *StatementCSVMapping {
+	if mapping, ok := m[bankCode]; ok {
+		return &mapping
+	}
+	if mapping, ok := m["default"]; ok {
+		return &mapping
+	}
+	return nil
+}
+
+func (m *StatementCSVMapping) validate() error {
+	if utf8.RuneCountInString(m.Delimiter) > 1 {
+		return fmt.Errorf("delimiter must be a single character")
+	}
+	if m.DateColumn == "" || len(m.DateFormats) == 0 {
+		return fmt.Errorf("date_column and date_formats are required")
+	}
+	if m.AmountColumn == "" && (m.DebitColumn == "" || m.CreditColumn == "") {
+		return fmt.Errorf("either amount_column or both debit_column and credit_column are required")
+	}
+	return nil
+}
+
+func parseCSVStatement(data []byte, m *StatementCSVMapping) (*parsedStatement, error) {
+	// Выгрузки российских банков часто в Windows-1251
+	if !utf8.Valid(data) {
+		data = decodeWindows1251(data)
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = ','
+	if m.Delimiter != "" {
+		r.Comma, _ = utf8.DecodeRuneInString(m.Delimiter)
+	}
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	for i := 0; i < m.SkipRows; i++ {
+		if _, err := r.Read(); err != nil {
+			return nil, fmt.Errorf("failed to skip row %d: %w", i+1, err)
+		}
+	}
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	col := func(name string) int {
+		if i, ok := columns[strings.ToLower(name)]; ok && name != "" {
+			return i
+		}
+		return -1
+	}
+
+	var idx struct{ date, amount, debit, credit, description, category, reference, currency int }
+	idx.date, idx.amount, idx.debit, idx.credit = col(m.DateColumn), col(m.AmountColumn), col(m.DebitColumn), col(m.CreditColumn)
+	idx.description, idx.category = col(m.DescriptionColumn), col(m.CategoryColumn)
+	idx.reference, idx.currency = col(m.ReferenceColumn), col(m.CurrencyColumn)
+	if idx.date < 0 {
+		return nil, fmt.Errorf("column %q not found in CSV header", m.DateColumn)
+	}
+	if m.AmountColumn != "" && idx.amount < 0 {
+		return nil, fmt.Errorf("column %q not found in CSV header", m.AmountColumn)
+	}
+	if m.AmountColumn == "" && (idx.debit < 0 || idx.credit < 0) {
+		return nil, fmt.Errorf("columns %q and %q not found in CSV header", m.DebitColumn, m.CreditColumn)
+	}
+
+	st := &parsedStatement{}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			st.fail(parseErr.StartLine, "%v", parseErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		line, _ := r.FieldPos(0)
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		field := func(i int) string {
+			if i < 0 || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		e := statementEntry{
+			Line:        line,
+			Description: field(idx.description),
+			Category:    field(idx.category),
+			Reference:   field(idx.reference),
+			Currency:    strings.ToUpper(field(idx.currency)),
+		}
+		if e.OccurredAt, err = parseStatementDate(field(idx.date), m.DateFormats); err != nil {
+			st.fail(line, "%v", err)
+			continue
+		}
+		if e.Amount, err = csvAmount(field, idx.amount, idx.debit, idx.credit, m.DecimalComma); err != nil {
+			st.fail(line, "%v", err)
+			continue
+		}
+		st.Entries = append(st.Entries, e)
+	}
+	return st, nil
+}
+
+// csvAmount — сумма строки со знаком: из колонки суммы либо как зачисление минус списание
+func csvAmount(field func(int) string, amount, debit, credit int, decimalComma bool) (int64, error) {
+	if amount >= 0 {
+		k, err := statementAmount(field(amount), decimalComma)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount: %w", err)
+		}
+		return k, nil
+	}
+
+	var total int64
+	for _, c := range []struct {
+		i    int
+		sign int64
+	}{{credit, 1}, {debit, -1}} {
+		v := field(c.i)
+		if v == "" {
+			continue
+		}
+		k, err := statementAmount(v, decimalComma)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount: %w", err)
+		}
+		if k < 0 {
+			k = -k
+		}
+		total += c.sign * k
+	}
+	if field(debit) == "" && field(credit) == "" {
+		return 0, fmt.Errorf("empty amount")
+	}
+	return total, nil
+}
+
+func parseStatementDate(s string, layouts []string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q, expected %s", s, strings.Join(layouts, " or "))
+}
+
+// windows1251High — символы Windows-1251 с кодами 0x80–0xBF; 0xC0–0xFF — подряд А…я
+var windows1251High = [64]rune{
+	'Ђ', 'Ѓ', '‚', 'ѓ', '„', '…', '†', '‡', '€', '‰', 'Љ', '‹', 'Њ', 'Ќ', 'Ћ', 'Џ',
+	'ђ', '‘', '’', '“', '”', '•', '–', '—', '\ufffd', '™', 'љ', '›', 'њ', 'ќ', 'ћ', 'џ',
+	'\u00a0', 'Ў', 'ў', 'Ј', '¤', 'Ґ', '¦', '§', 'Ё', '©', 'Є', '«', '¬', '\u00ad', '®', 'Ї',
+	'°', '±', 'І', 'і', 'ґ', 'µ', '¶', '·', 'ё', '№', 'є', '»', 'ј', 'Ѕ', 'ѕ', 'ї',
+}
+
+func decodeWindows1251(data []byte) []byte {
+	var b strings.Builder
+	b.Grow(len(data) * 2)
+	for _, c := range data {
+		switch {
+		case c < 0x80:
+			b.WriteByte(c)
+		case c < 0xC0:
+			b.WriteRune(windows1251High[c-0x80])
+		default:
+			b.WriteRune(rune(0x0410 + int(c) - 0xC0))
+		}
+	}
+	return []byte(b.String())
+}
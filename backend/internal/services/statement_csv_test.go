@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCSVStatement(t *testing.T) {
+	mappings, err := LoadStatementCSVMappings("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		bank    string
+		data    string
+		want    []statementEntry
+		errors  int
+		wantErr bool
+	}{
+		{
+			name: "default mapping",
+			bank: "UNKNOWN",
+			data: "Date,Amount,Description,Category,Reference,Currency\n" +
+				"2025-03-01,85000.00,Зарплата,Salary,REF-1,rub\n" +
+				"01.03.2025,-1200.50,\"Магазин, продукты\",Groceries,,RUB\n" +
+				"\n" +
+				"2025-13-01,10.00,Bad date,,,\n",
+			want: []statementEntry{
+				{OccurredAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 8500000, Currency: "RUB", Description: "Зарплата", Category: "Salary", Reference: "REF-1"},
+				{OccurredAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Amount: -120050, Currency: "RUB", Description: "Магазин, продукты", Category: "Groceries"},
+			},
+			errors: 1,
+		},
+		{
+			name: "debit and credit columns with decimal comma",
+			bank: "ALFA",
+			data: "Дата операции;Приход;Расход;Описание;Референс проводки;Валюта\n" +
+				"05.03.2025;1 000,00;;Перевод;A1;RUB\n" +
+				"06.03.25;;250,75;Такси;A2;RUB\n" +
+				"07.03.2025;;;Пусто;A3;RUB\n",
+			want: []statementEntry{
+				{OccurredAt: time.Date(2025, 3, 5, 0, 0, 0, 0, time.UTC), Amount: 100000, Currency: "RUB", Description: "Перевод", Reference: "A1"},
+				{OccurredAt: time.Date(2025, 3, 6, 0, 0, 0, 0, time.UTC), Amount: -25075, Currency: "RUB", Description: "Такси", Reference: "A2"},
+			},
+			errors: 1,
+		},
+		{
+			name: "Windows-1251",
+			bank: "OPT",
+			data: "\xc4\xe0\xf2\xe0;\xc4\xe5\xe1\xe5\xf2;\xca\xf0\xe5\xe4\xe8\xf2\n" + // Дата;Дебет;Кредит
+				"10.03.2025;;500,00\n",
+			want: []statementEntry{
+				{OccurredAt: time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC), Amount: 50000},
+			},
+		},
+		{
+			name:    "missing amount column",
+			bank:    "UNKNOWN",
+			data:    "date,sum\n2025-03-01,10.00\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st, err := parseCSVStatement([]byte(tt.data), mappings.For(tt.bank))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseCSVStatement error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			checkEntries(t, st.Entries, tt.want)
+			if len(st.Errors) != tt.errors {
+				t.Errorf("got %d line errors, want %d: %+v", len(st.Errors), tt.errors, st.Errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// mt940Field — поле сообщения MT940 (:61:, :86: …) со строкой, на которой оно начинается
+type mt940Field struct {
+	Tag   string
+	Value string
+	Line  int
+}
+
+var mt940TagPattern = regexp.MustCompile(`^:(\d{2}[A-Z]?):`)
+
+// mt940LinePattern — первая строка :61:: дата валютирования YYMMDD, [дата проводки MMDD],
+// признак C/D/RC/RD, [код средств], сумма с запятой, тип операции, референс клиента[//референс банка]
+var mt940LinePattern = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d{0,2})([A-Z][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// parseMT940Statement разбирает SWIFT MT940: операции из полей :61:, описание — из следующего за ним :86:
+func parseMT940Statement(data []byte) (*parsedStatement, error) {
+	if !utf8.Valid(data) {
+		data = decodeWindows1251(data)
+	}
+	fields := mt940Fields(string(data))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("not an MT940 statement: no fields")
+	}
+
+	st := &parsedStatement{}
+	var pending *statementEntry
+	flush := func() {
+		if pending != nil {
+			st.Entries = append(st.Entries, *pending)
+			pending = nil
+		}
+	}
+	for _, f := range fields {
+		switch f.Tag {
+		case "25":
+			if st.Account == "" {
+				st.Account = strings.TrimSpace(f.Value)
+			}
+		case "60F", "60M":
+			// C|D, дата YYMMDD, валюта, сумма
+			if v := strings.TrimSpace(f.Value); len(v) >= 10 && st.Currency == "" {
+				st.Currency = v[7:10]
+			}
+		case "61":
+			flush()
+			e, err := parseMT940Line(f)
+			if err != nil {
+				st.fail(f.Line, "%v", err)
+				continue
+			}
+			e.Currency = st.Currency
+			pending = e
+		case "86":
+			if pending != nil {
+				pending.Description = strings.Join(strings.Fields(f.Value), " ")
+			}
+			flush()
+		default:
+			flush()
+		}
+	}
+	flush()
+	return st, nil
+}
+
+// mt940Fields делит сообщение на поля; строки без тега продолжают предыдущее поле.
+// Заголовки блоков {1:…}{2:…}{4: и конец сообщения "-}" пропускаются
+func mt940Fields(text string) []mt940Field {
+	var fields []mt940Field
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if j := strings.Index(line, "{4:"); j >= 0 {
+			line = line[j+3:]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "-" || trimmed == "-}" || strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+		if m := mt940TagPattern.FindStringSubmatch(line); m != nil {
+			fields = append(fields, mt940Field{Tag: m[1], Value: line[len(m[0]):], Line: i + 1})
+			continue
+		}
+		if len(fields) > 0 {
+			fields[len(fields)-1].Value += "\n" + line
+		}
+	}
+	return fields
+}
+
+func parseMT940Line(f mt940Field) (*statementEntry, error) {
+	first, supplementary, _ := strings.Cut(f.Value, "\n")
+	m := mt940LinePattern.FindStringSubmatch(strings.TrimSpace(first))
+	if m == nil {
+		return nil, fmt.Errorf("invalid :61: statement line %q", first)
+	}
+	date, err := time.Parse("060102", m[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid value date %q", m[1])
+	}
+	amount, err := statementAmount(m[5], true)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	// Сторно кредита уменьшает остаток, сторно дебета — увеличивает
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	e := &statementEntry{
+		Line:        f.Line,
+		OccurredAt:  date,
+		Amount:      amount,
+		Description: strings.Join(strings.Fields(supplementary), " "),
+	}
+	customerRef, bankRef := strings.TrimSpace(m[7]), strings.TrimSpace(m[8])
+	switch {
+	case bankRef != "":
+		e.Reference = bankRef
+	case customerRef != "" && customerRef != "NONREF":
+		e.Reference = customerRef
+	}
+	return e, nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMT940Statement(t *testing.T) {
+	msg := "{1:F01BANKRUMMAXXX0000000000}{2:O9400000000000BANKRUMMXXXX}{4:\r\n" +
+		":20:STMT001\r\n" +
+		":25:40817810000000000001\r\n" +
+		":28C:1/1\r\n" +
+		":60F:C250228RUB1000,00\r\n" +
+		":61:2503010301C85000,00NTRFNONREF//B1\r\n" +
+		":86:Зарплата\r\n" +
+		"за февраль\r\n" +
+		":61:250302D1200,5NMSCORDER-7\r\n" +
+		":86:Оплата покупки\r\n" +
+		":61:250303RD300,00NTRFNONREF\r\n" +
+		":61:BROKEN\r\n" +
+		":62F:C250303RUB84099,50\r\n" +
+		"-}"
+	st, err := parseMT940Statement([]byte(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Account != "40817810000000000001" || st.Currency != "RUB" {
+		t.Errorf("account = %s %s, want 40817810000000000001 RUB", st.Account, st.Currency)
+	}
+	checkEntries(t, st.Entries, []statementEntry{
+		{OccurredAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), Amount: 8500000, Currency: "RUB", Description: "Зарплата за февраль", Reference: "B1"},
+		{OccurredAt: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Amount: -120050, Currency: "RUB", Description: "Оплата покупки", Reference: "ORDER-7"},
+		{OccurredAt: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), Amount: 30000, Currency: "RUB"},
+	})
+	if len(st.Errors) != 1 || st.Errors[0].Line != 12 {
+		t.Errorf("line errors = %+v, want one on line 12", st.Errors)
+	}
+
+	if _, err := parseMT940Statement([]byte("date,amount\n")); err == nil {
+		t.Error("parseMT940Statement accepted a CSV file")
+	}
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// parseOFXStatement разбирает OFX 1.x (SGML, теги без закрывающих) и OFX 2.x (XML):
+// из каждого STMTTRN берутся TRNAMT, DTPOSTED, FITID, NAME и MEMO
+func parseOFXStatement(data []byte) (*parsedStatement, error) {
+	if !utf8.Valid(data) {
+		data = decodeWindows1251(data)
+	}
+	text := string(data)
+	if !strings.Contains(text, "<STMTTRN>") && !strings.Contains(text, "<OFX>") {
+		return nil, fmt.Errorf("not an OFX statement")
+	}
+
+	st := &parsedStatement{
+		Account:  ofxValue(text, "ACCTID"),
+		Currency: strings.ToUpper(ofxValue(text, "CURDEF")),
+	}
+
+	pos, counted, line := 0, 0, 1
+	for {
+		start := strings.Index(text[pos:], "<STMTTRN>")
+		if start < 0 {
+			break
+		}
+		start += pos
+		line += strings.Count(text[counted:start], "\n")
+		counted = start
+
+		end := strings.Index(text[start:], "</STMTTRN>")
+		if end < 0 {
+			// В SGML закрывающий тег может отсутствовать: операция кончается перед следующей или концом списка
+			end = len(text) - start
+			for _, next := range []string{"<STMTTRN>", "</BANKTRANLIST>"} {
+				if i := strings.Index(text[start+1:], next); i >= 0 && i+1 < end {
+					end = i + 1
+				}
+			}
+		}
+		block := text[start : start+end]
+		pos = start + end
+
+		e := statementEntry{Line: line, Reference: ofxValue(block, "FITID")}
+		var err error
+		if e.OccurredAt, err = parseOFXDate(ofxValue(block, "DTPOSTED")); err != nil {
+			st.fail(line, "%v", err)
+			continue
+		}
+		amount := ofxValue(block, "TRNAMT")
+		if e.Amount, err = statementAmount(amount, strings.Contains(amount, ",") && !strings.Contains(amount, ".")); err != nil {
+			st.fail(line, "invalid TRNAMT: %v", err)
+			continue
+		}
+		name, memo := ofxValue(block, "NAME"), ofxValue(block, "MEMO")
+		switch {
+		case name == "" || name == memo:
+			e.Description = memo
+		case memo == "":
+			e.Description = name
+		default:
+			e.Description = name + " " + memo
+		}
+		st.Entries = append(st.Entries, e)
+	}
+	return st, nil
+}
+
+// ofxValue — значение первого тега tag в s: до закрывающего тега, следующего тега или конца строки
+func ofxValue(s, tag string) string {
+	i := strings.Index(s, "<"+tag+">")
+	if i < 0 {
+		return ""
+	}
+	v := s[i+len(tag)+2:]
+	if j := strings.IndexAny(v, "<\r\n"); j >= 0 {
+		v = v[:j]
+	}
+	return strings.TrimSpace(html.UnescapeString(v))
+}
+
+// ofxDatePattern — YYYYMMDD[HHMMSS[.XXX]][[±часы[:зона]]]
+var ofxDatePattern = regexp.MustCompile(`^(\d{8})(\d{6})?(?:\.\d+)?(?:\[([+-]?\d+(?:\.\d+)?)(?::[^\]]*)?\])?$`)
+
+func parseOFXDate(s string) (time.Time, error) {
+	m := ofxDatePattern.FindStringSubmatch(s)
+	if m == nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q", s)
+	}
+	loc := time.UTC
+	if m[3] != "" {
+		hours, err := strconv.ParseFloat(m[3], 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid DTPOSTED %q: %w", s, err)
+		}
+		loc = time.FixedZone("", int(hours*3600))
+	}
+	layout, value := "20060102", m[1]
+	if m[2] != "" {
+		layout, value = "20060102150405", m[1]+m[2]
+	}
+	t, err := time.ParseInLocation(layout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid DTPOSTED %q: %w", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseOFXStatement(t *testing.T) {
+	sgml := `OFXHEADER:100
+DATA:OFXSGML
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>rub
+<BANKACCTFROM><ACCTID>40817810000000000001</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250301120000[+3:MSK]
+<TRNAMT>85000.00
+<FITID>F1
+<NAME>ООО Ромашка
+<MEMO>Зарплата за февраль
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250302
+<TRNAMT>-1200,50
+<FITID>F2
+<NAME>Магазин &amp; Ко
+<STMTTRN>
+<DTPOSTED>yesterday
+<TRNAMT>-1.00
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+	st, err := parseOFXStatement([]byte(sgml))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.Account != "40817810000000000001" || st.Currency != "RUB" {
+		t.Errorf("account = %s %s, want 40817810000000000001 RUB", st.Account, st.Currency)
+	}
+	checkEntries(t, st.Entries, []statementEntry{
+		{OccurredAt: time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), Amount: 8500000, Description: "ООО Ромашка Зарплата за февраль", Reference: "F1"},
+		{OccurredAt: time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC), Amount: -120050, Description: "Магазин & Ко", Reference: "F2"},
+	})
+	if len(st.Errors) != 1 {
+		t.Errorf("got %d line errors, want 1: %+v", len(st.Errors), st.Errors)
+	}
+
+	if _, err := parseOFXStatement([]byte("date,amount\n")); err == nil {
+		t.Error("parseOFXStatement accepted a CSV file")
+	}
+}
+
+func TestParseOFXDate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{"20250301", time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), false},
+		{"20250301153000", time.Date(2025, 3, 1, 15, 30, 0, 0, time.UTC), false},
+		{"20250301153000.000[-5:EST]", time.Date(2025, 3, 1, 20, 30, 0, 0, time.UTC), false},
+		{"20250301000000[+5.5]", time.Date(2025, 2, 28, 18, 30, 0, 0, time.UTC), false},
+		{"2025-03-01", time.Time{}, true},
+		{"20251301", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseOFXDate(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOFXDate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parseOFXDate(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
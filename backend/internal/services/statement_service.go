@@ -0,0 +1,240 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// StatementImport — загружаемая выписка
+type StatementImport struct {
+	UserID   int64
+	BankID   int16
+	Format   string // пусто — определить по содержимому и имени файла
+	FileName string
+	Data     []byte
+}
+
+// StatementService импортирует банковские выписки (CSV, OFX, camt.053, MT940) в transactions.
+// Операции, которые уже есть у пользователя в этом банке, пропускаются; импорт выписки
+// не меняет остатки счетов — они ведутся главной книгой. Операции выписки помечаются источником STATEMENT
+type StatementService interface {
+	Import(ctx context.Context, in *StatementImport) (*models.StatementImportReport, error)
+}
+
+type statementServiceImpl struct {
+	transactionRepo repos.TransactionRepository
+	accountRepo     repos.UserBankAccountRepository
+	bankRepo        repos.BankRepository
+	txManager       repos.TxManager
+	mappings        StatementCSVMappings
+}
+
+func NewStatementService(
+	transactionRepo repos.TransactionRepository,
+	accountRepo repos.UserBankAccountRepository,
+	bankRepo repos.BankRepository,
+	txManager repos.TxManager,
+	mappings StatementCSVMappings,
+) StatementService {
+	return &statementServiceImpl{
+		transactionRepo: transactionRepo,
+		accountRepo:     accountRepo,
+		bankRepo:        bankRepo,
+		txManager:       txManager,
+		mappings:        mappings,
+	}
+}
+
+func (s *statementServiceImpl) Import(ctx context.Context, in *StatementImport) (*models.StatementImportReport, error) {
+	bank, err := s.bankRepo.GetBankByID(ctx, in.BankID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: bank %d", apperrors.ErrNotFound, in.BankID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bank: %w", err)
+	}
+
+	format := strings.ToUpper(strings.TrimSpace(in.Format))
+	if format == "" {
+		if format = detectStatementFormat(in.FileName, in.Data); format == "" {
+			return nil, fmt.Errorf("%w: cannot detect statement format, pass it explicitly", apperrors.ErrBadRequest)
+		}
+	}
+	var mapping *StatementCSVMapping
+	if format == models.StatementCSV {
+		if mapping = s.mappings.For(bank.Code); mapping == nil {
+			return nil, fmt.Errorf("%w: no CSV mapping for bank %s", apperrors.ErrBadRequest, bank.Code)
+		}
+	}
+	st, err := parseStatement(format, in.Data, mapping)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", apperrors.ErrBadRequest, err)
+	}
+
+	currency, err := s.accountCurrency(ctx, in.UserID, in.BankID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.StatementImportReport{
+		UserID:  in.UserID,
+		BankID:  in.BankID,
+		Format:  format,
+		Account: st.Account,
+		Total:   len(st.Entries) + len(st.Errors),
+		Errors:  append([]models.StatementLineError{}, st.Errors...),
+	}
+	// Валюту операций проверяем до записи: у транзакций нет своей валюты, это валюта счёта
+	entries := st.Entries[:0:0]
+	for _, e := range st.Entries {
+		c := e.Currency
+		if c == "" {
+			c = st.Currency
+		}
+		if c != "" && c != currency {
+			report.Errors = append(report.Errors, models.StatementLineError{
+				Line:  e.Line,
+				Error: fmt.Sprintf("currency %s differs from account currency %s", c, currency),
+			})
+			continue
+		}
+		entries = append(entries, e)
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		imported, duplicates, err := importStatementEntries(ctx, s.transactionRepo, in.UserID, in.BankID, format, models.TransactionSourceStatement, entries)
+		report.Imported, report.Duplicates = imported, duplicates
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	report.Failed = len(report.Errors)
+	return report, nil
+}
+
+// accountCurrency — валюта счёта пользователя в банке; без счёта — рубли
+func (s *statementServiceImpl) accountCurrency(ctx context.Context, userID int64, bankID int16) (string, error) {
+	accounts, err := s.accountRepo.GetUserAccounts(ctx, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get accounts: %w", err)
+	}
+	for _, a := range accounts {
+		if a.BankID == bankID {
+			return a.Currency, nil
+		}
+	}
+	return models.CurrencyRUB, nil
+}
+
+// importStatementEntries записывает операции выписки или ленты банка, пропуская дубли. Дублем считается
+// операция с уже известным ключом (повторный импорт) либо совпавшая по дате и сумме с ещё не сопоставленной
+// транзакцией за тот же день — например, созданной самой платформой при переводе или выдаче кредита.
+// source — источник записываемых операций (models.TransactionSource*)
+func importStatementEntries(ctx context.Context, transactionRepo repos.TransactionRepository, userID int64, bankID int16, format, source string, entries []statementEntry) (int, int, error) {
+	if len(entries) == 0 {
+		return 0, 0, nil
+	}
+
+	// Ключи операций: одинаковые без идентификатора банка различаются номером повтора
+	ids := make([]string, len(entries))
+	repeats := make(map[string]int)
+	from, to := entries[0].OccurredAt, entries[0].OccurredAt
+	for i := range entries {
+		e := &entries[i]
+		key := statementExternalID(format, e, 0)
+		ids[i] = statementExternalID(format, e, repeats[key])
+		repeats[key]++
+		if e.OccurredAt.Before(from) {
+			from = e.OccurredAt
+		}
+		if e.OccurredAt.After(to) {
+			to = e.OccurredAt
+		}
+	}
+
+	existing, err := transactionRepo.ListBankTransactionsBetween(ctx, userID, bankID, dateOnly(from).AddDate(0, 0, -1), dateOnly(to).AddDate(0, 0, 2))
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list existing transactions: %w", err)
+	}
+	known := make(map[string]int, len(existing)) // external_id → индекс в existing
+	for i, t := range existing {
+		if t.ExternalID != nil {
+			known[*t.ExternalID] = i
+		}
+	}
+
+	duplicate := make([]bool, len(entries))
+	used := make([]bool, len(existing))
+	for i, id := range ids {
+		if j, ok := known[id]; ok {
+			duplicate[i], used[j] = true, true
+		}
+	}
+	unmatched := make(map[string][]int) // дата|сумма → индексы несопоставленных транзакций
+	for j, t := range existing {
+		if used[j] {
+			continue
+		}
+		k, err := parseMoney(t.Amount)
+		if err != nil {
+			return 0, 0, err
+		}
+		key := statementMatchKey(t.OccurredAt, k)
+		unmatched[key] = append(unmatched[key], j)
+	}
+
+	imported, duplicates := 0, 0
+	for i := range entries {
+		e := &entries[i]
+		if !duplicate[i] {
+			key := statementMatchKey(e.OccurredAt, e.Amount)
+			if js := unmatched[key]; len(js) > 0 {
+				unmatched[key] = js[1:]
+				duplicate[i] = true
+			}
+		}
+		if duplicate[i] {
+			duplicates++
+			continue
+		}
+
+		category := strings.ToLower(strings.TrimSpace(e.Category))
+		if category == "" {
+			category = models.TransactionCategoryUncategorized
+		}
+		t := &models.Transaction{
+			UserID:      userID,
+			BankID:      bankID,
+			OccurredAt:  e.OccurredAt,
+			Amount:      formatMoney(e.Amount),
+			Category:    category,
+			Description: e.Description,
+			ExternalID:  &ids[i],
+			Source:      source,
+		}
+		ok, err := transactionRepo.ImportTransaction(ctx, t)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to import transaction from line %d: %w", e.Line, err)
+		}
+		if ok {
+			imported++
+		} else {
+			duplicates++
+		}
+	}
+	return imported, duplicates, nil
+}
+
+// statementMatchKey — дата и сумма операции для сопоставления с существующими транзакциями
+func statementMatchKey(t time.Time, amount int64) string {
+	return t.UTC().Format("2006-01-02") + "|" + formatMoney(amount)
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/models"
+)
+
+// checkEntries сравнивает разобранные операции с ожидаемыми (без номеров строк)
+func checkEntries(t *testing.T, got, want []statementEntry) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !g.OccurredAt.Equal(w.OccurredAt) || g.Amount != w.Amount || g.Currency != w.Currency ||
+			g.Description != w.Description || g.Category != w.Category || g.Reference != w.Reference {
+			t.Errorf("entry %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func TestStatementAmount(t *testing.T) {
+	tests := []struct {
+		in           string
+		decimalComma bool
+		want         int64
+		wantErr      bool
+	}{
+		{"1234.56", false, 123456, false},
+		{"-1,234.56", false, -123456, false},
+		{"1 234,56", true, 123456, false},
+		{"1.234,5", true, 123450, false},
+		{"1 000,00-", true, -100000, false},
+		{"(250.00)", false, -25000, false},
+		{"1'000.00", false, 100000, false},
+		{"", false, 0, true},
+		{"abc", false, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := statementAmount(tt.in, tt.decimalComma)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("statementAmount error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("statementAmount(%q) = %d, want %d", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectStatementFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     string
+		want     string
+	}{
+		{"OFX header", "export.txt", "OFXHEADER:100\nDATA:OFXSGML\n<OFX>", models.StatementOFX},
+		{"OFX 2 XML", "export.xml", `<?xml version="1.0"?><OFX><BANKMSGSRSV1>`, models.StatementOFX},
+		{"camt.053 namespace", "export.xml", `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">`, models.StatementCAMT053},
+		{"MT940 fields", "export.txt", ":20:STMT\n:25:40817810\n:61:2503010301C100,00NTRFNONREF", models.StatementMT940},
+		{"CSV by extension", "export.CSV", "date,amount\n2025-03-01,100.00", models.StatementCSV},
+		{"MT940 by extension", "export.sta", "", models.StatementMT940},
+		{"unknown", "export.pdf", "%PDF-1.4", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectStatementFormat(tt.filename, []byte(tt.data)); got != tt.want {
+				t.Errorf("detectStatementFormat = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatementExternalID(t *testing.T) {
+	day := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	base := statementEntry{OccurredAt: day, Amount: -50000, Description: "Кофейня  на углу"}
+	spaced := base
+	spaced.Description = "Кофейня на углу"
+	otherTime := base
+	otherTime.OccurredAt = day.Add(5 * time.Hour)
+	withRef := base
+	withRef.Reference = "TX-42"
+
+	hash := statementExternalID(models.StatementCSV, &base, 0)
+	tests := []struct {
+		name  string
+		entry statementEntry
+		n     int
+		same  bool
+	}{
+		{"spaces in description are ignored", spaced, 0, true},
+		{"time of day is ignored", otherTime, 0, true},
+		{"repeat in the same file", base, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := statementExternalID(models.StatementCSV, &tt.entry, tt.n); (got == hash) != tt.same {
+				t.Errorf("statementExternalID = %s, base %s, want same %v", got, hash, tt.same)
+			}
+		})
+	}
+	if got := statementExternalID(models.StatementOFX, &withRef, 3); got != "ofx:TX-42" {
+		t.Errorf("statementExternalID with reference = %s, want ofx:TX-42", got)
+	}
+}
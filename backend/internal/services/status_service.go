@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// Допустимые переходы статусов кредита
+var loanTransitions = map[string][]string{
+	models.LoanStatusActive:    {models.LoanStatusOverdue, models.LoanStatusDefaulted, models.LoanStatusClosed},
+	models.LoanStatusOverdue:   {models.LoanStatusActive, models.LoanStatusDefaulted, models.LoanStatusClosed},
+	models.LoanStatusDefaulted: {models.LoanStatusActive, models.LoanStatusClosed},
+}
+
+// Допустимые переходы статусов заявки: решение по заявке принимается один раз,
+// черновик можно отправить в банки или отменить
+var applicationTransitions = map[string][]string{
+	models.ApplicationStatusDraft:   {models.ApplicationStatusPending, models.ApplicationStatusCancelled},
+	models.ApplicationStatusPending: {models.ApplicationStatusApproved, models.ApplicationStatusRejected, models.ApplicationStatusCancelled},
+}
+
+func canTransition(transitions map[string][]string, from, to string) bool {
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// StatusService меняет статусы кредитов и заявок по state machine и пишет историю.
+// actorID — пользователь, выполнивший изменение; nil для системных изменений.
+type StatusService interface {
+	// RecordLoanCreated / RecordApplicationCreated пишут начальную запись истории
+	RecordLoanCreated(ctx context.Context, loanID int64, status string, actorID *int64) error
+	RecordApplicationCreated(ctx context.Context, appID int64, status string, actorID *int64) error
+	TransitionLoan(ctx context.Context, loanID int64, to string, actorID *int64, reason string) error
+	TransitionApplication(ctx context.Context, appID int64, to string, actorID *int64, reason string) error
+	LoanHistory(ctx context.Context, loanID int64) ([]models.StatusHistoryEntry, error)
+	ApplicationHistory(ctx context.Context, appID int64) ([]models.StatusHistoryEntry, error)
+}
+
+type statusServiceImpl struct {
+	loanRepo    repos.LoanRepository
+	appRepo     repos.CreditApplicationRepository
+	historyRepo repos.StatusHistoryRepository
+	txManager   repos.TxManager
+}
+
+func NewStatusService(
+	loanRepo repos.LoanRepository,
+	appRepo repos.CreditApplicationRepository,
+	historyRepo repos.StatusHistoryRepository,
+	txManager repos.TxManager,
+) StatusService {
+	return &statusServiceImpl{
+		loanRepo:    loanRepo,
+		appRepo:     appRepo,
+		historyRepo: historyRepo,
+		txManager:   txManager,
+	}
+}
+
+func (s *statusServiceImpl) RecordLoanCreated(ctx context.Context, loanID int64, status string, actorID *int64) error {
+	return s.record(ctx, models.StatusEntityLoan, loanID, nil, status, actorID, "created")
+}
+
+func (s *statusServiceImpl) RecordApplicationCreated(ctx context.Context, appID int64, status string, actorID *int64) error {
+	reason := "submitted"
+	if status == models.ApplicationStatusDraft {
+		reason = "draft created"
+	}
+	return s.record(ctx, models.StatusEntityApplication, appID, nil, status, actorID, reason)
+}
+
+func (s *statusServiceImpl) TransitionLoan(ctx context.Context, loanID int64, to string, actorID *int64, reason string) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		loan, err := s.loanRepo.GetLoanByID(ctx, loanID)
+		if err != nil {
+			return fmt.Errorf("failed to get loan: %w", err)
+		}
+		if !canTransition(loanTransitions, loan.Status, to) {
+			return fmt.Errorf("%w: loan %d cannot change status from %s to %s", apperrors.ErrConflict, loanID, loan.Status, to)
+		}
+
+		// Условное обновление защищает от гонки двух одновременных переходов
+		ok, err := s.loanRepo.UpdateLoanStatus(ctx, loanID, loan.Status, to)
+		if err != nil {
+			return fmt.Errorf("failed to update loan status: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: loan %d status changed concurrently", apperrors.ErrConflict, loanID)
+		}
+		return s.record(ctx, models.StatusEntityLoan, loanID, &loan.Status, to, actorID, reason)
+	})
+}
+
+func (s *statusServiceImpl) TransitionApplication(ctx context.Context, appID int64, to string, actorID *int64, reason string) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		app, err := s.appRepo.GetApplicationByID(ctx, appID)
+		if err != nil {
+			return fmt.Errorf("failed to get application: %w", err)
+		}
+		if !canTransition(applicationTransitions, app.StatusCode, to) {
+			return fmt.Errorf("%w: application %d cannot change status from %s to %s", apperrors.ErrConflict, appID, app.StatusCode, to)
+		}
+
+		ok, err := s.appRepo.UpdateApplicationStatus(ctx, appID, app.StatusCode, to)
+		if err != nil {
+			return fmt.Errorf("failed to update application status: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("%w: application %d status changed concurrently", apperrors.ErrConflict, appID)
+		}
+		return s.record(ctx, models.StatusEntityApplication, appID, &app.StatusCode, to, actorID, reason)
+	})
+}
+
+func (s *statusServiceImpl) LoanHistory(ctx context.Context, loanID int64) ([]models.StatusHistoryEntry, error) {
+	return s.historyRepo.ListEntries(ctx, models.StatusEntityLoan, loanID)
+}
+
+func (s *statusServiceImpl) ApplicationHistory(ctx context.Context, appID int64) ([]models.StatusHistoryEntry, error) {
+	return s.historyRepo.ListEntries(ctx, models.StatusEntityApplication, appID)
+}
+
+func (s *statusServiceImpl) record(ctx context.Context, entityType string, entityID int64, from *string, to string, actorID *int64, reason string) error {
+	entry := &models.StatusHistoryEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedBy:  actorID,
+		Reason:     reason,
+	}
+	if err := s.historyRepo.CreateEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+	return nil
+}
@@ -5,10 +5,10 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/Arlandaren/easyfund/internal/config"
 	"github.com/Arlandaren/easyfund/internal/middleware"
 	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/golang-jwt/jwt/v5"
 )
 
 type TokenService interface {
@@ -35,6 +35,8 @@ func (s *tokenServiceImpl) GenerateToken(user *models.User) (string, error) {
 	claims := &middleware.CustomClaims{
 		UserID: user.UserID,
 		Email:  user.Email,
+		Role:   user.Role,
+		BankID: bankIDClaim(user.BankID),
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -94,7 +96,19 @@ func (s *tokenServiceImpl) RefreshToken(oldToken string) (string, error) {
 	user := &models.User{
 		UserID: claims.UserID,
 		Email:  claims.Email,
+		Role:   claims.Role,
+	}
+	if claims.BankID != 0 {
+		user.BankID = &claims.BankID
 	}
 
 	return s.GenerateToken(user)
-}
\ No newline at end of file
+}
+
+// bankIDClaim — банк сотрудника для claims, 0 для остальных ролей
+func bankIDClaim(bankID *int16) int16 {
+	if bankID == nil {
+		return 0
+	}
+	return *bankID
+}
@@ -0,0 +1,378 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apperrors "github.com/Arlandaren/easyfund/internal/erors"
+	"github.com/Arlandaren/easyfund/internal/logger"
+	"github.com/Arlandaren/easyfund/internal/models"
+	"github.com/Arlandaren/easyfund/internal/repos"
+)
+
+// TransferService — переводы между собственными счетами пользователя.
+// Каждый запуск списывает и зачисляет атомарно: две транзакции в выписках и запись главной книги
+type TransferService interface {
+	// CreateTransfer создаёт перевод; если первый запуск не позже текущего момента, выполняет его сразу,
+	// и при неудаче (например, нехватке средств) перевод не сохраняется
+	CreateTransfer(ctx context.Context, t *models.Transfer) (*models.Transfer, error)
+	// GetTransfer возвращает перевод вместе с историей запусков
+	GetTransfer(ctx context.Context, transferID int64) (*models.Transfer, error)
+	ListUserTransfers(ctx context.Context, userID int64) ([]models.Transfer, error)
+	// CancelTransfer отменяет будущие запуски; выполненные не откатываются
+	CancelTransfer(ctx context.Context, transferID int64) (*models.Transfer, error)
+	// ExecuteDue выполняет наступившие запуски отложенных и регулярных переводов; возвращает их число
+	ExecuteDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type transferServiceImpl struct {
+	transferRepo    repos.TransferRepository
+	accountRepo     repos.UserBankAccountRepository
+	transactionRepo repos.TransactionRepository
+	ledger          LedgerService
+	txManager       repos.TxManager
+}
+
+func NewTransferService(
+	transferRepo repos.TransferRepository,
+	accountRepo repos.UserBankAccountRepository,
+	transactionRepo repos.TransactionRepository,
+	ledger LedgerService,
+	txManager repos.TxManager,
+) TransferService {
+	return &transferServiceImpl{
+		transferRepo:    transferRepo,
+		accountRepo:     accountRepo,
+		transactionRepo: transactionRepo,
+		ledger:          ledger,
+		txManager:       txManager,
+	}
+}
+
+func (s *transferServiceImpl) CreateTransfer(ctx context.Context, t *models.Transfer) (*models.Transfer, error) {
+	now := time.Now()
+	if err := s.validateTransfer(ctx, t, now); err != nil {
+		return nil, err
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		t.Status = models.TransferStatusScheduled
+		next := t.StartsAt
+		t.NextRunAt = &next
+		if err := s.transferRepo.CreateTransfer(ctx, t); err != nil {
+			return fmt.Errorf("failed to create transfer: %w", err)
+		}
+		if t.StartsAt.After(now) {
+			return nil
+		}
+		failure, err := s.run(ctx, t, now)
+		if err != nil {
+			return err
+		}
+		return failure
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(ctx, t.TransferID)
+}
+
+// validateTransfer проверяет сумму, периодичность и счета: оба принадлежат пользователю,
+// различны и в одной валюте. Нормализует сумму и заполняет валюту и время первого запуска
+func (s *transferServiceImpl) validateTransfer(ctx context.Context, t *models.Transfer, now time.Time) error {
+	amount, err := parseMoney(t.Amount)
+	if err != nil || amount <= 0 {
+		return fmt.Errorf("%w: amount must be a positive sum", apperrors.ErrBadRequest)
+	}
+	t.Amount = formatMoney(amount)
+
+	if t.Recurrence == "" {
+		t.Recurrence = models.TransferOnce
+	}
+	switch t.Recurrence {
+	case models.TransferOnce:
+		if t.EndsAt != nil {
+			return fmt.Errorf("%w: ends_at is only allowed for recurring transfers", apperrors.ErrBadRequest)
+		}
+	case models.TransferDaily, models.TransferWeekly, models.TransferMonthly:
+	default:
+		return fmt.Errorf("%w: unknown recurrence %q", apperrors.ErrBadRequest, t.Recurrence)
+	}
+
+	if t.StartsAt.IsZero() || t.StartsAt.Before(now) {
+		t.StartsAt = now
+	}
+	if t.EndsAt != nil && t.EndsAt.Before(t.StartsAt) {
+		return fmt.Errorf("%w: ends_at is before the first run", apperrors.ErrBadRequest)
+	}
+
+	if t.FromAccountID == t.ToAccountID {
+		return fmt.Errorf("%w: source and destination accounts must differ", apperrors.ErrBadRequest)
+	}
+	from, err := s.userAccount(ctx, t.UserID, t.FromAccountID)
+	if err != nil {
+		return err
+	}
+	to, err := s.userAccount(ctx, t.UserID, t.ToAccountID)
+	if err != nil {
+		return err
+	}
+	if from.Currency != to.Currency {
+		return fmt.Errorf("%w: accounts are in different currencies (%s, %s)", apperrors.ErrBadRequest, from.Currency, to.Currency)
+	}
+	t.Currency = from.Currency
+	return nil
+}
+
+func (s *transferServiceImpl) userAccount(ctx context.Context, userID, accountID int64) (*models.UserBankAccount, error) {
+	acc, err := s.accountRepo.GetAccountByID(ctx, accountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: account %d", apperrors.ErrNotFound, accountID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account: %w", err)
+	}
+	if acc.UserID != userID {
+		return nil, fmt.Errorf("%w: account %d belongs to another user", apperrors.ErrForbidden, accountID)
+	}
+	return acc, nil
+}
+
+// run выполняет очередной запуск перевода и сдвигает расписание. Неудача запуска
+// (failure) сохраняется в истории и не откатывает транзакцию; err — ошибка сохранения
+func (s *transferServiceImpl) run(ctx context.Context, t *models.Transfer, now time.Time) (failure error, err error) {
+	exec := &models.TransferExecution{
+		TransferID:   t.TransferID,
+		Status:       models.ExecutionCompleted,
+		Amount:       t.Amount,
+		ScheduledFor: *t.NextRunAt,
+		ExecutedAt:   now,
+	}
+	failure = s.txManager.WithinSavepoint(ctx, func(ctx context.Context) error {
+		return s.move(ctx, t, exec)
+	})
+	t.LastError = nil
+	if failure != nil {
+		reason := failure.Error()
+		exec.Status = models.ExecutionFailed
+		exec.Error = &reason
+		exec.DebitTransactionID, exec.CreditTransactionID, exec.LedgerEntryID = nil, nil, nil
+		t.LastError = &reason
+	}
+	if err := s.transferRepo.CreateExecution(ctx, exec); err != nil {
+		return nil, fmt.Errorf("failed to save transfer execution: %w", err)
+	}
+
+	t.Runs++
+	next := transferRunAt(t.StartsAt, t.Recurrence, t.Runs)
+	switch {
+	case t.Recurrence == models.TransferOnce:
+		t.NextRunAt = nil
+		t.Status = models.TransferStatusCompleted
+		if failure != nil {
+			t.Status = models.TransferStatusFailed
+		}
+	case t.EndsAt != nil && next.After(*t.EndsAt):
+		t.NextRunAt = nil
+		t.Status = models.TransferStatusCompleted
+	default:
+		t.NextRunAt = &next
+	}
+	if err := s.transferRepo.UpdateTransferRun(ctx, t); err != nil {
+		return nil, fmt.Errorf("failed to update transfer: %w", err)
+	}
+	return failure, nil
+}
+
+// move списывает сумму перевода со счёта-источника и зачисляет на счёт-получатель.
+// Счета блокируются в порядке возрастания id, чтобы встречные переводы не взаимоблокировались
+func (s *transferServiceImpl) move(ctx context.Context, t *models.Transfer, exec *models.TransferExecution) error {
+	ids := []int64{t.FromAccountID, t.ToAccountID}
+	if ids[0] > ids[1] {
+		ids[0], ids[1] = ids[1], ids[0]
+	}
+	locked := make(map[int64]*models.UserBankAccount, 2)
+	for _, id := range ids {
+		acc, err := s.accountRepo.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return fmt.Errorf("failed to lock account %d: %w", id, err)
+		}
+		locked[id] = acc
+	}
+	from, to := locked[t.FromAccountID], locked[t.ToAccountID]
+
+	amount, err := parseMoney(t.Amount)
+	if err != nil {
+		return err
+	}
+	balance, err := parseMoney(from.Balance)
+	if err != nil {
+		return err
+	}
+	if balance < amount {
+		return fmt.Errorf("%w: insufficient funds on account %d: balance %s, transfer %s",
+			apperrors.ErrBadRequest, from.AccountID, formatMoney(balance), t.Amount)
+	}
+	if from.Currency != t.Currency || to.Currency != t.Currency {
+		return fmt.Errorf("%w: accounts are not in %s", apperrors.ErrBadRequest, t.Currency)
+	}
+
+	description := t.Description
+	if strings.TrimSpace(description) == "" {
+		description = fmt.Sprintf("Перевод %d со счёта %d на счёт %d", t.TransferID, from.AccountID, to.AccountID)
+	}
+	debit := &models.Transaction{
+		UserID:      t.UserID,
+		BankID:      from.BankID,
+		OccurredAt:  exec.ExecutedAt,
+		Amount:      formatMoney(-amount),
+		Category:    models.TransactionCategoryTransfer,
+		Description: description,
+	}
+	credit := &models.Transaction{
+		UserID:      t.UserID,
+		BankID:      to.BankID,
+		OccurredAt:  exec.ExecutedAt,
+		Amount:      t.Amount,
+		Category:    models.TransactionCategoryTransfer,
+		Description: description,
+	}
+	for _, tr := range []*models.Transaction{debit, credit} {
+		if err := s.transactionRepo.CreateTransaction(ctx, tr); err != nil {
+			return fmt.Errorf("failed to create transaction: %w", err)
+		}
+	}
+	entry, err := s.ledger.RecordTransfer(ctx, from, to, t.Amount, exec.ExecutedAt, description)
+	if err != nil {
+		return fmt.Errorf("failed to record transfer: %w", err)
+	}
+
+	exec.DebitTransactionID = &debit.TransactionID
+	exec.CreditTransactionID = &credit.TransactionID
+	exec.LedgerEntryID = &entry.EntryID
+	return nil
+}
+
+func (s *transferServiceImpl) GetTransfer(ctx context.Context, transferID int64) (*models.Transfer, error) {
+	t, err := s.transferRepo.GetTransfer(ctx, transferID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: transfer %d", apperrors.ErrNotFound, transferID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transfer: %w", err)
+	}
+	if t.Executions, err = s.transferRepo.ListExecutions(ctx, transferID); err != nil {
+		return nil, fmt.Errorf("failed to list transfer executions: %w", err)
+	}
+	return t, nil
+}
+
+func (s *transferServiceImpl) ListUserTransfers(ctx context.Context, userID int64) ([]models.Transfer, error) {
+	transfers, err := s.transferRepo.ListUserTransfers(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transfers: %w", err)
+	}
+	return transfers, nil
+}
+
+func (s *transferServiceImpl) CancelTransfer(ctx context.Context, transferID int64) (*models.Transfer, error) {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		t, err := s.transferRepo.GetTransferForUpdate(ctx, transferID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: transfer %d", apperrors.ErrNotFound, transferID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get transfer: %w", err)
+		}
+		if t.Status != models.TransferStatusScheduled {
+			return fmt.Errorf("%w: transfer %d is %s", apperrors.ErrConflict, transferID, t.Status)
+		}
+		t.Status = models.TransferStatusCancelled
+		t.NextRunAt = nil
+		if err := s.transferRepo.UpdateTransferRun(ctx, t); err != nil {
+			return fmt.Errorf("failed to cancel transfer: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetTransfer(ctx, transferID)
+}
+
+func (s *transferServiceImpl) ExecuteDue(ctx context.Context, now time.Time) (int, error) {
+	ids, err := s.transferRepo.ListDueTransferIDs(ctx, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list due transfers: %w", err)
+	}
+
+	n := 0
+	for _, id := range ids {
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			t, err := s.transferRepo.GetTransferForUpdate(ctx, id)
+			if err != nil {
+				return fmt.Errorf("failed to get transfer: %w", err)
+			}
+			// Перевод могли отменить или выполнить, пока он ждал блокировки
+			if t.Status != models.TransferStatusScheduled || t.NextRunAt == nil || t.NextRunAt.After(now) {
+				return nil
+			}
+			failure, err := s.run(ctx, t, now)
+			if err != nil {
+				return err
+			}
+			if failure != nil {
+				logger.Log.Warnf("Transfer %d run failed: %v", id, failure)
+			}
+			n++
+			return nil
+		})
+		if err != nil {
+			logger.Log.Errorf("Failed to execute transfer %d: %v", id, err)
+		}
+	}
+	return n, nil
+}
+
+// RunScheduledTransfers периодически выполняет наступившие отложенные и регулярные переводы.
+// Пропущенные запуски догоняются по одному за проход
+func RunScheduledTransfers(ctx context.Context, svc TransferService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := svc.ExecuteDue(ctx, time.Now())
+		if err != nil {
+			logger.Log.Errorf("Scheduled transfers failed: %v", err)
+		} else if n > 0 {
+			logger.Log.Infof("Executed %d scheduled transfers", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// transferRunAt — время n-го запуска (с нуля) перевода с первым запуском в start.
+// Ежемесячные запуски привязаны к дню первого; в коротких месяцах — последний день месяца
+func transferRunAt(start time.Time, recurrence string, n int) time.Time {
+	switch recurrence {
+	case models.TransferDaily:
+		return start.AddDate(0, 0, n)
+	case models.TransferWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case models.TransferMonthly:
+		y, m, d := start.Date()
+		sinceMidnight := start.Sub(time.Date(y, m, d, 0, 0, 0, 0, start.Location()))
+		return addMonths(start, n).Add(sinceMidnight)
+	default:
+		return start
+	}
+}
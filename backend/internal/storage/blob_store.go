@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+// ErrBlobNotFound — объекта с таким ключом нет в хранилище
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore хранит содержимое файлов по ключу; метаданные файлов живут в БД
+type BlobStore interface {
+	// Put сохраняет объект; size — точный размер содержимого r
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get открывает объект на чтение; вызывающий закрывает ReadCloser
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет объект; отсутствие объекта ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+// NewBlobStore создаёт хранилище по конфигурации
+func NewBlobStore(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case DriverLocal:
+		return NewLocalStore(cfg.LocalDir)
+	case DriverS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
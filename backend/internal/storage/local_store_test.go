@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreKeys(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := store.(*localStore)
+
+	tests := []struct {
+		key     string
+		want    string // путь относительно root
+		wantErr bool
+	}{
+		{"applications/1/doc.pdf", "applications/1/doc.pdf", false},
+		{"/applications/1/doc.pdf", "applications/1/doc.pdf", false},
+		{"applications//1/./doc.pdf", "applications/1/doc.pdf", false},
+		{"", "", true},
+		{"/", "", true},
+		{"../secret", "", true},
+		{"applications/../../secret", "", true},
+		{"applications/1/../../../etc/passwd", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, err := s.path(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("path(%q) error = %v, wantErr %v", tt.key, err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if want := filepath.Join(root, filepath.FromSlash(tt.want)); got != want {
+				t.Errorf("path(%q) = %s, want %s", tt.key, got, want)
+			}
+		})
+	}
+
+	// Ключ с выходом за root отклоняется всеми операциями
+	ctx := context.Background()
+	if err := store.Put(ctx, "../escape", strings.NewReader("x"), 1, ""); err == nil {
+		t.Error("Put accepted a key outside root")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(root), "escape")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file written outside root: %v", err)
+	}
+	if _, err := store.Get(ctx, "../escape"); err == nil {
+		t.Error("Get accepted a key outside root")
+	}
+	if err := store.Delete(ctx, "../escape"); err == nil {
+		t.Error("Delete accepted a key outside root")
+	}
+}
+
+func TestLocalStoreRoundTrip(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	data := bytes.Repeat([]byte("statement line\n"), 1000)
+	const key = "applications/7/statement.csv"
+
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "text/csv"); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sha256.Sum256(got) != sha256.Sum256(data) {
+		t.Error("content checksum changed after round trip")
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob: %v", err)
+	}
+}
+
+func TestLocalStoreSizeMismatch(t *testing.T) {
+	root := t.TempDir()
+	store, err := NewLocalStore(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	const key = "applications/7/passport.pdf"
+
+	// Объект, записанный ранее, не должен пострадать от неудачной перезаписи
+	if err := store.Put(ctx, key, strings.NewReader("original"), 8, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		body string
+		size int64
+	}{
+		{"truncated upload", "short", 10},
+		{"longer than declared", "much longer body", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := store.Put(ctx, key, strings.NewReader(tt.body), tt.size, ""); err == nil {
+				t.Fatal("Put accepted a body of the wrong size")
+			}
+			rc, err := store.Get(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, _ := io.ReadAll(rc)
+			rc.Close()
+			if string(got) != "original" {
+				t.Errorf("stored object = %q, want the original", got)
+			}
+		})
+	}
+
+	// Временные файлы неудачных загрузок удаляются
+	entries, err := os.ReadDir(filepath.Join(root, "applications", "7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the object", len(entries))
+	}
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+// s3Store работает с любым S3-совместимым хранилищем (AWS S3, MinIO, Yandex Object Storage)
+// через REST API с подписью AWS Signature V4
+type s3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    *sigV4Signer
+	client    *http.Client
+}
+
+func NewS3Store(cfg config.S3Config) (BlobStore, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 endpoint and bucket are required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &s3Store{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		signer:    &sigV4Signer{accessKey: cfg.AccessKey, secretKey: cfg.SecretKey, region: region, service: "s3"},
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+// objectURL — адрес объекта: path-style (endpoint/bucket/key) для MinIO,
+// virtual-hosted (bucket.endpoint/key) для AWS
+func (s *s3Store) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	if s.pathStyle {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	}
+	return &u
+}
+
+func (s *s3Store) do(ctx context.Context, method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+	}
+	// Тело не хэшируем: сервер проверяет целостность по Content-Length, контрольная сумма хранится в БД
+	s.signer.sign(req, unsignedPayload, time.Now())
+	return s.client.Do(req)
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, contentType)
+	if err != nil {
+		return fmt.Errorf("s3 put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return s3Error("put", key, resp)
+	}
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, "")
+	if err != nil {
+		return nil, fmt.Errorf("s3 get %s: %w", key, err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrBlobNotFound, key)
+	default:
+		defer resp.Body.Close()
+		return nil, s3Error("get", key, resp)
+	}
+}
+
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, "")
+	if err != nil {
+		return fmt.Errorf("s3 delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+	// S3 отвечает 204 и на удаление несуществующего объекта
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error("delete", key, resp)
+	}
+	return nil
+}
+
+func s3Error(op, key string, resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("s3 %s %s: status %d: %s", op, key, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Arlandaren/easyfund/internal/config"
+)
+
+// fakeS3 — минимальный S3 в памяти: PUT, GET и DELETE объектов в path-style адресации
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=minio/") ||
+		!strings.Contains(auth, "/ru-central1/s3/aws4_request") ||
+		r.Header.Get("X-Amz-Content-Sha256") != unsignedPayload {
+		http.Error(w, "SignatureDoesNotMatch", http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil || int64(len(data)) != r.ContentLength {
+			http.Error(w, "IncompleteBody", http.StatusBadRequest)
+			return
+		}
+		f.objects[r.URL.Path] = data
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3StoreRoundTrip(t *testing.T) {
+	backend := &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+	srv := httptest.NewServer(backend)
+	defer srv.Close()
+
+	store, err := NewS3Store(config.S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "documents",
+		Region:    "ru-central1",
+		AccessKey: "minio",
+		SecretKey: "minio-secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	const key = "applications/7/income statement.pdf"
+	data := []byte("%PDF-1.7 test document")
+
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	if got := backend.types["/documents/"+key]; got != "application/pdf" {
+		t.Errorf("stored content type = %q", got)
+	}
+
+	rc, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Errorf("Get = %q, want %q", got, data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing object: %v", err)
+	}
+}
+
+func TestS3StoreErrors(t *testing.T) {
+	srv := httptest.NewServer(&fakeS3{objects: map[string][]byte{}, types: map[string]string{}})
+	defer srv.Close()
+
+	// Чужой ключ доступа: сервер отвечает 403, ошибка содержит статус и ответ
+	store, err := NewS3Store(config.S3Config{Endpoint: srv.URL, Bucket: "documents", AccessKey: "other", PathStyle: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = store.Put(context.Background(), "a.txt", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "status 403") || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Put error = %v, want status 403", err)
+	}
+}
+
+func TestS3ObjectURL(t *testing.T) {
+	tests := []struct {
+		endpoint  string
+		pathStyle bool
+		want      string
+	}{
+		{"http://minio:9000", true, "http://minio:9000/documents/applications/1/a.pdf"},
+		{"https://s3.amazonaws.com", false, "https://documents.s3.amazonaws.com/applications/1/a.pdf"},
+		{"https://storage.example.com/prefix/", true, "https://storage.example.com/prefix/documents/applications/1/a.pdf"},
+	}
+	for _, tt := range tests {
+		store, err := NewS3Store(config.S3Config{Endpoint: tt.endpoint, Bucket: "documents", PathStyle: tt.pathStyle})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := store.(*s3Store).objectURL("applications/1/a.pdf").String(); got != tt.want {
+			t.Errorf("objectURL(%s, pathStyle=%v) = %s, want %s", tt.endpoint, tt.pathStyle, got, tt.want)
+		}
+	}
+}
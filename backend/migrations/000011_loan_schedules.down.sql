@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_loan_schedule_items_due_date;
+DROP TABLE IF EXISTS loan_schedule_items;
+
+ALTER TABLE loans
+  DROP CONSTRAINT IF EXISTS chk_loans_term_months,
+  DROP CONSTRAINT IF EXISTS chk_loans_repayment_type,
+  DROP COLUMN IF EXISTS repayment_type,
+  DROP COLUMN IF EXISTS term_months;
+
+COMMIT;
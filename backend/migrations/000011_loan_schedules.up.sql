@@ -0,0 +1,27 @@
+BEGIN;
+
+-- Срок и тип погашения кредита
+ALTER TABLE loans
+  ADD COLUMN term_months integer NOT NULL DEFAULT 12,
+  ADD COLUMN repayment_type text NOT NULL DEFAULT 'ANNUITY';
+
+ALTER TABLE loans
+  ADD CONSTRAINT chk_loans_repayment_type CHECK (repayment_type IN ('ANNUITY', 'DIFFERENTIATED')),
+  ADD CONSTRAINT chk_loans_term_months CHECK (term_months > 0);
+
+-- График платежей по каждой доле (split) кредита
+CREATE TABLE loan_schedule_items (
+  item_id bigserial PRIMARY KEY,
+  split_id bigint NOT NULL REFERENCES loan_splits(split_id) ON DELETE CASCADE,
+  installment_no integer NOT NULL,
+  due_date date NOT NULL,
+  principal numeric(18,2) NOT NULL,
+  interest numeric(18,2) NOT NULL,
+  total_payment numeric(18,2) NOT NULL,
+  remaining_balance numeric(18,2) NOT NULL,
+  UNIQUE(split_id, installment_no)
+);
+
+CREATE INDEX idx_loan_schedule_items_due_date ON loan_schedule_items(due_date);
+
+COMMIT;
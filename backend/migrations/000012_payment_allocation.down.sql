@@ -0,0 +1,17 @@
+BEGIN;
+
+ALTER TABLE banks DROP COLUMN IF EXISTS payment_priority;
+
+ALTER TABLE loan_splits DROP COLUMN IF EXISTS interest_rate;
+
+DROP INDEX IF EXISTS idx_loan_payments_loan;
+
+ALTER TABLE loan_payments
+  ALTER COLUMN method DROP DEFAULT,
+  DROP COLUMN IF EXISTS allocation_strategy,
+  DROP COLUMN IF EXISTS comment,
+  DROP COLUMN IF EXISTS user_id;
+
+ALTER TABLE loan_payments RENAME COLUMN total_amount TO amount;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- loan_payments приводим к модели LoanPayment (user_id, total_amount, comment)
+ALTER TABLE loan_payments RENAME COLUMN amount TO total_amount;
+
+ALTER TABLE loan_payments ADD COLUMN user_id bigint REFERENCES users(user_id) ON DELETE CASCADE;
+UPDATE loan_payments p SET user_id = l.user_id FROM loans l WHERE l.loan_id = p.loan_id;
+ALTER TABLE loan_payments ALTER COLUMN user_id SET NOT NULL;
+
+ALTER TABLE loan_payments
+  ADD COLUMN comment text NOT NULL DEFAULT '',
+  ADD COLUMN allocation_strategy text NOT NULL DEFAULT 'PRO_RATA',
+  ALTER COLUMN method SET DEFAULT 'manual';
+
+CREATE INDEX idx_loan_payments_loan ON loan_payments(loan_id);
+
+-- Ставка на уровне доли: банки консорциума могут кредитовать под разный процент
+ALTER TABLE loan_splits ADD COLUMN interest_rate numeric(5,2);
+UPDATE loan_splits ls SET interest_rate = l.interest_rate FROM loans l WHERE l.loan_id = ls.loan_id;
+ALTER TABLE loan_splits ALTER COLUMN interest_rate SET NOT NULL;
+
+-- Очерёдность банков при распределении платежа (меньше — раньше)
+ALTER TABLE banks ADD COLUMN payment_priority smallint NOT NULL DEFAULT 100;
+
+COMMIT;
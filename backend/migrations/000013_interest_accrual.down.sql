@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TABLE IF EXISTS loan_interest_accruals;
+
+ALTER TABLE loan_splits
+  DROP COLUMN IF EXISTS accrued_through,
+  DROP COLUMN IF EXISTS accrued_interest;
+
+ALTER TABLE loans
+  DROP CONSTRAINT IF EXISTS chk_loans_day_count_convention,
+  DROP COLUMN IF EXISTS day_count_convention;
+
+COMMIT;
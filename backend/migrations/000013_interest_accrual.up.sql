@@ -0,0 +1,31 @@
+BEGIN;
+
+-- Конвенция подсчёта дней для начисления процентов
+ALTER TABLE loans ADD COLUMN day_count_convention text NOT NULL DEFAULT 'ACT_365';
+ALTER TABLE loans
+  ADD CONSTRAINT chk_loans_day_count_convention CHECK (day_count_convention IN ('ACT_365', 'ACT_ACT', '30_360'));
+
+-- Начисленные, но не уплаченные проценты и дата, по которую они начислены (включительно)
+ALTER TABLE loan_splits
+  ADD COLUMN accrued_interest numeric(18,2) NOT NULL DEFAULT 0,
+  ADD COLUMN accrued_through date;
+
+UPDATE loan_splits ls SET accrued_through = (l.taken_at::date - 1)
+FROM loans l WHERE l.loan_id = ls.loan_id;
+
+ALTER TABLE loan_splits ALTER COLUMN accrued_through SET NOT NULL;
+
+-- Ежедневные начисления по долям
+CREATE TABLE loan_interest_accruals (
+  accrual_id bigserial PRIMARY KEY,
+  split_id bigint NOT NULL REFERENCES loan_splits(split_id) ON DELETE CASCADE,
+  accrual_date date NOT NULL,
+  principal_base numeric(18,2) NOT NULL,
+  interest_rate numeric(5,2) NOT NULL,
+  day_count_convention text NOT NULL,
+  amount numeric(18,2) NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE(split_id, accrual_date)
+);
+
+COMMIT;
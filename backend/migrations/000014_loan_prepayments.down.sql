@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE loan_payments
+  DROP CONSTRAINT IF EXISTS chk_loan_payments_payment_type,
+  DROP COLUMN IF EXISTS payment_type;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Тип платежа: плановый или досрочный
+ALTER TABLE loan_payments ADD COLUMN payment_type text NOT NULL DEFAULT 'REGULAR';
+ALTER TABLE loan_payments
+  ADD CONSTRAINT chk_loan_payments_payment_type CHECK (payment_type IN ('REGULAR', 'PREPAYMENT'));
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+DROP MATERIALIZED VIEW IF EXISTS total_debt_view;
+CREATE MATERIALIZED VIEW total_debt_view AS
+ SELECT l.user_id, SUM(ls.remaining_principal) as total_debt
+ FROM loans l
+ JOIN loan_splits ls ON l.loan_id = ls.loan_id
+ WHERE l.status = 'ACTIVE'
+ GROUP BY l.user_id;
+
+ALTER TABLE loans DROP CONSTRAINT IF EXISTS chk_loans_status;
+
+ALTER TABLE payment_allocations DROP COLUMN IF EXISTS penalty_paid;
+
+DELETE FROM loan_interest_accruals WHERE accrual_type = 'PENALTY';
+ALTER TABLE loan_interest_accruals
+  DROP CONSTRAINT IF EXISTS loan_interest_accruals_split_date_type_key,
+  DROP CONSTRAINT IF EXISTS chk_loan_interest_accruals_type,
+  DROP COLUMN IF EXISTS accrual_type,
+  ADD CONSTRAINT loan_interest_accruals_split_id_accrual_date_key UNIQUE (split_id, accrual_date);
+
+DROP INDEX IF EXISTS idx_loan_splits_bank_dpd;
+
+ALTER TABLE loan_splits
+  DROP COLUMN IF EXISTS penalty_interest,
+  DROP COLUMN IF EXISTS overdue_amount,
+  DROP COLUMN IF EXISTS days_past_due;
+
+COMMIT;
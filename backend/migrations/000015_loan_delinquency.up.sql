@@ -0,0 +1,32 @@
+BEGIN;
+
+-- Просрочка по долям: дни просрочки, просроченная сумма и начисленные, но не уплаченные пени
+ALTER TABLE loan_splits
+  ADD COLUMN days_past_due integer NOT NULL DEFAULT 0,
+  ADD COLUMN overdue_amount numeric(18,2) NOT NULL DEFAULT 0,
+  ADD COLUMN penalty_interest numeric(18,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_loan_splits_bank_dpd ON loan_splits(bank_id, days_past_due) WHERE days_past_due > 0;
+
+-- Пени начисляются в той же таблице, что и проценты
+ALTER TABLE loan_interest_accruals ADD COLUMN accrual_type text NOT NULL DEFAULT 'INTEREST';
+ALTER TABLE loan_interest_accruals
+  ADD CONSTRAINT chk_loan_interest_accruals_type CHECK (accrual_type IN ('INTEREST', 'PENALTY')),
+  DROP CONSTRAINT loan_interest_accruals_split_id_accrual_date_key,
+  ADD CONSTRAINT loan_interest_accruals_split_date_type_key UNIQUE (split_id, accrual_date, accrual_type);
+
+ALTER TABLE payment_allocations ADD COLUMN penalty_paid numeric(18,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE loans
+  ADD CONSTRAINT chk_loans_status CHECK (status IN ('ACTIVE', 'OVERDUE', 'DEFAULTED', 'CLOSED'));
+
+-- Просроченные кредиты тоже входят в общую задолженность
+DROP MATERIALIZED VIEW IF EXISTS total_debt_view;
+CREATE MATERIALIZED VIEW total_debt_view AS
+ SELECT l.user_id, SUM(ls.remaining_principal) as total_debt
+ FROM loans l
+ JOIN loan_splits ls ON l.loan_id = ls.loan_id
+ WHERE l.status IN ('ACTIVE', 'OVERDUE', 'DEFAULTED')
+ GROUP BY l.user_id;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- История смены статусов кредитов и заявок
+CREATE TABLE status_history (
+  history_id bigserial PRIMARY KEY,
+  entity_type text NOT NULL CHECK (entity_type IN ('LOAN', 'APPLICATION')),
+  entity_id bigint NOT NULL,
+  from_status text,
+  to_status text NOT NULL,
+  changed_by bigint REFERENCES users(user_id) ON DELETE SET NULL, -- NULL — системное изменение
+  reason text NOT NULL DEFAULT '',
+  changed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_status_history_entity ON status_history(entity_type, entity_id, changed_at);
+
+-- Начальные записи для уже существующих кредитов и заявок
+INSERT INTO status_history (entity_type, entity_id, from_status, to_status, reason, changed_at)
+SELECT 'LOAN', loan_id, NULL, status, 'backfill', created_at FROM loans;
+
+INSERT INTO status_history (entity_type, entity_id, from_status, to_status, reason, changed_at)
+SELECT 'APPLICATION', application_id, NULL, status_code, 'backfill', updated_at FROM credit_applications;
+
+COMMIT;
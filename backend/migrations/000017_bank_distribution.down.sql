@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS bank_product_appetite;
+DROP TABLE IF EXISTS bank_lending_settings;
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- Параметры участия банка в распределении синдицированного кредита
+CREATE TABLE bank_lending_settings (
+  bank_id smallint PRIMARY KEY REFERENCES banks(bank_id) ON DELETE CASCADE,
+  min_ticket numeric(18,2) NOT NULL DEFAULT 0,        -- минимальная доля в одном кредите
+  max_ticket numeric(18,2) NOT NULL,                  -- максимальная доля в одном кредите
+  exposure_limit numeric(18,2) NOT NULL,              -- лимит совокупного непогашенного долга по банку
+  target_share numeric(5,2) NOT NULL DEFAULT 0,       -- целевая доля в кредите, %
+  active boolean NOT NULL DEFAULT true,
+  CHECK (min_ticket >= 0 AND max_ticket >= min_ticket),
+  CHECK (target_share >= 0 AND target_share <= 100)
+);
+
+-- Аппетит банка к типам кредита: 0 — тип не кредитуется, 1 — обычный вес
+CREATE TABLE bank_product_appetite (
+  bank_id smallint NOT NULL REFERENCES banks(bank_id) ON DELETE CASCADE,
+  type_code text NOT NULL REFERENCES credit_application_types(type_code),
+  weight numeric(5,2) NOT NULL DEFAULT 1 CHECK (weight >= 0),
+  PRIMARY KEY (bank_id, type_code)
+);
+
+INSERT INTO bank_lending_settings (bank_id, min_ticket, max_ticket, exposure_limit, target_share) VALUES
+  (1, 10000, 3000000, 500000000, 25),
+  (2, 50000, 5000000, 800000000, 25),
+  (3, 50000, 10000000, 1000000000, 30),
+  (4, 10000, 2000000, 300000000, 15),
+  (5, 10000, 1000000, 100000000, 5);
+
+INSERT INTO bank_product_appetite (bank_id, type_code, weight)
+SELECT b.bank_id, t.type_code, 1 FROM banks b CROSS JOIN credit_application_types t;
+
+-- ОПТ-Банк не кредитует ипотеку, ТБанк предпочитает потребительские кредиты
+UPDATE bank_product_appetite SET weight = 0 WHERE bank_id = 5 AND type_code = 'MORTGAGE';
+UPDATE bank_product_appetite SET weight = 1.5 WHERE bank_id = 4 AND type_code = 'PERSONAL';
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE users
+  DROP CONSTRAINT IF EXISTS chk_users_role,
+  DROP COLUMN IF EXISTS role;
+
+ALTER TABLE bank_product_appetite DROP COLUMN IF EXISTS base_rate;
+
+ALTER TABLE bank_lending_settings
+  DROP CONSTRAINT IF EXISTS chk_lending_currencies,
+  DROP CONSTRAINT IF EXISTS chk_lending_risk_appetite,
+  DROP COLUMN IF EXISTS updated_at,
+  DROP COLUMN IF EXISTS risk_appetite,
+  DROP COLUMN IF EXISTS currencies;
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- Профиль кредитора: валюты, риск-аппетит и базовые ставки по продуктам
+ALTER TABLE bank_lending_settings
+  ADD COLUMN currencies text[] NOT NULL DEFAULT '{RUB}',
+  ADD COLUMN risk_appetite text NOT NULL DEFAULT 'MEDIUM',
+  ADD COLUMN updated_at timestamptz NOT NULL DEFAULT now(),
+  ADD CONSTRAINT chk_lending_risk_appetite CHECK (risk_appetite IN ('LOW','MEDIUM','HIGH')),
+  ADD CONSTRAINT chk_lending_currencies CHECK (cardinality(currencies) > 0);
+
+-- Базовая ставка банка по типу кредита, % годовых; NULL — банк не выдаёт этот продукт
+ALTER TABLE bank_product_appetite
+  ADD COLUMN base_rate numeric(5,2) CHECK (base_rate > 0);
+
+UPDATE bank_product_appetite SET base_rate = CASE type_code
+  WHEN 'PERSONAL' THEN 17.90
+  WHEN 'AUTO' THEN 14.50
+  WHEN 'MORTGAGE' THEN 11.90
+  ELSE 19.90
+END
+WHERE weight > 0;
+
+-- Отличия базовых ставок и риск-аппетита между банками
+UPDATE bank_product_appetite SET base_rate = base_rate - 0.5 WHERE bank_id = 3 AND base_rate IS NOT NULL;
+UPDATE bank_product_appetite SET base_rate = base_rate + 1.0 WHERE bank_id = 4 AND base_rate IS NOT NULL;
+UPDATE bank_lending_settings SET risk_appetite = 'LOW' WHERE bank_id IN (2, 3);
+UPDATE bank_lending_settings SET risk_appetite = 'HIGH' WHERE bank_id = 4;
+
+-- Роли пользователей: ADMIN управляет профилями кредиторов
+ALTER TABLE users
+  ADD COLUMN role text NOT NULL DEFAULT 'CLIENT',
+  ADD CONSTRAINT chk_users_role CHECK (role IN ('CLIENT','ADMIN'));
+
+-- Демо-администратор
+UPDATE users SET role = 'ADMIN' WHERE email = 'ivan@example.com';
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TABLE IF EXISTS application_offers;
+
+DELETE FROM users WHERE role = 'BANK';
+ALTER TABLE users
+  DROP CONSTRAINT IF EXISTS chk_users_bank_role,
+  DROP CONSTRAINT IF EXISTS chk_users_role,
+  DROP COLUMN IF EXISTS bank_id;
+ALTER TABLE users ADD CONSTRAINT chk_users_role CHECK (role IN ('CLIENT','ADMIN'));
+
+COMMIT;
@@ -0,0 +1,40 @@
+BEGIN;
+
+-- Сотрудники банков: роль BANK привязана к банку
+ALTER TABLE users DROP CONSTRAINT chk_users_role;
+ALTER TABLE users
+  ADD COLUMN bank_id smallint REFERENCES banks(bank_id),
+  ADD CONSTRAINT chk_users_role CHECK (role IN ('CLIENT','ADMIN','BANK')),
+  ADD CONSTRAINT chk_users_bank_role CHECK (role <> 'BANK' OR bank_id IS NOT NULL);
+
+-- Демо-сотрудники банков (пароль как у демо-клиентов)
+INSERT INTO users (full_name, email, phone, password_hash, role, bank_id)
+SELECT 'Officer ' || b.code, 'officer' || b.bank_id || '@easyfund.local', '+7999000000' || b.bank_id,
+       '$2y$10$Kjh5eRBHUIhi38Dc/9za9ORrAwDzn8qE0.ZgR1W5NsJDndjzk/mJ2', 'BANK', b.bank_id
+FROM banks b
+ON CONFLICT (email) DO NOTHING;
+
+-- Предложения банков по заявкам
+CREATE TABLE application_offers (
+  offer_id bigserial PRIMARY KEY,
+  application_id bigint NOT NULL REFERENCES credit_applications(application_id) ON DELETE CASCADE,
+  bank_id smallint NOT NULL REFERENCES banks(bank_id),
+  amount numeric(18,2) NOT NULL CHECK (amount > 0),
+  interest_rate numeric(5,2) NOT NULL CHECK (interest_rate >= 0),
+  term_months integer NOT NULL CHECK (term_months BETWEEN 1 AND 360),
+  repayment_type text NOT NULL DEFAULT 'ANNUITY' CHECK (repayment_type IN ('ANNUITY','DIFFERENTIATED')),
+  status text NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING','ACCEPTED','DECLINED','EXPIRED','WITHDRAWN')),
+  expires_at timestamptz NOT NULL,
+  created_by bigint REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  decided_at timestamptz,                             -- момент перехода из PENDING
+  decision_reason text
+);
+
+-- У банка не больше одного действующего предложения по заявке
+CREATE UNIQUE INDEX uq_application_offers_pending
+  ON application_offers(application_id, bank_id) WHERE status = 'PENDING';
+CREATE INDEX idx_application_offers_app ON application_offers(application_id);
+CREATE INDEX idx_application_offers_expiry ON application_offers(expires_at) WHERE status = 'PENDING';
+
+COMMIT;
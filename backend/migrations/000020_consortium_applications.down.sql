@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP TABLE IF EXISTS application_participants;
+
+-- Консорциумные заявки без банка не переживают откат
+DELETE FROM credit_applications WHERE bank_id IS NULL;
+
+ALTER TABLE credit_applications
+  DROP COLUMN IF EXISTS any_bank,
+  ALTER COLUMN bank_id SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,53 @@
+BEGIN;
+
+-- Консорциумная заявка адресована нескольким банкам (или любому банку),
+-- bank_id остаётся только у заявок в один банк
+ALTER TABLE credit_applications
+  ALTER COLUMN bank_id DROP NOT NULL,
+  ADD COLUMN any_bank boolean NOT NULL DEFAULT false;
+
+-- Участие банка в заявке
+CREATE TABLE application_participants (
+  application_id bigint NOT NULL REFERENCES credit_applications(application_id) ON DELETE CASCADE,
+  bank_id smallint NOT NULL REFERENCES banks(bank_id),
+  status text NOT NULL DEFAULT 'INVITED'
+    CHECK (status IN ('INVITED','OFFERED','DECLINED','ACCEPTED','CLOSED')),
+  decline_reason text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (application_id, bank_id)
+);
+
+CREATE INDEX idx_application_participants_bank ON application_participants(bank_id, status);
+
+-- Существующие заявки: единственный участник — банк заявки
+INSERT INTO application_participants (application_id, bank_id, status, created_at)
+SELECT a.application_id, a.bank_id,
+       CASE
+         WHEN EXISTS (SELECT 1 FROM application_offers o
+                      WHERE o.application_id = a.application_id AND o.bank_id = a.bank_id AND o.status = 'ACCEPTED') THEN 'ACCEPTED'
+         WHEN a.status_code <> 'PENDING' THEN 'CLOSED'
+         WHEN EXISTS (SELECT 1 FROM application_offers o
+                      WHERE o.application_id = a.application_id AND o.bank_id = a.bank_id AND o.status = 'PENDING') THEN 'OFFERED'
+         ELSE 'INVITED'
+       END,
+       a.submitted_at
+FROM credit_applications a
+WHERE a.bank_id IS NOT NULL;
+
+-- Банки, уже сделавшие предложения по чужим заявкам, тоже становятся участниками
+INSERT INTO application_participants (application_id, bank_id, status, created_at)
+SELECT o.application_id, o.bank_id,
+       CASE
+         WHEN bool_or(o.status = 'ACCEPTED') THEN 'ACCEPTED'
+         WHEN bool_or(o.status = 'PENDING') THEN 'OFFERED'
+         WHEN a.status_code = 'PENDING' THEN 'INVITED'
+         ELSE 'CLOSED'
+       END,
+       min(o.created_at)
+FROM application_offers o
+JOIN credit_applications a ON a.application_id = o.application_id
+GROUP BY o.application_id, o.bank_id, a.status_code
+ON CONFLICT (application_id, bank_id) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Неотправленные черновики не переживают откат
+DELETE FROM credit_applications WHERE status_code = 'DRAFT';
+DELETE FROM credit_application_statuses WHERE status_code = 'DRAFT';
+
+COMMIT;
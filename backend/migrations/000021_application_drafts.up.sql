@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Черновик заявки: заёмщик может править его до отправки в банки
+INSERT INTO credit_application_statuses(status_code, display_name) VALUES
+  ('DRAFT','Черновик')
+ON CONFLICT (status_code) DO NOTHING;
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- Документы к заявке: метаданные в БД, содержимое — в хранилище файлов по storage_key
+CREATE TABLE application_documents (
+  document_id bigserial PRIMARY KEY,
+  application_id bigint NOT NULL REFERENCES credit_applications(application_id) ON DELETE CASCADE,
+  doc_type text NOT NULL CHECK (doc_type IN ('INCOME_PROOF','PASSPORT','COLLATERAL','OTHER')),
+  file_name text NOT NULL,
+  content_type text NOT NULL,
+  size_bytes bigint NOT NULL CHECK (size_bytes > 0),
+  checksum_sha256 char(64) NOT NULL,
+  storage_key text NOT NULL UNIQUE,
+  uploaded_by bigint REFERENCES users(user_id) ON DELETE SET NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_application_documents_app ON application_documents(application_id);
+
+COMMIT;
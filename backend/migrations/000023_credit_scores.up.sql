@@ -0,0 +1,19 @@
+BEGIN;
+
+-- История внутренних кредитных оценок; application_id — предскоринг заявки при подаче
+CREATE TABLE credit_scores (
+  score_id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  application_id bigint REFERENCES credit_applications(application_id) ON DELETE SET NULL,
+  score integer NOT NULL CHECK (score BETWEEN 300 AND 850),
+  grade text NOT NULL CHECK (grade IN ('A','B','C','D','E')),
+  model_version text NOT NULL,
+  factors jsonb NOT NULL,   -- вклад факторов в оценку
+  inputs jsonb NOT NULL,    -- исходные данные на момент расчёта
+  computed_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_credit_scores_user ON credit_scores(user_id, computed_at DESC);
+CREATE INDEX idx_credit_scores_application ON credit_scores(application_id) WHERE application_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TABLE IF EXISTS application_affordability;
+
+ALTER TABLE credit_applications
+  DROP CONSTRAINT IF EXISTS chk_credit_applications_term_months,
+  DROP COLUMN IF EXISTS term_months;
+
+COMMIT;
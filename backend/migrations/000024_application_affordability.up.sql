@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Срок, на который заёмщик просит кредит
+ALTER TABLE credit_applications
+  ADD COLUMN term_months integer NOT NULL DEFAULT 12,
+  ADD CONSTRAINT chk_credit_applications_term_months CHECK (term_months BETWEEN 1 AND 360);
+
+-- Оценка долговой нагрузки (ПДН) при подаче заявки
+CREATE TABLE application_affordability (
+  application_id bigint PRIMARY KEY REFERENCES credit_applications(application_id) ON DELETE CASCADE,
+  status text NOT NULL CHECK (status IN ('OK','FLAGGED')),
+  monthly_income numeric(18,2) NOT NULL,
+  existing_payments numeric(18,2) NOT NULL,   -- среднемесячные платежи по действующим кредитам
+  new_payment numeric(18,2) NOT NULL,         -- оценка платежа по запрошенному кредиту
+  assumed_rate numeric(5,2) NOT NULL,
+  term_months integer NOT NULL,
+  pdn numeric(9,2),                           -- %, NULL — доход не подтверждён
+  flag_threshold numeric(5,2) NOT NULL,       -- пороги ПДН на момент проверки
+  reject_threshold numeric(5,2) NOT NULL,
+  reasons jsonb NOT NULL DEFAULT '[]',
+  checked_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_application_affordability_flagged ON application_affordability(status) WHERE status = 'FLAGGED';
+
+COMMIT;
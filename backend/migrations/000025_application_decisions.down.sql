@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS application_decisions;
+DROP TABLE IF EXISTS decision_rule_sets;
+
+COMMIT;
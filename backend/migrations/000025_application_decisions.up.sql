@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Версии наборов правил автоматического решения; checksum различает правки без смены version
+CREATE TABLE decision_rule_sets (
+  rule_set_id bigserial PRIMARY KEY,
+  version text NOT NULL,
+  checksum text NOT NULL UNIQUE,
+  rules jsonb NOT NULL,
+  loaded_at timestamptz NOT NULL DEFAULT now()
+);
+
+-- Автоматические решения по заявкам для аудита
+CREATE TABLE application_decisions (
+  decision_id bigserial PRIMARY KEY,
+  application_id bigint NOT NULL REFERENCES credit_applications(application_id) ON DELETE CASCADE,
+  rule_set_id bigint NOT NULL REFERENCES decision_rule_sets(rule_set_id),
+  outcome text NOT NULL CHECK (outcome IN ('APPROVE','REJECT','MANUAL_REVIEW')),
+  fired_rules jsonb NOT NULL DEFAULT '[]',
+  inputs jsonb NOT NULL,        -- данные заявки на момент решения
+  enforced boolean NOT NULL DEFAULT false,
+  decided_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_application_decisions_application ON application_decisions(application_id, decided_at DESC);
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS product_id;
+ALTER TABLE credit_applications DROP COLUMN IF EXISTS product_id;
+DROP TABLE IF EXISTS loan_product_banks;
+DROP TABLE IF EXISTS loan_products;
+
+COMMIT;
@@ -0,0 +1,54 @@
+BEGIN;
+
+-- Каталог кредитных продуктов
+CREATE TABLE loan_products (
+  product_id bigserial PRIMARY KEY,
+  code text NOT NULL UNIQUE,
+  name text NOT NULL,
+  description text NOT NULL DEFAULT '',
+  type_code text NOT NULL REFERENCES credit_application_types(type_code),
+  currency text NOT NULL DEFAULT 'RUB',
+  min_amount numeric(18,2) NOT NULL CHECK (min_amount > 0),
+  max_amount numeric(18,2) NOT NULL,
+  min_term_months integer NOT NULL CHECK (min_term_months >= 1),
+  max_term_months integer NOT NULL CHECK (max_term_months <= 360),
+  min_rate numeric(5,2) NOT NULL CHECK (min_rate >= 0),
+  max_rate numeric(5,2) NOT NULL,
+  origination_fee_percent numeric(5,2) NOT NULL DEFAULT 0 CHECK (origination_fee_percent >= 0),
+  origination_fee_fixed numeric(18,2) NOT NULL DEFAULT 0 CHECK (origination_fee_fixed >= 0),
+  monthly_fee numeric(18,2) NOT NULL DEFAULT 0 CHECK (monthly_fee >= 0),
+  grace_period_days integer NOT NULL DEFAULT 0 CHECK (grace_period_days >= 0),
+  active boolean NOT NULL DEFAULT true,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT chk_loan_products_amount CHECK (max_amount >= min_amount),
+  CONSTRAINT chk_loan_products_term CHECK (max_term_months >= min_term_months),
+  CONSTRAINT chk_loan_products_rate CHECK (max_rate >= min_rate)
+);
+
+-- Банки, выдающие продукт
+CREATE TABLE loan_product_banks (
+  product_id bigint NOT NULL REFERENCES loan_products(product_id) ON DELETE CASCADE,
+  bank_id smallint NOT NULL REFERENCES banks(bank_id) ON DELETE CASCADE,
+  PRIMARY KEY (product_id, bank_id)
+);
+
+-- Заявки и кредиты ссылаются на продукт; у существующих продукта нет
+ALTER TABLE credit_applications ADD COLUMN product_id bigint REFERENCES loan_products(product_id);
+ALTER TABLE loans ADD COLUMN product_id bigint REFERENCES loan_products(product_id);
+
+-- Базовые продукты по типам кредита; выдают банки, у которых есть ставка по типу
+INSERT INTO loan_products (code, name, description, type_code, min_amount, max_amount, min_term_months, max_term_months,
+                           min_rate, max_rate, origination_fee_percent, grace_period_days)
+VALUES
+  ('PERSONAL_STANDARD', 'Потребительский кредит', 'Кредит наличными на любые цели', 'PERSONAL', 30000, 5000000, 6, 84, 9.90, 29.90, 0, 3),
+  ('AUTO_STANDARD', 'Автокредит', 'Кредит на покупку автомобиля', 'AUTO', 100000, 10000000, 12, 96, 7.90, 24.90, 1.00, 3),
+  ('MORTGAGE_STANDARD', 'Ипотека', 'Кредит на покупку жилья', 'MORTGAGE', 500000, 50000000, 36, 360, 5.90, 19.90, 0, 5),
+  ('OTHER_STANDARD', 'Кредит на другие цели', 'Прочие кредиты', 'OTHER', 10000, 1000000, 3, 60, 9.90, 34.90, 0, 0);
+
+INSERT INTO loan_product_banks (product_id, bank_id)
+SELECT p.product_id, a.bank_id
+FROM loan_products p
+JOIN bank_product_appetite a ON a.type_code = p.type_code AND a.base_rate IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+ALTER TABLE application_offers DROP COLUMN IF EXISTS psk_amount, DROP COLUMN IF EXISTS psk;
+ALTER TABLE loan_splits DROP COLUMN IF EXISTS psk_amount, DROP COLUMN IF EXISTS psk;
+ALTER TABLE loans
+  DROP COLUMN IF EXISTS psk_amount,
+  DROP COLUMN IF EXISTS psk,
+  DROP COLUMN IF EXISTS monthly_fee,
+  DROP COLUMN IF EXISTS insurance_premium,
+  DROP COLUMN IF EXISTS origination_fee;
+ALTER TABLE loan_products DROP COLUMN IF EXISTS insurance_percent;
+
+COMMIT;
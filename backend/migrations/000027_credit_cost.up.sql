@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Страховая премия продукта: % от суммы кредита, уплачивается при выдаче
+ALTER TABLE loan_products
+  ADD COLUMN insurance_percent numeric(5,2) NOT NULL DEFAULT 0 CHECK (insurance_percent >= 0);
+
+-- Комиссии и страховка, зафиксированные при выдаче, и полная стоимость кредита (ПСК)
+ALTER TABLE loans
+  ADD COLUMN origination_fee numeric(18,2) NOT NULL DEFAULT 0,
+  ADD COLUMN insurance_premium numeric(18,2) NOT NULL DEFAULT 0,
+  ADD COLUMN monthly_fee numeric(18,2) NOT NULL DEFAULT 0,
+  ADD COLUMN psk numeric(7,3),
+  ADD COLUMN psk_amount numeric(18,2);
+
+ALTER TABLE loan_splits
+  ADD COLUMN psk numeric(7,3),
+  ADD COLUMN psk_amount numeric(18,2);
+
+ALTER TABLE application_offers
+  ADD COLUMN psk numeric(7,3),
+  ADD COLUMN psk_amount numeric(18,2);
+
+COMMIT;
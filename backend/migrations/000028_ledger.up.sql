@@ -0,0 +1,98 @@
+BEGIN;
+
+-- Счета главной книги (двойная запись). Сторона normal_balance задаёт знак остатка:
+-- у DEBIT-счетов остаток = Σ проводок, у CREDIT-счетов — минус Σ проводок
+CREATE TABLE ledger_accounts (
+  ledger_account_id bigserial PRIMARY KEY,
+  code text NOT NULL UNIQUE, -- KIND:bank_id для счетов банка, KIND:account_id / KIND:split_id для счетов клиента и долей
+  kind text NOT NULL CHECK (kind IN ('CUSTOMER_FUNDS', 'LOAN_PRINCIPAL', 'BANK_SETTLEMENT',
+                                     'INTEREST_INCOME', 'PENALTY_INCOME', 'FEE_INCOME', 'INSURANCE_PAYABLE')),
+  normal_balance text NOT NULL CHECK (normal_balance IN ('DEBIT', 'CREDIT')),
+  bank_id smallint NOT NULL REFERENCES banks(bank_id),
+  account_id bigint REFERENCES user_bank_accounts(account_id), -- CUSTOMER_FUNDS
+  split_id bigint REFERENCES loan_splits(split_id),            -- LOAN_PRINCIPAL
+  currency text NOT NULL DEFAULT 'RUB',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT chk_ledger_accounts_ref CHECK (
+    (kind = 'CUSTOMER_FUNDS') = (account_id IS NOT NULL) AND (kind = 'LOAN_PRINCIPAL') = (split_id IS NOT NULL)
+  )
+);
+
+CREATE UNIQUE INDEX idx_ledger_accounts_account ON ledger_accounts(account_id) WHERE account_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_ledger_accounts_split ON ledger_accounts(split_id) WHERE split_id IS NOT NULL;
+
+-- Проводка (журнальная запись) — одна хозяйственная операция
+CREATE TABLE ledger_entries (
+  entry_id bigserial PRIMARY KEY,
+  entry_type text NOT NULL CHECK (entry_type IN ('OPENING', 'DISBURSEMENT', 'REPAYMENT', 'FEE', 'TRANSFER')),
+  description text NOT NULL DEFAULT '',
+  loan_id bigint REFERENCES loans(loan_id),
+  payment_id bigint REFERENCES loan_payments(payment_id),
+  occurred_at timestamptz NOT NULL DEFAULT now(),
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_ledger_entries_loan ON ledger_entries(loan_id) WHERE loan_id IS NOT NULL;
+
+-- Движения по счетам: amount > 0 — дебет, amount < 0 — кредит
+CREATE TABLE ledger_postings (
+  posting_id bigserial PRIMARY KEY,
+  entry_id bigint NOT NULL REFERENCES ledger_entries(entry_id),
+  ledger_account_id bigint NOT NULL REFERENCES ledger_accounts(ledger_account_id),
+  amount numeric(18,2) NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX idx_ledger_postings_entry ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account ON ledger_postings(ledger_account_id);
+
+-- Дебет равен кредиту в каждой записи; проверяется при фиксации транзакции,
+-- когда все движения записи уже вставлены
+CREATE OR REPLACE FUNCTION ledger_check_entry_balanced() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  IF (SELECT SUM(amount) FROM ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+    RAISE EXCEPTION 'ledger entry % is not balanced', NEW.entry_id;
+  END IF;
+  RETURN NULL;
+END $$;
+
+CREATE CONSTRAINT TRIGGER trg_ledger_postings_balanced
+AFTER INSERT OR UPDATE ON ledger_postings
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW EXECUTE FUNCTION ledger_check_entry_balanced();
+
+-- Счета для уже существующих остатков клиентов и долей кредитов
+INSERT INTO ledger_accounts (code, kind, normal_balance, bank_id)
+SELECT 'BANK_SETTLEMENT:' || bank_id, 'BANK_SETTLEMENT', 'DEBIT', bank_id FROM banks;
+
+INSERT INTO ledger_accounts (code, kind, normal_balance, bank_id, account_id, currency)
+SELECT 'CUSTOMER_FUNDS:' || account_id, 'CUSTOMER_FUNDS', 'CREDIT', bank_id, account_id, currency
+FROM user_bank_accounts;
+
+INSERT INTO ledger_accounts (code, kind, normal_balance, bank_id, split_id)
+SELECT 'LOAN_PRINCIPAL:' || split_id, 'LOAN_PRINCIPAL', 'DEBIT', bank_id, split_id
+FROM loan_splits;
+
+-- Входящие остатки: средства клиентов и непогашенный основной долг против расчётов с банком
+WITH e AS (
+  INSERT INTO ledger_entries (entry_type, description)
+  VALUES ('OPENING', 'Входящие остатки при запуске главной книги')
+  RETURNING entry_id
+), opening AS (
+  SELECT a.ledger_account_id, a.bank_id, -u.balance AS amount
+  FROM user_bank_accounts u JOIN ledger_accounts a ON a.account_id = u.account_id
+  UNION ALL
+  SELECT a.ledger_account_id, a.bank_id, s.remaining_principal
+  FROM loan_splits s JOIN ledger_accounts a ON a.split_id = s.split_id
+)
+INSERT INTO ledger_postings (entry_id, ledger_account_id, amount)
+SELECT e.entry_id, x.ledger_account_id, x.amount
+FROM e, (
+  SELECT ledger_account_id, amount FROM opening
+  UNION ALL
+  SELECT a.ledger_account_id, -SUM(o.amount)
+  FROM opening o JOIN ledger_accounts a ON a.code = 'BANK_SETTLEMENT:' || o.bank_id
+  GROUP BY a.ledger_account_id
+) x
+WHERE x.amount <> 0;
+
+COMMIT;
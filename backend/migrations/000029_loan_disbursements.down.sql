@@ -0,0 +1,15 @@
+BEGIN;
+
+-- Проводки PAYOUT остаются в главной книге: допустимый тип записи не сужаем
+
+DROP INDEX IF EXISTS idx_loan_splits_disbursement;
+
+ALTER TABLE loan_splits
+  DROP COLUMN IF EXISTS transaction_id,
+  DROP COLUMN IF EXISTS account_id,
+  DROP COLUMN IF EXISTS disbursed_at,
+  DROP COLUMN IF EXISTS disbursement_error,
+  DROP COLUMN IF EXISTS disbursement_attempts,
+  DROP COLUMN IF EXISTS disbursement_status;
+
+COMMIT;
@@ -0,0 +1,24 @@
+BEGIN;
+
+-- Зачисление долей кредита на счета заёмщика: статус по каждой доле,
+-- неудачные попытки видны и повторяются
+ALTER TABLE loan_splits
+  ADD COLUMN disbursement_status text NOT NULL DEFAULT 'PENDING'
+    CHECK (disbursement_status IN ('PENDING', 'DISBURSED', 'FAILED')),
+  ADD COLUMN disbursement_attempts integer NOT NULL DEFAULT 0,
+  ADD COLUMN disbursement_error text,
+  ADD COLUMN disbursed_at timestamptz,
+  ADD COLUMN account_id bigint REFERENCES user_bank_accounts(account_id),
+  ADD COLUMN transaction_id bigint REFERENCES transactions(transaction_id);
+
+-- Кредиты, выданные до появления зачисления, считаем выплаченными вне платформы
+UPDATE loan_splits SET disbursement_status = 'DISBURSED';
+
+CREATE INDEX idx_loan_splits_disbursement ON loan_splits(disbursement_status) WHERE disbursement_status <> 'DISBURSED';
+
+-- Зачисление выданных средств с расчётов банка на счёт клиента
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_entry_type_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_entry_type_check
+  CHECK (entry_type IN ('OPENING', 'DISBURSEMENT', 'PAYOUT', 'REPAYMENT', 'FEE', 'TRANSFER'));
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Транзакции и проводки выполненных переводов остаются: остатки счетов выведены из них
+
+DROP TABLE IF EXISTS transfer_executions;
+DROP TABLE IF EXISTS transfers;
+
+COMMIT;
@@ -0,0 +1,45 @@
+BEGIN;
+
+-- Переводы между собственными счетами пользователя: разовые, отложенные и регулярные
+CREATE TABLE transfers (
+  transfer_id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  from_account_id bigint NOT NULL REFERENCES user_bank_accounts(account_id),
+  to_account_id bigint NOT NULL REFERENCES user_bank_accounts(account_id),
+  amount numeric(18,2) NOT NULL CHECK (amount > 0),
+  currency text NOT NULL DEFAULT 'RUB',
+  description text NOT NULL DEFAULT '',
+  status text NOT NULL DEFAULT 'SCHEDULED'
+    CHECK (status IN ('SCHEDULED', 'COMPLETED', 'FAILED', 'CANCELLED')),
+  recurrence text NOT NULL DEFAULT 'ONCE'
+    CHECK (recurrence IN ('ONCE', 'DAILY', 'WEEKLY', 'MONTHLY')),
+  starts_at timestamptz NOT NULL,
+  ends_at timestamptz,
+  next_run_at timestamptz, -- NULL, когда запусков больше не будет
+  runs integer NOT NULL DEFAULT 0,
+  last_error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT chk_transfers_accounts CHECK (from_account_id <> to_account_id),
+  CONSTRAINT chk_transfers_period CHECK (ends_at IS NULL OR ends_at >= starts_at)
+);
+
+CREATE INDEX idx_transfers_user ON transfers(user_id);
+CREATE INDEX idx_transfers_due ON transfers(next_run_at) WHERE status = 'SCHEDULED';
+
+-- Запуски перевода: парные транзакции по счетам и запись главной книги либо причина отказа
+CREATE TABLE transfer_executions (
+  execution_id bigserial PRIMARY KEY,
+  transfer_id bigint NOT NULL REFERENCES transfers(transfer_id) ON DELETE CASCADE,
+  status text NOT NULL CHECK (status IN ('COMPLETED', 'FAILED')),
+  amount numeric(18,2) NOT NULL,
+  scheduled_for timestamptz NOT NULL,
+  executed_at timestamptz NOT NULL DEFAULT now(),
+  debit_transaction_id bigint REFERENCES transactions(transaction_id),
+  credit_transaction_id bigint REFERENCES transactions(transaction_id),
+  ledger_entry_id bigint REFERENCES ledger_entries(entry_id),
+  error text,
+  UNIQUE (transfer_id, scheduled_for)
+);
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_transactions_user_bank_time;
+DROP INDEX IF EXISTS uq_transactions_external_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS external_id;
+
+COMMIT;
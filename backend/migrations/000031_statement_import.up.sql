@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Ключ операции из импортированной выписки: повторный импорт того же файла не создаёт дублей
+ALTER TABLE transactions ADD COLUMN external_id text;
+
+CREATE UNIQUE INDEX uq_transactions_external_id ON transactions(user_id, bank_id, external_id)
+  WHERE external_id IS NOT NULL;
+
+CREATE INDEX idx_transactions_user_bank_time ON transactions(user_id, bank_id, occurred_at);
+
+COMMIT;
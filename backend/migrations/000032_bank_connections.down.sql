@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Проводки BANK_SYNC остаются в главной книге: допустимый тип записи не сужаем
+
+DROP TABLE IF EXISTS bank_connections;
+
+COMMIT;
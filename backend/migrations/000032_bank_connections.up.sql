@@ -0,0 +1,27 @@
+BEGIN;
+
+-- Подключения к API банков: клиент банка, выбранный счёт и курсор синхронизации операций
+CREATE TABLE bank_connections (
+  connection_id bigserial PRIMARY KEY,
+  user_id bigint NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+  bank_id smallint NOT NULL REFERENCES banks(bank_id),
+  customer_id text NOT NULL,              -- идентификатор клиента в API банка
+  external_account_id text,               -- счёт в банке, который отражается в user_bank_accounts
+  sync_cursor text NOT NULL DEFAULT '',    -- курсор ленты операций после последней синхронизации
+  bank_balance numeric(18,2),              -- остаток по данным банка на last_synced_at
+  status text NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'DISCONNECTED')),
+  last_synced_at timestamptz,
+  last_error text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  UNIQUE (user_id, bank_id)
+);
+
+CREATE INDEX idx_bank_connections_active ON bank_connections(last_synced_at NULLS FIRST) WHERE status = 'ACTIVE';
+
+-- Выравнивание остатка счёта по данным банка
+ALTER TABLE ledger_entries DROP CONSTRAINT ledger_entries_entry_type_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_entry_type_check
+  CHECK (entry_type IN ('OPENING', 'DISBURSEMENT', 'PAYOUT', 'REPAYMENT', 'FEE', 'TRANSFER', 'BANK_SYNC'));
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE loans DROP COLUMN IF EXISTS grace_period_days;
+
+COMMIT;
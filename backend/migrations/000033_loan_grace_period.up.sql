@@ -0,0 +1,7 @@
+BEGIN;
+
+-- Льготный период продукта, зафиксированный при выдаче: дней после даты платежа без пени
+ALTER TABLE loans
+  ADD COLUMN grace_period_days integer NOT NULL DEFAULT 0 CHECK (grace_period_days >= 0);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE payment_allocations DROP COLUMN IF EXISTS fee_paid;
+ALTER TABLE loan_schedule_items DROP COLUMN IF EXISTS fee;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- Плата за обслуживание: начисляется вместе с каждым плановым платежом доли
+ALTER TABLE loan_schedule_items ADD COLUMN fee numeric(18,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE payment_allocations ADD COLUMN fee_paid numeric(18,2) NOT NULL DEFAULT 0;
+
+COMMIT;
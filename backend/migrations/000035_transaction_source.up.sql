@@ -0,0 +1,11 @@
+BEGIN;
+
+-- Источник операции: создана платформой, получена из API банка или загружена клиентом в выписке
+ALTER TABLE transactions
+  ADD COLUMN source text NOT NULL DEFAULT 'PLATFORM'
+  CHECK (source IN ('PLATFORM', 'BANK_API', 'STATEMENT'));
+
+UPDATE transactions SET source = 'BANK_API' WHERE external_id LIKE 'api:%';
+UPDATE transactions SET source = 'STATEMENT' WHERE external_id IS NOT NULL AND external_id NOT LIKE 'api:%';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE loan_splits
+  DROP COLUMN IF EXISTS penalty_remainder,
+  DROP COLUMN IF EXISTS interest_remainder;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Доли копейки, не вошедшие в округлённые дневные начисления: переносятся на следующий день,
+-- чтобы сумма начислений за период не теряла проценты на округлении
+ALTER TABLE loan_splits
+  ADD COLUMN interest_remainder numeric(12,6) NOT NULL DEFAULT 0,
+  ADD COLUMN penalty_remainder numeric(12,6) NOT NULL DEFAULT 0;
+
+COMMIT;